@@ -0,0 +1,88 @@
+package taskmanager
+
+import (
+	"slices"
+	"time"
+)
+
+// ActivityKind tells what an activity entry records
+type ActivityKind int
+
+const (
+	// ActivityCreated is the entry for the task being added
+	ActivityCreated ActivityKind = iota + 1
+	// ActivityStatusChange is a change of the task's status
+	ActivityStatusChange
+	// ActivityFieldChange is a change of any other tracked field
+	ActivityFieldChange
+	// ActivityComment is a comment on the task
+	ActivityComment
+	// ActivityCompletionNote is the note given when the task was completed
+	ActivityCompletionNote
+)
+
+// String returns a human-readable name for the activity kind
+func (k ActivityKind) String() string {
+	switch k {
+	case ActivityCreated:
+		return "created"
+	case ActivityStatusChange:
+		return "status change"
+	case ActivityFieldChange:
+		return "field change"
+	case ActivityComment:
+		return "comment"
+	case ActivityCompletionNote:
+		return "completion note"
+	default:
+		return "unknown"
+	}
+}
+
+// ActivityEntry is one item of a task's activity feed. Field, OldValue and
+// NewValue are set for changes, Comment for comments.
+type ActivityEntry struct {
+	Time     time.Time
+	Kind     ActivityKind
+	Field    string
+	OldValue string
+	NewValue string
+	Comment  *Comment
+}
+
+// GetActivity returns the history and comments of a task as one feed,
+// oldest first
+func (tm *TaskManager) GetActivity(id int) ([]ActivityEntry, error) {
+	task, err := tm.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := make([]ActivityEntry, 0, len(task.History)+len(task.Comments))
+	for _, h := range task.History {
+		entry := ActivityEntry{
+			Time:     h.Time,
+			Kind:     ActivityFieldChange,
+			Field:    h.Field,
+			OldValue: h.OldValue,
+			NewValue: h.NewValue,
+		}
+		switch h.Field {
+		case HistoryCreated:
+			entry.Kind = ActivityCreated
+		case "status":
+			entry.Kind = ActivityStatusChange
+		case HistoryCompletionNote:
+			entry.Kind = ActivityCompletionNote
+		}
+		feed = append(feed, entry)
+	}
+	for _, c := range task.Comments {
+		comment := c
+		feed = append(feed, ActivityEntry{Time: c.CreatedAt, Kind: ActivityComment, Comment: &comment})
+	}
+	slices.SortStableFunc(feed, func(a, b ActivityEntry) int {
+		return a.Time.Compare(b.Time)
+	})
+	return feed, nil
+}
@@ -0,0 +1,58 @@
+package taskmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetActivity(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Report")
+
+	now = now.Add(time.Minute)
+	if _, err := tm.AddComment(task.ID, "alice", "Starting"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := tm.Transition(task.ID, StatusInProgress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := tm.AssignTask(task.ID, "bob"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	feed, err := tm.GetActivity(task.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		kind  ActivityKind
+		field string
+	}{
+		{ActivityCreated, HistoryCreated},
+		{ActivityComment, ""},
+		{ActivityStatusChange, "status"},
+		{ActivityFieldChange, "assignee_id"},
+	}
+	if len(feed) != len(expected) {
+		t.Fatalf("Expected %d entries, got %+v", len(expected), feed)
+	}
+	for i, e := range expected {
+		if feed[i].Kind != e.kind || feed[i].Field != e.field {
+			t.Errorf("Entry %d: expected %v %q, got %v %q", i, e.kind, e.field, feed[i].Kind, feed[i].Field)
+		}
+	}
+	if c := feed[1].Comment; c == nil || c.Body != "Starting" {
+		t.Errorf("Expected the comment to be attached, got %+v", c)
+	}
+	if feed[2].OldValue != "todo" || feed[2].NewValue != "in progress" {
+		t.Errorf("Expected status change todo -> in progress, got %+v", feed[2])
+	}
+
+	if _, err := tm.GetActivity(999); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package taskmanager
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// DefaultColor is the color of tasks created without one
+	DefaultColor = "#9e9e9e"
+	// DefaultIcon is the icon of tasks created without one
+	DefaultIcon = "task"
+)
+
+var (
+	// ErrInvalidColor is returned when a task color is not a #rgb or #rrggbb hex string
+	ErrInvalidColor = errors.New("invalid task color")
+	// ErrInvalidIcon is returned when a task icon is not one of the known names
+	ErrInvalidIcon = errors.New("invalid task icon")
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// Icons lists the icon names a task may use. They match the Material icon
+// names the client ships with.
+var Icons = []string{
+	"task", "bug", "star", "flag", "home", "work", "shopping", "phone",
+	"mail", "calendar", "book", "heart", "travel", "fitness", "money", "idea",
+}
+
+// WithColor sets the color of a task as a hex string. The short #rgb form is
+// expanded and letters are lowercased.
+func WithColor(color string) TaskOption {
+	color = normalizeColor(color)
+	return func(t *Task) {
+		t.Color = color
+	}
+}
+
+// WithIcon sets the icon of a task to one of Icons
+func WithIcon(icon string) TaskOption {
+	icon = strings.ToLower(strings.TrimSpace(icon))
+	return func(t *Task) {
+		t.Icon = icon
+	}
+}
+
+// normalizeColor lowercases a hex color and expands #rgb to #rrggbb. Strings
+// that are not hex colors are returned trimmed so validation can reject them.
+func normalizeColor(color string) string {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if len(color) == 4 && color[0] == '#' {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return color
+}
+
+// validateAppearance checks the color and icon of a task
+func validateAppearance(task *Task) error {
+	if !colorPattern.MatchString(task.Color) {
+		return ErrInvalidColor
+	}
+	if !slices.Contains(Icons, task.Icon) {
+		return ErrInvalidIcon
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package taskmanager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestColorAndIcon(t *testing.T) {
+	tm := NewTaskManager()
+
+	tests := []struct {
+		name          string
+		opts          []TaskOption
+		expectedColor string
+		expectedIcon  string
+		expectError   error
+	}{
+		{name: "defaults", expectedColor: DefaultColor, expectedIcon: DefaultIcon},
+		{name: "long hex", opts: []TaskOption{WithColor("#FF5722"), WithIcon("Bug")}, expectedColor: "#ff5722", expectedIcon: "bug"},
+		{name: "short hex", opts: []TaskOption{WithColor("#0af")}, expectedColor: "#00aaff", expectedIcon: DefaultIcon},
+		{name: "missing hash", opts: []TaskOption{WithColor("ff5722")}, expectError: ErrInvalidColor},
+		{name: "not hex", opts: []TaskOption{WithColor("#gggggg")}, expectError: ErrInvalidColor},
+		{name: "color name", opts: []TaskOption{WithColor("red")}, expectError: ErrInvalidColor},
+		{name: "unknown icon", opts: []TaskOption{WithIcon("rocket-ship")}, expectError: ErrInvalidIcon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := tm.AddTask("Task", "", tt.opts...)
+			if err != tt.expectError {
+				t.Fatalf("Expected %v, got %v", tt.expectError, err)
+			}
+			if err != nil {
+				return
+			}
+			if task.Color != tt.expectedColor || task.Icon != tt.expectedIcon {
+				t.Errorf("Expected %s %s, got %s %s", tt.expectedColor, tt.expectedIcon, task.Color, task.Icon)
+			}
+		})
+	}
+}
+
+func TestPatchColor(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Task", WithIcon("star"))
+
+	if err := tm.UpdateTaskFields(task.ID, TaskPatch{Color: ptr("#ABC")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Color != "#aabbcc" || task.Icon != "star" {
+		t.Errorf("Expected #aabbcc star, got %s %s", task.Color, task.Icon)
+	}
+
+	err := tm.UpdateTaskFields(task.ID, TaskPatch{Color: ptr("blue")})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "color" || !errors.Is(err, ErrInvalidColor) {
+		t.Errorf("Expected color: %v, got %v", ErrInvalidColor, err)
+	}
+}
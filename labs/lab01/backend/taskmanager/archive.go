@@ -0,0 +1,73 @@
+package taskmanager
+
+import "errors"
+
+// ErrTaskNotClosed is returned when archiving a task that is not done or cancelled
+var ErrTaskNotClosed = errors.New("only done or cancelled tasks can be archived")
+
+// archiveFilter selects tasks by their archived flag
+type archiveFilter int
+
+const (
+	excludeArchived archiveFilter = iota
+	includeArchived
+	onlyArchived
+)
+
+func (f archiveFilter) matches(task *Task) bool {
+	switch f {
+	case includeArchived:
+		return true
+	case onlyArchived:
+		return task.Archived
+	default:
+		return !task.Archived
+	}
+}
+
+// ArchiveTask hides a done or cancelled task from default listings
+func (tm *TaskManager) ArchiveTask(id int) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if !task.Status.Closed() {
+		return ErrTaskNotClosed
+	}
+	tm.update(task, func() {
+		task.Archived = true
+	})
+	return nil
+}
+
+// UnarchiveTask returns an archived task to default listings. Reopening a
+// task also unarchives it.
+func (tm *TaskManager) UnarchiveTask(id int) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.Archived = false
+	})
+	return nil
+}
+
+// ListArchived returns archived tasks, oldest first
+func (tm *TaskManager) ListArchived(opts ...ListOption) []*Task {
+	return tm.ListTasks(nil, append(opts, OnlyArchived())...)
+}
+
+// IncludeArchived makes ListTasks return archived tasks alongside the others
+func IncludeArchived() ListOption {
+	return func(q *listQuery) {
+		q.archived = includeArchived
+	}
+}
+
+// OnlyArchived limits ListTasks to archived tasks
+func OnlyArchived() ListOption {
+	return func(q *listQuery) {
+		q.archived = onlyArchived
+	}
+}
@@ -0,0 +1,67 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestArchiveTask(t *testing.T) {
+	tm := NewTaskManager()
+	done := mustAddTask(t, tm, "Done")
+	open := mustAddTask(t, tm, "Open")
+	if err := tm.Transition(done.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		id          int
+		expectError error
+	}{
+		{name: "done task", id: done.ID},
+		{name: "open task", id: open.ID, expectError: ErrTaskNotClosed},
+		{name: "missing task", id: 999, expectError: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tm.ArchiveTask(tt.id); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if got := tm.ListTasks(nil); len(got) != 1 || got[0] != open {
+		t.Errorf("Expected archived task to be hidden, got %v", got)
+	}
+	if got := tm.ListArchived(); len(got) != 1 || got[0] != done {
+		t.Errorf("Expected the archived task, got %v", got)
+	}
+	if got := tm.ListTasks(nil, IncludeArchived()); len(got) != 2 {
+		t.Errorf("Expected 2 tasks including archived, got %d", len(got))
+	}
+
+	if err := tm.UnarchiveTask(done.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := tm.ListArchived(); len(got) != 0 {
+		t.Errorf("Expected no archived tasks, got %v", got)
+	}
+}
+
+func TestReopenUnarchives(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Task")
+	if err := tm.Transition(task.ID, StatusCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.ArchiveTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.Transition(task.ID, StatusTodo); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Archived {
+		t.Error("Expected reopened task to be unarchived")
+	}
+}
@@ -0,0 +1,55 @@
+package taskmanager
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidAssignee is returned when a task is assigned to an empty assignee ID
+var ErrInvalidAssignee = errors.New("assignee ID cannot be empty")
+
+// AssignTask assigns a task to the given assignee, replacing any previous one
+func (tm *TaskManager) AssignTask(id int, assigneeID string) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	assigneeID = strings.TrimSpace(assigneeID)
+	if assigneeID == "" {
+		return ErrInvalidAssignee
+	}
+	tm.update(task, func() {
+		task.AssigneeID = assigneeID
+	})
+	return nil
+}
+
+// UnassignTask removes the assignee from a task
+func (tm *TaskManager) UnassignTask(id int) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.AssigneeID = ""
+	})
+	return nil
+}
+
+// ListByAssignee returns all tasks assigned to the given assignee
+func (tm *TaskManager) ListByAssignee(assigneeID string, opts ...ListOption) []*Task {
+	return tm.ListTasks(nil, append(opts, FilterByAssignee(assigneeID))...)
+}
+
+// FilterByAssignee limits ListTasks to tasks assigned to the given assignee
+func FilterByAssignee(assigneeID string) ListOption {
+	assigneeID = strings.TrimSpace(assigneeID)
+	return func(q *listQuery) {
+		q.assignee = &assigneeID
+	}
+}
+
+// FilterUnassigned limits ListTasks to tasks without an assignee
+func FilterUnassigned() ListOption {
+	return FilterByAssignee("")
+}
@@ -0,0 +1,63 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestAssignTask(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Report")
+
+	tests := []struct {
+		name        string
+		id          int
+		assigneeID  string
+		expectError error
+	}{
+		{name: "assign", id: task.ID, assigneeID: "alice"},
+		{name: "reassign", id: task.ID, assigneeID: " bob "},
+		{name: "empty assignee", id: task.ID, assigneeID: "  ", expectError: ErrInvalidAssignee},
+		{name: "missing task", id: 999, assigneeID: "alice", expectError: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tm.AssignTask(tt.id, tt.assigneeID)
+			if err != tt.expectError {
+				t.Fatalf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if task.AssigneeID != "bob" {
+		t.Errorf("Expected assignee bob, got %q", task.AssigneeID)
+	}
+	if err := tm.UnassignTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.AssigneeID != "" {
+		t.Errorf("Expected task to be unassigned, got %q", task.AssigneeID)
+	}
+}
+
+func TestListByAssignee(t *testing.T) {
+	tm := NewTaskManager()
+	report := mustAddTask(t, tm, "Report")
+	review := mustAddTask(t, tm, "Review")
+	mustAddTask(t, tm, "Unassigned")
+	for _, id := range []int{report.ID, review.ID} {
+		if err := tm.AssignTask(id, "alice"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := tm.AssignTask(review.ID, "bob"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := tm.ListByAssignee("alice"); len(got) != 1 || got[0] != report {
+		t.Errorf("Expected only the report for alice, got %v", got)
+	}
+	if got := tm.ListTasks(nil, FilterUnassigned()); len(got) != 1 || got[0].Title != "Unassigned" {
+		t.Errorf("Expected only the unassigned task, got %v", got)
+	}
+}
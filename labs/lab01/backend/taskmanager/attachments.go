@@ -0,0 +1,208 @@
+package taskmanager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultMaxAttachmentSize is the attachment size limit used unless
+// WithMaxAttachmentSize says otherwise
+const DefaultMaxAttachmentSize = 10 << 20
+
+var (
+	// ErrAttachmentNotFound is returned when a task has no attachment with the given ID
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrInvalidAttachment is returned when attachment metadata is incomplete or malformed
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentTooLarge is returned when an attachment exceeds the size limit
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrBlobNotFound is returned by a BlobStore when no blob has the given key
+	ErrBlobNotFound = errors.New("blob not found")
+)
+
+// Attachment describes a file attached to a task. The file contents live in
+// a BlobStore under StorageKey.
+type Attachment struct {
+	ID         int
+	Name       string
+	MIMEType   string
+	Size       int64
+	StorageKey string
+	AddedAt    time.Time
+}
+
+// BlobStore stores attachment contents by key
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// WithBlobStore sets where uploaded attachment contents are stored
+func WithBlobStore(store BlobStore) Option {
+	return func(tm *TaskManager) {
+		tm.blobs = store
+	}
+}
+
+// WithMaxAttachmentSize sets the largest attachment accepted, in bytes
+func WithMaxAttachmentSize(size int64) Option {
+	return func(tm *TaskManager) {
+		tm.maxAttachmentSize = size
+	}
+}
+
+// AddAttachment records attachment metadata on a task. The contents are
+// expected to be in the blob store already; use UploadAttachment to store
+// them as well.
+func (tm *TaskManager) AddAttachment(taskID int, a Attachment) (Attachment, error) {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if err := tm.validateAttachment(&a); err != nil {
+		return Attachment{}, err
+	}
+
+	a.ID = tm.nextAttachmentID
+	tm.nextAttachmentID++
+	a.AddedAt = tm.now()
+	tm.update(task, func() {
+		task.Attachments = append(task.Attachments, a)
+	})
+	return a, nil
+}
+
+// UploadAttachment stores the contents read from r in the blob store and
+// attaches them to a task
+func (tm *TaskManager) UploadAttachment(taskID int, name, mimeType string, r io.Reader) (Attachment, error) {
+	if _, err := tm.lookup(taskID); err != nil {
+		return Attachment{}, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, tm.maxAttachmentSize+1))
+	if err != nil {
+		return Attachment{}, err
+	}
+	a := Attachment{
+		Name:       name,
+		MIMEType:   mimeType,
+		Size:       int64(len(data)),
+		StorageKey: fmt.Sprintf("tasks/%d/attachments/%d", taskID, tm.nextAttachmentID),
+	}
+	if err := tm.validateAttachment(&a); err != nil {
+		return Attachment{}, err
+	}
+	if err := tm.blobs.Put(a.StorageKey, bytes.NewReader(data)); err != nil {
+		return Attachment{}, err
+	}
+	return tm.AddAttachment(taskID, a)
+}
+
+// RemoveAttachment detaches an attachment from a task and deletes its contents
+func (tm *TaskManager) RemoveAttachment(taskID, attachmentID int) error {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(task.Attachments, func(a Attachment) bool {
+		return a.ID == attachmentID
+	})
+	if i < 0 {
+		return ErrAttachmentNotFound
+	}
+	if err := tm.deleteBlob(task.Attachments[i].StorageKey); err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.Attachments = slices.Delete(task.Attachments, i, i+1)
+	})
+	return nil
+}
+
+// ListAttachments returns the attachments of a task in the order they were added
+func (tm *TaskManager) ListAttachments(taskID int) ([]Attachment, error) {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(task.Attachments), nil
+}
+
+// validateAttachment checks attachment metadata and normalizes the MIME type
+func (tm *TaskManager) validateAttachment(a *Attachment) error {
+	if strings.TrimSpace(a.Name) == "" || a.StorageKey == "" || a.Size < 0 {
+		return ErrInvalidAttachment
+	}
+	if a.Size > tm.maxAttachmentSize {
+		return ErrAttachmentTooLarge
+	}
+	mediaType, params, err := mime.ParseMediaType(a.MIMEType)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAttachment, err)
+	}
+	if !strings.Contains(mediaType, "/") {
+		return fmt.Errorf("%w: MIME type %q has no subtype", ErrInvalidAttachment, a.MIMEType)
+	}
+	a.MIMEType = mime.FormatMediaType(mediaType, params)
+	return nil
+}
+
+// releaseAttachments deletes the stored contents of a removed task's attachments
+func (tm *TaskManager) releaseAttachments(task *Task) {
+	for _, a := range task.Attachments {
+		// The task is gone either way; a blob that fails to delete is only leaked storage.
+		_ = tm.deleteBlob(a.StorageKey)
+	}
+}
+
+// deleteBlob removes contents from the blob store, treating missing blobs as deleted
+func (tm *TaskManager) deleteBlob(key string) error {
+	if err := tm.blobs.Delete(key); err != nil && !errors.Is(err, ErrBlobNotFound) {
+		return err
+	}
+	return nil
+}
+
+// MemoryBlobStore is a BlobStore that keeps contents in memory
+type MemoryBlobStore struct {
+	blobs map[string][]byte
+}
+
+// NewMemoryBlobStore creates an empty in-memory blob store
+func NewMemoryBlobStore() *MemoryBlobStore {
+	return &MemoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+// Put stores the contents read from r under key
+func (s *MemoryBlobStore) Put(key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.blobs[key] = data
+	return nil
+}
+
+// Get returns a reader over the contents stored under key
+func (s *MemoryBlobStore) Get(key string) (io.ReadCloser, error) {
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete removes the contents stored under key
+func (s *MemoryBlobStore) Delete(key string) error {
+	if _, ok := s.blobs[key]; !ok {
+		return ErrBlobNotFound
+	}
+	delete(s.blobs, key)
+	return nil
+}
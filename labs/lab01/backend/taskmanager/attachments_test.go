@@ -0,0 +1,126 @@
+package taskmanager
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAddAttachment(t *testing.T) {
+	tm := NewTaskManager(WithMaxAttachmentSize(1024))
+	task := mustAddTask(t, tm, "Report")
+
+	tests := []struct {
+		name        string
+		attachment  Attachment
+		expectError error
+	}{
+		{
+			name:       "valid attachment",
+			attachment: Attachment{Name: "report.pdf", MIMEType: "application/pdf", Size: 512, StorageKey: "blobs/1"},
+		},
+		{
+			name:        "missing name",
+			attachment:  Attachment{MIMEType: "application/pdf", Size: 512, StorageKey: "blobs/2"},
+			expectError: ErrInvalidAttachment,
+		},
+		{
+			name:        "bad MIME type",
+			attachment:  Attachment{Name: "report.pdf", MIMEType: "pdf", Size: 512, StorageKey: "blobs/3"},
+			expectError: ErrInvalidAttachment,
+		},
+		{
+			name:        "too large",
+			attachment:  Attachment{Name: "video.mp4", MIMEType: "video/mp4", Size: 2048, StorageKey: "blobs/4"},
+			expectError: ErrAttachmentTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tm.AddAttachment(task.ID, tt.attachment)
+
+			if tt.expectError != nil {
+				if !errors.Is(err, tt.expectError) {
+					t.Errorf("Expected %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.ID == 0 || got.AddedAt.IsZero() {
+				t.Errorf("Expected ID and AddedAt to be set, got %+v", got)
+			}
+		})
+	}
+
+	attachments, err := tm.ListAttachments(task.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(attachments) != 1 {
+		t.Errorf("Expected 1 attachment, got %d", len(attachments))
+	}
+}
+
+func TestUploadAndRemoveAttachment(t *testing.T) {
+	blobs := NewMemoryBlobStore()
+	tm := NewTaskManager(WithBlobStore(blobs), WithMaxAttachmentSize(16))
+	task := mustAddTask(t, tm, "Notes")
+
+	a, err := tm.UploadAttachment(task.ID, "notes.txt", "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Size != 5 {
+		t.Errorf("Expected size 5, got %d", a.Size)
+	}
+	r, err := blobs.Get(a.StorageKey)
+	if err != nil {
+		t.Fatalf("Expected blob to be stored: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	if string(data) != "hello" {
+		t.Errorf("Expected stored contents %q, got %q", "hello", data)
+	}
+
+	_, err = tm.UploadAttachment(task.ID, "big.txt", "text/plain", strings.NewReader(strings.Repeat("x", 17)))
+	if err != ErrAttachmentTooLarge {
+		t.Errorf("Expected ErrAttachmentTooLarge, got %v", err)
+	}
+
+	if err := tm.RemoveAttachment(task.ID, a.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := blobs.Get(a.StorageKey); err != ErrBlobNotFound {
+		t.Errorf("Expected blob to be deleted, got %v", err)
+	}
+	if err := tm.RemoveAttachment(task.ID, a.ID); err != ErrAttachmentNotFound {
+		t.Errorf("Expected ErrAttachmentNotFound, got %v", err)
+	}
+}
+
+func TestPurgeReleasesAttachments(t *testing.T) {
+	blobs := NewMemoryBlobStore()
+	tm := NewTaskManager(WithBlobStore(blobs))
+	task := mustAddTask(t, tm, "Notes")
+	a, err := tm.UploadAttachment(task.ID, "notes.txt", "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.DeleteTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := blobs.Get(a.StorageKey); err != nil {
+		t.Errorf("Expected blob to be kept while the task is in the trash, got %v", err)
+	}
+
+	tm.PurgeTrash()
+	if _, err := blobs.Get(a.StorageKey); err != ErrBlobNotFound {
+		t.Errorf("Expected blob to be deleted with the task, got %v", err)
+	}
+}
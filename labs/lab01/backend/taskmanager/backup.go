@@ -0,0 +1,525 @@
+package taskmanager
+
+import (
+	"archive/tar"
+	"cmp"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidBackup is returned by Restore for an archive that is not a
+	// backup, is damaged, or holds data that fails validation
+	ErrInvalidBackup = errors.New("invalid backup")
+	// ErrUnsupportedBackupVersion is returned by Restore for a backup
+	// written by a newer version of the package
+	ErrUnsupportedBackupVersion = errors.New("unsupported backup version")
+)
+
+// backupFormat names the archives Backup writes in their manifest
+const backupFormat = "taskmanager-backup"
+
+// backupVersion is the version of the archive layout Backup writes
+const backupVersion = 1
+
+// The files of a backup archive, in the order Backup writes them
+const (
+	backupManifestFile  = "manifest.json"
+	backupTasksFile     = "tasks.json"
+	backupProjectsFile  = "projects.json"
+	backupFieldsFile    = "fields.json"
+	backupFiltersFile   = "filters.json"
+	backupTemplatesFile = "templates.json"
+)
+
+// backupManifest is the first file of a backup archive
+type backupManifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Files maps the other files of the archive to the hex SHA-256 of
+	// their contents
+	Files map[string]string `json:"files"`
+}
+
+// backupFile is a file of a backup archive
+type backupFile struct {
+	name string
+	data []byte
+}
+
+// backup is the contents of a backup archive
+type backup struct {
+	createdAt time.Time
+	tasks     []*Task
+	projects  []*Project
+	fields    []FieldDefinition
+	filters   []*SavedFilter
+	templates []*Template
+}
+
+// RestoreMode tells Restore what to do with the manager's current data
+type RestoreMode int
+
+const (
+	// RestoreMerge adds the backup to the current data. A task in both
+	// is taken from the backup only when the backup's copy was updated
+	// later; projects, fields, filters and templates the manager has
+	// already are kept as they are.
+	RestoreMerge RestoreMode = iota
+	// RestoreReplace drops the current tasks, trash, projects, fields,
+	// filters and templates and puts the backup's in their place
+	RestoreReplace
+)
+
+// RestoreOptions tells Restore how to restore a backup
+type RestoreOptions struct {
+	Mode RestoreMode
+}
+
+// RestoreResult reports what Restore restored
+type RestoreResult struct {
+	// CreatedAt is when the backup was taken, by the clock of the manager
+	// that took it
+	CreatedAt time.Time
+	// Tasks is the number of tasks taken from the backup
+	Tasks int
+	// Kept is the number of tasks of a merge left as they were, since the
+	// manager's copy was as recent as the backup's
+	Kept int
+	// Projects is the number of projects taken from the backup
+	Projects int
+}
+
+// Backup writes the active tasks, with their comments, checklists and
+// attachment metadata, and the projects, custom field definitions, saved
+// filters and templates as a gzipped tar archive that Restore reads back.
+// The archive starts with a manifest giving its version and the checksum
+// of every other file. The trash, the undo history and the contents of
+// attachments, which live in the BlobStore, are not included.
+func (tm *TaskManager) Backup(w io.Writer) error {
+	files, err := tm.backupFiles()
+	if err != nil {
+		return err
+	}
+	return writeBackup(w, files, tm.now())
+}
+
+// backupFiles encodes the files of a backup of the manager
+func (tm *TaskManager) backupFiles() ([]backupFile, error) {
+	tasks := slices.SortedFunc(tm.tasks.List(), func(a, b *Task) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	projects := tm.ListProjects()
+	templates := slices.SortedFunc(maps.Values(tm.templates), func(a, b *Template) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	contents := []struct {
+		name  string
+		value any
+	}{
+		{backupTasksFile, tasks},
+		{backupProjectsFile, projects},
+		{backupFieldsFile, tm.ListFields()},
+		{backupFiltersFile, tm.ListFilters()},
+		{backupTemplatesFile, templates},
+	}
+	files := make([]backupFile, len(contents))
+	for i, c := range contents {
+		data, err := json.Marshal(c.value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", c.name, err)
+		}
+		files[i] = backupFile{name: c.name, data: data}
+	}
+	return files, nil
+}
+
+// writeBackup writes files as a backup archive taken at createdAt
+func writeBackup(w io.Writer, files []backupFile, createdAt time.Time) error {
+	manifest := backupManifest{
+		Format:    backupFormat,
+		Version:   backupVersion,
+		CreatedAt: createdAt,
+		Files:     make(map[string]string, len(files)),
+	}
+	for _, f := range files {
+		sum := sha256.Sum256(f.data)
+		manifest.Files[f.name] = hex.EncodeToString(sum[:])
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	files = append([]backupFile{{name: backupManifestFile, data: data}}, files...)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    f.name,
+			Mode:    0o644,
+			Size:    int64(len(f.data)),
+			ModTime: createdAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("writing backup: %w", err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return fmt.Errorf("writing backup: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	return nil
+}
+
+// Restore reads an archive written by Backup and restores it into the
+// manager after checking its checksums and validating every task,
+// project, field, filter and template against the data it would be
+// restored alongside and against the manager's limits. Nothing is changed
+// unless the whole backup is valid; the errors of a backup that is not
+// wrap ErrInvalidBackup, and ErrTooManyTasks is returned for one that
+// does not fit under WithMaxTasks.
+// Restored tasks keep their IDs; when the manager's IDGenerator is not its
+// store's sequence, it must not hand those IDs out again. Restoring can't
+// be undone, so it clears the undo history.
+func (tm *TaskManager) Restore(r io.Reader, opts RestoreOptions) (*RestoreResult, error) {
+	b, err := readBackup(r)
+	if err != nil {
+		return nil, err
+	}
+	return tm.restore(b, opts)
+}
+
+// readBackup reads and checks a backup archive
+func readBackup(r io.Reader) (*backup, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if _, ok := files[header.Name]; ok {
+			return nil, fmt.Errorf("%w: %s appears twice", ErrInvalidBackup, header.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackup, header.Name, err)
+		}
+		files[header.Name] = data
+	}
+
+	var manifest backupManifest
+	data, ok := files[backupManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidBackup, backupManifestFile)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.Format != backupFormat {
+		return nil, fmt.Errorf("%w: malformed %s", ErrInvalidBackup, backupManifestFile)
+	}
+	if manifest.Version > backupVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedBackupVersion, manifest.Version)
+	}
+	for name, checksum := range manifest.Files {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidBackup, name)
+		}
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != checksum {
+			return nil, fmt.Errorf("%w: %s: checksum mismatch", ErrInvalidBackup, name)
+		}
+	}
+
+	b := &backup{createdAt: manifest.CreatedAt}
+	targets := []struct {
+		name     string
+		value    any
+		required bool
+	}{
+		{backupTasksFile, &b.tasks, true},
+		{backupProjectsFile, &b.projects, false},
+		{backupFieldsFile, &b.fields, false},
+		{backupFiltersFile, &b.filters, false},
+		{backupTemplatesFile, &b.templates, false},
+	}
+	for _, t := range targets {
+		if _, listed := manifest.Files[t.name]; !listed {
+			if t.required {
+				return nil, fmt.Errorf("%w: no %s", ErrInvalidBackup, t.name)
+			}
+			continue
+		}
+		if err := json.Unmarshal(files[t.name], t.value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackup, t.name, err)
+		}
+	}
+	return b, nil
+}
+
+// restore validates a backup against the data it is restored alongside
+// and restores it
+func (tm *TaskManager) restore(b *backup, opts RestoreOptions) (*RestoreResult, error) {
+	replace := opts.Mode == RestoreReplace
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidBackup, fmt.Sprintf(format, args...))
+	}
+
+	fields := make(map[string]FieldType)
+	if !replace {
+		maps.Copy(fields, tm.fields)
+	}
+	for _, f := range b.fields {
+		if strings.TrimSpace(f.Name) == "" || f.Type < FieldString || f.Type > FieldDate {
+			return nil, invalid("field %q of type %d", f.Name, f.Type)
+		}
+		if existing, ok := fields[f.Name]; ok && (existing != f.Type || replace) {
+			return nil, invalid("field %q is defined twice", f.Name)
+		}
+		fields[f.Name] = f.Type
+	}
+
+	projects := make(map[int]*Project)
+	if !replace {
+		maps.Copy(projects, tm.projects)
+	}
+	var newProjects []*Project
+	for _, p := range b.projects {
+		if p == nil || p.ID <= 0 || strings.TrimSpace(p.Name) == "" {
+			return nil, invalid("project %v", p)
+		}
+		if existing, ok := projects[p.ID]; ok {
+			if replace || !strings.EqualFold(existing.Name, p.Name) {
+				return nil, invalid("project %d is %q and %q", p.ID, existing.Name, p.Name)
+			}
+			continue
+		}
+		for _, other := range projects {
+			if strings.EqualFold(other.Name, p.Name) {
+				return nil, invalid("projects %d and %d are both %q", other.ID, p.ID, p.Name)
+			}
+		}
+		projects[p.ID] = p
+		newProjects = append(newProjects, p)
+	}
+
+	// ids are the tasks active once the backup is restored
+	ids := make(map[int]bool, len(b.tasks))
+	if !replace {
+		for task := range tm.tasks.List() {
+			ids[task.ID] = true
+		}
+	}
+	restored := make(map[int]bool, len(b.tasks))
+	for _, task := range b.tasks {
+		if task == nil || task.ID <= 0 {
+			return nil, invalid("task without an ID")
+		}
+		if restored[task.ID] {
+			return nil, invalid("task %d appears twice", task.ID)
+		}
+		restored[task.ID] = true
+		ids[task.ID] = true
+	}
+	for _, task := range b.tasks {
+		if err := validateTask(task); err != nil {
+			return nil, invalid("task %d: %v", task.ID, err)
+		}
+		if err := tm.validateLengths(task); err != nil {
+			return nil, fmt.Errorf("%w: task %d: %w", ErrInvalidBackup, task.ID, err)
+		}
+		if err := restoreCustomFields(task, fields); err != nil {
+			return nil, invalid("task %d: %v", task.ID, err)
+		}
+		if task.ProjectID != 0 && projects[task.ProjectID] == nil {
+			return nil, invalid("task %d: project %d not found", task.ID, task.ProjectID)
+		}
+		if task.ParentID != 0 && !ids[task.ParentID] {
+			return nil, invalid("task %d: parent %d not found", task.ID, task.ParentID)
+		}
+		for _, dep := range task.DependsOn {
+			if !ids[dep] {
+				return nil, invalid("task %d: dependency %d not found", task.ID, dep)
+			}
+		}
+	}
+	if id, ok := tm.restoredCycle(b.tasks, replace); ok {
+		return nil, fmt.Errorf("%w: task %d: %w", ErrInvalidBackup, id, ErrCyclicParent)
+	}
+	if err := tm.reserveRestored(b.tasks, replace); err != nil {
+		return nil, err
+	}
+	for _, f := range b.filters {
+		if f == nil || strings.TrimSpace(f.Name) == "" {
+			return nil, invalid("filter without a name")
+		}
+		if err := f.Options.Validate(); err != nil {
+			return nil, invalid("filter %q: %v", f.Name, err)
+		}
+	}
+	for _, t := range b.templates {
+		if t == nil || t.ID <= 0 {
+			return nil, invalid("template without an ID")
+		}
+	}
+
+	result := &RestoreResult{CreatedAt: b.createdAt, Projects: len(newProjects)}
+	if replace {
+		for task := range tm.tasks.List() {
+			tm.markChanged(task.ID)
+			tm.tasks.Delete(task.ID)
+		}
+		tm.trash = make(map[int]*Task)
+		tm.index = newTaskIndex()
+		tm.filters = make(map[string]*SavedFilter)
+		tm.templates = make(map[int]*Template)
+		tm.lastPosition = 0
+	}
+	tm.fields = fields
+	tm.projects = projects
+	for _, p := range newProjects {
+		tm.nextProjectID = max(tm.nextProjectID, p.ID+1)
+	}
+	for _, f := range b.filters {
+		if _, ok := tm.filters[f.Name]; !ok {
+			tm.filters[f.Name] = f
+		}
+	}
+	for _, t := range b.templates {
+		if _, ok := tm.templates[t.ID]; !ok {
+			tm.templates[t.ID] = t
+			tm.nextTemplateID = max(tm.nextTemplateID, t.ID+1)
+		}
+	}
+
+	var added []*Task
+	maxID := 0
+	for _, task := range b.tasks {
+		maxID = max(maxID, task.ID)
+		if existing, ok := tm.tasks.Get(task.ID); ok {
+			if !task.UpdatedAt.After(existing.UpdatedAt) {
+				result.Kept++
+				continue
+			}
+			tm.tasks.Put(task)
+			tm.index.put(task)
+		} else {
+			delete(tm.trash, task.ID)
+			tm.tasks.Put(task)
+			added = append(added, task)
+		}
+		tm.markChanged(task.ID)
+		result.Tasks++
+		tm.lastPosition = max(tm.lastPosition, task.Position)
+		for _, c := range task.Comments {
+			tm.nextCommentID = max(tm.nextCommentID, c.ID+1)
+		}
+		for _, a := range task.Attachments {
+			tm.nextAttachmentID = max(tm.nextAttachmentID, a.ID+1)
+		}
+		for _, item := range task.Checklist {
+			tm.nextChecklistItemID = max(tm.nextChecklistItemID, item.ID+1)
+		}
+	}
+	tm.index.putAll(added)
+	if a, ok := tm.ids.(idAdvancer); ok {
+		a.advanceIDs(maxID + 1)
+	}
+	for _, task := range b.tasks {
+		if task.ParentID != 0 {
+			tm.refreshProgress(task.ParentID)
+		}
+	}
+	tm.clearUndo()
+	return result, nil
+}
+
+// restoredCycle returns a restored task that would be its own ancestor
+// once the tasks are restored, following the parents of the tasks each
+// would keep or replace. The manager's own tasks form no cycle, so every
+// cycle goes through a restored task.
+func (tm *TaskManager) restoredCycle(tasks []*Task, replace bool) (int, bool) {
+	parents := make(map[int]int)
+	if !replace {
+		for task := range tm.tasks.List() {
+			parents[task.ID] = task.ParentID
+		}
+	}
+	for _, task := range tasks {
+		if existing, ok := tm.tasks.Get(task.ID); ok && !replace && !task.UpdatedAt.After(existing.UpdatedAt) {
+			continue
+		}
+		parents[task.ID] = task.ParentID
+	}
+	// acyclic holds the tasks whose ancestors are known to end at the top
+	acyclic := make(map[int]bool, len(parents))
+	for _, task := range tasks {
+		ancestors := make(map[int]bool)
+		for id := task.ID; id != 0 && !acyclic[id]; id = parents[id] {
+			if ancestors[id] {
+				return id, true
+			}
+			ancestors[id] = true
+		}
+		maps.Copy(acyclic, ancestors)
+	}
+	return 0, false
+}
+
+// reserveRestored checks that the tasks the manager holds once tasks are
+// restored fit under WithMaxTasks. Restoring a task that is already active
+// or in the trash takes no more room.
+func (tm *TaskManager) reserveRestored(tasks []*Task, replace bool) error {
+	if replace {
+		if tm.maxTasks > 0 && len(tasks) > tm.maxTasks {
+			return fmt.Errorf("%w: the limit is %d", ErrTooManyTasks, tm.maxTasks)
+		}
+		return nil
+	}
+	n := 0
+	for _, task := range tasks {
+		_, active := tm.tasks.Get(task.ID)
+		if _, trashed := tm.trash[task.ID]; !active && !trashed {
+			n++
+		}
+	}
+	return tm.reserve(n)
+}
+
+// restoreCustomFields brings back the custom field values of a restored
+// task, which JSON turned into plain values, and checks them against the
+// field definitions
+func restoreCustomFields(task *Task, fields map[string]FieldType) error {
+	for name, value := range task.CustomFields {
+		if s, ok := value.(string); ok && fields[name] == FieldDate {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("%w: %s must be a %v", ErrFieldTypeMismatch, name, FieldDate)
+			}
+			task.CustomFields[name] = t
+		}
+	}
+	return (&TaskManager{fields: fields}).validateCustomFields(task)
+}
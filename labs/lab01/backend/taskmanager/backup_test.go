@@ -0,0 +1,278 @@
+package taskmanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// backupManager returns a manager with a little of everything a backup
+// holds, and its backup
+func backupManager(t *testing.T) (*TaskManager, *bytes.Buffer) {
+	t.Helper()
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	tm := NewTaskManager(WithClock(clock))
+	course, _ := tm.CreateProject("Course")
+	tm.DefineField("due", FieldDate)
+	tm.DefineField("points", FieldNumber)
+	report := mustAddTask(t, tm, "Write report", WithProject(course.ID), WithTags("work"),
+		WithCustomField("due", time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)), WithCustomField("points", 5))
+	mustAddTask(t, tm, "Outline", WithParent(report.ID))
+	removed := mustAddTask(t, tm, "Removed")
+	tm.AddComment(report.ID, "alice", "Looks good")
+	tm.AddAttachment(report.ID, Attachment{Name: "notes.txt", Size: 12})
+	item, _ := tm.AddChecklistItem(report.ID, "Sources")
+	tm.ToggleChecklistItem(report.ID, item.ID)
+	tm.DeleteTask(removed.ID)
+	tm.SaveFilter("work", ListOptions{Tags: []string{"work"}})
+	tm.CreateTemplate(Template{Name: "Weekly", TitlePattern: "Weekly review"})
+
+	var b bytes.Buffer
+	if err := tm.Backup(&b); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return tm, &b
+}
+
+func TestBackupRestore(t *testing.T) {
+	tm, b := backupManager(t)
+
+	restored := NewTaskManager()
+	result, err := restored.Restore(b, RestoreOptions{Mode: RestoreReplace})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (RestoreResult{CreatedAt: time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC), Tasks: 2, Projects: 1}); *result != want {
+		t.Errorf("Expected %+v, got %+v", want, *result)
+	}
+	if got, want := restored.ListTasks(nil), tm.ListTasks(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"projects", restored.ListProjects(), tm.ListProjects()},
+		{"fields", restored.ListFields(), tm.ListFields()},
+		{"filters", restored.ListFilters(), tm.ListFilters()},
+		{"templates", restored.ListTemplates(), tm.ListTemplates()},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("Expected %s %+v, got %+v", c.name, c.want, c.got)
+		}
+	}
+	if len(restored.ListTrash()) != 0 {
+		t.Errorf("Expected the trash left out of the backup, got %v", restored.ListTrash())
+	}
+
+	// New tasks, projects and comments don't reuse restored IDs
+	task := mustAddTask(t, restored, "Later")
+	if task.ID != 3 {
+		t.Errorf("Expected IDs to carry on at 3, got %d", task.ID)
+	}
+	if project, _ := restored.CreateProject("Home"); project.ID != 2 {
+		t.Errorf("Expected project IDs to carry on at 2, got %d", project.ID)
+	}
+	if comment, _ := restored.AddComment(task.ID, "bob", "Hi"); comment.ID != 2 {
+		t.Errorf("Expected comment IDs to carry on at 2, got %d", comment.ID)
+	}
+}
+
+func TestRestoreModes(t *testing.T) {
+	_, b := backupManager(t)
+	archive := b.Bytes()
+
+	tests := []struct {
+		name   string
+		mode   RestoreMode
+		setup  func(tm *TaskManager)
+		want   []string
+		result RestoreResult
+	}{
+		{
+			name:   "replace drops current tasks",
+			mode:   RestoreReplace,
+			setup:  func(tm *TaskManager) { tm.AddTask("Current", "") },
+			want:   []string{"Write report", "Outline"},
+			result: RestoreResult{Tasks: 2, Projects: 1},
+		},
+		{
+			name: "merge keeps newer tasks",
+			mode: RestoreMerge,
+			setup: func(tm *TaskManager) {
+				tm.Restore(bytes.NewReader(archive), RestoreOptions{})
+				tm.UpdateTask(1, "Write final report", "", false)
+				tm.DeleteTask(2)
+			},
+			want:   []string{"Write final report", "Outline"},
+			result: RestoreResult{Tasks: 1, Kept: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := func() time.Time { return time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC) }
+			tm := NewTaskManager(WithClock(clock))
+			tt.setup(tm)
+			result, err := tm.Restore(bytes.NewReader(archive), RestoreOptions{Mode: tt.mode})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result.CreatedAt = time.Time{}
+			if *result != tt.result {
+				t.Errorf("Expected %+v, got %+v", tt.result, *result)
+			}
+			if got := titles(tm.ListTasks(nil)); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			if tm.CanUndo() {
+				t.Error("Expected the undo history cleared")
+			}
+		})
+	}
+}
+
+// rewriteBackup rewrites the files of a backup archive with edit, leaving
+// the manifest as it was
+func rewriteBackup(t *testing.T, archive []byte, edit func(name string, data []byte) []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		data, _ := io.ReadAll(tr)
+		if data = edit(header.Name, data); data == nil {
+			continue
+		}
+		header.Size = int64(len(data))
+		tw.WriteHeader(header)
+		tw.Write(data)
+	}
+	tw.Close()
+	gw.Close()
+	return out.Bytes()
+}
+
+func TestRestoreValidation(t *testing.T) {
+	tm, b := backupManager(t)
+	archive := b.Bytes()
+	files, _ := tm.backupFiles()
+	// rebuilt writes a valid archive of files with one of them replaced
+	rebuilt := func(name, data string) []byte {
+		edited := slices.Clone(files)
+		for i, f := range edited {
+			if f.name == name {
+				edited[i].data = []byte(data)
+			}
+		}
+		var b bytes.Buffer
+		writeBackup(&b, edited, time.Now())
+		return b.Bytes()
+	}
+	// orphan is the subtask of the backup without its parent
+	var tasks []*Task
+	json.Unmarshal(files[0].data, &tasks)
+	orphan, _ := json.Marshal(tasks[1:])
+	// cyclic has the task and its subtask each the parent of the other
+	tasks[0].ParentID = tasks[1].ID
+	cyclic, _ := json.Marshal(tasks)
+
+	tests := []struct {
+		name    string
+		archive []byte
+		want    error
+	}{
+		{"not gzip", []byte("tasks"), ErrInvalidBackup},
+		{"truncated", archive[:len(archive)/2], ErrInvalidBackup},
+		{"checksum mismatch", rewriteBackup(t, archive, func(name string, data []byte) []byte {
+			if name == backupTasksFile {
+				return bytes.Replace(data, []byte("Write report"), []byte("Write rep0rt"), 1)
+			}
+			return data
+		}), ErrInvalidBackup},
+		{"missing file", rewriteBackup(t, archive, func(name string, data []byte) []byte {
+			if name == backupProjectsFile {
+				return nil
+			}
+			return data
+		}), ErrInvalidBackup},
+		{"newer version", rewriteBackup(t, archive, func(name string, data []byte) []byte {
+			if name == backupManifestFile {
+				return bytes.Replace(data, []byte(`"version": 1`), []byte(`"version": 2`), 1)
+			}
+			return data
+		}), ErrUnsupportedBackupVersion},
+		{"unknown project", rebuilt(backupProjectsFile, "[]"), ErrInvalidBackup},
+		{"undefined field", rebuilt(backupFieldsFile, `[{"Name": "points", "Type": 2}]`), ErrInvalidBackup},
+		{"missing parent", rebuilt(backupTasksFile, string(orphan)), ErrInvalidBackup},
+		{"invalid task", rebuilt(backupTasksFile, `[{"ID": 1, "Title": "", "Status": 1, "Priority": 2}]`), ErrInvalidBackup},
+		{"duplicate task", rebuilt(backupTasksFile, `[{"ID": 1, "Title": "A", "Status": 1, "Priority": 2}, {"ID": 1, "Title": "B", "Status": 1, "Priority": 2}]`), ErrInvalidBackup},
+		{"cyclic parents", rebuilt(backupTasksFile, string(cyclic)), ErrCyclicParent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := NewTaskManager()
+			target.AddTask("Current", "")
+			if _, err := target.Restore(bytes.NewReader(tt.archive), RestoreOptions{Mode: RestoreReplace}); !errors.Is(err, tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, err)
+			}
+			if got := titles(target.ListTasks(nil)); !slices.Equal(got, []string{"Current"}) {
+				t.Errorf("Expected nothing changed, got %v", got)
+			}
+		})
+	}
+}
+
+func TestRestoreLimits(t *testing.T) {
+	source := NewTaskManager()
+	for range 5 {
+		mustAddTask(t, source, "A title of some length")
+	}
+	var b bytes.Buffer
+	source.Backup(&b)
+
+	tests := []struct {
+		name string
+		opts []Option
+		mode RestoreMode
+		want error
+	}{
+		{"too many tasks", []Option{WithMaxTasks(2)}, RestoreReplace, ErrTooManyTasks},
+		{"too many once merged", []Option{WithMaxTasks(4)}, RestoreMerge, ErrTooManyTasks},
+		{"title too long", []Option{WithMaxTitleLength(5)}, RestoreReplace, ErrTitleTooLong},
+		{"within limits", []Option{WithMaxTasks(5), WithMaxTitleLength(30)}, RestoreReplace, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := NewTaskManager(tt.opts...)
+			mustAddTask(t, target, "Other")
+			_, err := target.Restore(bytes.NewReader(b.Bytes()), RestoreOptions{Mode: tt.mode})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, err)
+			}
+			want := 5
+			if err != nil {
+				want = 1
+			}
+			if got := len(target.ListTasks(nil)); got != want {
+				t.Errorf("Expected %d tasks, got %d", want, got)
+			}
+		})
+	}
+}
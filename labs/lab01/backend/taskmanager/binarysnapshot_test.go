@@ -0,0 +1,209 @@
+package taskmanager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// binaryManager returns a manager with a task using most fields
+func binaryManager(t *testing.T) *TaskManager {
+	t.Helper()
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	tm := NewTaskManager(WithClock(clock))
+	tm.DefineField("due", FieldDate)
+	tm.DefineField("points", FieldNumber)
+	due := time.Date(2025, 7, 3, 17, 0, 0, 0, time.UTC)
+	report := mustAddTask(t, tm, "Write report", WithTags("work", "urgent"), WithDueDate(due), WithPriority(PriorityHigh),
+		WithCustomField("due", due), WithCustomField("points", 5), WithRecurrence(Recurrence{Frequency: Weekly, Weekdays: []time.Weekday{time.Monday}}))
+	mustAddTask(t, tm, "Outline", WithParent(report.ID))
+	tm.AddComment(report.ID, "alice", "Looks good")
+	tm.AddChecklistItem(report.ID, "Sources")
+	tm.CompleteTask(2, "")
+	return tm
+}
+
+func TestBinarySnapshot(t *testing.T) {
+	tm := binaryManager(t)
+	snapshot := tm.Snapshot()
+	var b bytes.Buffer
+	if err := snapshot.WriteBinary(&b); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	read, err := ReadSnapshot(&b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !read.TakenAt().Equal(snapshot.TakenAt()) {
+		t.Errorf("Expected %v, got %v", snapshot.TakenAt(), read.TakenAt())
+	}
+	if got, want := read.ListTasks(nil), snapshot.ListTasks(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := titles(read.ListTasks(nil, FilterByAnyTag("urgent"))); !slices.Equal(got, []string{"Write report"}) {
+		t.Errorf("Expected the read tasks indexed, got %v", got)
+	}
+
+	// Binary snapshots are smaller than the JSON of the same tasks
+	for range 500 {
+		mustAddTask(t, tm, "Another task", WithTags("work"), WithPriority(PriorityLow))
+	}
+	b.Reset()
+	tm.Snapshot().WriteBinary(&b)
+	encoded, _ := json.Marshal(tm.ListTasks(nil))
+	if b.Len() >= len(encoded)/2 {
+		t.Errorf("Expected less than half the %d bytes of JSON, got %d", len(encoded), b.Len())
+	}
+}
+
+// earlierTask is a task as an earlier version of the package might have
+// had it
+type earlierTask struct {
+	ID        int
+	Title     string
+	Status    Status
+	Priority  Priority
+	Tags      []string
+	CreatedAt time.Time
+}
+
+// laterTask is a task as a later version of the package might have it,
+// with a field this version does not know
+type laterTask struct {
+	ID     int
+	Title  string
+	Status Status
+	Energy int
+}
+
+func TestBinarySnapshotVersions(t *testing.T) {
+	created := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	// written encodes a snapshot of the given format version and tasks
+	written := func(version uint16, tasks ...any) []byte {
+		var b bytes.Buffer
+		header := binarySnapshotHeader{TakenAt: created, Tasks: len(tasks)}
+		err := writeBinarySnapshot(&b, version, header, func(enc *gob.Encoder) error {
+			for _, task := range tasks {
+				if err := enc.Encode(task); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return b.Bytes()
+	}
+	current := written(binarySnapshotVersion, &Task{ID: 1, Title: "Current"})
+	tampered := slices.Clone(current)
+	tampered[len(tampered)-sha256.Size-1] ^= 1
+
+	tests := []struct {
+		name string
+		data []byte
+		want *Task
+		err  error
+	}{
+		{
+			name: "older task fields",
+			data: written(1, earlierTask{ID: 1, Title: "Old", Status: StatusDone, Priority: PriorityHigh, Tags: []string{"work"}, CreatedAt: created}),
+			want: &Task{ID: 1, Title: "Old", Status: StatusDone, Priority: PriorityHigh, Tags: []string{"work"}, CreatedAt: created},
+		},
+		{
+			name: "newer task fields",
+			data: written(1, laterTask{ID: 1, Title: "New", Status: StatusTodo, Energy: 3}),
+			want: &Task{ID: 1, Title: "New", Status: StatusTodo},
+		},
+		{name: "newer format", data: written(binarySnapshotVersion + 1), err: ErrUnsupportedFileVersion},
+		{name: "version 0", data: written(0), err: ErrCorruptFile},
+		{name: "checksum mismatch", data: tampered, err: ErrCorruptFile},
+		{name: "truncated", data: current[:len(current)/2], err: ErrCorruptFile},
+		{name: "json", data: []byte(`{"version": 1}`), err: ErrCorruptFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read, err := ReadSnapshot(bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			got, err := read.GetTask(1)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFileStoreBinary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := OpenFileStoreWith(path, FileOptions{Format: FileBinary, Encryption: testEncryption(t, "secret")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s := NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{}))
+	first, _ := s.AddTask("Write report", "", WithTags("work"))
+	s.Flush()
+	s.AddTask("Read", "")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A store saving JSON loads the binary file, and saves JSON from then on
+	reopened, err := OpenEncryptedFileStore(path, testEncryption(t, "secret"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r := NewSafeTaskManager(WithTaskStore(reopened), WithPersister(reopened, FlushPolicy{}))
+	if got := titles(r.ListTasks(nil)); !slices.Equal(got, []string{"Write report", "Read"}) {
+		t.Errorf("Expected the tasks of the binary file, got %v", got)
+	}
+	if task, _ := r.AddTask("Later", ""); task.ID != 3 {
+		t.Errorf("Expected IDs to carry on at 3, got %d", task.ID)
+	}
+	r.UpdateTaskFields(first.ID, TaskPatch{Title: ptr("Write final report")})
+	r.Close()
+	data, _ := os.ReadFile(path)
+	plain, _ := testEncryption(t, "secret").open(data)
+	if !json.Valid(plain) {
+		t.Errorf("Expected a JSON file, got %q", plain)
+	}
+}
+
+func TestWALStoreBinaryCheckpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	opts := WALOptions{Format: FileBinary, CheckpointRecords: 2, KeepSnapshots: 1}
+	store, s := openWALManager(t, path, opts)
+	for _, title := range []string{"First", "Second", "Third", "Fourth"} {
+		s.AddTask(title, "")
+		s.Flush()
+	}
+	s.Close()
+	store.Close()
+
+	for _, file := range []string{path, path + ".1"} {
+		data, _ := os.ReadFile(file)
+		if _, err := ReadSnapshot(bytes.NewReader(data)); err != nil {
+			t.Errorf("Expected %s to be a binary snapshot, got %v", file, err)
+		}
+	}
+	_, r := openWALManager(t, path, opts)
+	defer r.Close()
+	if got := titles(r.ListTasks(nil)); !slices.Equal(got, []string{"First", "Second", "Third", "Fourth"}) {
+		t.Errorf("Expected every task, got %v", got)
+	}
+}
@@ -0,0 +1,502 @@
+package taskmanager
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BoardImportOptions tells ImportTodoist and ImportTrello what to import
+type BoardImportOptions struct {
+	// DryRun reports what the import would create without creating
+	// anything
+	DryRun bool
+	// IncludeArchived imports the tasks of archived Todoist projects and
+	// archived Trello lists and cards, which are skipped otherwise
+	IncludeArchived bool
+}
+
+// BoardImport reports what ImportTodoist or ImportTrello created, or would
+// create on a dry run
+type BoardImport struct {
+	// Projects are the names of the projects created. Projects of the
+	// export named like an existing project, ignoring case, go into that
+	// project instead, and those no imported task goes into are not
+	// created.
+	Projects []string
+	// Tasks are the tasks created, in the order of the export
+	Tasks []BoardTask
+	// Skipped are the entries of the export that were not imported
+	Skipped []BoardSkip
+}
+
+// BoardTask is one task of a BoardImport
+type BoardTask struct {
+	// Source is the ID of the Todoist task or Trello card
+	Source string
+	// Project is the name of the project the task went into, empty when it
+	// has none
+	Project string
+	// Task is a copy of the task created. On a dry run it is the task as
+	// it would be created, without an ID or project ID.
+	Task *Task
+}
+
+// BoardSkip is an entry of an export that was not imported
+type BoardSkip struct {
+	// Source is the ID of the Todoist task or Trello card
+	Source string
+	Title  string
+	Err    error
+}
+
+var (
+	// ErrArchivedEntry is the error of the BoardSkip of an archived entry
+	ErrArchivedEntry = errors.New("archived")
+	// ErrDeletedEntry is the error of the BoardSkip of a deleted entry
+	ErrDeletedEntry = errors.New("deleted")
+)
+
+// boardTimeLayouts are the layouts of due dates without a time zone in
+// exports, after RFC 3339
+var boardTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02"}
+
+// boardExport is an export read into the task model, ready to import
+type boardExport struct {
+	// projects are the names of the projects of the cards, in the order
+	// they first appear
+	projects []string
+	cards    []boardCard
+	skipped  []BoardSkip
+}
+
+// boardCard is a task of an export
+type boardCard struct {
+	source      string
+	project     string
+	title       string
+	description string
+	tags        []string
+	priority    Priority
+	due         *time.Time
+	done        bool
+	checklist   []ChecklistItem
+}
+
+// addProject records the project of a card
+func (b *boardExport) addProject(name string) string {
+	name = strings.TrimSpace(name)
+	if name != "" && !slices.Contains(b.projects, name) {
+		b.projects = append(b.projects, name)
+	}
+	return name
+}
+
+// todoistID is the ID of a Todoist entry, which older exports give as a
+// number and newer ones as a string
+type todoistID string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (id *todoistID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = todoistID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = todoistID(n)
+	return nil
+}
+
+// todoistExport is the part of a Todoist JSON export that is imported
+type todoistExport struct {
+	Projects []struct {
+		ID         todoistID `json:"id"`
+		Name       string    `json:"name"`
+		IsArchived bool      `json:"is_archived"`
+	} `json:"projects"`
+	Items []struct {
+		ID          todoistID `json:"id"`
+		ProjectID   todoistID `json:"project_id"`
+		ParentID    todoistID `json:"parent_id"`
+		Content     string    `json:"content"`
+		Description string    `json:"description"`
+		Priority    int       `json:"priority"`
+		Labels      []string  `json:"labels"`
+		Due         *struct {
+			Date string `json:"date"`
+		} `json:"due"`
+		Checked   bool `json:"checked"`
+		IsDeleted bool `json:"is_deleted"`
+	} `json:"items"`
+}
+
+// ImportTodoist imports a Todoist JSON export, as the Todoist sync API
+// returns it. Projects become projects, labels become tags, and Todoist
+// priorities 1 to 4 become PriorityLow to PriorityUrgent. Subtasks become
+// checklist items of their top-level task, and completed tasks are
+// imported as done. The tasks are added with ImportTasks; those it rejects
+// are reported in Skipped rather than as an error, which is only returned
+// when the export cannot be read.
+func (tm *TaskManager) ImportTodoist(r io.Reader, opts BoardImportOptions) (*BoardImport, error) {
+	export, err := parseTodoist(r, opts, tm.timezone)
+	if err != nil {
+		return nil, err
+	}
+	return tm.importBoard(export, opts.DryRun)
+}
+
+// parseTodoist reads a Todoist export, taking due dates without a time zone
+// in loc
+func parseTodoist(r io.Reader, opts BoardImportOptions, loc *time.Location) (*boardExport, error) {
+	var in todoistExport
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("reading Todoist export: %w", err)
+	}
+	projects := make(map[todoistID]string, len(in.Projects))
+	archived := make(map[todoistID]bool)
+	for _, p := range in.Projects {
+		projects[p.ID] = p.Name
+		archived[p.ID] = p.IsArchived && !opts.IncludeArchived
+	}
+	parents := make(map[todoistID]todoistID, len(in.Items))
+	for _, item := range in.Items {
+		parents[item.ID] = item.ParentID
+	}
+	// root is the top-level task an item is a checklist item of, or the
+	// item itself. The walk is bounded so a cycle of parents ends it.
+	root := func(id todoistID) todoistID {
+		for range len(in.Items) {
+			parent := parents[id]
+			if _, ok := parents[parent]; !ok {
+				break
+			}
+			id = parent
+		}
+		return id
+	}
+
+	export := &boardExport{}
+	cards := make(map[todoistID]int)
+	var subtasks []int
+	for i, item := range in.Items {
+		skip := func(err error) {
+			export.skipped = append(export.skipped, BoardSkip{Source: string(item.ID), Title: item.Content, Err: err})
+		}
+		switch {
+		case item.IsDeleted:
+			skip(ErrDeletedEntry)
+			continue
+		case archived[item.ProjectID]:
+			skip(ErrArchivedEntry)
+			continue
+		case root(item.ID) != item.ID:
+			subtasks = append(subtasks, i)
+			continue
+		}
+		card := boardCard{
+			source:      string(item.ID),
+			title:       item.Content,
+			description: item.Description,
+			tags:        item.Labels,
+			priority:    PriorityMedium,
+			done:        item.Checked,
+		}
+		if p := Priority(item.Priority); p.Valid() {
+			card.priority = p
+		}
+		if name, ok := projects[item.ProjectID]; ok {
+			card.project = export.addProject(name)
+		}
+		if item.Due != nil && item.Due.Date != "" {
+			due, err := parseBoardTime(item.Due.Date, loc)
+			if err != nil {
+				skip(err)
+				continue
+			}
+			card.due = &due
+		}
+		cards[item.ID] = len(export.cards)
+		export.cards = append(export.cards, card)
+	}
+	// Subtasks of skipped tasks are skipped with them
+	for _, i := range subtasks {
+		item := in.Items[i]
+		if c, ok := cards[root(item.ID)]; ok {
+			card := &export.cards[c]
+			card.checklist = append(card.checklist, ChecklistItem{Text: item.Content, Done: item.Checked})
+		}
+	}
+	return export, nil
+}
+
+// trelloExport is the part of a Trello board JSON export that is imported
+type trelloExport struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Labels []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+	Cards []struct {
+		ID          string   `json:"id"`
+		Name        string   `json:"name"`
+		Desc        string   `json:"desc"`
+		IDList      string   `json:"idList"`
+		IDLabels    []string `json:"idLabels"`
+		Due         string   `json:"due"`
+		DueComplete bool     `json:"dueComplete"`
+		Closed      bool     `json:"closed"`
+	} `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+}
+
+// trelloChecklist is a checklist of a Trello card
+type trelloChecklist struct {
+	IDCard     string            `json:"idCard"`
+	Pos        float64           `json:"pos"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+// trelloCheckItem is an item of a Trello checklist
+type trelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// ImportTrello imports a Trello board JSON export. Lists become projects,
+// labels become tags, named by their color when they have no name, and the
+// items of a card's checklists become its checklist. Cards whose due date
+// is marked complete are imported as done. As with ImportTodoist, the
+// tasks ImportTasks rejects are reported in Skipped.
+func (tm *TaskManager) ImportTrello(r io.Reader, opts BoardImportOptions) (*BoardImport, error) {
+	export, err := parseTrello(r, opts, tm.timezone)
+	if err != nil {
+		return nil, err
+	}
+	return tm.importBoard(export, opts.DryRun)
+}
+
+// parseTrello reads a Trello export, taking due dates without a time zone
+// in loc
+func parseTrello(r io.Reader, opts BoardImportOptions, loc *time.Location) (*boardExport, error) {
+	var in trelloExport
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("reading Trello export: %w", err)
+	}
+	lists := make(map[string]string, len(in.Lists))
+	archived := make(map[string]bool)
+	for _, l := range in.Lists {
+		lists[l.ID] = l.Name
+		archived[l.ID] = l.Closed && !opts.IncludeArchived
+	}
+	labels := make(map[string]string, len(in.Labels))
+	for _, l := range in.Labels {
+		labels[l.ID] = cmp.Or(l.Name, l.Color)
+	}
+	// Trello keeps checklists and their items in the order of pos
+	slices.SortStableFunc(in.Checklists, func(a, b trelloChecklist) int {
+		return cmp.Compare(a.Pos, b.Pos)
+	})
+	checklists := make(map[string][]ChecklistItem)
+	for _, c := range in.Checklists {
+		items := slices.Clone(c.CheckItems)
+		slices.SortStableFunc(items, func(a, b trelloCheckItem) int {
+			return cmp.Compare(a.Pos, b.Pos)
+		})
+		for _, item := range items {
+			checklists[c.IDCard] = append(checklists[c.IDCard], ChecklistItem{Text: item.Name, Done: item.State == "complete"})
+		}
+	}
+
+	export := &boardExport{}
+	for _, c := range in.Cards {
+		skip := func(err error) {
+			export.skipped = append(export.skipped, BoardSkip{Source: c.ID, Title: c.Name, Err: err})
+		}
+		if (c.Closed && !opts.IncludeArchived) || archived[c.IDList] {
+			skip(ErrArchivedEntry)
+			continue
+		}
+		card := boardCard{
+			source:      c.ID,
+			title:       c.Name,
+			description: c.Desc,
+			priority:    PriorityMedium,
+			done:        c.DueComplete,
+			checklist:   checklists[c.ID],
+		}
+		for _, id := range c.IDLabels {
+			if name, ok := labels[id]; ok && name != "" {
+				card.tags = append(card.tags, name)
+			}
+		}
+		if name, ok := lists[c.IDList]; ok {
+			card.project = export.addProject(name)
+		}
+		if c.Due != "" {
+			due, err := parseBoardTime(c.Due, loc)
+			if err != nil {
+				skip(err)
+				continue
+			}
+			card.due = &due
+		}
+		export.cards = append(export.cards, card)
+	}
+	return export, nil
+}
+
+// parseBoardTime parses a due date of an export
+func parseBoardTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range boardTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q", s)
+}
+
+// importBoard creates the projects and tasks of an export, or on a dry run
+// reports what it would create. Projects it creates are removed again when
+// the import fails, or when none of the tasks imported goes into them.
+func (tm *TaskManager) importBoard(export *boardExport, dryRun bool) (*BoardImport, error) {
+	result := &BoardImport{Skipped: export.skipped}
+	projectIDs := make(map[string]int, len(export.projects))
+	var created []string
+	// dropCreated removes the projects created and not kept
+	dropCreated := func(keep map[string]bool) {
+		result.Projects = nil
+		for _, name := range created {
+			if keep[name] {
+				result.Projects = append(result.Projects, name)
+			} else {
+				delete(tm.projects, projectIDs[name])
+			}
+		}
+	}
+	for _, name := range export.projects {
+		if project := tm.projectNamed(name); project != nil {
+			projectIDs[name] = project.ID
+			continue
+		}
+		result.Projects = append(result.Projects, name)
+		if dryRun {
+			continue
+		}
+		project, err := tm.CreateProject(name)
+		if err != nil {
+			dropCreated(nil)
+			return nil, err
+		}
+		projectIDs[name] = project.ID
+		created = append(created, name)
+	}
+
+	nextItemID := tm.nextChecklistItemID
+	inputs := make([]TaskInput, len(export.cards))
+	for i, card := range export.cards {
+		opts := []TaskOption{
+			WithTags(card.tags...),
+			WithPriority(card.priority),
+			importedChecklist(card.checklist, &nextItemID),
+		}
+		if card.due != nil {
+			opts = append(opts, WithDueDate(*card.due))
+		}
+		if card.done {
+			opts = append(opts, importedStatus(StatusDone, nil))
+		}
+		if id := projectIDs[card.project]; id != 0 {
+			opts = append(opts, WithProject(id))
+		}
+		inputs[i] = TaskInput{Title: card.title, Description: card.description, Options: opts}
+	}
+	skip := func(card boardCard, err error) {
+		result.Skipped = append(result.Skipped, BoardSkip{Source: card.source, Title: card.title, Err: err})
+	}
+
+	if dryRun {
+		for i, in := range inputs {
+			task := &Task{}
+			initTask(task, in.Title, sanitizeMarkdown(in.Description), tm.now(), in.Options)
+			err := validateTask(task)
+			if err == nil {
+				err = tm.validateLengths(task)
+			}
+			if err != nil {
+				skip(export.cards[i], err)
+				continue
+			}
+			result.Tasks = append(result.Tasks, BoardTask{Source: export.cards[i].source, Project: export.cards[i].project, Task: task})
+		}
+		return result, nil
+	}
+
+	tasks, err := tm.ImportTasks(inputs)
+	var bulk *BulkError
+	if errors.As(err, &bulk) {
+		for _, f := range bulk.Failures {
+			skip(export.cards[f.Index], f.Err)
+		}
+	} else if err != nil {
+		dropCreated(nil)
+		return nil, err
+	}
+	tm.nextChecklistItemID = nextItemID
+	used := make(map[string]bool, len(created))
+	for i, task := range tasks {
+		if task != nil {
+			result.Tasks = append(result.Tasks, BoardTask{Source: export.cards[i].source, Project: export.cards[i].project, Task: task.clone()})
+			used[export.cards[i].project] = true
+		}
+	}
+	dropCreated(used)
+	return result, nil
+}
+
+// projectNamed returns the project with the name, ignoring case, or nil
+func (tm *TaskManager) projectNamed(name string) *Project {
+	for _, project := range tm.projects {
+		if strings.EqualFold(project.Name, name) {
+			return project
+		}
+	}
+	return nil
+}
+
+// importedChecklist gives an imported task its checklist, numbering the
+// items from *next
+func importedChecklist(items []ChecklistItem, next *int) TaskOption {
+	return func(t *Task) {
+		if len(items) == 0 {
+			return
+		}
+		t.Checklist = make([]ChecklistItem, 0, len(items))
+		for _, item := range items {
+			item.Text = strings.TrimSpace(item.Text)
+			if item.Text == "" {
+				continue
+			}
+			item.ID = *next
+			*next++
+			t.Checklist = append(t.Checklist, item)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package taskmanager
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const todoistFixture = `{
+	"projects": [
+		{"id": "220474322", "name": "Inbox"},
+		{"id": 220474323, "name": "Course"},
+		{"id": "220474324", "name": "Old", "is_archived": true}
+	],
+	"items": [
+		{"id": "1", "project_id": "220474323", "content": "Write report", "description": "Chapter 2", "priority": 4, "labels": ["Work", "urgent"], "due": {"date": "2025-07-03T12:00:00"}},
+		{"id": "2", "project_id": "220474323", "parent_id": "1", "content": "Outline", "checked": true},
+		{"id": "3", "project_id": "220474323", "parent_id": "2", "content": "Find sources"},
+		{"id": "4", "project_id": "220474322", "content": "Buy milk", "priority": 1, "checked": true, "due": {"date": "2025-07-01"}},
+		{"id": "5", "project_id": "220474324", "content": "Archived"},
+		{"id": "6", "project_id": "220474322", "content": "Gone", "is_deleted": true},
+		{"id": "7", "project_id": "220474322", "content": "   "},
+		{"id": "8", "project_id": "220474322", "content": "Bad date", "due": {"date": "tomorrow"}}
+	]
+}`
+
+const trelloFixture = `{
+	"name": "Lab board",
+	"lists": [
+		{"id": "l1", "name": "To do"},
+		{"id": "l2", "name": "Course"},
+		{"id": "l3", "name": "Old", "closed": true}
+	],
+	"labels": [
+		{"id": "b1", "name": "work", "color": "green"},
+		{"id": "b2", "name": "", "color": "red"}
+	],
+	"cards": [
+		{"id": "c1", "name": "Slides", "desc": "For Friday", "idList": "l2", "idLabels": ["b1", "b2"], "due": "2025-07-04T09:30:00.000Z"},
+		{"id": "c2", "name": "Groceries", "idList": "l1", "dueComplete": true, "due": "2025-06-30T10:00:00.000Z"},
+		{"id": "c3", "name": "Closed card", "idList": "l1", "closed": true},
+		{"id": "c4", "name": "In closed list", "idList": "l3"}
+	],
+	"checklists": [
+		{"idCard": "c1", "pos": 2, "checkItems": [{"name": "Review", "state": "incomplete", "pos": 1}]},
+		{"idCard": "c1", "pos": 1, "checkItems": [
+			{"name": "Design", "state": "incomplete", "pos": 2},
+			{"name": "Outline", "state": "complete", "pos": 1}
+		]}
+	]
+}`
+
+func TestImportTodoist(t *testing.T) {
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	tm := NewTaskManager(WithClock(clock))
+	tm.CreateProject("inbox")
+
+	result, err := tm.ImportTodoist(strings.NewReader(todoistFixture), BoardImportOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"Course"}; !reflect.DeepEqual(result.Projects, want) {
+		t.Errorf("Expected projects %v created, got %v", want, result.Projects)
+	}
+	if len(result.Tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(result.Tasks))
+	}
+
+	report := result.Tasks[0].Task
+	due := time.Date(2025, 7, 3, 12, 0, 0, 0, time.UTC)
+	if result.Tasks[0].Source != "1" || result.Tasks[0].Project != "Course" {
+		t.Errorf("Expected task 1 of Course, got %+v", result.Tasks[0])
+	}
+	if report.Priority != PriorityUrgent || !slices.Equal(report.Tags, []string{"work", "urgent"}) || report.DueDate == nil || !report.DueDate.Equal(due) {
+		t.Errorf("Expected an urgent task tagged work and urgent due %v, got %+v", due, report)
+	}
+	wantChecklist := []string{"Outline", "Find sources"}
+	var checklist []string
+	for _, item := range report.Checklist {
+		checklist = append(checklist, item.Text)
+	}
+	if !slices.Equal(checklist, wantChecklist) || !report.Checklist[0].Done || report.Checklist[1].Done {
+		t.Errorf("Expected checklist %v with the first item done, got %+v", wantChecklist, report.Checklist)
+	}
+	if report.Checklist[0].ID == report.Checklist[1].ID {
+		t.Errorf("Expected distinct checklist item IDs, got %+v", report.Checklist)
+	}
+
+	milk := result.Tasks[1].Task
+	inbox := tm.projectNamed("Inbox")
+	if milk.Status != StatusDone || milk.Priority != PriorityLow || milk.ProjectID != inbox.ID {
+		t.Errorf("Expected a done, low priority task in the existing inbox, got %+v", milk)
+	}
+
+	skipped := map[string]error{}
+	for _, s := range result.Skipped {
+		skipped[s.Source] = s.Err
+	}
+	if !errors.Is(skipped["5"], ErrArchivedEntry) || !errors.Is(skipped["6"], ErrDeletedEntry) || !errors.Is(skipped["7"], ErrEmptyTitle) || skipped["8"] == nil {
+		t.Errorf("Expected items 5 to 8 skipped, got %v", result.Skipped)
+	}
+	if got := len(tm.ListTasks(nil)); got != 2 {
+		t.Errorf("Expected 2 tasks stored, got %d", got)
+	}
+
+	if _, err := tm.ImportTodoist(strings.NewReader("{"), BoardImportOptions{}); err == nil {
+		t.Error("Expected an error for a malformed export")
+	}
+}
+
+func TestImportTrello(t *testing.T) {
+	tm := NewTaskManager()
+	result, err := tm.ImportTrello(strings.NewReader(trelloFixture), BoardImportOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"Course", "To do"}; !reflect.DeepEqual(result.Projects, want) {
+		t.Errorf("Expected projects %v created, got %v", want, result.Projects)
+	}
+	if got := len(result.Tasks); got != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", got)
+	}
+	slides := result.Tasks[0].Task
+	if !slices.Equal(slides.Tags, []string{"work", "red"}) || slides.Description != "For Friday" {
+		t.Errorf("Expected the card's labels and description, got %+v", slides)
+	}
+	var checklist []string
+	for _, item := range slides.Checklist {
+		checklist = append(checklist, item.Text)
+	}
+	if want := []string{"Outline", "Design", "Review"}; !slices.Equal(checklist, want) {
+		t.Errorf("Expected checklist %v, got %v", want, checklist)
+	}
+	if groceries := result.Tasks[1].Task; groceries.Status != StatusDone {
+		t.Errorf("Expected the completed card done, got %v", groceries.Status)
+	}
+	if len(result.Skipped) != 2 {
+		t.Errorf("Expected the closed card and list skipped, got %v", result.Skipped)
+	}
+
+	archived, err := NewTaskManager().ImportTrello(strings.NewReader(trelloFixture), BoardImportOptions{IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(archived.Tasks) != 4 || len(archived.Skipped) != 0 {
+		t.Errorf("Expected every card imported, got %d tasks and %v", len(archived.Tasks), archived.Skipped)
+	}
+}
+
+func TestBoardImportRejected(t *testing.T) {
+	// Only Slides fits, so the project of Groceries is not created
+	tm := NewTaskManager(WithMaxTasks(1))
+	result, err := tm.ImportTrello(strings.NewReader(trelloFixture), BoardImportOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"Course"}; !reflect.DeepEqual(result.Projects, want) {
+		t.Errorf("Expected projects %v created, got %v", want, result.Projects)
+	}
+	if got := len(tm.ListProjects()); got != 1 {
+		t.Errorf("Expected 1 project stored, got %d", got)
+	}
+
+	// With no room left, nothing is created at all
+	full, err := tm.ImportTodoist(strings.NewReader(todoistFixture), BoardImportOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(full.Projects) != 0 || len(full.Tasks) != 0 || len(tm.ListProjects()) != 1 {
+		t.Errorf("Expected no project or task created, got %v and %d tasks", tm.ListProjects(), len(full.Tasks))
+	}
+}
+
+func TestBoardImportDryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(tm *TaskManager, r io.Reader, opts BoardImportOptions) (*BoardImport, error)
+		fixture string
+	}{
+		{"todoist", (*TaskManager).ImportTodoist, todoistFixture},
+		{"trello", (*TaskManager).ImportTrello, trelloFixture},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dry := NewTaskManager()
+			planned, err := tt.run(dry, strings.NewReader(tt.fixture), BoardImportOptions{DryRun: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(dry.ListTasks(nil)) != 0 || len(dry.ListProjects()) != 0 {
+				t.Errorf("Expected a dry run to create nothing")
+			}
+
+			tm := NewTaskManager()
+			done, err := tt.run(tm, strings.NewReader(tt.fixture), BoardImportOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(planned.Projects, done.Projects) || len(planned.Tasks) != len(done.Tasks) || len(planned.Skipped) != len(done.Skipped) {
+				t.Errorf("Expected the dry run to report %+v, got %+v", done, planned)
+			}
+			for i, task := range planned.Tasks {
+				if task.Task.ID != 0 || task.Task.Title != done.Tasks[i].Task.Title || task.Project != done.Tasks[i].Project {
+					t.Errorf("Expected %+v without an ID, got %+v", done.Tasks[i], task)
+				}
+			}
+		})
+	}
+}
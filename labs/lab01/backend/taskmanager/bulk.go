@@ -0,0 +1,130 @@
+package taskmanager
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaskInput describes one task to add with AddTasks
+type TaskInput struct {
+	Title       string
+	Description string
+	Options     []TaskOption
+}
+
+// TaskUpdate describes one change to apply with UpdateTasks. Its fields
+// have the same meaning as the arguments of UpdateTask.
+type TaskUpdate struct {
+	ID          int
+	Title       string
+	Description string
+	Done        bool
+	Options     []TaskOption
+}
+
+// ItemError is the failure of a single item of a bulk call
+type ItemError struct {
+	// Index is the position of the item in the bulk call's input
+	Index int
+	// ID is the task the item referred to, or zero for a task that was
+	// never added
+	ID  int
+	Err error
+}
+
+// BulkError lists the items of a bulk call that failed. The other items
+// were applied.
+type BulkError struct {
+	Failures []ItemError
+}
+
+// Error implements the error interface
+func (e *BulkError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		if f.ID != 0 {
+			parts[i] = fmt.Sprintf("task %d: %v", f.ID, f.Err)
+		} else {
+			parts[i] = fmt.Sprintf("item %d: %v", f.Index, f.Err)
+		}
+	}
+	return fmt.Sprintf("%d of the items failed: %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// Unwrap lets errors.Is and errors.As look at every item's error
+func (e *BulkError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// AddTasks adds several tasks at once. The returned slice lines up with
+// inputs and holds nil for every input that failed; the failures are
+// reported in a *BulkError. The whole call is undone as one operation.
+func (tm *TaskManager) AddTasks(inputs []TaskInput) ([]*Task, error) {
+	defer tm.beginOperation()()
+
+	var failures []ItemError
+	tasks := make([]*Task, len(inputs))
+	for i, in := range inputs {
+		task, err := tm.AddTask(in.Title, in.Description, in.Options...)
+		if err != nil {
+			failures = append(failures, ItemError{Index: i, Err: err})
+			continue
+		}
+		tasks[i] = task
+	}
+	return tasks, bulkError(failures)
+}
+
+// UpdateTasks applies several updates at once, reporting the ones that
+// failed in a *BulkError
+func (tm *TaskManager) UpdateTasks(updates []TaskUpdate) error {
+	defer tm.beginOperation()()
+
+	var failures []ItemError
+	for i, u := range updates {
+		if err := tm.UpdateTask(u.ID, u.Title, u.Description, u.Done, u.Options...); err != nil {
+			failures = append(failures, ItemError{Index: i, ID: u.ID, Err: err})
+		}
+	}
+	return bulkError(failures)
+}
+
+// DeleteTasks moves several tasks to the trash, reporting the ones that
+// failed in a *BulkError
+func (tm *TaskManager) DeleteTasks(ids []int) error {
+	return tm.eachTask(ids, tm.DeleteTask)
+}
+
+// CompleteTasks moves several tasks to StatusDone, reporting the ones that
+// failed in a *BulkError
+func (tm *TaskManager) CompleteTasks(ids []int) error {
+	return tm.eachTask(ids, func(id int) error {
+		return tm.CompleteTask(id, "")
+	})
+}
+
+// eachTask runs fn for every ID as a single operation and collects the failures
+func (tm *TaskManager) eachTask(ids []int, fn func(int) error) error {
+	defer tm.beginOperation()()
+
+	var failures []ItemError
+	for i, id := range ids {
+		if err := fn(id); err != nil {
+			failures = append(failures, ItemError{Index: i, ID: id, Err: err})
+		}
+	}
+	return bulkError(failures)
+}
+
+// bulkError returns nil when nothing failed, so callers can compare the
+// result against nil without a typed nil slipping through
+func bulkError(failures []ItemError) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &BulkError{Failures: failures}
+}
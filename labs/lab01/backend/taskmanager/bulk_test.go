@@ -0,0 +1,102 @@
+package taskmanager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddTasks(t *testing.T) {
+	tm := NewTaskManager()
+	tasks, err := tm.AddTasks([]TaskInput{
+		{Title: "A"},
+		{Title: " "},
+		{Title: "C", Options: []TaskOption{WithPriority(PriorityHigh)}},
+	})
+
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Failures) != 1 {
+		t.Fatalf("Expected one failure, got %v", err)
+	}
+	if f := bulkErr.Failures[0]; f.Index != 1 || f.Err != ErrEmptyTitle {
+		t.Errorf("Expected item 1 to fail with ErrEmptyTitle, got %+v", f)
+	}
+	if !errors.Is(err, ErrEmptyTitle) {
+		t.Errorf("Expected errors.Is to see ErrEmptyTitle in %v", err)
+	}
+	if len(tasks) != 3 || tasks[0] == nil || tasks[1] != nil || tasks[2].Priority != PriorityHigh {
+		t.Errorf("Expected results to line up with inputs, got %v", tasks)
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tm.ListTasks(nil)) != 0 {
+		t.Errorf("Expected undo to remove the whole batch, got %d tasks", len(tm.ListTasks(nil)))
+	}
+}
+
+func TestBulkMutations(t *testing.T) {
+	tm := NewTaskManager()
+	a := mustAddTask(t, tm, "A")
+	b := mustAddTask(t, tm, "B")
+	c := mustAddTask(t, tm, "C")
+	if err := tm.Transition(c.ID, StatusCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		run       func() error
+		failedIDs []int
+	}{
+		{
+			name: "update",
+			run: func() error {
+				return tm.UpdateTasks([]TaskUpdate{
+					{ID: a.ID, Title: "A2"},
+					{ID: 999, Title: "Missing"},
+				})
+			},
+			failedIDs: []int{999},
+		},
+		{
+			name:      "complete",
+			run:       func() error { return tm.CompleteTasks([]int{a.ID, c.ID}) },
+			failedIDs: []int{c.ID},
+		},
+		{
+			name:      "delete",
+			run:       func() error { return tm.DeleteTasks([]int{b.ID, 999, b.ID}) },
+			failedIDs: []int{999, b.ID},
+		},
+		{
+			name: "no failures",
+			run:  func() error { return tm.DeleteTasks([]int{a.ID}) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if len(tt.failedIDs) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var bulkErr *BulkError
+			if !errors.As(err, &bulkErr) || len(bulkErr.Failures) != len(tt.failedIDs) {
+				t.Fatalf("Expected %d failures, got %v", len(tt.failedIDs), err)
+			}
+			for i, id := range tt.failedIDs {
+				if bulkErr.Failures[i].ID != id {
+					t.Errorf("Expected failure %d for task %d, got %+v", i, id, bulkErr.Failures[i])
+				}
+			}
+		})
+	}
+
+	if a.Title != "A2" || a.Status != StatusDone {
+		t.Errorf("Expected successful items to be applied, got %+v", a)
+	}
+}
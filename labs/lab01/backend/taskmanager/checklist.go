@@ -0,0 +1,113 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrChecklistItemNotFound is returned when a task has no checklist item with the given ID
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	// ErrEmptyChecklistItem is returned when a checklist item has no text
+	ErrEmptyChecklistItem = errors.New("checklist item text cannot be empty")
+	// ErrInvalidPosition is returned when moving a checklist item outside the list
+	ErrInvalidPosition = errors.New("invalid checklist position")
+)
+
+// ChecklistItem is a single step inside a task
+type ChecklistItem struct {
+	ID   int
+	Text string
+	Done bool
+}
+
+// AddChecklistItem appends a step to a task's checklist
+func (tm *TaskManager) AddChecklistItem(taskID int, text string) (ChecklistItem, error) {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return ChecklistItem{}, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ChecklistItem{}, ErrEmptyChecklistItem
+	}
+
+	item := ChecklistItem{ID: tm.nextChecklistItemID, Text: text}
+	tm.nextChecklistItemID++
+	tm.update(task, func() {
+		task.Checklist = append(task.Checklist, item)
+	})
+	return item, nil
+}
+
+// ToggleChecklistItem flips the done state of a checklist item
+func (tm *TaskManager) ToggleChecklistItem(taskID, itemID int) error {
+	task, i, err := tm.findChecklistItem(taskID, itemID)
+	if err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.Checklist[i].Done = !task.Checklist[i].Done
+	})
+	return nil
+}
+
+// RemoveChecklistItem deletes a step from a task's checklist
+func (tm *TaskManager) RemoveChecklistItem(taskID, itemID int) error {
+	task, i, err := tm.findChecklistItem(taskID, itemID)
+	if err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.Checklist = slices.Delete(task.Checklist, i, i+1)
+	})
+	return nil
+}
+
+// MoveChecklistItem moves a checklist item to the given zero-based position
+func (tm *TaskManager) MoveChecklistItem(taskID, itemID, position int) error {
+	task, i, err := tm.findChecklistItem(taskID, itemID)
+	if err != nil {
+		return err
+	}
+	if position < 0 || position >= len(task.Checklist) {
+		return ErrInvalidPosition
+	}
+	tm.update(task, func() {
+		item := task.Checklist[i]
+		items := slices.Delete(task.Checklist, i, i+1)
+		task.Checklist = slices.Insert(items, position, item)
+	})
+	return nil
+}
+
+// ChecklistProgress returns the fraction of checklist items that are done,
+// or zero when the task has no checklist
+func (t *Task) ChecklistProgress() float64 {
+	if len(t.Checklist) == 0 {
+		return 0
+	}
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return float64(done) / float64(len(t.Checklist))
+}
+
+// findChecklistItem returns a task and the index of one of its checklist items
+func (tm *TaskManager) findChecklistItem(taskID, itemID int) (*Task, int, error) {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+	i := slices.IndexFunc(task.Checklist, func(item ChecklistItem) bool {
+		return item.ID == itemID
+	})
+	if i < 0 {
+		return nil, 0, ErrChecklistItemNotFound
+	}
+	return task, i, nil
+}
@@ -0,0 +1,75 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestChecklist(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Pack")
+
+	var ids []int
+	for _, text := range []string{"Socks", "Shirts", "Charger"} {
+		item, err := tm.AddChecklistItem(task.ID, text)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+	if _, err := tm.AddChecklistItem(task.ID, "  "); err != ErrEmptyChecklistItem {
+		t.Errorf("Expected ErrEmptyChecklistItem, got %v", err)
+	}
+
+	if err := tm.ToggleChecklistItem(task.ID, ids[0]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if progress := task.ChecklistProgress(); progress != 1.0/3 {
+		t.Errorf("Expected progress 1/3, got %v", progress)
+	}
+
+	if err := tm.MoveChecklistItem(task.ID, ids[2], 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Checklist[0].Text != "Charger" || task.Checklist[1].Text != "Socks" {
+		t.Errorf("Expected Charger to move to the top, got %+v", task.Checklist)
+	}
+	if err := tm.MoveChecklistItem(task.ID, ids[2], 3); err != ErrInvalidPosition {
+		t.Errorf("Expected ErrInvalidPosition, got %v", err)
+	}
+
+	if err := tm.RemoveChecklistItem(task.ID, ids[1]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.ToggleChecklistItem(task.ID, ids[1]); err != ErrChecklistItemNotFound {
+		t.Errorf("Expected ErrChecklistItemNotFound, got %v", err)
+	}
+	if progress := task.ChecklistProgress(); progress != 0.5 {
+		t.Errorf("Expected progress 1/2, got %v", progress)
+	}
+}
+
+func TestChecklistProgressEmpty(t *testing.T) {
+	task := &Task{Title: "Task"}
+	if progress := task.ChecklistProgress(); progress != 0 {
+		t.Errorf("Expected zero progress without a checklist, got %v", progress)
+	}
+}
+
+func TestChecklistUndo(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Pack")
+	item, err := tm.AddChecklistItem(task.ID, "Socks")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.UpdateTask(task.ID, "Pack bags", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(task.Checklist) != 1 || task.Checklist[0].ID != item.ID {
+		t.Errorf("Expected the checklist to survive undoing an unrelated update, got %+v", task.Checklist)
+	}
+}
@@ -0,0 +1,88 @@
+package taskmanager
+
+// CloneOptions controls what CloneTask copies besides the task's own fields
+type CloneOptions struct {
+	// Title replaces the title of the copy when non-empty
+	Title string
+	// IncludeSubtasks copies the whole subtree under the task
+	IncludeSubtasks bool
+	// IncludeChecklist copies checklist items, all marked as not done
+	IncludeChecklist bool
+}
+
+// CloneTask adds a copy of a task with its completion state reset. Comments,
+// attachments, history and tracked time belong to the original and are not
+// copied.
+func (tm *TaskManager) CloneTask(id int, opts CloneOptions) (*Task, error) {
+	defer tm.beginOperation()()
+
+	src, err := tm.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	c := tm.freshCopy(src, opts.IncludeChecklist)
+	if opts.Title != "" {
+		c.Title = opts.Title
+	}
+	if err := tm.validate(c); err != nil {
+		return nil, err
+	}
+	if err := tm.reserve(1); err != nil {
+		return nil, err
+	}
+	tm.insert(c)
+
+	if opts.IncludeSubtasks {
+		childOpts := opts
+		childOpts.Title = ""
+		if err := tm.cloneChildren(src.ID, c.ID, childOpts); err != nil {
+			return nil, err
+		}
+	}
+	return tm.export(c), nil
+}
+
+// cloneChildren copies the subtasks of srcID, and theirs in turn, under parentID
+func (tm *TaskManager) cloneChildren(srcID, parentID int, opts CloneOptions) error {
+	for _, child := range tm.children(srcID) {
+		c := tm.freshCopy(child, opts.IncludeChecklist)
+		c.ParentID = parentID
+		if err := tm.validate(c); err != nil {
+			return err
+		}
+		if err := tm.reserve(1); err != nil {
+			return err
+		}
+		tm.insert(c)
+		if err := tm.cloneChildren(child.ID, c.ID, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freshCopy returns an unsaved copy of a task as if it had just been created
+func (tm *TaskManager) freshCopy(src *Task, includeChecklist bool) *Task {
+	c := src.clone()
+	c.ID = 0
+	c.Status = StatusTodo
+	c.CreatedAt = tm.now()
+	c.CompletedAt = nil
+	c.History = nil
+	c.Comments = nil
+	c.Attachments = nil
+	c.TrackedTime = 0
+	c.TimerStarted = nil
+	c.Archived = false
+	c.SnoozedUntil = nil
+	c.DeletedAt = nil
+	if !includeChecklist {
+		c.Checklist = nil
+	}
+	for i := range c.Checklist {
+		c.Checklist[i].ID = tm.nextChecklistItemID
+		c.Checklist[i].Done = false
+		tm.nextChecklistItemID++
+	}
+	return c
+}
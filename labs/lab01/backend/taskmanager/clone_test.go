@@ -0,0 +1,92 @@
+package taskmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCloneTask(t *testing.T) {
+	tm := NewTaskManager()
+	due := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	src := mustAddTask(t, tm, "Report",
+		WithPriority(PriorityHigh),
+		WithTags("work"),
+		WithDueDate(due),
+		WithEstimate(time.Hour),
+	)
+	item, err := tm.AddChecklistItem(src.ID, "Outline")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.ToggleChecklistItem(src.ID, item.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.AddComment(src.ID, "alice", "First draft done"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Transition(src.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		opts          CloneOptions
+		expectedTitle string
+		expectedItems int
+	}{
+		{name: "plain copy", opts: CloneOptions{}, expectedTitle: "Report", expectedItems: 0},
+		{name: "with checklist and title", opts: CloneOptions{Title: "Report v2", IncludeChecklist: true}, expectedTitle: "Report v2", expectedItems: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tm.CloneTask(src.ID, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if c.ID == src.ID || c.Title != tt.expectedTitle {
+				t.Errorf("Expected a new task titled %q, got %d %q", tt.expectedTitle, c.ID, c.Title)
+			}
+			if c.Status != StatusTodo || len(c.Comments) != 0 {
+				t.Errorf("Expected completion state and comments to be reset, got %+v", c)
+			}
+			if c.Priority != PriorityHigh || !c.HasTag("work") || !c.DueDate.Equal(due) || c.Estimate != time.Hour {
+				t.Errorf("Expected fields to be copied, got %+v", c)
+			}
+			if len(c.Checklist) != tt.expectedItems {
+				t.Fatalf("Expected %d checklist items, got %+v", tt.expectedItems, c.Checklist)
+			}
+			if tt.expectedItems > 0 && (c.Checklist[0].Done || c.Checklist[0].ID == item.ID) {
+				t.Errorf("Expected a fresh unchecked item, got %+v", c.Checklist[0])
+			}
+		})
+	}
+}
+
+func TestCloneTaskWithSubtasks(t *testing.T) {
+	tm := NewTaskManager()
+	root := mustAddTask(t, tm, "Release")
+	child := mustAddTask(t, tm, "Build", WithParent(root.ID))
+	mustAddTask(t, tm, "Test", WithParent(child.ID))
+
+	c, err := tm.CloneTask(root.ID, CloneOptions{Title: "Release 2", IncludeSubtasks: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	children, _ := tm.ListChildren(c.ID)
+	if len(children) != 1 || children[0].Title != "Build" {
+		t.Fatalf("Expected the copied Build subtask, got %v", children)
+	}
+	grandchildren, _ := tm.ListChildren(children[0].ID)
+	if len(grandchildren) != 1 || grandchildren[0].Title != "Test" {
+		t.Errorf("Expected the copied Test subtask, got %v", grandchildren)
+	}
+	if len(tm.ListTasks(nil)) != 6 {
+		t.Errorf("Expected 6 tasks after cloning the tree, got %d", len(tm.ListTasks(nil)))
+	}
+
+	if _, err := tm.CloneTask(999, CloneOptions{}); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCommentNotFound is returned when a task has no comment with the given ID
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrEmptyComment is returned when a comment body is empty
+	ErrEmptyComment = errors.New("comment body cannot be empty")
+	// ErrEmptyAuthor is returned when a comment has no author
+	ErrEmptyAuthor = errors.New("comment author cannot be empty")
+)
+
+// Comment is a message in a task's discussion thread
+type Comment struct {
+	ID        int
+	Author    string
+	Body      string
+	CreatedAt time.Time
+	EditedAt  *time.Time
+}
+
+// AddComment appends a comment to a task's thread
+func (tm *TaskManager) AddComment(taskID int, author, body string) (Comment, error) {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return Comment{}, err
+	}
+	author = strings.TrimSpace(author)
+	if author == "" {
+		return Comment{}, ErrEmptyAuthor
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return Comment{}, ErrEmptyComment
+	}
+
+	comment := Comment{
+		ID:        tm.nextCommentID,
+		Author:    author,
+		Body:      body,
+		CreatedAt: tm.now(),
+	}
+	tm.nextCommentID++
+	task.Comments = append(task.Comments, comment)
+	task.UpdatedAt = comment.CreatedAt
+	tm.markChanged(task.ID)
+	return comment, nil
+}
+
+// EditComment replaces the body of a comment and records when it was edited
+func (tm *TaskManager) EditComment(taskID, commentID int, body string) error {
+	task, comment, err := tm.findComment(taskID, commentID)
+	if err != nil {
+		return err
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return ErrEmptyComment
+	}
+	now := tm.now()
+	comment.Body = body
+	comment.EditedAt = &now
+	task.UpdatedAt = now
+	tm.markChanged(task.ID)
+	return nil
+}
+
+// DeleteComment removes a comment from a task's thread
+func (tm *TaskManager) DeleteComment(taskID, commentID int) error {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(task.Comments, func(c Comment) bool {
+		return c.ID == commentID
+	})
+	if i < 0 {
+		return ErrCommentNotFound
+	}
+	task.Comments = slices.Delete(task.Comments, i, i+1)
+	task.UpdatedAt = tm.now()
+	tm.markChanged(task.ID)
+	return nil
+}
+
+// ListComments returns the comments of a task, oldest first
+func (tm *TaskManager) ListComments(taskID int) ([]Comment, error) {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return nil, err
+	}
+	comments := slices.Clone(task.Comments)
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	return comments, nil
+}
+
+// findComment returns a task and a pointer to one of its comments
+func (tm *TaskManager) findComment(taskID, commentID int) (*Task, *Comment, error) {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range task.Comments {
+		if task.Comments[i].ID == commentID {
+			return task, &task.Comments[i], nil
+		}
+	}
+	return nil, nil, ErrCommentNotFound
+}
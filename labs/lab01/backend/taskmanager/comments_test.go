@@ -0,0 +1,89 @@
+package taskmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddComment(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Report")
+
+	tests := []struct {
+		name        string
+		taskID      int
+		author      string
+		body        string
+		expectError error
+	}{
+		{name: "valid comment", taskID: task.ID, author: "alice", body: "Looks good"},
+		{name: "missing task", taskID: 999, author: "alice", body: "Hello", expectError: ErrTaskNotFound},
+		{name: "empty author", taskID: task.ID, author: " ", body: "Hello", expectError: ErrEmptyAuthor},
+		{name: "empty body", taskID: task.ID, author: "alice", body: "", expectError: ErrEmptyComment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comment, err := tm.AddComment(tt.taskID, tt.author, tt.body)
+
+			if tt.expectError != nil {
+				if err != tt.expectError {
+					t.Errorf("Expected %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if comment.Author != tt.author || comment.Body != tt.body {
+				t.Errorf("Expected %s: %s, got %s: %s", tt.author, tt.body, comment.Author, comment.Body)
+			}
+		})
+	}
+}
+
+func TestEditAndDeleteComment(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Report")
+	first, err := tm.AddComment(task.ID, "alice", "First")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	second, err := tm.AddComment(task.ID, "bob", "Second")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := tm.EditComment(task.ID, first.ID, "First, edited"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.EditComment(task.ID, 999, "Nope"); err != ErrCommentNotFound {
+		t.Errorf("Expected ErrCommentNotFound, got %v", err)
+	}
+
+	comments, err := tm.ListComments(task.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != first.ID || comments[1].ID != second.ID {
+		t.Fatalf("Expected comments in creation order, got %+v", comments)
+	}
+	if comments[0].Body != "First, edited" || comments[0].EditedAt == nil || !comments[0].EditedAt.Equal(now) {
+		t.Errorf("Expected edited comment with EditedAt set, got %+v", comments[0])
+	}
+
+	if err := tm.DeleteComment(task.ID, first.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteComment(task.ID, first.ID); err != ErrCommentNotFound {
+		t.Errorf("Expected ErrCommentNotFound, got %v", err)
+	}
+	comments, _ = tm.ListComments(task.ID)
+	if len(comments) != 1 || comments[0].ID != second.ID {
+		t.Errorf("Expected only the second comment to remain, got %+v", comments)
+	}
+}
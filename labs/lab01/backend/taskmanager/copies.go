@@ -0,0 +1,31 @@
+package taskmanager
+
+// WithDefensiveCopies makes every method that returns tasks, such as
+// GetTask, ListTasks and AddTask, return deep copies, so callers cannot
+// change stored tasks without going through the manager's validation.
+// Tasks and ForEachTask still visit the stored tasks without copying them,
+// for read-heavy code that can promise not to modify what it is given.
+func WithDefensiveCopies() Option {
+	return func(tm *TaskManager) {
+		tm.copies = true
+	}
+}
+
+// export returns the task as handed to callers: a deep copy when defensive
+// copies are enabled, and the stored task otherwise
+func (tm *TaskManager) export(task *Task) *Task {
+	if tm.copies {
+		return task.clone()
+	}
+	return task
+}
+
+// exportAll replaces each task in place with what export returns for it
+func (tm *TaskManager) exportAll(tasks []*Task) []*Task {
+	if tm.copies {
+		for i, task := range tasks {
+			tasks[i] = task.clone()
+		}
+	}
+	return tasks
+}
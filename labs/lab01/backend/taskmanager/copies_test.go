@@ -0,0 +1,83 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestDefensiveCopies(t *testing.T) {
+	tm := NewTaskManager(WithDefensiveCopies())
+	added := mustAddTask(t, tm, "Write report", WithTags("work"))
+	mustAddTask(t, tm, "Buy milk")
+
+	template, err := tm.CreateTemplate(Template{Name: "Weekly", TitlePattern: "Weekly review", Checklist: []string{"Inbox"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fromTemplate, err := tm.CreateFromTemplate(template.ID, TemplateOverrides{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fromTemplate.Checklist) != 1 {
+		t.Errorf("Expected the returned copy to include the checklist, got %+v", fromTemplate.Checklist)
+	}
+	clone, err := tm.CloneTask(added.ID, CloneOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, _ := tm.GetTask(added.ID)
+	page, _ := tm.FindTasks(ListOptions{})
+	sources := map[string]*Task{
+		"AddTask":            added,
+		"GetTask":            got,
+		"ListTasks":          tm.ListTasks(nil)[0],
+		"FindTasks":          page.Tasks[0],
+		"Search":             tm.Search("report")[0],
+		"CreateFromTemplate": fromTemplate,
+		"CloneTask":          clone,
+	}
+	for name, task := range sources {
+		task.Title = "Changed"
+		stored, _ := tm.lookup(task.ID)
+		if stored == task || stored.Title == "Changed" {
+			t.Errorf("%s: expected a copy, the stored task was changed", name)
+		}
+		if len(task.Tags) > 0 {
+			task.Tags[0] = "changed"
+			if stored.Tags[0] == "changed" {
+				t.Errorf("%s: expected a deep copy, the stored tags were changed", name)
+			}
+		}
+	}
+
+	// Operations that work on stored tasks internally are unaffected
+	if err := tm.MoveTask(clone.ID, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first := tm.ListTasks(nil, SortByPosition())[0]; first.ID != clone.ID {
+		t.Errorf("Expected the moved task first, got %d", first.ID)
+	}
+	if err := tm.UpdateTask(added.ID, "Write the report", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, _ := tm.GetTask(added.ID); got.Title != "Write the report" {
+		t.Errorf("Expected updates to go through, got %q", got.Title)
+	}
+
+	var visited *Task
+	_ = tm.ForEachTask(ListOptions{}, func(task *Task) bool {
+		visited = task
+		return false
+	})
+	if stored, _ := tm.lookup(visited.ID); stored != visited {
+		t.Error("Expected ForEachTask to visit the stored tasks")
+	}
+}
+
+func TestSharedTasksByDefault(t *testing.T) {
+	tm := NewTaskManager()
+	added := mustAddTask(t, tm, "Write report")
+	if got, _ := tm.GetTask(added.ID); got != added {
+		t.Error("Expected GetTask to return the stored task without WithDefensiveCopies")
+	}
+}
@@ -0,0 +1,56 @@
+package taskmanager
+
+import "maps"
+
+// TaskCounts gives the number of active tasks in total and by status,
+// priority and tag. Archived and snoozed tasks are included.
+type TaskCounts struct {
+	Total int
+	// Open is the number of tasks that are neither done nor cancelled
+	Open       int
+	Done       int
+	ByStatus   map[Status]int
+	ByPriority map[Priority]int
+	ByTag      map[string]int
+}
+
+// TaskCounts returns the current task counts. They are kept up to date by
+// the index on every change, so the cost depends on the number of distinct
+// statuses, priorities and tags rather than on the number of tasks, and
+// dashboards can poll it often.
+func (tm *TaskManager) TaskCounts() TaskCounts {
+	counts := TaskCounts{
+		Total:      len(tm.index.entries),
+		ByStatus:   make(map[Status]int, len(tm.index.byStatus)),
+		ByPriority: maps.Clone(tm.index.priorities),
+		ByTag:      make(map[string]int, len(tm.index.byTag)),
+	}
+	for status, ids := range tm.index.byStatus {
+		counts.ByStatus[status] = len(ids)
+		if !status.Closed() {
+			counts.Open += len(ids)
+		}
+	}
+	counts.Done = counts.ByStatus[StatusDone]
+	for tag, ids := range tm.index.byTag {
+		counts.ByTag[tag] = len(ids)
+	}
+	return counts
+}
+
+// add adds other's counts to c
+func (c *TaskCounts) add(other TaskCounts) {
+	c.Total += other.Total
+	c.Open += other.Open
+	c.Done += other.Done
+	addCounts(c.ByStatus, other.ByStatus)
+	addCounts(c.ByPriority, other.ByPriority)
+	addCounts(c.ByTag, other.ByTag)
+}
+
+// addCounts adds the counts of src to dst
+func addCounts[K comparable](dst, src map[K]int) {
+	for key, n := range src {
+		dst[key] += n
+	}
+}
@@ -0,0 +1,51 @@
+package taskmanager
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestTaskCounts(t *testing.T) {
+	tm := NewTaskManager()
+	report := mustAddTask(t, tm, "Write report", WithTags("work"), WithPriority(PriorityHigh))
+	milk := mustAddTask(t, tm, "Buy milk", WithTags("home"))
+	review := mustAddTask(t, tm, "Review PR", WithTags("work", "code"), WithPriority(PriorityHigh))
+	mustAddTask(t, tm, "Call mom")
+
+	if err := tm.CompleteTask(report.ID, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Transition(review.ID, StatusCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.ArchiveTask(report.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.UpdateTaskFields(milk.ID, TaskPatch{Priority: ptr(PriorityUrgent)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteTask(review.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	counts := tm.TaskCounts()
+	if counts.Total != 3 || counts.Open != 2 || counts.Done != 1 {
+		t.Errorf("Expected 3 tasks, 2 open and 1 done, got %+v", counts)
+	}
+	if expected := map[Status]int{StatusTodo: 2, StatusDone: 1}; !maps.Equal(counts.ByStatus, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts.ByStatus)
+	}
+	if expected := map[Priority]int{PriorityHigh: 1, PriorityMedium: 1, PriorityUrgent: 1}; !maps.Equal(counts.ByPriority, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts.ByPriority)
+	}
+	if expected := map[string]int{"work": 1, "home": 1}; !maps.Equal(counts.ByTag, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts.ByTag)
+	}
+
+	// The counts agree with a full scan of the same tasks
+	all := tm.ListTasks(nil, IncludeArchived(), IncludeSnoozed())
+	if len(all) != counts.Total {
+		t.Errorf("Expected %d tasks listed, got %d", counts.Total, len(all))
+	}
+	checkIndex(t, tm, "counts")
+}
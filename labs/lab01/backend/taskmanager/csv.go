@@ -0,0 +1,434 @@
+package taskmanager
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVField is a task field a CSV column holds
+type CSVField string
+
+const (
+	// CSVID is the task ID. It is exported only: imported tasks get new
+	// IDs.
+	CSVID CSVField = "id"
+	// CSVTitle is the task title
+	CSVTitle CSVField = "title"
+	// CSVDescription is the task description
+	CSVDescription CSVField = "description"
+	// CSVStatus is the status by name, such as "in progress"
+	CSVStatus CSVField = "status"
+	// CSVPriority is the priority by name, such as "high", or by number
+	// from 1 for low to 4 for urgent
+	CSVPriority CSVField = "priority"
+	// CSVTags are the tags, separated by semicolons
+	CSVTags CSVField = "tags"
+	// CSVDueDate is the due date, as RFC 3339 or, in the manager's time
+	// zone, as "2006-01-02 15:04" or "2006-01-02"
+	CSVDueDate CSVField = "due_date"
+	// CSVAssignee is the ID of the assignee
+	CSVAssignee CSVField = "assignee"
+	// CSVCreatedAt is the creation time. It is exported only: imported
+	// tasks are created at the time of the import.
+	CSVCreatedAt CSVField = "created_at"
+	// CSVCompletedAt is the completion time of a done task, in the format
+	// of CSVDueDate
+	CSVCompletedAt CSVField = "completed_at"
+)
+
+// DefaultCSVColumns are the columns ExportCSV writes, in order, and those
+// ImportCSV expects of a sheet without a header unless told otherwise
+var DefaultCSVColumns = []CSVField{
+	CSVID, CSVTitle, CSVDescription, CSVStatus, CSVPriority, CSVTags,
+	CSVDueDate, CSVAssignee, CSVCreatedAt, CSVCompletedAt,
+}
+
+// csvTimeLayouts are the layouts ImportCSV accepts for times without a
+// time zone, after RFC 3339
+var csvTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// CSVHeader tells ImportCSV whether the first row names the columns
+type CSVHeader int
+
+const (
+	// CSVHeaderAuto takes the first row as a header when one of its cells
+	// names a field or a column of CSVImportOptions.Columns
+	CSVHeaderAuto CSVHeader = iota
+	// CSVHeaderPresent always takes the first row as a header
+	CSVHeaderPresent
+	// CSVHeaderAbsent takes every row as a task
+	CSVHeaderAbsent
+)
+
+// CSVImportOptions tells ImportCSV how to read a sheet
+type CSVImportOptions struct {
+	// Columns maps headers to the fields their columns hold, for sheets
+	// whose headers are not field names, such as {"Assignment": CSVTitle}.
+	// Other headers naming a field, ignoring case and with spaces or
+	// dashes for underscores, hold that field, and the rest are ignored.
+	Columns map[string]CSVField
+	// Header tells whether the first row names the columns
+	Header CSVHeader
+	// Order gives the fields of the columns of a sheet without a header.
+	// Nil means DefaultCSVColumns.
+	Order []CSVField
+	// Comma is the field separator. Zero means a comma.
+	Comma rune
+}
+
+// RowError is the error of one row ImportCSV skipped
+type RowError struct {
+	// Line is the line the row starts on, counting from 1
+	Line int
+	// Field is the field that failed to parse, empty when the row failed
+	// as a whole
+	Field CSVField
+	Err   error
+}
+
+// CSVError reports every row ImportCSV skipped. The other rows were
+// imported.
+type CSVError struct {
+	Rows []RowError
+}
+
+// Error implements the error interface
+func (e *CSVError) Error() string {
+	parts := make([]string, len(e.Rows))
+	for i, row := range e.Rows {
+		if row.Field != "" {
+			parts[i] = fmt.Sprintf("line %d: %s: %v", row.Line, row.Field, row.Err)
+		} else {
+			parts[i] = fmt.Sprintf("line %d: %v", row.Line, row.Err)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Unwrap returns the error of each row, so errors.Is matches any of them
+func (e *CSVError) Unwrap() []error {
+	errs := make([]error, len(e.Rows))
+	for i, row := range e.Rows {
+		errs[i] = row.Err
+	}
+	return errs
+}
+
+// ExportCSV writes the tasks matching the filter as CSV, with a header row
+// and the columns of DefaultCSVColumns, in the filter's order. The page
+// and cursor of the filter are ignored, so every matching task is written.
+// Cells starting with =, +, - or @ get a leading apostrophe, so that
+// spreadsheets show them as text rather than run them as formulas.
+func (tm *TaskManager) ExportCSV(w io.Writer, filter ListOptions) error {
+	tasks, err := tm.exportTasks(filter)
+	if err != nil {
+		return err
+	}
+	return writeCSV(w, tasks)
+}
+
+// exportTasks returns copies of every task matching a filter, for exports
+func (tm *TaskManager) exportTasks(filter ListOptions) ([]*Task, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	tasks := tm.matching(tm.newQuery(filter.Done, filter.listOptions()))
+	for i, task := range tasks {
+		tasks[i] = task.clone()
+	}
+	return tasks, nil
+}
+
+// writeCSV writes tasks as ExportCSV does
+func writeCSV(w io.Writer, tasks []*Task) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(DefaultCSVColumns))
+	for i, field := range DefaultCSVColumns {
+		header[i] = string(field)
+	}
+	cw.Write(header)
+	row := make([]string, len(DefaultCSVColumns))
+	for _, task := range tasks {
+		for i, field := range DefaultCSVColumns {
+			row[i] = escapeFormula(csvValue(task, field))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue formats a field of a task for a CSV cell
+func csvValue(task *Task, field CSVField) string {
+	switch field {
+	case CSVID:
+		return strconv.Itoa(task.ID)
+	case CSVTitle:
+		return task.Title
+	case CSVDescription:
+		return task.Description
+	case CSVStatus:
+		return task.Status.String()
+	case CSVPriority:
+		return task.Priority.String()
+	case CSVTags:
+		return strings.Join(task.Tags, ";")
+	case CSVDueDate:
+		return formatTime(task.DueDate)
+	case CSVAssignee:
+		return task.AssigneeID
+	case CSVCreatedAt:
+		return formatTime(&task.CreatedAt)
+	case CSVCompletedAt:
+		return formatTime(task.CompletedAt)
+	}
+	return ""
+}
+
+// formulaPrefixes are the characters a spreadsheet takes as the start of a
+// formula
+const formulaPrefixes = "=+-@"
+
+// escapeFormula quotes a cell a spreadsheet would run as a formula with a
+// leading apostrophe, so exported text cannot run formulas once opened
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unescapeFormula drops the apostrophe escapeFormula puts in front of a
+// cell
+func unescapeFormula(cell string) string {
+	if rest, ok := strings.CutPrefix(cell, "'"); ok && rest != "" && strings.ContainsRune(formulaPrefixes, rune(rest[0])) {
+		return rest
+	}
+	return cell
+}
+
+// csvImport is a sheet read by parseCSV, ready to import
+type csvImport struct {
+	inputs []TaskInput
+	// lines holds the line of each input
+	lines  []int
+	failed []RowError
+}
+
+// ImportCSV adds a task for each row of a sheet, as ImportTasks does, and
+// returns the tasks added in row order. A row that fails to parse or to
+// validate is skipped, and a *CSVError reports each one by line, while
+// the other rows are still imported. The apostrophe ExportCSV puts in
+// front of cells that look like formulas is dropped. The whole call is
+// undone as one operation.
+func (tm *TaskManager) ImportCSV(r io.Reader, opts CSVImportOptions) ([]*Task, error) {
+	sheet, err := parseCSV(r, opts, tm.timezone)
+	if err != nil {
+		return nil, err
+	}
+	return tm.importCSV(sheet)
+}
+
+// importCSV imports the rows of a parsed sheet
+func (tm *TaskManager) importCSV(sheet *csvImport) ([]*Task, error) {
+	tasks, err := tm.ImportTasks(sheet.inputs)
+	var bulk *BulkError
+	if errors.As(err, &bulk) {
+		for _, f := range bulk.Failures {
+			sheet.failed = append(sheet.failed, RowError{Line: sheet.lines[f.Index], Err: f.Err})
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	added := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task != nil {
+			added = append(added, task)
+		}
+	}
+	if len(sheet.failed) > 0 {
+		slices.SortStableFunc(sheet.failed, func(a, b RowError) int {
+			return cmp.Compare(a.Line, b.Line)
+		})
+		return added, &CSVError{Rows: sheet.failed}
+	}
+	return added, nil
+}
+
+// parseCSV reads a sheet into task inputs. It only fails when the reader
+// does; rows that do not parse are recorded in the result.
+func parseCSV(r io.Reader, opts CSVImportOptions, loc *time.Location) (*csvImport, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	columns := opts.Order
+	if columns == nil {
+		columns = DefaultCSVColumns
+	}
+	sheet := &csvImport{}
+	first := true
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return sheet, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			sheet.failed = append(sheet.failed, RowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			first = false
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if first {
+			first = false
+			if header, ok := csvHeader(record, opts); ok {
+				columns = header
+				continue
+			}
+		}
+		in, rowErr := csvInput(record, columns, loc)
+		if rowErr != nil {
+			rowErr.Line = line
+			sheet.failed = append(sheet.failed, *rowErr)
+			continue
+		}
+		sheet.inputs = append(sheet.inputs, in)
+		sheet.lines = append(sheet.lines, line)
+	}
+}
+
+// csvHeader returns the fields of the columns a header row names, when the
+// options take the row as a header. Columns naming no field get an empty
+// field.
+func csvHeader(record []string, opts CSVImportOptions) ([]CSVField, bool) {
+	if opts.Header == CSVHeaderAbsent {
+		return nil, false
+	}
+	mapped := make(map[string]CSVField, len(opts.Columns))
+	for name, field := range opts.Columns {
+		mapped[strings.ToLower(strings.TrimSpace(name))] = field
+	}
+	known := make(map[string]bool, len(DefaultCSVColumns))
+	for _, field := range DefaultCSVColumns {
+		known[string(field)] = true
+	}
+	fields := make([]CSVField, len(record))
+	found := false
+	for i, cell := range record {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		if field, ok := mapped[name]; ok {
+			fields[i], found = field, true
+			continue
+		}
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if known[name] {
+			fields[i], found = CSVField(name), true
+		}
+	}
+	return fields, found || opts.Header == CSVHeaderPresent
+}
+
+// csvInput parses a row into a task input
+func csvInput(record []string, columns []CSVField, loc *time.Location) (TaskInput, *RowError) {
+	var in TaskInput
+	status := StatusTodo
+	var completed *time.Time
+	for i, cell := range record {
+		if i >= len(columns) {
+			break
+		}
+		cell = unescapeFormula(strings.TrimSpace(cell))
+		field := columns[i]
+		if cell == "" {
+			continue
+		}
+		var err error
+		switch field {
+		case CSVTitle:
+			in.Title = cell
+		case CSVDescription:
+			in.Description = cell
+		case CSVStatus:
+			status, err = parseStatus(cell)
+		case CSVPriority:
+			var p Priority
+			if p, err = parseCSVPriority(cell); err == nil {
+				in.Options = append(in.Options, WithPriority(p))
+			}
+		case CSVTags:
+			in.Options = append(in.Options, WithTags(strings.Split(cell, ";")...))
+		case CSVDueDate:
+			var due time.Time
+			if due, err = parseCSVTime(cell, loc); err == nil {
+				in.Options = append(in.Options, WithDueDate(due))
+			}
+		case CSVAssignee:
+			assignee := cell
+			in.Options = append(in.Options, func(t *Task) { t.AssigneeID = assignee })
+		case CSVCompletedAt:
+			var at time.Time
+			if at, err = parseCSVTime(cell, loc); err == nil {
+				completed = &at
+			}
+		}
+		if err != nil {
+			return in, &RowError{Field: field, Err: err}
+		}
+	}
+	if status != StatusTodo {
+		in.Options = append(in.Options, importedStatus(status, completed))
+	}
+	return in, nil
+}
+
+// importedStatus sets the status of an imported task, with its completion
+// time when it is done, which defaults to its creation
+func importedStatus(status Status, completed *time.Time) TaskOption {
+	return func(t *Task) {
+		t.Status = status
+		if status != StatusDone {
+			return
+		}
+		at := t.CreatedAt
+		if completed != nil {
+			at = *completed
+		}
+		t.CompletedAt = &at
+	}
+}
+
+// parseCSVPriority parses a priority by name or number
+func parseCSVPriority(cell string) (Priority, error) {
+	if n, err := strconv.Atoi(cell); err == nil {
+		if p := Priority(n); p.Valid() {
+			return p, nil
+		}
+		return 0, fmt.Errorf("%w: %d", ErrInvalidPriority, n)
+	}
+	return parsePriority(cell)
+}
+
+// parseCSVTime parses a time in one of the formats of CSVDueDate
+func parseCSVTime(cell string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, cell); err == nil {
+		return t, nil
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, cell, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", cell)
+}
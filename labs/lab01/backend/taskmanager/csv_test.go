@@ -0,0 +1,151 @@
+package taskmanager
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportCSV(t *testing.T) {
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return at }))
+	mustAddTask(t, tm, "Report, final", WithTags("work", "urgent"), WithPriority(PriorityHigh), WithDueDate(at.AddDate(0, 0, 1)))
+	done := mustAddTask(t, tm, "Groceries", WithTags("home"))
+	tm.CompleteTask(done.ID, "")
+	mustAddTask(t, tm, "Other")
+
+	var b bytes.Buffer
+	if err := tm.ExportCSV(&b, ListOptions{AnyTags: []string{"work", "home"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `id,title,description,status,priority,tags,due_date,assignee,created_at,completed_at
+1,"Report, final",,todo,high,work;urgent,2025-07-02T09:00:00Z,,2025-07-01T09:00:00Z,
+2,Groceries,,done,medium,home,,,2025-07-01T09:00:00Z,2025-07-01T09:00:00Z
+`
+	if b.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, b.String())
+	}
+
+	if err := tm.ExportCSV(&b, ListOptions{Statuses: []Status{99}}); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		sheet string
+		opts  CSVImportOptions
+		want  []string
+		lines []int
+	}{
+		{"header", "Title,Due Date,Tags\nReport,2025-07-02,work; urgent\nSlides,,\n", CSVImportOptions{}, []string{"Report", "Slides"}, nil},
+		{"no header", "7,Report,Draft,in progress,high\n", CSVImportOptions{}, []string{"Report"}, nil},
+		{"column mapping", "Assignment;Student\nEssay;alice\n", CSVImportOptions{Columns: map[string]CSVField{"Assignment": CSVTitle, "student": CSVAssignee}, Comma: ';'}, []string{"Essay"}, nil},
+		{"order", "Essay,2\n", CSVImportOptions{Header: CSVHeaderAbsent, Order: []CSVField{CSVTitle, CSVPriority}}, []string{"Essay"}, nil},
+		{"bad rows", "title,priority,due_date\nGood,low,\nBad priority,extreme,\n,high,\nBad date,,tomorrow\n\"Bad \"quote,,\nAlso good,4,\n", CSVImportOptions{}, []string{"Good", "Also good"}, []int{3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTaskManager(WithClock(func() time.Time { return at }), WithTimezone(time.UTC))
+			tasks, err := tm.ImportCSV(strings.NewReader(tt.sheet), tt.opts)
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			var csvErr *CSVError
+			if tt.lines == nil {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &csvErr) {
+				t.Fatalf("Expected a *CSVError, got %v", err)
+			}
+			var lines []int
+			for _, row := range csvErr.Rows {
+				lines = append(lines, row.Line)
+			}
+			if !slices.Equal(lines, tt.lines) {
+				t.Errorf("Expected errors on lines %v, got %v", tt.lines, csvErr)
+			}
+		})
+	}
+}
+
+func TestCSVFormulaCells(t *testing.T) {
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return at }))
+	if _, err := tm.AddTask("=HYPERLINK(\"http://example.com\")", "+1 from me", WithTags("@home", "-later", "q&a, notes")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := tm.ExportCSV(&b, ListOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `id,title,description,status,priority,tags,due_date,assignee,created_at,completed_at
+1,"'=HYPERLINK(""http://example.com"")",'+1 from me,todo,medium,"'@home;-later;q&a, notes",,,2025-07-01T09:00:00Z,
+`
+	if b.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, b.String())
+	}
+
+	// The apostrophes are dropped on import, and tags split on semicolons
+	// alone
+	again, err := NewTaskManager().ImportCSV(&b, CSVImportOptions{})
+	if err != nil || len(again) != 1 {
+		t.Fatalf("Expected 1 task, got %d, %v", len(again), err)
+	}
+	task := storedTask(tm, 1)
+	if again[0].Title != task.Title || again[0].Description != task.Description || !slices.Equal(again[0].Tags, task.Tags) {
+		t.Errorf("Expected %+v, got %+v", task, again[0])
+	}
+}
+
+func TestImportCSVFields(t *testing.T) {
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return at }), WithTimezone(time.UTC))
+	sheet := "title,status,priority,tags,due_date,assignee,completed_at,id\n" +
+		"Report,done,urgent,work; urgent,2025-07-02 17:30,alice,2025-06-30,42\n"
+	tasks, err := tm.ImportCSV(strings.NewReader(sheet), CSVImportOptions{})
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %d, %v", len(tasks), err)
+	}
+	task := tasks[0]
+	due := time.Date(2025, 7, 2, 17, 30, 0, 0, time.UTC)
+	completed := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	switch {
+	case task.ID != 1:
+		t.Errorf("Expected a new ID, got %d", task.ID)
+	case task.Status != StatusDone || task.CompletedAt == nil || !task.CompletedAt.Equal(completed):
+		t.Errorf("Expected done at %v, got %v at %v", completed, task.Status, task.CompletedAt)
+	case task.Priority != PriorityUrgent:
+		t.Errorf("Expected urgent, got %v", task.Priority)
+	case !slices.Equal(task.Tags, []string{"work", "urgent"}):
+		t.Errorf("Expected tags work and urgent, got %v", task.Tags)
+	case task.DueDate == nil || !task.DueDate.Equal(due):
+		t.Errorf("Expected due %v, got %v", due, task.DueDate)
+	case task.AssigneeID != "alice":
+		t.Errorf("Expected alice, got %q", task.AssigneeID)
+	}
+
+	// What ExportCSV writes imports back the same
+	var b bytes.Buffer
+	tm.ExportCSV(&b, ListOptions{})
+	other := NewTaskManager(WithClock(func() time.Time { return at }))
+	again, err := other.ImportCSV(&b, CSVImportOptions{})
+	if err != nil || len(again) != 1 {
+		t.Fatalf("Expected 1 task, got %d, %v", len(again), err)
+	}
+	if again[0].Title != task.Title || !again[0].DueDate.Equal(due) || !slices.Equal(again[0].Tags, task.Tags) {
+		t.Errorf("Expected %+v, got %+v", task, again[0])
+	}
+	if tm.Undo(); tm.tasks.Len() != 0 {
+		t.Errorf("Expected the import undone as one, got %d tasks", tm.tasks.Len())
+	}
+}
@@ -0,0 +1,80 @@
+package taskmanager
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a cursor is malformed or was issued for
+// a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorKey is the content of a cursor: the sort order it belongs to, the
+// sort key and ID of the last task on the page it was issued for, and the
+// offset just past that page. Titles are left out, so cursors do not carry
+// task content into URLs and logs.
+type cursorKey struct {
+	Sort      string     `json:"s,omitempty"`
+	ID        int        `json:"i"`
+	Offset    int        `json:"o,omitempty"`
+	Priority  Priority   `json:"p,omitempty"`
+	DueDate   *time.Time `json:"d,omitempty"`
+	CreatedAt time.Time  `json:"c"`
+	UpdatedAt time.Time  `json:"u"`
+}
+
+// encodeCursor returns the opaque cursor pointing just past task, which
+// ends a page at offset in a listing ordered by spec
+func encodeCursor(task *Task, offset int, spec SortSpec) string {
+	data, _ := json.Marshal(cursorKey{
+		Sort:      spec.String(),
+		ID:        task.ID,
+		Offset:    offset,
+		Priority:  task.Priority,
+		DueDate:   task.DueDate,
+		CreatedAt: task.CreatedAt,
+		UpdatedAt: task.UpdatedAt,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the content of a cursor issued for spec
+func decodeCursor(cursor string, spec SortSpec) (cursorKey, error) {
+	var key cursorKey
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return key, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &key); err != nil || key.ID <= 0 || key.Offset < 0 || key.Sort != spec.String() {
+		return key, ErrInvalidCursor
+	}
+	return key, nil
+}
+
+// offset returns where the page after the cursor's starts in tasks, a
+// listing in the order of q. Without the title, an order by title cannot
+// be searched for the cursor's place, so the page starts after the
+// cursor's task wherever it now is, or at the offset the cursor was issued
+// at once the task is no longer listed.
+func (k cursorKey) offset(tasks []*Task, q *listQuery) int {
+	if slices.ContainsFunc(q.sort, func(key SortKey) bool { return key.Field == SortTitle }) {
+		if i := slices.IndexFunc(tasks, func(task *Task) bool { return task.ID == k.ID }); i >= 0 {
+			return i + 1
+		}
+		return k.Offset
+	}
+	last := &Task{
+		ID:        k.ID,
+		Priority:  k.Priority,
+		DueDate:   k.DueDate,
+		CreatedAt: k.CreatedAt,
+		UpdatedAt: k.UpdatedAt,
+	}
+	return sort.Search(len(tasks), func(i int) bool {
+		return q.less(last, tasks[i])
+	})
+}
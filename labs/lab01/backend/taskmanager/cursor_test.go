@@ -0,0 +1,142 @@
+package taskmanager
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindTasksCursor(t *testing.T) {
+	// A fixed clock gives every task the same creation time, so the order
+	// rests entirely on the ID tie-break
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for i := 1; i <= 7; i++ {
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i))
+	}
+
+	var seen []string
+	opts := ListOptions{Page: Page{Limit: 3}}
+	for pages := 0; ; pages++ {
+		page, err := tm.FindTasks(opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		seen = append(seen, titles(page.Tasks)...)
+		if pages == 0 {
+			// Changes while scrolling: a task already seen goes away and
+			// a new one is added at the end
+			if err := tm.DeleteTask(1); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			mustAddTask(t, tm, "Task 8")
+		}
+		if page.NextCursor == "" {
+			if page.HasMore() {
+				t.Error("Expected a cursor while there are more tasks")
+			}
+			break
+		}
+		if pages > 5 {
+			t.Fatal("Expected the listing to end")
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	expected := []string{"Task 1", "Task 2", "Task 3", "Task 4", "Task 5", "Task 6", "Task 7", "Task 8"}
+	if !slices.Equal(seen, expected) {
+		t.Errorf("Expected %v, got %v", expected, seen)
+	}
+}
+
+func TestFindTasksCursorSorted(t *testing.T) {
+	tm := NewTaskManager()
+	for _, title := range []string{"delta", "alpha", "echo", "charlie"} {
+		mustAddTask(t, tm, title)
+	}
+	opts := ListOptions{Sort: SortSpec{{Field: SortTitle}}, Page: Page{Limit: 2}}
+
+	first, err := tm.FindTasks(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(first.Tasks); !slices.Equal(got, []string{"alpha", "charlie"}) {
+		t.Fatalf("Expected the first page to be sorted by title, got %v", got)
+	}
+
+	// bravo sorts into the page already read; an offset would repeat charlie
+	mustAddTask(t, tm, "bravo")
+	opts.Cursor = first.NextCursor
+	second, err := tm.FindTasks(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(second.Tasks); !slices.Equal(got, []string{"delta", "echo"}) {
+		t.Errorf("Expected the second page to continue after charlie, got %v", got)
+	}
+	if second.Offset != 3 || second.Total != 5 || second.HasMore() || second.NextCursor != "" {
+		t.Errorf("Expected the last page at offset 3 of 5, got %+v", second)
+	}
+
+	// The cursor holds no titles, and renaming the task it points past
+	// does not invalidate it
+	data, _ := base64.RawURLEncoding.DecodeString(first.NextCursor)
+	if strings.Contains(string(data), "charlie") {
+		t.Errorf("Expected no title in the cursor, got %s", data)
+	}
+	charlie := first.Tasks[1]
+	if err := tm.UpdateTask(charlie.ID, "zulu", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	third, err := tm.FindTasks(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(third.Tasks); len(got) != 0 {
+		t.Errorf("Expected the page after the renamed task to be empty, got %v", got)
+	}
+
+	// Once the task is gone the page starts where the cursor was issued
+	if err := tm.DeleteTask(charlie.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fourth, err := tm.FindTasks(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(fourth.Tasks); !slices.Equal(got, []string{"delta", "echo"}) {
+		t.Errorf("Expected the page at the cursor's offset, got %v", got)
+	}
+}
+
+func TestFindTasksCursorErrors(t *testing.T) {
+	tm := NewTaskManager()
+	for i := 1; i <= 3; i++ {
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i))
+	}
+	page, err := tm.FindTasks(ListOptions{Page: Page{Limit: 1}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts ListOptions
+	}{
+		{name: "garbage", opts: ListOptions{Cursor: "not a cursor!"}},
+		{name: "not json", opts: ListOptions{Cursor: "bm90IGpzb24"}},
+		{name: "other sort order", opts: ListOptions{Cursor: page.NextCursor, Sort: SortSpec{{Field: SortTitle}}}},
+		{name: "with offset", opts: ListOptions{Cursor: page.NextCursor, Page: Page{Offset: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tm.FindTasks(tt.opts); err != ErrInvalidCursor {
+				t.Errorf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrFieldNotDefined is returned when a task uses a custom field that has not been defined
+	ErrFieldNotDefined = errors.New("custom field not defined")
+	// ErrFieldExists is returned when defining a custom field that already exists
+	ErrFieldExists = errors.New("custom field already defined")
+	// ErrInvalidFieldName is returned when a custom field name is empty
+	ErrInvalidFieldName = errors.New("custom field name cannot be empty")
+	// ErrInvalidFieldType is returned when a custom field type is unknown
+	ErrInvalidFieldType = errors.New("invalid custom field type")
+	// ErrFieldTypeMismatch is returned when a custom field value does not match the field's type
+	ErrFieldTypeMismatch = errors.New("custom field value has the wrong type")
+)
+
+// FieldType is the type of values a custom field holds
+type FieldType int
+
+const (
+	// FieldString holds string values
+	FieldString FieldType = iota + 1
+	// FieldNumber holds float64 values; integers are converted on write
+	FieldNumber
+	// FieldBool holds bool values
+	FieldBool
+	// FieldDate holds time.Time values
+	FieldDate
+)
+
+// FieldDefinition describes a custom field defined on the manager
+type FieldDefinition struct {
+	Name string
+	Type FieldType
+}
+
+// String returns a human-readable name for the field type
+func (f FieldType) String() string {
+	switch f {
+	case FieldString:
+		return "string"
+	case FieldNumber:
+		return "number"
+	case FieldBool:
+		return "bool"
+	case FieldDate:
+		return "date"
+	default:
+		return "unknown"
+	}
+}
+
+// WithCustomField sets a custom field value on a task. A nil value removes
+// the field from the task.
+func WithCustomField(name string, value any) TaskOption {
+	return func(t *Task) {
+		fields := maps.Clone(t.CustomFields)
+		if fields == nil {
+			fields = make(map[string]any)
+		}
+		if value == nil {
+			delete(fields, name)
+		} else {
+			fields[name] = value
+		}
+		t.CustomFields = fields
+	}
+}
+
+// DefineField adds a custom field that tasks may carry
+func (tm *TaskManager) DefineField(name string, fieldType FieldType) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidFieldName
+	}
+	if fieldType < FieldString || fieldType > FieldDate {
+		return ErrInvalidFieldType
+	}
+	if _, ok := tm.fields[name]; ok {
+		return ErrFieldExists
+	}
+	tm.fields[name] = fieldType
+	return nil
+}
+
+// RemoveField deletes a custom field definition and its values on every task
+func (tm *TaskManager) RemoveField(name string) error {
+	if _, ok := tm.fields[name]; !ok {
+		return ErrFieldNotDefined
+	}
+	for task := range tm.everyTask() {
+		if _, ok := task.CustomFields[name]; ok {
+			tm.update(task, func() {
+				task.CustomFields = maps.Clone(task.CustomFields)
+				delete(task.CustomFields, name)
+			})
+		}
+	}
+	delete(tm.fields, name)
+	return nil
+}
+
+// ListFields returns the custom field definitions sorted by name
+func (tm *TaskManager) ListFields() []FieldDefinition {
+	result := make([]FieldDefinition, 0, len(tm.fields))
+	for _, name := range slices.Sorted(maps.Keys(tm.fields)) {
+		result = append(result, FieldDefinition{Name: name, Type: tm.fields[name]})
+	}
+	return result
+}
+
+// SetCustomField sets or, with a nil value, clears a custom field on a task
+func (tm *TaskManager) SetCustomField(id int, name string, value any) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	updated := task.clone()
+	WithCustomField(name, value)(updated)
+	if err := tm.validateCustomFields(updated); err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.CustomFields = updated.CustomFields
+	})
+	return nil
+}
+
+// FilterByCustomField limits ListTasks to tasks whose custom field equals value
+func FilterByCustomField(name string, value any) ListOption {
+	return func(q *listQuery) {
+		if q.customFields == nil {
+			q.customFields = make(map[string]any)
+		}
+		if number, ok := toNumber(value); ok {
+			value = number
+		}
+		q.customFields[name] = value
+	}
+}
+
+// validateCustomFields checks every custom field value against its
+// definition, converting integer numbers to float64
+func (tm *TaskManager) validateCustomFields(task *Task) error {
+	for name, value := range task.CustomFields {
+		fieldType, ok := tm.fields[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrFieldNotDefined, name)
+		}
+		switch fieldType {
+		case FieldString:
+			_, ok = value.(string)
+		case FieldNumber:
+			var number float64
+			if number, ok = toNumber(value); ok {
+				task.CustomFields[name] = number
+			}
+		case FieldBool:
+			_, ok = value.(bool)
+		case FieldDate:
+			_, ok = value.(time.Time)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s must be a %v", ErrFieldTypeMismatch, name, fieldType)
+		}
+	}
+	return nil
+}
+
+// toNumber converts the numeric types accepted by number fields to float64
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// customFieldEqual compares two custom field values, treating dates as equal
+// when they denote the same instant
+func customFieldEqual(a, b any) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return a == b
+}
+
+// formatFieldValue formats a custom field value for history entries
+func formatFieldValue(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
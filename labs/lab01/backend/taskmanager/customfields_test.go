@@ -0,0 +1,117 @@
+package taskmanager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDefineField(t *testing.T) {
+	tm := NewTaskManager()
+	if err := tm.DefineField("estimate", FieldNumber); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		fieldName   string
+		fieldType   FieldType
+		expectError error
+	}{
+		{name: "valid field", fieldName: "sprint", fieldType: FieldString},
+		{name: "duplicate", fieldName: "estimate", fieldType: FieldNumber, expectError: ErrFieldExists},
+		{name: "empty name", fieldName: " ", fieldType: FieldBool, expectError: ErrInvalidFieldName},
+		{name: "unknown type", fieldName: "color", fieldType: FieldType(42), expectError: ErrInvalidFieldType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tm.DefineField(tt.fieldName, tt.fieldType); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	fields := tm.ListFields()
+	if len(fields) != 2 || fields[0].Name != "estimate" || fields[1].Name != "sprint" {
+		t.Errorf("Expected fields sorted by name, got %+v", fields)
+	}
+}
+
+func TestCustomFieldValues(t *testing.T) {
+	tm := NewTaskManager()
+	for name, fieldType := range map[string]FieldType{
+		"sprint":   FieldString,
+		"estimate": FieldNumber,
+		"billable": FieldBool,
+		"review":   FieldDate,
+	} {
+		if err := tm.DefineField(name, fieldType); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		field       string
+		value       any
+		expectError error
+	}{
+		{name: "string", field: "sprint", value: "week-27"},
+		{name: "integer number", field: "estimate", value: 3},
+		{name: "bool", field: "billable", value: true},
+		{name: "date", field: "review", value: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "wrong type", field: "estimate", value: "three", expectError: ErrFieldTypeMismatch},
+		{name: "undefined field", field: "color", value: "red", expectError: ErrFieldNotDefined},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tm.AddTask("Task", "", WithCustomField(tt.field, tt.value))
+			if !errors.Is(err, tt.expectError) {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	task := mustAddTask(t, tm, "Report", WithCustomField("estimate", 5))
+	if task.CustomFields["estimate"] != 5.0 {
+		t.Errorf("Expected integer to be stored as float64, got %#v", task.CustomFields["estimate"])
+	}
+}
+
+func TestFilterByCustomField(t *testing.T) {
+	tm := NewTaskManager()
+	if err := tm.DefineField("sprint", FieldString); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DefineField("estimate", FieldNumber); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report := mustAddTask(t, tm, "Report", WithCustomField("sprint", "week-27"), WithCustomField("estimate", 2))
+	review := mustAddTask(t, tm, "Review", WithCustomField("sprint", "week-28"))
+
+	if got := tm.ListTasks(nil, FilterByCustomField("sprint", "week-27")); len(got) != 1 || got[0] != report {
+		t.Errorf("Expected only the report, got %v", got)
+	}
+	if got := tm.ListTasks(nil, FilterByCustomField("estimate", 2)); len(got) != 1 || got[0] != report {
+		t.Errorf("Expected integer filter to match the stored number, got %v", got)
+	}
+
+	if err := tm.SetCustomField(review.ID, "sprint", "week-27"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.SetCustomField(review.ID, "estimate", false); !errors.Is(err, ErrFieldTypeMismatch) {
+		t.Errorf("Expected ErrFieldTypeMismatch, got %v", err)
+	}
+	if got := tm.ListTasks(nil, FilterByCustomField("sprint", "week-27")); len(got) != 2 {
+		t.Errorf("Expected 2 tasks in week-27, got %d", len(got))
+	}
+
+	if err := tm.RemoveField("sprint"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := report.CustomFields["sprint"]; ok {
+		t.Error("Expected removed field to be stripped from tasks")
+	}
+}
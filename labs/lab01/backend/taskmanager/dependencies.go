@@ -0,0 +1,139 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrSelfDependency is returned when a task is made to depend on itself
+	ErrSelfDependency = errors.New("task cannot depend on itself")
+	// ErrCyclicDependency is returned when a dependency would create a cycle
+	ErrCyclicDependency = errors.New("dependency would create a cycle")
+)
+
+// AddDependency records that a task is blocked until another task is done
+func (tm *TaskManager) AddDependency(taskID, dependsOnID int) error {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return err
+	}
+	if _, err := tm.lookup(dependsOnID); err != nil {
+		return err
+	}
+	if taskID == dependsOnID {
+		return ErrSelfDependency
+	}
+	if slices.Contains(task.DependsOn, dependsOnID) {
+		return nil
+	}
+	if tm.dependsOn(dependsOnID, taskID) {
+		return ErrCyclicDependency
+	}
+	tm.update(task, func() {
+		task.DependsOn = append(task.DependsOn, dependsOnID)
+	})
+	return nil
+}
+
+// RemoveDependency removes a dependency between two tasks
+func (tm *TaskManager) RemoveDependency(taskID, dependsOnID int) error {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(task.DependsOn, dependsOnID) {
+		return nil
+	}
+	tm.update(task, func() {
+		task.DependsOn = slices.DeleteFunc(task.DependsOn, func(id int) bool {
+			return id == dependsOnID
+		})
+	})
+	return nil
+}
+
+// IsBlocked reports whether the task has a dependency that is still open
+func (tm *TaskManager) IsBlocked(task *Task) bool {
+	for _, id := range task.DependsOn {
+		if dep, ok := tm.tasks.Get(id); ok && !dep.Status.Closed() {
+			return true
+		}
+	}
+	return false
+}
+
+// ListBlocked returns open tasks waiting on at least one open dependency
+func (tm *TaskManager) ListBlocked(opts ...ListOption) []*Task {
+	return tm.filterOpen(tm.IsBlocked, opts)
+}
+
+// ListActionable returns open tasks whose dependencies are all done or cancelled
+func (tm *TaskManager) ListActionable(opts ...ListOption) []*Task {
+	return tm.filterOpen(func(task *Task) bool {
+		return !tm.IsBlocked(task)
+	}, opts)
+}
+
+// filterOpen returns open tasks matching keep, oldest first unless opts
+// say otherwise
+func (tm *TaskManager) filterOpen(keep func(*Task) bool, opts []ListOption) []*Task {
+	return tm.ListTasks(nil, append(opts, where(func(task *Task) bool {
+		return !task.Status.Closed() && keep(task)
+	}))...)
+}
+
+// dependsOn reports whether from depends on target directly or transitively
+func (tm *TaskManager) dependsOn(from, target int) bool {
+	seen := make(map[int]bool)
+	stack := []int{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == target {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if task, ok := tm.tasks.Get(id); ok {
+			stack = append(stack, task.DependsOn...)
+		}
+	}
+	return false
+}
+
+// removeDependents drops a deleted task from the dependency lists of other tasks
+func (tm *TaskManager) removeDependents(id int) {
+	for task := range tm.everyTask() {
+		if slices.Contains(task.DependsOn, id) {
+			tm.update(task, func() {
+				task.DependsOn = slices.DeleteFunc(task.DependsOn, func(dep int) bool {
+					return dep == id
+				})
+			})
+		}
+	}
+}
+
+// moveDependents makes the tasks depending on the task with ID from depend on
+// the task with ID to instead. A dependency that would then be on the task
+// itself, be repeated or close a cycle is dropped.
+func (tm *TaskManager) moveDependents(from, to int) {
+	for task := range tm.everyTask() {
+		if !slices.Contains(task.DependsOn, from) {
+			continue
+		}
+		keep := task.ID != to && !slices.Contains(task.DependsOn, to) && !tm.dependsOn(to, task.ID)
+		tm.update(task, func() {
+			if keep {
+				task.DependsOn[slices.Index(task.DependsOn, from)] = to
+				return
+			}
+			task.DependsOn = slices.DeleteFunc(task.DependsOn, func(dep int) bool {
+				return dep == from
+			})
+		})
+	}
+}
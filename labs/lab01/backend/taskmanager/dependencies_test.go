@@ -0,0 +1,95 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestAddDependency(t *testing.T) {
+	tm := NewTaskManager()
+	design := mustAddTask(t, tm, "Design")
+	build := mustAddTask(t, tm, "Build")
+	ship := mustAddTask(t, tm, "Ship")
+
+	if err := tm.AddDependency(build.ID, design.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.AddDependency(ship.ID, build.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		taskID      int
+		dependsOnID int
+		expected    error
+	}{
+		{name: "missing task", taskID: 999, dependsOnID: design.ID, expected: ErrTaskNotFound},
+		{name: "missing dependency", taskID: design.ID, dependsOnID: 999, expected: ErrTaskNotFound},
+		{name: "self dependency", taskID: design.ID, dependsOnID: design.ID, expected: ErrSelfDependency},
+		{name: "cycle", taskID: design.ID, dependsOnID: ship.ID, expected: ErrCyclicDependency},
+		{name: "duplicate is ignored", taskID: build.ID, dependsOnID: design.ID, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tm.AddDependency(tt.taskID, tt.dependsOnID)
+			if err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if len(build.DependsOn) != 1 {
+		t.Errorf("Expected one dependency on build, got %v", build.DependsOn)
+	}
+}
+
+func TestBlockedAndActionable(t *testing.T) {
+	tm := NewTaskManager()
+	design := mustAddTask(t, tm, "Design")
+	build := mustAddTask(t, tm, "Build")
+	if err := tm.AddDependency(build.ID, design.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	blocked := tm.ListBlocked()
+	if len(blocked) != 1 || blocked[0] != build {
+		t.Errorf("Expected build to be blocked, got %v", blocked)
+	}
+	actionable := tm.ListActionable()
+	if len(actionable) != 1 || actionable[0] != design {
+		t.Errorf("Expected design to be actionable, got %v", actionable)
+	}
+
+	if err := tm.UpdateTask(design.ID, design.Title, "", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if blocked := tm.ListBlocked(); len(blocked) != 0 {
+		t.Errorf("Expected nothing blocked once design is done, got %v", blocked)
+	}
+	actionable = tm.ListActionable()
+	if len(actionable) != 1 || actionable[0] != build {
+		t.Errorf("Expected build to become actionable, got %v", actionable)
+	}
+}
+
+func TestPurgeRemovesDependency(t *testing.T) {
+	tm := NewTaskManager()
+	design := mustAddTask(t, tm, "Design")
+	build := mustAddTask(t, tm, "Build")
+	if err := tm.AddDependency(build.ID, design.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.DeleteTask(design.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tm.IsBlocked(build) {
+		t.Error("Expected a deleted dependency not to block")
+	}
+
+	tm.PurgeTrash()
+	if len(build.DependsOn) != 0 {
+		t.Errorf("Expected dependency to be removed on purge, got %v", build.DependsOn)
+	}
+}
@@ -0,0 +1,86 @@
+package taskmanager
+
+import "time"
+
+// WithDueDate sets the due date of a task
+func WithDueDate(due time.Time) TaskOption {
+	return func(t *Task) {
+		t.DueDate = &due
+	}
+}
+
+// ClearDueDate removes the due date from a task
+func ClearDueDate() TaskOption {
+	return func(t *Task) {
+		t.DueDate = nil
+	}
+}
+
+// IsOverdue reports whether the task is still open and its due date is before now
+func (t *Task) IsOverdue(now time.Time) bool {
+	return !t.Status.Closed() && t.DueDate != nil && t.DueDate.Before(now)
+}
+
+// WithTimezone sets the time zone that decides where days and weeks start
+// for DueToday, DueThisWeek and Overdue. The default is the local zone.
+func WithTimezone(loc *time.Location) Option {
+	return func(tm *TaskManager) {
+		tm.timezone = loc
+	}
+}
+
+// DueToday returns open tasks due between the start and end of the current
+// day, earliest first unless opts say otherwise
+func (tm *TaskManager) DueToday(opts ...ListOption) []*Task {
+	today := tm.today()
+	return tm.listDueIn(TimeRange{From: today, To: today.AddDate(0, 0, 1)}, opts)
+}
+
+// DueThisWeek returns open tasks due between the start and end of the
+// current week, which starts on Monday, earliest first unless opts say
+// otherwise. It includes the tasks due today.
+func (tm *TaskManager) DueThisWeek(opts ...ListOption) []*Task {
+	today := tm.today()
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	return tm.listDueIn(TimeRange{From: monday, To: monday.AddDate(0, 0, 7)}, opts)
+}
+
+// Overdue returns open tasks due before the current day started, earliest
+// first unless opts say otherwise. Unlike ListOverdue it leaves out tasks
+// due earlier today, which DueToday lists instead.
+func (tm *TaskManager) Overdue(opts ...ListOption) []*Task {
+	return tm.listDueIn(TimeRange{To: tm.today()}, opts)
+}
+
+// today returns midnight of the current day in the manager's time zone
+func (tm *TaskManager) today() time.Time {
+	now := tm.now().In(tm.timezone)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tm.timezone)
+}
+
+// listDueIn returns open tasks due inside the range, earliest first
+func (tm *TaskManager) listDueIn(r TimeRange, opts []ListOption) []*Task {
+	return tm.filterOpen(func(task *Task) bool {
+		return task.DueDate != nil && r.Contains(*task.DueDate)
+	}, append([]ListOption{sortByDueDate()}, opts...))
+}
+
+// ListOverdue returns open tasks whose due date has passed
+func (tm *TaskManager) ListOverdue(opts ...ListOption) []*Task {
+	return tm.ListDueBefore(tm.now(), opts...)
+}
+
+// ListDueBefore returns open tasks due before the given time, earliest first
+// unless opts say otherwise
+func (tm *TaskManager) ListDueBefore(before time.Time, opts ...ListOption) []*Task {
+	return tm.filterOpen(func(task *Task) bool {
+		return task.DueDate != nil && task.DueDate.Before(before)
+	}, append([]ListOption{sortByDueDate()}, opts...))
+}
+
+// sortByDueDate orders tasks by due date. Every listed task must have one.
+func sortByDueDate() ListOption {
+	return func(q *listQuery) {
+		q.sortByDueDate = true
+	}
+}
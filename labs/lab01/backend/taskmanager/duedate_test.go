@@ -0,0 +1,136 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTaskDueDate(t *testing.T) {
+	tm := NewTaskManager()
+	due := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	task := mustAddTask(t, tm, "Report", WithDueDate(due))
+	if task.DueDate == nil || !task.DueDate.Equal(due) {
+		t.Fatalf("Expected due date %v, got %v", due, task.DueDate)
+	}
+
+	if err := tm.UpdateTask(task.ID, "Report", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.DueDate == nil {
+		t.Error("Update without due date option should keep the due date")
+	}
+
+	if err := tm.UpdateTask(task.ID, "Report", "", false, ClearDueDate()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.DueDate != nil {
+		t.Errorf("Expected due date to be cleared, got %v", task.DueDate)
+	}
+}
+
+func TestListOverdue(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+
+	late := mustAddTask(t, tm, "Late", WithDueDate(now.Add(-2*time.Hour)))
+	later := mustAddTask(t, tm, "Later", WithDueDate(now.Add(-time.Hour)))
+	mustAddTask(t, tm, "Future", WithDueDate(now.Add(time.Hour)))
+	mustAddTask(t, tm, "No due date")
+	done := mustAddTask(t, tm, "Done", WithDueDate(now.Add(-time.Hour)))
+	if err := tm.UpdateTask(done.ID, done.Title, "", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	overdue := tm.ListOverdue()
+	if len(overdue) != 2 {
+		t.Fatalf("Expected 2 overdue tasks, got %d", len(overdue))
+	}
+	if overdue[0] != late || overdue[1] != later {
+		t.Errorf("Expected overdue tasks ordered by due date, got %q, %q", overdue[0].Title, overdue[1].Title)
+	}
+	if !late.IsOverdue(now) {
+		t.Error("Expected late task to be overdue")
+	}
+}
+
+func TestListDueBefore(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+
+	mustAddTask(t, tm, "Tomorrow", WithDueDate(now.Add(24*time.Hour)))
+	mustAddTask(t, tm, "Next week", WithDueDate(now.Add(7*24*time.Hour)))
+
+	tests := []struct {
+		name     string
+		before   time.Time
+		expected int
+	}{
+		{name: "nothing due", before: now, expected: 0},
+		{name: "due within two days", before: now.Add(48 * time.Hour), expected: 1},
+		{name: "due within a month", before: now.Add(30 * 24 * time.Hour), expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tm.ListDueBefore(tt.before)
+			if len(got) != tt.expected {
+				t.Errorf("Expected %d tasks, got %d", tt.expected, len(got))
+			}
+		})
+	}
+}
+
+func TestDueViews(t *testing.T) {
+	// 22:00 on Tuesday 1 July in the manager's zone, already Wednesday in UTC
+	zone := time.FixedZone("UTC-4", -4*60*60)
+	now := time.Date(2025, 7, 2, 2, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }), WithTimezone(zone))
+	at := func(day, hour, minute int) TaskOption {
+		return WithDueDate(time.Date(2025, 7, day, hour, minute, 0, 0, zone))
+	}
+
+	mustAddTask(t, tm, "Next week", at(8, 9, 0))
+	mustAddTask(t, tm, "Sunday", at(6, 12, 0))
+	mustAddTask(t, tm, "Tonight", at(1, 23, 30))
+	mustAddTask(t, tm, "Earlier today", at(1, 9, 0))
+	mustAddTask(t, tm, "Monday", at(0, 15, 0))
+	mustAddTask(t, tm, "No due date")
+	done := mustAddTask(t, tm, "Done on Monday", at(0, 10, 0))
+	archived := mustAddTask(t, tm, "Archived last week", at(-5, 10, 0))
+	if err := tm.Transition(done.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Transition(archived.ID, StatusCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.ArchiveTask(archived.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		list     func(...ListOption) []*Task
+		expected []string
+	}{
+		{name: "due today", list: tm.DueToday, expected: []string{"Earlier today", "Tonight"}},
+		{name: "due this week", list: tm.DueThisWeek, expected: []string{"Monday", "Earlier today", "Tonight", "Sunday"}},
+		{name: "overdue", list: tm.Overdue, expected: []string{"Monday"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tt.list()); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	utc := NewTaskManager(WithClock(func() time.Time { return now }), WithTimezone(time.UTC))
+	mustAddTask(t, utc, "Tonight", at(1, 23, 30))
+	mustAddTask(t, utc, "Earlier today", at(1, 9, 0))
+	if got := titles(utc.DueToday()); !slices.Equal(got, []string{"Tonight"}) {
+		t.Errorf("Expected days to follow the manager's zone, got %v", got)
+	}
+}
@@ -0,0 +1,266 @@
+package taskmanager
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ErrEmptyPassphrase is returned by NewEncryption for an empty passphrase
+var ErrEmptyPassphrase = errors.New("empty passphrase")
+
+// ErrWrongPassphrase is returned for an encrypted file none of the
+// passphrases of an Encryption unlocks
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// ErrEncryptedFile is returned when an encrypted file is opened without an
+// Encryption
+var ErrEncryptedFile = errors.New("task file is encrypted")
+
+// Layout of the header of sealed data, which is authenticated along with
+// the data: magic, format version, scrypt costs, salt and key check,
+// followed by the GCM nonce and the ciphertext
+const (
+	sealMagic       = "TMENC"
+	sealVersion     = 1
+	sealSaltSize    = 16
+	sealCheckSize   = 8
+	sealHeaderSize  = len(sealMagic) + 1 + 3 + sealSaltSize + sealCheckSize
+	sealKeySize     = 32
+	sealNonceSize   = 12
+	sealGCMOverhead = 16
+)
+
+// kdfDefaults are the scrypt costs that derive a key from a passphrase:
+// N = 2^15 and r = 8, taking 32MB and about a tenth of a second
+var kdfDefaults = kdfParams{logN: 15, r: 8, p: 1}
+
+// Caps on the costs a sealed header may ask for, so a damaged header
+// cannot stall opening a file or run it out of memory
+const (
+	maxKDFLogN   = 20
+	maxKDFMemory = 256 << 20
+	maxKDFP      = 16
+)
+
+// Encryption seals the files FileStore and WALStore write with AES-256-GCM,
+// under a key derived with scrypt from a passphrase and a random salt kept
+// in the clear at the start of each file. Open a store with one to encrypt
+// it at rest; files saved in the clear before are still read, and are
+// encrypted the next time they are written. Data is always sealed under the
+// first passphrase, while files sealed under any of the previous ones open
+// too, so rotating the key is a matter of adding the new passphrase in
+// front of the old one and calling ReencryptFiles, or waiting for the store
+// to write its files again. Each key carries a short check value, so a
+// wrong passphrase is told apart from a damaged file, which a FileStore
+// recovers from its backup. Keys are derived once per salt and cached; an
+// Encryption is safe for concurrent use.
+type Encryption struct {
+	passphrases []string
+	kdf         kdfParams
+	salt        []byte
+
+	mu   sync.Mutex
+	keys map[sealKeyID]*sealKey
+}
+
+// sealKeyID identifies a derived key
+type sealKeyID struct {
+	passphrase int
+	salt       string
+	kdf        kdfParams
+}
+
+// sealKey is a derived key, ready to seal and open data
+type sealKey struct {
+	aead  cipher.AEAD
+	check [sealCheckSize]byte
+}
+
+// NewEncryption returns an Encryption sealing under passphrase and opening
+// what was sealed under passphrase or any of the previous ones
+func NewEncryption(passphrase string, previous ...string) (*Encryption, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	salt := make([]byte, sealSaltSize)
+	rand.Read(salt)
+	return &Encryption{
+		passphrases: append([]string{passphrase}, previous...),
+		kdf:         kdfDefaults,
+		salt:        salt,
+		keys:        make(map[sealKeyID]*sealKey),
+	}, nil
+}
+
+// key returns the key derived from a passphrase and salt, deriving it the
+// first time
+func (e *Encryption) key(id sealKeyID) (*sealKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if key, ok := e.keys[id]; ok {
+		return key, nil
+	}
+	derived, err := scryptKey(e.passphrases[id.passphrase], []byte(id.salt), id.kdf, sealKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	key := &sealKey{aead: aead}
+	sum := sha256.Sum256(append([]byte("taskmanager key check\x00"), derived...))
+	copy(key.check[:], sum[:])
+	e.keys[id] = key
+	return key, nil
+}
+
+// isSealed reports whether data was sealed by an Encryption
+func isSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(sealMagic))
+}
+
+// seal encrypts data under the first passphrase. A nil Encryption returns
+// data as it is.
+func (e *Encryption) seal(data []byte) ([]byte, error) {
+	if e == nil {
+		return data, nil
+	}
+	key, err := e.key(sealKeyID{salt: string(e.salt), kdf: e.kdf})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, sealHeaderSize+sealNonceSize+len(data)+sealGCMOverhead)
+	out = append(out, sealMagic...)
+	out = append(out, sealVersion)
+	out = append(out, e.kdf.logN, e.kdf.r, e.kdf.p)
+	out = append(out, e.salt...)
+	out = append(out, key.check[:]...)
+	header := out[:sealHeaderSize]
+	nonce := make([]byte, sealNonceSize)
+	rand.Read(nonce)
+	out = append(out, nonce...)
+	return key.aead.Seal(out, nonce, data, header), nil
+}
+
+// open decrypts data sealed under any of the passphrases, and returns data
+// that is not sealed as it is. The errors wrap ErrEncryptedFile for sealed
+// data and a nil Encryption, ErrWrongPassphrase when no passphrase matches
+// the key check, and ErrCorruptFile when the data fails to authenticate.
+func (e *Encryption) open(data []byte) ([]byte, error) {
+	if !isSealed(data) {
+		return data, nil
+	}
+	if e == nil {
+		return nil, ErrEncryptedFile
+	}
+	if len(data) < sealHeaderSize+sealNonceSize+sealGCMOverhead {
+		return nil, fmt.Errorf("%w: truncated", ErrCorruptFile)
+	}
+	header := data[:sealHeaderSize]
+	rest := header[len(sealMagic):]
+	if version := rest[0]; version != sealVersion {
+		return nil, fmt.Errorf("%w: encryption version %d", ErrUnsupportedFileVersion, version)
+	}
+	kdf := kdfParams{logN: rest[1], r: rest[2], p: rest[3]}
+	if kdf.logN < 1 || kdf.logN > maxKDFLogN || kdf.r < 1 || kdf.p < 1 || kdf.p > maxKDFP || kdf.memory() > maxKDFMemory {
+		return nil, fmt.Errorf("%w: key derivation costs %+v", ErrCorruptFile, kdf)
+	}
+	salt := rest[4 : 4+sealSaltSize]
+	check := rest[4+sealSaltSize:]
+	nonce := data[sealHeaderSize : sealHeaderSize+sealNonceSize]
+	for i := range e.passphrases {
+		key, err := e.key(sealKeyID{passphrase: i, salt: string(salt), kdf: kdf})
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(key.check[:], check) {
+			continue
+		}
+		plain, err := key.aead.Open(nil, nonce, data[sealHeaderSize+sealNonceSize:], header)
+		if err != nil {
+			return nil, fmt.Errorf("%w: authentication failed", ErrCorruptFile)
+		}
+		return plain, nil
+	}
+	return nil, ErrWrongPassphrase
+}
+
+// ReencryptFiles rewrites the files of the FileStore or WALStore at path
+// under the first passphrase of enc: the file itself, its backup, its log
+// and its older checkpoints. Files are read with any of the passphrases of
+// enc, or in the clear, so it both rotates the key of an encrypted store
+// and encrypts one saved in the clear. A record left half-written at the
+// end of the log is dropped, as opening the store would. No store may be
+// open on path meanwhile.
+func ReencryptFiles(path string, enc *Encryption) error {
+	files := []string{path, path + backupSuffix}
+	for n := 1; ; n++ {
+		snapshot := path + "." + strconv.Itoa(n)
+		if _, err := os.Stat(snapshot); err != nil {
+			break
+		}
+		files = append(files, snapshot)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if data, err = enc.open(data); err != nil {
+			return fmt.Errorf("%w: %s", err, file)
+		}
+		if data, err = enc.seal(data); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(file, data, ""); err != nil {
+			return err
+		}
+	}
+	return reencryptLog(path+walSuffix, enc)
+}
+
+// reencryptLog rewrites each whole record of a WALStore's log under the
+// first passphrase of enc
+func reencryptLog(path string, enc *Encryption) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var out []byte
+	for offset := 0; ; {
+		record, n, ok, err := decodeWALRecord(data[offset:], enc)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, path)
+		}
+		if !ok {
+			break
+		}
+		frame, err := encodeWALRecord(record, enc)
+		if err != nil {
+			return err
+		}
+		out = append(out, frame...)
+		offset += n
+	}
+	return writeFileAtomic(path, out, "")
+}
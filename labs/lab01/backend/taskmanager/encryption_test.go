@@ -0,0 +1,228 @@
+package taskmanager
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testEncryption returns an Encryption deriving its keys at low scrypt
+// costs, so tests stay fast
+func testEncryption(t *testing.T, passphrase string, previous ...string) *Encryption {
+	t.Helper()
+	enc, err := NewEncryption(passphrase, previous...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	enc.kdf = kdfParams{logN: 4, r: 8, p: 1}
+	return enc
+}
+
+func TestEncryptionSealOpen(t *testing.T) {
+	plain := []byte(`{"tasks": []}`)
+	old := testEncryption(t, "old secret")
+	sealed, err := old.seal(plain)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bytes.Contains(sealed, plain) {
+		t.Fatal("Expected the data encrypted")
+	}
+	tampered := slices.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	newer := slices.Clone(sealed)
+	newer[len(sealMagic)] = sealVersion + 1
+	costly := slices.Clone(sealed)
+	costly[len(sealMagic)+1] = maxKDFLogN + 1
+
+	tests := []struct {
+		name string
+		enc  *Encryption
+		data []byte
+		want error
+	}{
+		{"same passphrase", testEncryption(t, "old secret"), sealed, nil},
+		{"previous passphrase", testEncryption(t, "new secret", "old secret"), sealed, nil},
+		{"in the clear", testEncryption(t, "new secret"), plain, nil},
+		{"wrong passphrase", testEncryption(t, "new secret"), sealed, ErrWrongPassphrase},
+		{"no encryption", nil, sealed, ErrEncryptedFile},
+		{"tampered", old, tampered, ErrCorruptFile},
+		{"truncated", old, sealed[:sealHeaderSize+4], ErrCorruptFile},
+		{"newer version", old, newer, ErrUnsupportedFileVersion},
+		{"costs too high", old, costly, ErrCorruptFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.enc.open(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, err)
+			}
+			if err == nil && !bytes.Equal(got, plain) {
+				t.Errorf("Expected %s, got %s", plain, got)
+			}
+		})
+	}
+
+	if _, err := NewEncryption(""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("Expected %v, got %v", ErrEmptyPassphrase, err)
+	}
+}
+
+func TestScryptKey(t *testing.T) {
+	// Test vectors of RFC 7914, section 12
+	tests := []struct {
+		passphrase, salt string
+		params           kdfParams
+		want             string
+	}{
+		{"", "", kdfParams{logN: 4, r: 1, p: 1}, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", kdfParams{logN: 10, r: 8, p: 16}, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+	}
+	for _, tt := range tests {
+		got, err := scryptKey(tt.passphrase, []byte(tt.salt), tt.params, 64)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("Expected %s, got %x", tt.want, got)
+		}
+	}
+}
+
+// openEncryptedManager opens the encrypted store at path and a manager
+// saving to it
+func openEncryptedManager(t *testing.T, path string, enc *Encryption) (*FileStore, *SafeTaskManager) {
+	t.Helper()
+	store, err := OpenEncryptedFileStore(path, enc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return store, NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{}))
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	enc := testEncryption(t, "secret")
+	_, s := openEncryptedManager(t, path, enc)
+	s.AddTask("Call the doctor", "")
+	s.Flush()
+	s.AddTask("Pay rent", "")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, file := range []string{path, path + backupSuffix} {
+		if data, _ := os.ReadFile(file); !isSealed(data) || bytes.Contains(data, []byte("Call the doctor")) {
+			t.Errorf("Expected %s encrypted", file)
+		}
+	}
+
+	_, r := openEncryptedManager(t, path, testEncryption(t, "secret"))
+	if got := titles(r.ListTasks(nil)); !slices.Equal(got, []string{"Call the doctor", "Pay rent"}) {
+		t.Errorf("Expected the tasks decrypted, got %v", got)
+	}
+	r.Close()
+
+	tests := []struct {
+		name string
+		enc  *Encryption
+		want error
+	}{
+		{"wrong passphrase", testEncryption(t, "guess"), ErrWrongPassphrase},
+		{"no encryption", nil, ErrEncryptedFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := OpenEncryptedFileStore(path, tt.enc); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// A damaged file is recovered from its backup
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 1
+	os.WriteFile(path, data, 0o644)
+	store, err := OpenEncryptedFileStore(path, enc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !store.Recovered() || store.Len() != 1 {
+		t.Errorf("Expected the backup of 1 task loaded, got %d tasks", store.Len())
+	}
+}
+
+func TestEncryptedWALStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	opts := WALOptions{Encryption: testEncryption(t, "secret"), CheckpointRecords: 2}
+	store, s := openWALManager(t, path, opts)
+	for _, title := range []string{"Call the doctor", "Pay rent", "Renew passport"} {
+		s.AddTask(title, "")
+		s.Flush()
+	}
+	s.Close()
+	store.Close()
+	for _, file := range []string{path, path + walSuffix} {
+		if data, _ := os.ReadFile(file); len(data) == 0 || bytes.Contains(data, []byte("Renew passport")) || bytes.Contains(data, []byte("Call the doctor")) {
+			t.Errorf("Expected %s to hold encrypted tasks", file)
+		}
+	}
+
+	reopened, r := openWALManager(t, path, WALOptions{Encryption: testEncryption(t, "secret")})
+	defer r.Close()
+	if reopened.Replayed() != 1 {
+		t.Errorf("Expected 1 record replayed, got %d", reopened.Replayed())
+	}
+	if got := len(r.ListTasks(nil)); got != 3 {
+		t.Errorf("Expected 3 tasks, got %d", got)
+	}
+	if _, err := OpenWALStore(path, WALOptions{Encryption: testEncryption(t, "guess")}); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected %v, got %v", ErrWrongPassphrase, err)
+	}
+}
+
+func TestReencryptFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		// before is the Encryption the store is saved with, nil for one in
+		// the clear
+		before *Encryption
+	}{
+		{"rotate key", testEncryption(t, "old secret")},
+		{"encrypt store in the clear", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.json")
+			opts := WALOptions{Encryption: tt.before, CheckpointRecords: 2, KeepSnapshots: 1}
+			store, s := openWALManager(t, path, opts)
+			for _, title := range []string{"First", "Second", "Third", "Fourth", "Fifth"} {
+				s.AddTask(title, "")
+				s.Flush()
+			}
+			s.Close()
+			store.Close()
+
+			if err := ReencryptFiles(path, testEncryption(t, "new secret", "old secret")); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			current := testEncryption(t, "new secret")
+			reopened, r := openWALManager(t, path, WALOptions{Encryption: current, KeepSnapshots: 1})
+			defer r.Close()
+			if got := titles(r.ListTasks(nil)); !slices.Equal(got, []string{"First", "Second", "Third", "Fourth", "Fifth"}) {
+				t.Errorf("Expected every task under the new passphrase, got %v", got)
+			}
+			for _, file := range append(reopened.Snapshots(), path+backupSuffix) {
+				if _, err := OpenEncryptedFileStore(file, current); err != nil {
+					t.Errorf("Expected %s under the new passphrase, got %v", file, err)
+				}
+			}
+			if _, err := OpenWALStore(path, WALOptions{Encryption: testEncryption(t, "old secret")}); !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("Expected the old passphrase rejected, got %v", err)
+			}
+		})
+	}
+}
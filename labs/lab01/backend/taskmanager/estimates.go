@@ -0,0 +1,67 @@
+package taskmanager
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidEstimate is returned when a task estimate is negative
+var ErrInvalidEstimate = errors.New("estimate cannot be negative")
+
+// WithEstimate sets how long a task is expected to take
+func WithEstimate(estimate time.Duration) TaskOption {
+	return func(t *Task) {
+		t.Estimate = estimate
+	}
+}
+
+// RemainingEffort returns how much of the estimate is left: zero for closed
+// tasks, otherwise the estimate minus tracked time, never below zero
+func (t *Task) RemainingEffort(now time.Time) time.Duration {
+	if t.Status.Closed() {
+		return 0
+	}
+	return max(t.Estimate-t.trackedAt(now), 0)
+}
+
+// RolledUpEstimate returns the estimate of a task plus those of all its subtasks
+func (tm *TaskManager) RolledUpEstimate(id int) (time.Duration, error) {
+	if _, err := tm.lookup(id); err != nil {
+		return 0, err
+	}
+	return tm.sumSubtree(id, func(t *Task) time.Duration {
+		return t.Estimate
+	}), nil
+}
+
+// RolledUpRemaining returns the remaining effort of a task plus that of all its subtasks
+func (tm *TaskManager) RolledUpRemaining(id int) (time.Duration, error) {
+	if _, err := tm.lookup(id); err != nil {
+		return 0, err
+	}
+	now := tm.now()
+	return tm.sumSubtree(id, func(t *Task) time.Duration {
+		return t.RemainingEffort(now)
+	}), nil
+}
+
+// TotalEstimate returns the summed estimates of the tasks matching the list
+// options, snoozed ones included. Subtasks are counted on their own, not
+// rolled up into parents.
+func (tm *TaskManager) TotalEstimate(opts ...ListOption) time.Duration {
+	var total time.Duration
+	for _, task := range tm.list(nil, append([]ListOption{IncludeSnoozed()}, opts...)...) {
+		total += task.Estimate
+	}
+	return total
+}
+
+// sumSubtree adds up value over a task and all of its descendants
+func (tm *TaskManager) sumSubtree(id int, value func(*Task) time.Duration) time.Duration {
+	task, _ := tm.tasks.Get(id)
+	total := value(task)
+	for _, child := range tm.children(id) {
+		total += tm.sumSubtree(child.ID, value)
+	}
+	return total
+}
@@ -0,0 +1,68 @@
+package taskmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateValidation(t *testing.T) {
+	tm := NewTaskManager()
+	if _, err := tm.AddTask("Task", "", WithEstimate(-time.Hour)); err != ErrInvalidEstimate {
+		t.Errorf("Expected ErrInvalidEstimate, got %v", err)
+	}
+	task := mustAddTask(t, tm, "Task", WithEstimate(2*time.Hour))
+	if task.Estimate != 2*time.Hour {
+		t.Errorf("Expected 2h estimate, got %v", task.Estimate)
+	}
+}
+
+func TestRolledUpEstimate(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	parent := mustAddTask(t, tm, "Release", WithEstimate(time.Hour))
+	build := mustAddTask(t, tm, "Build", WithParent(parent.ID), WithEstimate(3*time.Hour))
+	test := mustAddTask(t, tm, "Test", WithParent(build.ID), WithEstimate(2*time.Hour))
+	mustAddTask(t, tm, "Docs", WithParent(parent.ID), WithEstimate(30*time.Minute))
+
+	total, err := tm.RolledUpEstimate(parent.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 6*time.Hour+30*time.Minute {
+		t.Errorf("Expected 6h30m rolled up, got %v", total)
+	}
+
+	if err := tm.Transition(test.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.StartTimer(build.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Hour)
+
+	remaining, err := tm.RolledUpRemaining(parent.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if remaining != 3*time.Hour+30*time.Minute {
+		t.Errorf("Expected 3h30m remaining, got %v", remaining)
+	}
+
+	if _, err := tm.RolledUpEstimate(999); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestTotalEstimate(t *testing.T) {
+	tm := NewTaskManager()
+	mustAddTask(t, tm, "Essay", WithTags("school"), WithEstimate(2*time.Hour))
+	mustAddTask(t, tm, "Lab", WithTags("school"), WithEstimate(time.Hour))
+	mustAddTask(t, tm, "Chores", WithTags("home"), WithEstimate(time.Hour))
+
+	if total := tm.TotalEstimate(FilterByAllTags("school")); total != 3*time.Hour {
+		t.Errorf("Expected 3h for school, got %v", total)
+	}
+	if total := tm.TotalEstimate(); total != 4*time.Hour {
+		t.Errorf("Expected 4h overall, got %v", total)
+	}
+}
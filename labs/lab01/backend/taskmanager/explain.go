@@ -0,0 +1,53 @@
+package taskmanager
+
+import "time"
+
+// QueryStats describes how a listing was run, for tuning the index and
+// finding slow queries
+type QueryStats struct {
+	// Index names the index that chose the candidates: "status", "tag" or
+	// "assignee", or "" when every active task was scanned
+	Index string
+	// Scanned is the number of tasks checked against the filters
+	Scanned int
+	// Matched is the number of tasks that passed the filters
+	Matched int
+	// FilterTime is the time spent finding and checking tasks
+	FilterTime time.Duration
+	// SortTime is the time spent ordering the matches
+	SortTime time.Duration
+}
+
+// Explain makes a listing fill stats with how it was run. The stats are
+// reset whenever a listing using the option starts.
+func Explain(stats *QueryStats) ListOption {
+	return func(q *listQuery) {
+		*stats = QueryStats{}
+		q.stats = stats
+	}
+}
+
+// add adds the counts and timings of other to s, taking its index when s
+// has none yet
+func (s *QueryStats) add(other QueryStats) {
+	if s.Index == "" {
+		s.Index = other.Index
+	}
+	s.Scanned += other.Scanned
+	s.Matched += other.Matched
+	s.FilterTime += other.FilterTime
+	s.SortTime += other.SortTime
+}
+
+// test reports whether the task passes the query, counting it in the
+// query's stats
+func (q *listQuery) test(task *Task) bool {
+	ok := q.matches(task)
+	if q.stats != nil {
+		q.stats.Scanned++
+		if ok {
+			q.stats.Matched++
+		}
+	}
+	return ok
+}
@@ -0,0 +1,75 @@
+package taskmanager
+
+import "testing"
+
+func TestExplain(t *testing.T) {
+	tm := NewTaskManager()
+	for i := range 10 {
+		var opts []TaskOption
+		if i < 3 {
+			opts = append(opts, WithTags("work"))
+		}
+		if i%2 == 0 {
+			opts = append(opts, WithPriority(PriorityHigh))
+		}
+		mustAddTask(t, tm, "Task", opts...)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ListOption
+		expected QueryStats
+	}{
+		{name: "tag index", opts: []ListOption{FilterByAllTags("work"), FilterByPriority(PriorityHigh)}, expected: QueryStats{Index: indexTag, Scanned: 3, Matched: 2}},
+		{name: "status index", opts: []ListOption{FilterByStatus(StatusTodo)}, expected: QueryStats{Index: indexStatus, Scanned: 10, Matched: 10}},
+		{name: "full scan", opts: []ListOption{FilterByPriority(PriorityHigh)}, expected: QueryStats{Scanned: 10, Matched: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := QueryStats{Scanned: 99}
+			tasks := tm.ListTasks(nil, append(tt.opts, Explain(&stats))...)
+			if stats.FilterTime < 0 || stats.SortTime < 0 {
+				t.Errorf("Expected non-negative timings, got %+v", stats)
+			}
+			stats.FilterTime, stats.SortTime = 0, 0
+			if stats != tt.expected || len(tasks) != tt.expected.Matched {
+				t.Errorf("Expected %+v, got %+v for %d tasks", tt.expected, stats, len(tasks))
+			}
+		})
+	}
+}
+
+func TestFindTasksExplain(t *testing.T) {
+	tm := NewTaskManager()
+	for range 4 {
+		mustAddTask(t, tm, "Task")
+	}
+	if err := tm.AssignTask(2, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	page, err := tm.FindTasks(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.Stats != nil {
+		t.Errorf("Expected no stats unless asked for, got %+v", page.Stats)
+	}
+
+	alice := "alice"
+	page, err = tm.FindTasks(ListOptions{AssigneeID: &alice, Explain: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.Stats == nil || page.Stats.Index != indexAssignee || page.Stats.Scanned != 1 || page.Stats.Matched != 1 {
+		t.Errorf("Expected the assignee index to yield 1 task, got %+v", page.Stats)
+	}
+	summaries, err := tm.FindSummaries(ListOptions{Explain: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if summaries.Stats == nil || summaries.Stats.Scanned != 4 {
+		t.Errorf("Expected stats with the summaries, got %+v", summaries.Stats)
+	}
+}
@@ -0,0 +1,279 @@
+package taskmanager
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrCorruptFile is returned when a task file fails to parse or its
+// checksum does not match its tasks
+var ErrCorruptFile = errors.New("corrupt task file")
+
+// ErrUnsupportedFileVersion is returned for a task file written by a newer
+// version of the package
+var ErrUnsupportedFileVersion = errors.New("unsupported task file version")
+
+// fileStoreVersion is the version of the file layout FileStore writes
+const fileStoreVersion = 1
+
+// backupSuffix is added to the path of a FileStore for the file it
+// replaced last
+const backupSuffix = ".bak"
+
+// taskFile is the JSON layout of a FileStore's file
+type taskFile struct {
+	Version int `json:"version"`
+	NextID  int `json:"next_id"`
+	// Checksum is the hex SHA-256 of Tasks as compact JSON
+	Checksum string          `json:"checksum"`
+	Tasks    json.RawMessage `json:"tasks"`
+}
+
+// FileStore keeps the active tasks in memory, as a MemoryStore does, and
+// saves them to a JSON file when given to WithPersister as well:
+//
+//	store, err := OpenFileStore("tasks.json")
+//	if err != nil {
+//		return err
+//	}
+//	s := NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{}))
+//	defer s.Close()
+//
+// Each save writes a temporary file next to the file and renames it over
+// the file, so a crash never leaves a half-written file behind, and keeps
+// the file it replaced with a .bak suffix. The file holds a checksum of its
+// tasks; when a file is found corrupt on open, the store is recovered from
+// the backup. Only the active tasks are saved: the trash, undo history,
+// projects, templates and attachment contents are not, so tasks come back
+// without their project, and custom field numbers come back from JSON
+// files as float64. OpenFileStoreWith opens a store whose files are
+// encrypted or in the binary snapshot format.
+type FileStore struct {
+	*MemoryStore
+	path   string
+	enc    *Encryption
+	format FileFormat
+	// mu keeps saves from overlapping
+	mu        sync.Mutex
+	recovered bool
+}
+
+// OpenFileStore loads the tasks saved at path, or starts an empty store
+// when there is no file there yet. A file that is corrupt is replaced by
+// its backup, which Recovered then reports; if the backup is corrupt too,
+// the error wraps ErrCorruptFile.
+func OpenFileStore(path string) (*FileStore, error) {
+	return OpenFileStoreWith(path, FileOptions{})
+}
+
+// OpenEncryptedFileStore opens the store at path as OpenFileStore does,
+// encrypting what it saves with enc and decrypting what it loads. A file
+// saved in the clear is loaded and encrypted on the next save; a file
+// sealed under none of the passphrases of enc fails with
+// ErrWrongPassphrase rather than being replaced by its backup. A nil enc
+// opens a store in the clear, which fails with ErrEncryptedFile on an
+// encrypted file.
+func OpenEncryptedFileStore(path string, enc *Encryption) (*FileStore, error) {
+	return OpenFileStoreWith(path, FileOptions{Encryption: enc})
+}
+
+// FileOptions sets how a FileStore saves its file
+type FileOptions struct {
+	// Encryption encrypts the file, as OpenEncryptedFileStore does
+	Encryption *Encryption
+	// Format is the encoding of the tasks in the file. Files are loaded in
+	// either format and saved in this one from then on.
+	Format FileFormat
+}
+
+// OpenFileStoreWith opens the store at path as OpenFileStore does, saving
+// as opts says
+func OpenFileStoreWith(path string, opts FileOptions) (*FileStore, error) {
+	f := &FileStore{MemoryStore: NewMemoryStore(), path: path, enc: opts.Encryption, format: opts.Format}
+	err := f.load(path)
+	if err == nil {
+		return f, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		// A crash between the two renames of a save leaves only the backup
+		switch err := f.load(path + backupSuffix); {
+		case err == nil, errors.Is(err, fs.ErrNotExist):
+			return f, nil
+		default:
+			return nil, err
+		}
+	}
+	if !errors.Is(err, ErrCorruptFile) {
+		return nil, err
+	}
+	f.MemoryStore = NewMemoryStore()
+	if backupErr := f.load(path + backupSuffix); backupErr != nil {
+		return nil, fmt.Errorf("%w; restoring %s%s: %w", err, path, backupSuffix, backupErr)
+	}
+	f.recovered = true
+	return f, nil
+}
+
+// load reads the tasks of a file into the store
+func (f *FileStore) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if data, err = f.enc.open(data); err != nil {
+		return fmt.Errorf("%w: %s", err, path)
+	}
+	var nextID int
+	var tasks []*Task
+	if isBinarySnapshot(data) {
+		header, decoded, err := readBinarySnapshot(data)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, path)
+		}
+		nextID, tasks = header.NextID, decoded
+	} else if nextID, tasks, err = decodeTaskFile(path, data); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task == nil || task.ID <= 0 {
+			return fmt.Errorf("%w: %s: invalid task ID", ErrCorruptFile, path)
+		}
+		if _, ok := f.Get(task.ID); ok {
+			return fmt.Errorf("%w: %s: duplicate task %d", ErrCorruptFile, path, task.ID)
+		}
+		f.Put(task)
+		nextID = max(nextID, task.ID+1)
+	}
+	f.seq = NewSequenceIDs(nextID, 1)
+	return nil
+}
+
+// decodeTaskFile decodes the file at path in the JSON layout, returning
+// the next ID and the tasks
+func decodeTaskFile(path string, data []byte) (int, []*Task, error) {
+	var file taskFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return 0, nil, fmt.Errorf("%w: %s: %v", ErrCorruptFile, path, err)
+	}
+	if file.Version > fileStoreVersion {
+		return 0, nil, fmt.Errorf("%w: %s has version %d", ErrUnsupportedFileVersion, path, file.Version)
+	}
+	// The checksum covers the tasks as compact JSON, however the file is
+	// indented
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, file.Tasks); err != nil {
+		return 0, nil, fmt.Errorf("%w: %s: %v", ErrCorruptFile, path, err)
+	}
+	if sum := sha256.Sum256(compact.Bytes()); hex.EncodeToString(sum[:]) != file.Checksum {
+		return 0, nil, fmt.Errorf("%w: %s: checksum mismatch", ErrCorruptFile, path)
+	}
+	var tasks []*Task
+	if err := json.Unmarshal(file.Tasks, &tasks); err != nil {
+		return 0, nil, fmt.Errorf("%w: %s: %v", ErrCorruptFile, path, err)
+	}
+	return file.NextID, tasks, nil
+}
+
+// Recovered reports whether OpenFileStore found the file corrupt and
+// loaded the backup instead
+func (f *FileStore) Recovered() bool {
+	return f.recovered
+}
+
+// Path returns the path of the file the store saves to
+func (f *FileStore) Path() string {
+	return f.path
+}
+
+// Persist saves the tasks of the snapshot to the file, keeping the
+// previous file as the backup
+func (f *FileStore) Persist(snapshot *Snapshot) error {
+	tasks := slices.Collect(snapshot.tm.tasks.List())
+	slices.SortFunc(tasks, func(a, b *Task) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	data, err := f.encode(tasks, snapshot.TakenAt())
+	if err != nil {
+		return err
+	}
+	if data, err = f.enc.seal(data); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return writeFileAtomic(f.path, data, f.path+backupSuffix)
+}
+
+// encode returns the file holding tasks in the store's format
+func (f *FileStore) encode(tasks []*Task, takenAt time.Time) ([]byte, error) {
+	if f.format == FileBinary {
+		var b bytes.Buffer
+		header := binarySnapshotHeader{TakenAt: takenAt, NextID: f.seq.peek(), Tasks: len(tasks)}
+		err := writeBinarySnapshot(&b, binarySnapshotVersion, header, encodeTasks(slices.Values(tasks)))
+		return b.Bytes(), err
+	}
+	encoded, err := json.Marshal(tasks)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(encoded)
+	return json.MarshalIndent(taskFile{
+		Version:  fileStoreVersion,
+		NextID:   f.seq.peek(),
+		Checksum: hex.EncodeToString(sum[:]),
+		Tasks:    encoded,
+	}, "", "  ")
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames
+// it over path, first moving the file at path to backup when backup is
+// not empty
+func writeFileAtomic(path string, data []byte, backup string) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if backup != "" {
+		if err := os.Rename(path, backup); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the renames in dir to disk where the platform allows
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,180 @@
+package taskmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openFileManager opens the store at path and a manager saving to it, with
+// a clock without monotonic readings, so tasks compare equal once loaded
+func openFileManager(t *testing.T, path string) (*FileStore, *SafeTaskManager) {
+	t.Helper()
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	return store, NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{Interval: time.Hour}), WithClock(clock))
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	_, s := openFileManager(t, path)
+	due := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	first, _ := s.AddTask("Write report", "Draft **first**", WithTags("work"), WithPriority(PriorityHigh), WithDueDate(due))
+	s.AddSubtask(first.ID, "Outline", "")
+	second, _ := s.AddTask("Removed", "")
+	s.DeleteTask(second.ID)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	store, reopened := openFileManager(t, path)
+	defer reopened.Close()
+	if store.Recovered() {
+		t.Error("Expected the file to load without recovery")
+	}
+	got, err := reopened.GetTask(first.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want, _ := s.GetTask(first.ID)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if titles := titles(reopened.ListTasks(nil)); !slices.Equal(titles, []string{"Write report", "Outline"}) {
+		t.Errorf("Expected the active tasks back, got %v", titles)
+	}
+	if tasks := reopened.ListTasks(nil, FilterByAnyTag("work")); len(tasks) != 1 {
+		t.Errorf("Expected the loaded tasks to be indexed, got %d", len(tasks))
+	}
+	task, _ := reopened.AddTask("After reopening", "")
+	if task.ID <= second.ID {
+		t.Errorf("Expected IDs to carry on after %d, got %d", second.ID, task.ID)
+	}
+
+	if err := reopened.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path + backupSuffix); err != nil {
+		t.Errorf("Expected the replaced file to be kept as a backup, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp") {
+			t.Errorf("Expected no temporary file left behind, got %s", entry.Name())
+		}
+	}
+}
+
+func TestFileStoreProjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	_, s := openFileManager(t, path)
+	course, _ := s.CreateProject("Course")
+	task, _ := s.AddTask("Write report", "", WithProject(course.ID))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Projects are not saved, so a task comes back without its project and
+	// can still be changed
+	_, reopened := openFileManager(t, path)
+	defer reopened.Close()
+	if got, _ := reopened.GetTask(task.ID); got.ProjectID != 0 {
+		t.Errorf("Expected the missing project cleared, got %d", got.ProjectID)
+	}
+	if err := reopened.UpdateTaskFields(task.ID, TaskPatch{Title: ptr("Final report")}); err != nil {
+		t.Errorf("Expected the task to be updated, got %v", err)
+	}
+	if err := reopened.UpdateTask(task.ID, "Final report", "", true); err != nil {
+		t.Errorf("Expected the task to be updated, got %v", err)
+	}
+}
+
+func TestFileStoreRecovery(t *testing.T) {
+	// saved writes two saves, so a backup holding the first exists
+	saved := func(t *testing.T) string {
+		path := filepath.Join(t.TempDir(), "tasks.json")
+		_, s := openFileManager(t, path)
+		s.AddTask("First", "")
+		s.Flush()
+		s.AddTask("Second", "")
+		s.Close()
+		return path
+	}
+	corrupt := func(t *testing.T, path string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		os.WriteFile(path, []byte(strings.Replace(string(data), "Second", "Altered", 1)), 0o644)
+	}
+
+	tests := []struct {
+		name      string
+		damage    func(t *testing.T, path string)
+		want      []string
+		recovered bool
+		err       error
+	}{
+		{"intact", func(*testing.T, string) {}, []string{"First", "Second"}, false, nil},
+		{"checksum mismatch", corrupt, []string{"First"}, true, nil},
+		{"truncated", func(t *testing.T, path string) {
+			data, _ := os.ReadFile(path)
+			os.WriteFile(path, data[:len(data)/2], 0o644)
+		}, []string{"First"}, true, nil},
+		{"missing file", func(t *testing.T, path string) {
+			os.Remove(path)
+		}, []string{"First"}, false, nil},
+		{"corrupt backup too", func(t *testing.T, path string) {
+			corrupt(t, path)
+			os.WriteFile(path+backupSuffix, []byte("{"), 0o644)
+		}, nil, false, ErrCorruptFile},
+		{"newer version", func(t *testing.T, path string) {
+			data, _ := os.ReadFile(path)
+			os.WriteFile(path, []byte(strings.Replace(string(data), `"version": 1`, `"version": 99`, 1)), 0o644)
+		}, nil, false, ErrUnsupportedFileVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := saved(t)
+			tt.damage(t, path)
+			store, err := OpenFileStore(path)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if store.Recovered() != tt.recovered {
+				t.Errorf("Expected Recovered to be %v", tt.recovered)
+			}
+			tm := NewTaskManager(WithTaskStore(store))
+			if got := titles(tm.ListTasks(nil)); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFileStoreEmpty(t *testing.T) {
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if store.Len() != 0 || store.Recovered() {
+		t.Errorf("Expected an empty store, got %d tasks", store.Len())
+	}
+	if _, err := OpenFileStore(filepath.Join(t.TempDir(), "missing", "dir", "tasks.json")); err != nil {
+		t.Errorf("Expected a missing file to open empty, got %v", err)
+	}
+}
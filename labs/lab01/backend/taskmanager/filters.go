@@ -0,0 +1,123 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrFilterNotFound is returned when no saved filter has the given name
+	ErrFilterNotFound = errors.New("filter not found")
+	// ErrEmptyFilterName is returned when a saved filter has no name
+	ErrEmptyFilterName = errors.New("filter name cannot be empty")
+	// ErrFilterExists is returned when another saved filter already has the same name
+	ErrFilterExists = errors.New("filter with this name already exists")
+)
+
+// SavedFilter is a named listing, such as a "Work" or "Urgent" view, kept in
+// the manager so every client shows the same tasks for it
+type SavedFilter struct {
+	Name    string
+	Options ListOptions
+}
+
+// SaveFilter stores a new named filter after validating its options
+func (tm *TaskManager) SaveFilter(name string, opts ListOptions) (*SavedFilter, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyFilterName
+	}
+	if _, ok := tm.filters[name]; ok {
+		return nil, ErrFilterExists
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	f := &SavedFilter{Name: name, Options: opts.clone()}
+	tm.filters[name] = f
+	return f, nil
+}
+
+// GetFilter retrieves a saved filter by name
+func (tm *TaskManager) GetFilter(name string) (*SavedFilter, error) {
+	f, ok := tm.filters[strings.TrimSpace(name)]
+	if !ok {
+		return nil, ErrFilterNotFound
+	}
+	return f, nil
+}
+
+// ListFilters returns all saved filters ordered by name
+func (tm *TaskManager) ListFilters() []*SavedFilter {
+	result := make([]*SavedFilter, 0, len(tm.filters))
+	for _, f := range tm.filters {
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// UpdateFilter replaces the options of a saved filter
+func (tm *TaskManager) UpdateFilter(name string, opts ListOptions) error {
+	f, err := tm.GetFilter(name)
+	if err != nil {
+		return err
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	f.Options = opts.clone()
+	return nil
+}
+
+// DeleteFilter removes a saved filter
+func (tm *TaskManager) DeleteFilter(name string) error {
+	f, err := tm.GetFilter(name)
+	if err != nil {
+		return err
+	}
+	delete(tm.filters, f.Name)
+	return nil
+}
+
+// ListByFilter returns the tasks the saved filter selects, as FindTasks
+// would for its options
+func (tm *TaskManager) ListByFilter(name string) (TaskPage, error) {
+	f, err := tm.GetFilter(name)
+	if err != nil {
+		return TaskPage{}, err
+	}
+	return tm.FindTasks(f.Options)
+}
+
+// clone returns a copy of the filter that shares no memory with f
+func (f *SavedFilter) clone() *SavedFilter {
+	return &SavedFilter{Name: f.Name, Options: f.Options.clone()}
+}
+
+// clone returns a copy of the options that shares no memory with o
+func (o ListOptions) clone() ListOptions {
+	c := o
+	if o.Done != nil {
+		done := *o.Done
+		c.Done = &done
+	}
+	if o.AssigneeID != nil {
+		assignee := *o.AssigneeID
+		c.AssigneeID = &assignee
+	}
+	if o.ProjectID != nil {
+		project := *o.ProjectID
+		c.ProjectID = &project
+	}
+	c.Statuses = slices.Clone(o.Statuses)
+	c.Priorities = slices.Clone(o.Priorities)
+	c.Tags = slices.Clone(o.Tags)
+	c.AnyTags = slices.Clone(o.AnyTags)
+	c.Sort = slices.Clone(o.Sort)
+	return c
+}
@@ -0,0 +1,90 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSavedFilters(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	add := func(title string, opts ...TaskOption) {
+		t.Helper()
+		if _, err := tm.AddTask(title, "", opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+	add("Write report", WithTags("work"), WithPriority(PriorityUrgent))
+	add("Buy milk", WithTags("home"))
+	add("Review PR", WithTags("work"))
+
+	tags := []string{"work"}
+	if _, err := tm.SaveFilter(" Work ", ListOptions{Tags: tags}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tags[0] = "home"
+	if _, err := tm.SaveFilter("Urgent", ListOptions{Priorities: []Priority{PriorityUrgent}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	page, err := tm.ListByFilter("Work")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, expected := titles(page.Tasks), []string{"Write report", "Review PR"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	var names []string
+	for _, f := range tm.ListFilters() {
+		names = append(names, f.Name)
+	}
+	if expected := []string{"Urgent", "Work"}; !slices.Equal(names, expected) {
+		t.Errorf("Expected filters %v, got %v", expected, names)
+	}
+
+	if err := tm.UpdateFilter("Work", ListOptions{Tags: []string{"work"}, Sort: SortSpec{{Field: SortTitle}}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	page, _ = tm.ListByFilter("Work")
+	if got, expected := titles(page.Tasks), []string{"Review PR", "Write report"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v after update, got %v", expected, got)
+	}
+
+	if err := tm.DeleteFilter("Urgent"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.ListByFilter("Urgent"); err != ErrFilterNotFound {
+		t.Errorf("Expected ErrFilterNotFound, got %v", err)
+	}
+}
+
+func TestSavedFilterErrors(t *testing.T) {
+	tm := NewTaskManager()
+	if _, err := tm.SaveFilter("Work", ListOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		run         func() error
+		expectError error
+	}{
+		{name: "empty name", run: func() error { _, err := tm.SaveFilter("  ", ListOptions{}); return err }, expectError: ErrEmptyFilterName},
+		{name: "duplicate name", run: func() error { _, err := tm.SaveFilter("Work", ListOptions{}); return err }, expectError: ErrFilterExists},
+		{name: "invalid options", run: func() error { _, err := tm.SaveFilter("Bad", ListOptions{Statuses: []Status{42}}); return err }, expectError: ErrInvalidStatus},
+		{name: "update invalid options", run: func() error { return tm.UpdateFilter("Work", ListOptions{Page: Page{Limit: -1}}) }, expectError: ErrInvalidPage},
+		{name: "update missing", run: func() error { return tm.UpdateFilter("Home", ListOptions{}) }, expectError: ErrFilterNotFound},
+		{name: "delete missing", run: func() error { return tm.DeleteFilter("Home") }, expectError: ErrFilterNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package taskmanager
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// FuzzySearch returns the tasks whose title loosely matches the query, best
+// match first, for quick-open style lookups. Each query word matches a
+// title word it starts, or one a few typos away, so "grocieries" finds
+// "Groceries". Failing that, a title matches when it contains the letters of
+// the query in order, so "bgr" finds "Buy groceries", ranked below every
+// typo match. Equally good matches keep the order ListTasks would give them.
+func (tm *TaskManager) FuzzySearch(query string, opts ...ListOption) []*Task {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	costs := make(map[int]int)
+	result := tm.ListTasks(nil, append(opts, where(func(task *Task) bool {
+		cost, ok := fuzzyCost(task.Title, terms)
+		costs[task.ID] = cost
+		return ok
+	}))...)
+	slices.SortStableFunc(result, func(a, b *Task) int {
+		return cmp.Compare(costs[a.ID], costs[b.ID])
+	})
+	return result
+}
+
+// fuzzyCost returns how far the title is from the query terms, lower being
+// closer, and whether it matches at all
+func fuzzyCost(title string, terms []string) (int, bool) {
+	words := tokenize(title)
+
+	total, worst := 0, 1
+	matched := true
+	for _, term := range terms {
+		limit := typoLimit(term)
+		worst += limit
+		best := limit + 1
+		for _, w := range words {
+			if strings.HasPrefix(w, term) {
+				best = 0
+				break
+			}
+			best = min(best, editDistance(term, w))
+		}
+		if best > limit {
+			matched = false
+		}
+		total += best
+	}
+	if matched {
+		return total, true
+	}
+	if isSubsequence(strings.Join(terms, ""), strings.Join(words, "")) {
+		return worst, true
+	}
+	return 0, false
+}
+
+// typoLimit is the number of edits a query word may be away from a title
+// word; short words must match exactly
+func typoLimit(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters needed to turn a into b
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(t)]
+}
+
+// isSubsequence reports whether the letters of sub appear in s in order
+func isSubsequence(sub, s string) bool {
+	rest := []rune(sub)
+	for _, r := range s {
+		if len(rest) == 0 {
+			break
+		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
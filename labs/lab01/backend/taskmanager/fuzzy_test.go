@@ -0,0 +1,59 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFuzzySearch(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for _, title := range []string{"Buy groceries", "Groceries list", "Book flights", "Fix bug", "Big green rug"} {
+		mustAddTask(t, tm, title)
+		now = now.Add(time.Minute)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "typo", query: "grocieries", expected: []string{"Buy groceries", "Groceries list"}},
+		{name: "swapped letters", query: "fligths", expected: []string{"Book flights"}},
+		{name: "prefix beats typo", query: "buy", expected: []string{"Buy groceries", "Fix bug"}},
+		{name: "several words", query: "groceries lst", expected: []string{"Groceries list"}},
+		{name: "letters in order", query: "bgr", expected: []string{"Buy groceries", "Big green rug"}},
+		{name: "short words must match exactly", query: "xy", expected: []string{}},
+		{name: "no match", query: "holiday", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tm.FuzzySearch(tt.query)); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"groceries", "groceries", 0},
+		{"grocieries", "groceries", 1},
+		{"fligths", "flights", 1},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q): expected %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
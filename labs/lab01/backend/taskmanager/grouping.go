@@ -0,0 +1,152 @@
+package taskmanager
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"slices"
+)
+
+// ErrInvalidGroupField is returned when tasks are grouped by an unknown field
+var ErrInvalidGroupField = errors.New("invalid group field")
+
+// GroupField is a task field tasks can be grouped by
+type GroupField int
+
+const (
+	// GroupByStatus puts each task in the bucket of its status
+	GroupByStatus GroupField = iota + 1
+	// GroupByTag puts each task in the bucket of every tag it carries, and
+	// untagged tasks in a bucket with an empty key
+	GroupByTag
+	// GroupByPriority puts each task in the bucket of its priority
+	GroupByPriority
+	// GroupByAssignee puts each task in the bucket of its assignee, and
+	// unassigned tasks in a bucket with an empty key
+	GroupByAssignee
+	// GroupByProject puts each task in the bucket of its project's name,
+	// and tasks outside a project in a bucket with an empty key
+	GroupByProject
+)
+
+// GroupOptions narrows and details a grouping
+type GroupOptions struct {
+	// Filter selects the tasks grouped; its Page and Sort are ignored
+	Filter ListOptions
+	// IncludeIDs fills Group.TaskIDs
+	IncludeIDs bool
+}
+
+// Group is one bucket of a grouping
+type Group struct {
+	// Key is the status or priority name, the tag, the assignee ID or the
+	// project name
+	Key   string
+	Count int
+	// TaskIDs lists the tasks in the bucket in ascending order when
+	// GroupOptions.IncludeIDs is set
+	TaskIDs []int
+}
+
+// GroupTasks counts the tasks matching opts.Filter per value of the given
+// field. Only non-empty buckets are returned; statuses and priorities come
+// in their natural order, tags, assignees and projects by key with the
+// empty key last.
+func (tm *TaskManager) GroupTasks(by GroupField, opts GroupOptions) ([]Group, error) {
+	keysOf, err := tm.groupKeys(by)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	q := tm.newQuery(opts.Filter.Done, opts.Filter.listOptions())
+	groups := make(map[any]*Group)
+	tm.scan(q, func(task *Task) bool {
+		for _, key := range keysOf(task) {
+			g, ok := groups[key]
+			if !ok {
+				g = &Group{Key: groupKeyName(key)}
+				groups[key] = g
+			}
+			g.Count++
+			if opts.IncludeIDs {
+				g.TaskIDs = append(g.TaskIDs, task.ID)
+			}
+		}
+		return true
+	})
+
+	keys := slices.SortedFunc(maps.Keys(groups), compareGroupKeys)
+	result := make([]Group, len(keys))
+	for i, key := range keys {
+		g := groups[key]
+		slices.Sort(g.TaskIDs)
+		result[i] = *g
+	}
+	return result, nil
+}
+
+// groupKeys returns the function giving the buckets of a task for a field
+func (tm *TaskManager) groupKeys(by GroupField) (func(*Task) []any, error) {
+	switch by {
+	case GroupByStatus:
+		return func(t *Task) []any { return []any{t.Status} }, nil
+	case GroupByPriority:
+		return func(t *Task) []any { return []any{t.Priority} }, nil
+	case GroupByAssignee:
+		return func(t *Task) []any { return []any{t.AssigneeID} }, nil
+	case GroupByProject:
+		return func(t *Task) []any {
+			if project, ok := tm.projects[t.ProjectID]; ok {
+				return []any{project.Name}
+			}
+			return []any{""}
+		}, nil
+	case GroupByTag:
+		return func(t *Task) []any {
+			if len(t.Tags) == 0 {
+				return []any{""}
+			}
+			keys := make([]any, len(t.Tags))
+			for i, tag := range t.Tags {
+				keys[i] = tag
+			}
+			return keys
+		}, nil
+	default:
+		return nil, ErrInvalidGroupField
+	}
+}
+
+// groupKeyName returns the Group.Key of a bucket
+func groupKeyName(key any) string {
+	switch k := key.(type) {
+	case Status:
+		return k.String()
+	case Priority:
+		return k.String()
+	default:
+		return k.(string)
+	}
+}
+
+// compareGroupKeys orders buckets of the same field
+func compareGroupKeys(a, b any) int {
+	switch a := a.(type) {
+	case Status:
+		return cmp.Compare(a, b.(Status))
+	case Priority:
+		return cmp.Compare(a, b.(Priority))
+	default:
+		x, y := a.(string), b.(string)
+		if (x == "") != (y == "") {
+			if x == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(x, y)
+	}
+}
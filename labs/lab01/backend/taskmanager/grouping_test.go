@@ -0,0 +1,104 @@
+package taskmanager
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupTasks(t *testing.T) {
+	tm := NewTaskManager()
+	report := mustAddTask(t, tm, "Write report")
+	milk := mustAddTask(t, tm, "Buy milk")
+	review := mustAddTask(t, tm, "Review PR")
+	call := mustAddTask(t, tm, "Call mom")
+
+	course, _ := tm.CreateProject("Course")
+	setup := []error{
+		tm.UpdateTaskFields(review.ID, TaskPatch{ProjectID: &course.ID}),
+		tm.UpdateTask(report.ID, report.Title, "", false, WithTags("work", "urgent"), WithPriority(PriorityHigh)),
+		tm.UpdateTask(milk.ID, milk.Title, "", true, WithTags("home")),
+		tm.UpdateTask(review.ID, review.Title, "", false, WithTags("work")),
+		tm.AssignTask(report.ID, "bob"),
+		tm.AssignTask(review.ID, "alice"),
+		tm.Transition(review.ID, StatusInProgress),
+	}
+	for _, err := range setup {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	open := false
+	tests := []struct {
+		name     string
+		by       GroupField
+		opts     GroupOptions
+		expected []Group
+	}{
+		{
+			name: "status",
+			by:   GroupByStatus,
+			expected: []Group{
+				{Key: "todo", Count: 2},
+				{Key: "in progress", Count: 1},
+				{Key: "done", Count: 1},
+			},
+		},
+		{
+			name: "tag with ids",
+			by:   GroupByTag,
+			opts: GroupOptions{IncludeIDs: true},
+			expected: []Group{
+				{Key: "home", Count: 1, TaskIDs: []int{milk.ID}},
+				{Key: "urgent", Count: 1, TaskIDs: []int{report.ID}},
+				{Key: "work", Count: 2, TaskIDs: []int{report.ID, review.ID}},
+				{Key: "", Count: 1, TaskIDs: []int{call.ID}},
+			},
+		},
+		{
+			name: "priority",
+			by:   GroupByPriority,
+			expected: []Group{
+				{Key: "medium", Count: 3},
+				{Key: "high", Count: 1},
+			},
+		},
+		{
+			name: "open tasks by assignee",
+			by:   GroupByAssignee,
+			opts: GroupOptions{Filter: ListOptions{Done: &open}},
+			expected: []Group{
+				{Key: "alice", Count: 1},
+				{Key: "bob", Count: 1},
+				{Key: "", Count: 1},
+			},
+		},
+		{
+			name: "project",
+			by:   GroupByProject,
+			expected: []Group{
+				{Key: "Course", Count: 1},
+				{Key: "", Count: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tm.GroupTasks(tt.by, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := tm.GroupTasks(42, GroupOptions{}); err != ErrInvalidGroupField {
+		t.Errorf("Expected ErrInvalidGroupField, got %v", err)
+	}
+	if _, err := tm.GroupTasks(GroupByTag, GroupOptions{Filter: ListOptions{Statuses: []Status{42}}}); err != ErrInvalidStatus {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+}
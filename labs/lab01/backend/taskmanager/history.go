@@ -0,0 +1,196 @@
+package taskmanager
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryEntry records a single change to a task field
+type HistoryEntry struct {
+	Time     time.Time
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+const (
+	// HistoryCreated is the Field of the entry recorded when a task is added
+	HistoryCreated = "created"
+	// HistoryCompletionNote is the Field of the entry holding the note given
+	// to CompleteTask
+	HistoryCompletionNote = "completion_note"
+)
+
+// trackedFields lists the task fields recorded in history, with how each is
+// formatted for display
+var trackedFields = []struct {
+	name  string
+	value func(*Task) string
+}{
+	{"title", func(t *Task) string { return t.Title }},
+	{"description", func(t *Task) string { return t.Description }},
+	{"status", func(t *Task) string { return t.Status.String() }},
+	{"priority", func(t *Task) string { return t.Priority.String() }},
+	{"color", func(t *Task) string { return t.Color }},
+	{"icon", func(t *Task) string { return t.Icon }},
+	{"due_date", func(t *Task) string { return formatTime(t.DueDate) }},
+	{"reminders", func(t *Task) string { return formatTimes(t.Reminders) }},
+	{"location", func(t *Task) string { return formatLocation(t.Location) }},
+	{"tags", func(t *Task) string { return strings.Join(t.Tags, ",") }},
+	{"parent_id", func(t *Task) string { return formatID(t.ParentID) }},
+	{"project_id", func(t *Task) string { return formatID(t.ProjectID) }},
+	{"recurrence", func(t *Task) string { return formatRecurrence(t.Recurrence) }},
+	{"depends_on", func(t *Task) string { return formatIDs(t.DependsOn) }},
+	{"related", func(t *Task) string { return formatLinks(t.Related) }},
+	{"assignee_id", func(t *Task) string { return t.AssigneeID }},
+	{"owner_id", func(t *Task) string { return t.OwnerID }},
+	{"visibility", func(t *Task) string { return t.Visibility.String() }},
+	{"custom_fields", func(t *Task) string { return formatCustomFields(t.CustomFields) }},
+	{"checklist", func(t *Task) string { return formatChecklist(t.Checklist) }},
+	{"estimate", func(t *Task) string { return t.Estimate.String() }},
+	{"tracked_time", func(t *Task) string { return t.TrackedTime.String() }},
+	{"attachments", func(t *Task) string { return formatAttachments(t.Attachments) }},
+	{"archived", func(t *Task) string { return strconv.FormatBool(t.Archived) }},
+	{"snoozed_until", func(t *Task) string { return formatTime(t.SnoozedUntil) }},
+	{"pinned", func(t *Task) string { return strconv.FormatBool(t.Pinned) }},
+	{"deleted_at", func(t *Task) string { return formatTime(t.DeletedAt) }},
+}
+
+// GetTaskHistory returns the recorded changes of a task, oldest first
+func (tm *TaskManager) GetTaskHistory(id int) ([]HistoryEntry, error) {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(task.History), nil
+}
+
+// update applies fn to a task in place, records every tracked field it
+// changed and bumps the version if there were any
+func (tm *TaskManager) update(task *Task, fn func()) {
+	tm.touch(task.ID)
+	before := task.clone()
+	fn()
+	task.Links = extractLinks(task.Description)
+	if tm.recordChanges(before, task) {
+		tm.fieldsChanged(task)
+	}
+	tm.reindex(task)
+	tm.refreshProgress(task.ID)
+	if before.ParentID != task.ParentID {
+		tm.refreshProgress(before.ParentID)
+	}
+}
+
+// recordChanges appends a history entry for each tracked field that differs
+// and reports whether there was one
+func (tm *TaskManager) recordChanges(before, after *Task) bool {
+	now := tm.now()
+	changed := false
+	for _, field := range trackedFields {
+		oldValue, newValue := field.value(before), field.value(after)
+		if oldValue != newValue {
+			changed = true
+			after.History = append(after.History, HistoryEntry{
+				Time:     now,
+				Field:    field.name,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+	return changed
+}
+
+// recordCreated appends the entry marking when a task was added
+func (tm *TaskManager) recordCreated(task *Task) {
+	task.History = append(task.History, HistoryEntry{
+		Time:     task.CreatedAt,
+		Field:    HistoryCreated,
+		NewValue: task.Title,
+	})
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatTimes(times []time.Time) string {
+	parts := make([]string, len(times))
+	for i, t := range times {
+		parts[i] = t.Format(time.RFC3339)
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatLocation(l *Location) string {
+	if l == nil {
+		return ""
+	}
+	return l.String()
+}
+
+func formatID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func formatIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatLinks(links []TaskLink) string {
+	parts := make([]string, len(links))
+	for i, l := range links {
+		parts[i] = fmt.Sprintf("%v %d", l.Type, l.TaskID)
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatRecurrence(r *Recurrence) string {
+	if r == nil {
+		return ""
+	}
+	return r.String()
+}
+
+func formatCustomFields(fields map[string]any) string {
+	parts := make([]string, 0, len(fields))
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		parts = append(parts, name+"="+formatFieldValue(fields[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatChecklist(items []ChecklistItem) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		mark := "[ ]"
+		if item.Done {
+			mark = "[x]"
+		}
+		parts[i] = mark + " " + item.Text
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatAttachments(attachments []Attachment) string {
+	parts := make([]string, len(attachments))
+	for i, a := range attachments {
+		parts[i] = fmt.Sprintf("%s#%d", a.Name, a.ID)
+	}
+	return strings.Join(parts, ",")
+}
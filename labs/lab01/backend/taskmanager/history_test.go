@@ -0,0 +1,109 @@
+package taskmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetTaskHistory(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Draft", WithTags("work"))
+
+	now = now.Add(time.Minute)
+	if err := tm.UpdateTask(task.ID, "Final", "", true, WithPriority(PriorityHigh)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := tm.AssignTask(task.ID, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.UpdateTask(task.ID, "Final", "", true, WithPriority(PriorityHigh)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	history, err := tm.GetTaskHistory(task.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []HistoryEntry{
+		{Time: now.Add(-2 * time.Minute), Field: HistoryCreated, NewValue: "Draft"},
+		{Time: now.Add(-time.Minute), Field: "title", OldValue: "Draft", NewValue: "Final"},
+		{Time: now.Add(-time.Minute), Field: "status", OldValue: "todo", NewValue: "done"},
+		{Time: now.Add(-time.Minute), Field: "priority", OldValue: "medium", NewValue: "high"},
+		{Time: now, Field: "assignee_id", OldValue: "", NewValue: "alice"},
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d history entries, got %+v", len(expected), history)
+	}
+	for i, entry := range expected {
+		if history[i] != entry {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, entry, history[i])
+		}
+	}
+
+	if _, err := tm.GetTaskHistory(999); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestHistoryIgnoresFailedUpdates(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Task")
+
+	if err := tm.UpdateTask(task.ID, "", "", false); err != ErrEmptyTitle {
+		t.Fatalf("Expected ErrEmptyTitle, got %v", err)
+	}
+	history, _ := tm.GetTaskHistory(task.ID)
+	if len(history) != 1 {
+		t.Errorf("Expected only the creation entry, got %+v", history)
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	due := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	task := &Task{Title: "Task", DueDate: &due, Tags: []string{"home"}, DependsOn: []int{1}}
+
+	c := task.clone()
+	c.Tags[0] = "work"
+	c.DependsOn[0] = 2
+	*c.DueDate = due.Add(time.Hour)
+
+	if task.Tags[0] != "home" || task.DependsOn[0] != 1 || !task.DueDate.Equal(due) {
+		t.Errorf("Expected original to be unchanged, got %+v", task)
+	}
+}
+
+func TestUpdatedAt(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Draft")
+	if !task.UpdatedAt.Equal(task.CreatedAt) {
+		t.Errorf("Expected UpdatedAt to start at CreatedAt, got %v", task.UpdatedAt)
+	}
+
+	now = now.Add(time.Hour)
+	if err := tm.AssignTask(task.ID, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !task.UpdatedAt.Equal(now) {
+		t.Errorf("Expected UpdatedAt %v after a change, got %v", now, task.UpdatedAt)
+	}
+
+	changed := now
+	now = now.Add(time.Hour)
+	if err := tm.AssignTask(task.ID, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !task.UpdatedAt.Equal(changed) {
+		t.Errorf("Expected a no-op to keep UpdatedAt %v, got %v", changed, task.UpdatedAt)
+	}
+
+	if _, err := tm.AddComment(task.ID, "bob", "On it"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !task.UpdatedAt.Equal(now) {
+		t.Errorf("Expected a comment to bump UpdatedAt to %v, got %v", now, task.UpdatedAt)
+	}
+}
@@ -0,0 +1,70 @@
+package taskmanager
+
+// OmitDetails leaves the description, comments, attachments and history
+// out of the copies of tasks a listing returns, so listings that only show
+// titles and states do not copy the heaviest fields of every task. Tasks
+// are still read whole from the TaskStore. The tasks returned are partial
+// copies whose IsPartial reports true; Hydrate fills in what was left out.
+// Tasks and ForEachTask, which visit the stored tasks, ignore this option.
+func OmitDetails() ListOption {
+	return func(q *listQuery) {
+		q.omitDetails = true
+	}
+}
+
+// IsPartial reports whether the task was listed without its description,
+// comments, attachments and history, which Hydrate fills in
+func (t *Task) IsPartial() bool {
+	return t.partial
+}
+
+// Hydrate fills in the description, comments, attachments and history of
+// a partial task and leaves any other task as it is. It fails with ErrTaskNotFound
+// when the task has been deleted since it was listed, and with ErrConflict
+// when it has been changed, since the details would not match the rest of
+// the task; list the task again in either case. The task is left partial
+// when Hydrate fails.
+func (tm *TaskManager) Hydrate(task *Task) error {
+	if !task.partial {
+		return nil
+	}
+	stored, err := tm.lookup(task.ID)
+	if err != nil {
+		return err
+	}
+	if stored.Version != task.Version {
+		return ErrConflict
+	}
+	full := stored.clone()
+	task.Description = full.Description
+	task.Comments = full.Comments
+	task.Attachments = full.Attachments
+	task.History = full.History
+	task.partial = false
+	return nil
+}
+
+// partialCopy returns a copy of the task without the fields OmitDetails
+// leaves out
+func (t *Task) partialCopy() *Task {
+	light := *t
+	light.Description = ""
+	light.Comments = nil
+	light.Attachments = nil
+	light.History = nil
+	c := light.clone()
+	c.partial = true
+	return c
+}
+
+// exportListing is exportAll for the tasks of a listing, which are
+// replaced by partial copies instead when omitDetails is set
+func (tm *TaskManager) exportListing(tasks []*Task, omitDetails bool) []*Task {
+	if !omitDetails {
+		return tm.exportAll(tasks)
+	}
+	for i, task := range tasks {
+		tasks[i] = task.partialCopy()
+	}
+	return tasks
+}
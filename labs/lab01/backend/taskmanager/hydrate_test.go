@@ -0,0 +1,85 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestOmitDetails(t *testing.T) {
+	tm := NewTaskManager()
+	task, err := tm.AddTask("Write report", "Quarterly numbers")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.AddComment(task.ID, "alice", "Due Friday"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.AddAttachment(task.ID, Attachment{Name: "data.csv", MIMEType: "text/csv", StorageKey: "k1"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	page, err := tm.FindTasks(ListOptions{OmitDetails: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	listings := map[string]*Task{
+		"ListTasks":     tm.ListTasks(nil, OmitDetails())[0],
+		"ListTasksInto": tm.ListTasksInto(nil, nil, OmitDetails())[0],
+		"FindTasks":     page.Tasks[0],
+	}
+	for name, listed := range listings {
+		if !listed.IsPartial() || listed.Description != "" || listed.Comments != nil || listed.Attachments != nil || listed.History != nil {
+			t.Errorf("%s: expected a partial task, got %+v", name, listed)
+		}
+		if listed.Title != "Write report" || listed == storedTask(tm, task.ID) {
+			t.Errorf("%s: expected a copy with the other fields, got %+v", name, listed)
+		}
+		if err := tm.Hydrate(listed); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if listed.IsPartial() || listed.Description != "Quarterly numbers" || len(listed.Comments) != 1 || len(listed.Attachments) != 1 || len(listed.History) != len(storedTask(tm, task.ID).History) {
+			t.Errorf("%s: expected the details to be loaded, got %+v", name, listed)
+		}
+		listed.Comments[0].Body = "Changed"
+		if storedTask(tm, task.ID).Comments[0].Body == "Changed" {
+			t.Errorf("%s: expected hydrated details to be copies", name)
+		}
+	}
+
+	full := tm.ListTasks(nil)[0]
+	if full.IsPartial() || tm.Hydrate(full) != nil || full.Description != "Quarterly numbers" {
+		t.Error("Expected a full task to be left as it is")
+	}
+}
+
+func TestHydrateErrors(t *testing.T) {
+	tm := NewTaskManager()
+	changed := mustAddTask(t, tm, "Changed")
+	deleted := mustAddTask(t, tm, "Deleted")
+	listed := tm.ListTasks(nil, OmitDetails())
+
+	if err := tm.UpdateTask(changed.ID, "Changed", "New description", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteTask(deleted.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		task     *Task
+		expected error
+	}{
+		{"changed", listed[0], ErrConflict},
+		{"deleted", listed[1], ErrTaskNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tm.Hydrate(tt.task); err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if !tt.task.IsPartial() {
+				t.Error("Expected the task to stay partial")
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package taskmanager
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ICSComponent is the kind of calendar entry ExportICS writes for a task
+type ICSComponent int
+
+const (
+	// ICSTodo writes VTODO entries, which calendar apps with task lists
+	// show alongside their own tasks
+	ICSTodo ICSComponent = iota
+	// ICSEvent writes VEVENT entries at the due time, for calendar apps
+	// without task lists
+	ICSEvent
+)
+
+// icsDomain is the domain of the UIDs of exported tasks when ICSOptions
+// doesn't give one
+const icsDomain = "taskmanager.local"
+
+// icsTimeLayout is the layout of times in UTC in iCalendar
+const icsTimeLayout = "20060102T150405Z"
+
+// icsLineLimit is the length in octets lines are folded at, as RFC 5545
+// asks
+const icsLineLimit = 75
+
+// ICSOptions tells ExportICS what to write
+type ICSOptions struct {
+	// Filter selects the tasks; tasks without a due date are always left
+	// out, and the page and cursor are ignored
+	Filter ListOptions
+	// Component is the kind of entry written for each task
+	Component ICSComponent
+	// Domain makes the UIDs of the entries unique to this manager, as in
+	// task-7@example.com. It defaults to taskmanager.local.
+	Domain string
+	// Name, when set, is the name calendar apps give the subscription
+	Name string
+}
+
+// icsTextEscaper escapes text values as RFC 5545 asks
+var icsTextEscaper = strings.NewReplacer(
+	`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`,
+)
+
+// icsTodoStatus is the STATUS of a VTODO for each task status
+var icsTodoStatus = map[Status]string{
+	StatusTodo:       "NEEDS-ACTION",
+	StatusInProgress: "IN-PROCESS",
+	StatusBlocked:    "NEEDS-ACTION",
+	StatusDone:       "COMPLETED",
+	StatusCancelled:  "CANCELLED",
+}
+
+// icsPriority is the PRIORITY of a VTODO for each task priority, where 1 is
+// the most urgent and 9 the least
+var icsPriority = map[Priority]int{
+	PriorityUrgent: 1,
+	PriorityHigh:   3,
+	PriorityMedium: 5,
+	PriorityLow:    9,
+}
+
+// ExportICS writes the tasks matching opts.Filter that have a due date as
+// an iCalendar feed, which calendar apps can import or subscribe to. Each
+// task keeps the UID task-<id>@<domain> and its version as SEQUENCE, so a
+// calendar refreshing the feed updates the entries it already has rather
+// than adding new ones.
+func (tm *TaskManager) ExportICS(w io.Writer, opts ICSOptions) error {
+	tasks, err := tm.exportTasks(opts.Filter)
+	if err != nil {
+		return err
+	}
+	return writeICS(w, tasks, opts, tm.now())
+}
+
+// writeICS writes tasks as ExportICS does, stamped with now
+func writeICS(w io.Writer, tasks []*Task, opts ICSOptions, now time.Time) error {
+	domain := opts.Domain
+	if domain == "" {
+		domain = icsDomain
+	}
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICSLine(bw, name+":"+value)
+	}
+	text := func(name, value string) {
+		if value != "" {
+			line(name, icsTextEscaper.Replace(value))
+		}
+	}
+	stamp := func(name string, t time.Time) {
+		line(name, t.UTC().Format(icsTimeLayout))
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//lab01//taskmanager//EN")
+	line("CALSCALE", "GREGORIAN")
+	text("X-WR-CALNAME", opts.Name)
+	for _, task := range tasks {
+		if task.DueDate == nil {
+			continue
+		}
+		component := "VTODO"
+		if opts.Component == ICSEvent {
+			component = "VEVENT"
+		}
+		line("BEGIN", component)
+		line("UID", "task-"+strconv.Itoa(task.ID)+"@"+domain)
+		stamp("DTSTAMP", now)
+		stamp("CREATED", task.CreatedAt)
+		stamp("LAST-MODIFIED", task.UpdatedAt)
+		line("SEQUENCE", strconv.Itoa(task.Version))
+		text("SUMMARY", task.Title)
+		text("DESCRIPTION", task.Description)
+		if len(task.Tags) > 0 {
+			escaped := make([]string, len(task.Tags))
+			for i, tag := range task.Tags {
+				escaped[i] = icsTextEscaper.Replace(tag)
+			}
+			line("CATEGORIES", strings.Join(escaped, ","))
+		}
+		if component == "VTODO" {
+			stamp("DUE", *task.DueDate)
+			line("STATUS", icsTodoStatus[task.Status])
+			if p, ok := icsPriority[task.Priority]; ok {
+				line("PRIORITY", strconv.Itoa(p))
+			}
+			if task.CompletedAt != nil {
+				stamp("COMPLETED", *task.CompletedAt)
+			}
+		} else {
+			// An event with a start time and no end ends when it starts
+			stamp("DTSTART", *task.DueDate)
+			line("TRANSP", "TRANSPARENT")
+			if task.Status == StatusCancelled {
+				line("STATUS", "CANCELLED")
+			} else {
+				line("STATUS", "CONFIRMED")
+			}
+		}
+		line("END", component)
+	}
+	line("END", "VCALENDAR")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing calendar: %w", err)
+	}
+	return nil
+}
+
+// writeICSLine writes a content line ended by CRLF, folded into lines of at
+// most icsLineLimit octets without splitting a character
+func writeICSLine(w *bufio.Writer, s string) {
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space of a continuation line counts toward its length
+		limit = icsLineLimit - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
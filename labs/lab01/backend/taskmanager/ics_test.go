@@ -0,0 +1,129 @@
+package taskmanager
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestExportICS(t *testing.T) {
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	tm := NewTaskManager(WithClock(clock))
+	due := time.Date(2025, 7, 3, 12, 30, 0, 0, time.UTC)
+	report := mustAddTask(t, tm, "Write report; part 1, draft", WithTags("work", "a,b"), WithDueDate(due), WithPriority(PriorityUrgent))
+	mustAddTask(t, tm, "No deadline")
+	milk := mustAddTask(t, tm, "Buy milk", WithDueDate(due.AddDate(0, 0, 1)))
+	if err := tm.UpdateTaskFields(milk.ID, TaskPatch{Status: ptr(StatusDone)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.UpdateTaskFields(report.ID, TaskPatch{Description: ptr("Line one\nLine two")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     ICSOptions
+		expected []string
+		absent   []string
+	}{
+		{
+			name: "todos",
+			opts: ICSOptions{Name: "Deadlines"},
+			expected: []string{
+				"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+				"X-WR-CALNAME:Deadlines\r\n",
+				"BEGIN:VTODO\r\nUID:task-1@taskmanager.local\r\nDTSTAMP:20250701T090000Z\r\n",
+				"SEQUENCE:2\r\n",
+				"SUMMARY:Write report\\; part 1\\, draft\r\n",
+				"DESCRIPTION:Line one\\nLine two\r\n",
+				"CATEGORIES:work,a\\,b\r\n",
+				"DUE:20250703T123000Z\r\nSTATUS:NEEDS-ACTION\r\nPRIORITY:1\r\n",
+				"UID:task-3@taskmanager.local\r\n",
+				"STATUS:COMPLETED\r\nPRIORITY:5\r\nCOMPLETED:20250701T090000Z\r\nEND:VTODO\r\n",
+				"END:VCALENDAR\r\n",
+			},
+			absent: []string{"No deadline", "VEVENT"},
+		},
+		{
+			name: "events",
+			opts: ICSOptions{Component: ICSEvent, Domain: "example.com", Filter: ListOptions{Statuses: []Status{StatusTodo}}},
+			expected: []string{
+				"BEGIN:VEVENT\r\nUID:task-1@example.com\r\n",
+				"DTSTART:20250703T123000Z\r\nTRANSP:TRANSPARENT\r\nSTATUS:CONFIRMED\r\nEND:VEVENT\r\n",
+			},
+			absent: []string{"X-WR-CALNAME", "VTODO", "Buy milk"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tm.ExportICS(&b, tt.opts); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got := b.String()
+			for _, want := range tt.expected {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected %q in %q", want, got)
+				}
+			}
+			for _, unwanted := range tt.absent {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Expected no %q in %q", unwanted, got)
+				}
+			}
+		})
+	}
+
+	// UIDs and the calendar around them stay the same across exports
+	var first, second strings.Builder
+	tm.ExportICS(&first, ICSOptions{})
+	tm.ExportICS(&second, ICSOptions{})
+	if first.String() != second.String() {
+		t.Errorf("Expected the same feed twice, got %q and %q", first.String(), second.String())
+	}
+
+	if err := tm.ExportICS(&first, ICSOptions{Filter: ListOptions{Statuses: []Status{42}}}); err != ErrInvalidStatus {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestWriteICSLineFolds(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Report"},
+		{"ascii", "SUMMARY:" + strings.Repeat("a", 200)},
+		{"multibyte", "SUMMARY:" + strings.Repeat("задача ", 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			bw := bufio.NewWriter(&b)
+			writeICSLine(bw, tt.line)
+			bw.Flush()
+			lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+			unfolded := lines[0]
+			for _, line := range lines[1:] {
+				if !strings.HasPrefix(line, " ") {
+					t.Fatalf("Expected continuation lines to start with a space, got %q", line)
+				}
+				unfolded += line[1:]
+			}
+			for _, line := range lines {
+				if len(line) > icsLineLimit {
+					t.Errorf("Expected lines of at most %d octets, got %d", icsLineLimit, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("Expected no character split across lines, got %q", line)
+				}
+			}
+			if unfolded != tt.line {
+				t.Errorf("Expected %q, got %q", tt.line, unfolded)
+			}
+		})
+	}
+}
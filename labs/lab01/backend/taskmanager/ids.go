@@ -0,0 +1,181 @@
+package taskmanager
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidNode is returned when a snowflake node number is out of range
+var ErrInvalidNode = errors.New("invalid snowflake node")
+
+// IDGenerator hands out the IDs of new tasks. It must never return the
+// same ID twice nor an ID below 1, and must be safe for concurrent use, so
+// IDs can be handed out without the manager's lock.
+type IDGenerator interface {
+	NextID() int
+}
+
+// IDReserver is an IDGenerator that can hand out many IDs in one step.
+// Bulk adds reserve their IDs this way when the generator offers it.
+type IDReserver interface {
+	IDGenerator
+	// ReserveIDs returns n new IDs in increasing order
+	ReserveIDs(n int) []int
+}
+
+// WithIDGenerator sets how task IDs are generated. By default the
+// TaskStore hands them out. ShardedTaskManager gives each shard its own sequence and
+// ignores this option.
+func WithIDGenerator(ids IDGenerator) Option {
+	return func(tm *TaskManager) {
+		tm.ids = ids
+	}
+}
+
+// reserveIDs returns n new IDs, in one step when the generator allows it
+func (tm *TaskManager) reserveIDs(n int) []int {
+	if r, ok := tm.ids.(IDReserver); ok {
+		return r.ReserveIDs(n)
+	}
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = tm.ids.NextID()
+	}
+	return ids
+}
+
+// SequenceIDs hands out the IDs first, first+step, first+2*step and so on,
+// with a single atomic operation per ID or per reserved range
+type SequenceIDs struct {
+	first, step int
+	issued      atomic.Int64
+}
+
+// NewSequenceIDs returns a sequence starting at first. A first below 1 is
+// treated as 1 and a step below 1 as 1.
+func NewSequenceIDs(first, step int) *SequenceIDs {
+	return &SequenceIDs{first: max(first, 1), step: max(step, 1)}
+}
+
+// NextID returns the next ID of the sequence
+func (s *SequenceIDs) NextID() int {
+	return s.at(s.issued.Add(1) - 1)
+}
+
+// ReserveIDs returns the next n IDs of the sequence
+func (s *SequenceIDs) ReserveIDs(n int) []int {
+	end := s.issued.Add(int64(n))
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = s.at(end - int64(n) + int64(i))
+	}
+	return ids
+}
+
+// peek returns the ID NextID would return next
+func (s *SequenceIDs) peek() int {
+	return s.at(s.issued.Load())
+}
+
+// advanceIDs skips the sequence ahead so it never returns an ID below next
+func (s *SequenceIDs) advanceIDs(next int) {
+	for {
+		issued := s.issued.Load()
+		if s.at(issued) >= next {
+			return
+		}
+		skip := int64((next - s.first + s.step - 1) / s.step)
+		if s.issued.CompareAndSwap(issued, skip) {
+			return
+		}
+	}
+}
+
+// idAdvancer is an IDGenerator that can be told to skip IDs already taken,
+// such as those of restored tasks
+type idAdvancer interface {
+	advanceIDs(next int)
+}
+
+// at returns the i-th ID of the sequence, counting from zero
+func (s *SequenceIDs) at(i int64) int {
+	return s.first + int(i)*s.step
+}
+
+// Bit layout of snowflake IDs, from the most significant bit: milliseconds
+// since the epoch, node, sequence within the millisecond
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	// MaxSnowflakeNode is the highest node number NewSnowflakeIDs accepts
+	MaxSnowflakeNode = 1<<snowflakeNodeBits - 1
+)
+
+// SnowflakeIDs hands out IDs that encode the time they were made, in the
+// style of Twitter's snowflake: the milliseconds since an epoch, then a
+// node number, so managers on different nodes can generate IDs without
+// coordinating, then a sequence number within the millisecond. IDs from
+// one generator increase over time even if the clock goes back, and a
+// millisecond that runs out of its 4096 sequence numbers borrows the next
+// one rather than waiting. The IDs need 64-bit ints, and are too large
+// for JSON clients that read numbers as 64-bit floats.
+type SnowflakeIDs struct {
+	node  int64
+	epoch time.Time
+	now   func() time.Time
+
+	mu       sync.Mutex
+	last     int64
+	sequence int64
+}
+
+// NewSnowflakeIDs returns a generator for the given node, between 0 and
+// MaxSnowflakeNode, counting time from epoch
+func NewSnowflakeIDs(node int, epoch time.Time) (*SnowflakeIDs, error) {
+	if node < 0 || node > MaxSnowflakeNode {
+		return nil, ErrInvalidNode
+	}
+	return &SnowflakeIDs{node: int64(node), epoch: epoch, now: time.Now}, nil
+}
+
+// NextID returns a new ID
+func (g *SnowflakeIDs) NextID() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.next()
+}
+
+// ReserveIDs returns n new IDs, taking the lock once for all of them
+func (g *SnowflakeIDs) ReserveIDs(n int) []int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = g.next()
+	}
+	return ids
+}
+
+// next returns a new ID. The caller must hold g.mu.
+func (g *SnowflakeIDs) next() int {
+	ms := max(g.now().Sub(g.epoch).Milliseconds(), g.last)
+	if ms == g.last {
+		g.sequence++
+		if g.sequence == 1<<snowflakeSequenceBits {
+			ms++
+			g.sequence = 0
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.last = ms
+	return int(ms<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence)
+}
+
+// Time returns the time encoded in an ID from this generator, to the
+// millisecond
+func (g *SnowflakeIDs) Time(id int) time.Time {
+	return g.epoch.Add(time.Duration(id>>(snowflakeNodeBits+snowflakeSequenceBits)) * time.Millisecond)
+}
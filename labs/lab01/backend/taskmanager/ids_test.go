@@ -0,0 +1,155 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSequenceIDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		first, step int
+		want        []int
+	}{
+		{"default", 1, 1, []int{1, 2, 3, 4}},
+		{"stepped", 2, 3, []int{2, 5, 8, 11}},
+		{"below one", 0, 0, []int{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSequenceIDs(tt.first, tt.step)
+			got := []int{s.NextID()}
+			got = append(got, s.ReserveIDs(2)...)
+			got = append(got, s.NextID())
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSequenceIDsConcurrent(t *testing.T) {
+	s := NewSequenceIDs(1, 1)
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := s.ReserveIDs(10)
+			for range 10 {
+				ids = append(ids, s.NextID())
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				if seen[id] {
+					t.Errorf("ID %d handed out twice", id)
+				}
+				seen[id] = true
+			}
+		}()
+	}
+	wg.Wait()
+	for id := 1; id <= 160; id++ {
+		if !seen[id] {
+			t.Errorf("Expected ID %d to be handed out", id)
+		}
+	}
+}
+
+func TestSnowflakeIDs(t *testing.T) {
+	if _, err := NewSnowflakeIDs(MaxSnowflakeNode+1, time.Time{}); !errors.Is(err, ErrInvalidNode) {
+		t.Errorf("Expected ErrInvalidNode, got %v", err)
+	}
+
+	epoch := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := epoch.Add(time.Hour)
+	g, err := NewSnowflakeIDs(7, epoch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g.now = func() time.Time { return now }
+
+	first := g.NextID()
+	if got := g.Time(first); !got.Equal(now) {
+		t.Errorf("Expected the ID to encode %v, got %v", now, got)
+	}
+	if node := first >> snowflakeSequenceBits & MaxSnowflakeNode; node != 7 {
+		t.Errorf("Expected node 7, got %d", node)
+	}
+
+	// Running out of sequence numbers borrows the next millisecond, and a
+	// clock going back never makes IDs go back
+	ids := g.ReserveIDs(1 << snowflakeSequenceBits)
+	now = now.Add(-time.Second)
+	ids = append(ids, g.NextID())
+	prev := first
+	for _, id := range ids {
+		if id <= prev {
+			t.Fatalf("Expected increasing IDs, got %d after %d", id, prev)
+		}
+		prev = id
+	}
+	if got, want := g.Time(ids[len(ids)-2]), epoch.Add(time.Hour+time.Millisecond); !got.Equal(want) {
+		t.Errorf("Expected the last reserved ID to encode %v, got %v", want, got)
+	}
+}
+
+func TestWithIDGenerator(t *testing.T) {
+	epoch := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids, err := NewSnowflakeIDs(1, epoch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tm := NewTaskManager(WithIDGenerator(ids))
+
+	task := mustAddTask(t, tm, "Single")
+	if got := ids.Time(task.ID); got.Before(epoch) || got.After(time.Now()) {
+		t.Errorf("Expected the ID to encode the time it was made, got %v", got)
+	}
+	batch, err := tm.AddTasksBatch([]TaskInput{{Title: "First"}, {Title: "Second"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if batch[0].ID <= task.ID || batch[1].ID <= batch[0].ID {
+		t.Errorf("Expected increasing IDs, got %d, %d, %d", task.ID, batch[0].ID, batch[1].ID)
+	}
+	if got, err := tm.GetTask(batch[1].ID); err != nil || got.Title != "Second" {
+		t.Errorf("Expected to get the task by its snowflake ID, got %v, %v", got, err)
+	}
+
+	var visited []string
+	if err := tm.ForEachTask(ListOptions{}, func(task *Task) bool {
+		visited = append(visited, task.Title)
+		return true
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"Single", "First", "Second"}; !slices.Equal(visited, want) {
+		t.Errorf("Expected ForEachTask to visit %v, got %v", want, visited)
+	}
+}
+
+// countingIDs is an IDGenerator that cannot reserve ranges
+type countingIDs struct{ next int }
+
+func (c *countingIDs) NextID() int {
+	c.next += 10
+	return c.next
+}
+
+func TestReserveIDsFallback(t *testing.T) {
+	tm := NewTaskManager(WithIDGenerator(&countingIDs{}))
+	tasks, err := tm.AddTasksBatch([]TaskInput{{Title: "First"}, {Title: "Second"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tasks[0].ID != 10 || tasks[1].ID != 20 {
+		t.Errorf("Expected IDs 10 and 20, got %d and %d", tasks[0].ID, tasks[1].ID)
+	}
+}
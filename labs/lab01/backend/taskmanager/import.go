@@ -0,0 +1,177 @@
+package taskmanager
+
+import "maps"
+
+// importSlabSize is the number of tasks ImportTasks allocates at a time
+const importSlabSize = 512
+
+// ImportTasks adds many tasks at once, with the same results as AddTasks
+// but much faster for large imports. Tasks and their first history entries
+// are allocated in slabs instead of one by one, repeated descriptions are
+// sanitized once, the store is grown once for the whole import, and the
+// sorted indexes and progress are brought up to date once at the end
+// instead of after every task. The memory of a slab is only
+// released once none of its tasks is referenced any more, so an import
+// that is mostly deleted afterwards keeps some memory in use. The whole
+// call is undone as one operation.
+func (tm *TaskManager) ImportTasks(inputs []TaskInput) ([]*Task, error) {
+	return tm.importTasks(inputs, sanitizeMarkdown)
+}
+
+// importTasks is ImportTasks with descriptions passed through sanitize,
+// which is the identity for inputs whose descriptions are sanitized already
+func (tm *TaskManager) importTasks(inputs []TaskInput, sanitize func(string) string) ([]*Task, error) {
+	defer tm.beginOperation()()
+
+	tm.growStore(len(inputs))
+	alloc := newSlabAllocator(len(inputs), sanitize)
+	var failures []ItemError
+	tasks := make([]*Task, len(inputs))
+	added := make([]*Task, 0, len(inputs))
+	for i, in := range inputs {
+		task := alloc.next(tm, in)
+		err := tm.validate(task)
+		if err == nil {
+			err = tm.reserve(1)
+		}
+		if err != nil {
+			failures = append(failures, ItemError{Index: i, Err: err})
+			continue
+		}
+		alloc.keep(task)
+		tm.store(task, tm.ids.NextID())
+		added = append(added, task)
+		tasks[i] = tm.export(task)
+	}
+	tm.index.putAll(added)
+	tm.refreshImported(added)
+	return tasks, bulkError(failures)
+}
+
+// AddTasksBatch adds several tasks at once, or none of them: every input
+// is validated before any task is stored, and a *BulkError reports every
+// input that failed. The tasks get their IDs in the order of inputs, with
+// no task added in between. Since nothing is stored until all inputs are
+// valid, an input cannot refer to a task of the same batch, such as by
+// WithParent; use ImportTasks for that. The tasks are allocated and
+// indexed in bulk as ImportTasks does, their IDs are reserved in one step
+// when the IDGenerator is an IDReserver, and the whole call is undone as
+// one operation. When the batch does not fit under WithMaxTasks, no task
+// is added and ErrTooManyTasks is returned.
+func (tm *TaskManager) AddTasksBatch(inputs []TaskInput) ([]*Task, error) {
+	alloc := newSlabAllocator(len(inputs), sanitizeMarkdown)
+	var failures []ItemError
+	added := make([]*Task, len(inputs))
+	for i, in := range inputs {
+		task := alloc.next(tm, in)
+		if err := tm.validate(task); err != nil {
+			failures = append(failures, ItemError{Index: i, Err: err})
+			continue
+		}
+		alloc.keep(task)
+		added[i] = task
+	}
+	if len(failures) > 0 {
+		return nil, bulkError(failures)
+	}
+	if err := tm.reserve(len(added)); err != nil {
+		return nil, err
+	}
+
+	defer tm.beginOperation()()
+	tm.growStore(len(inputs))
+	tasks := make([]*Task, len(inputs))
+	ids := tm.reserveIDs(len(added))
+	for i, task := range added {
+		tm.store(task, ids[i])
+		tasks[i] = tm.export(task)
+	}
+	tm.index.putAll(added)
+	tm.refreshImported(added)
+	return tasks, nil
+}
+
+// slabAllocator hands out the tasks of a bulk add from slabs, along with
+// their first history entries
+type slabAllocator struct {
+	remaining int
+	slab      []Task
+	history   []HistoryEntry
+	sanitize  func(string) string
+	// Bulk adds tend to repeat descriptions, empty ones above all, and
+	// sanitizing is the most expensive part of adding a task
+	sanitized map[string]string
+}
+
+// newSlabAllocator returns an allocator for n tasks whose descriptions are
+// passed through sanitize
+func newSlabAllocator(n int, sanitize func(string) string) *slabAllocator {
+	return &slabAllocator{remaining: n, sanitize: sanitize, sanitized: make(map[string]string)}
+}
+
+// next returns the input as a new, unvalidated task in the next free slot.
+// Until keep is called the slot stays free, so a task that fails
+// validation leaves it for the next one.
+func (a *slabAllocator) next(tm *TaskManager, in TaskInput) *Task {
+	if len(a.slab) == 0 {
+		n := min(a.remaining, importSlabSize)
+		a.slab = make([]Task, n)
+		a.history = make([]HistoryEntry, n)
+	}
+	description, ok := a.sanitized[in.Description]
+	if !ok {
+		description = a.sanitize(in.Description)
+		a.sanitized[in.Description] = description
+	}
+	task := &a.slab[0]
+	initTask(task, in.Title, description, tm.now(), in.Options)
+	return task
+}
+
+// keep takes the slot of the task returned by the last call to next
+func (a *slabAllocator) keep(task *Task) {
+	// The capacity of one makes a later append copy the history out of
+	// the slab instead of overwriting the next task's entry
+	task.History = a.history[:0:1]
+	a.slab, a.history = a.slab[1:], a.history[1:]
+	a.remaining--
+}
+
+// refreshImported computes the progress of newly imported tasks and of the
+// existing tasks they were added under, visiting each task once instead of
+// refreshing the whole chain of parents after every task
+func (tm *TaskManager) refreshImported(added []*Task) {
+	imported := make(map[int]bool, len(added))
+	children := make(map[int][]*Task)
+	for _, task := range added {
+		imported[task.ID] = true
+		if task.ParentID != 0 {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+	// Subtasks are imported after their parents, so going backwards
+	// computes every subtask before its parent
+	for i := len(added) - 1; i >= 0; i-- {
+		task := added[i]
+		task.Progress = progressFrom(task, children[task.ID])
+	}
+	refreshed := make(map[int]bool)
+	for _, task := range added {
+		if parent := task.ParentID; parent != 0 && !imported[parent] && !refreshed[parent] {
+			refreshed[parent] = true
+			tm.refreshProgress(parent)
+		}
+	}
+}
+
+// grow returns a map holding the entries of m with room for n more, so
+// that adding them does not rehash the map again and again. Small
+// additions keep m as it is, since copying it would cost more.
+func grow[K comparable, V any](m map[K]V, n int) map[K]V {
+	if n < len(m) {
+		return m
+	}
+	grown := make(map[K]V, len(m)+n)
+	maps.Copy(grown, m)
+	return grown
+}
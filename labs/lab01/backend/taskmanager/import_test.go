@@ -0,0 +1,158 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// importInputs returns n tasks to import, every tenth one invalid and
+// some with tags and subtasks of the first task
+func importInputs(n int) []TaskInput {
+	inputs := make([]TaskInput, n)
+	for i := range inputs {
+		inputs[i] = TaskInput{Title: fmt.Sprintf("Task %d", n-i), Description: "Imported"}
+		switch {
+		case i%10 == 9:
+			inputs[i].Title = " "
+		case i%7 == 3:
+			inputs[i].Options = []TaskOption{WithTags("work"), WithParent(1)}
+		}
+	}
+	return inputs
+}
+
+func TestImportTasks(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	imported := NewTaskManager(WithClock(clock))
+	added := NewTaskManager(WithClock(clock))
+	mustAddTask(t, imported, "Existing")
+	mustAddTask(t, added, "Existing")
+
+	inputs := importInputs(2*importSlabSize + 10)
+	tasks, err := imported.ImportTasks(inputs)
+	expected, expectedErr := added.AddTasks(inputs)
+	if !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("Expected the failures of AddTasks %v, got %v", expectedErr, err)
+	}
+	if !reflect.DeepEqual(tasks, expected) {
+		t.Error("Expected the same tasks as AddTasks")
+	}
+	if !reflect.DeepEqual(slices.Collect(imported.tasks.List()), slices.Collect(added.tasks.List())) {
+		t.Error("Expected the same stored tasks as AddTasks")
+	}
+	checkIndex(t, imported, "import")
+	if parent, _ := imported.GetTask(1); parent.Progress != storedTask(added, 1).Progress {
+		t.Errorf("Expected the parent's progress to be refreshed, got %v", parent.Progress)
+	}
+
+	// Growing a history must not spill into the next task in the slab
+	if err := imported.UpdateTask(2, "Renamed", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if next, _ := imported.GetTask(3); len(next.History) != 1 || next.History[0].Field != HistoryCreated {
+		t.Errorf("Expected the next task's history untouched, got %+v", next.History)
+	}
+
+	if err := imported.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := imported.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := len(imported.ListTasks(nil)); got != 1 {
+		t.Errorf("Expected undo to remove the whole import, got %d tasks", got)
+	}
+	checkIndex(t, imported, "undo")
+
+	var bulkErr *BulkError
+	if _, err := imported.ImportTasks([]TaskInput{{Title: ""}}); !errors.As(err, &bulkErr) || bulkErr.Failures[0].Err != ErrEmptyTitle {
+		t.Errorf("Expected ErrEmptyTitle in a *BulkError, got %v", err)
+	}
+}
+
+func BenchmarkAddTasks(b *testing.B) {
+	inputs := importInputs(10000)
+	b.ReportAllocs()
+	for b.Loop() {
+		tm := NewTaskManager()
+		mustAddTask(b, tm, "Parent")
+		tm.AddTasks(inputs)
+	}
+}
+
+func BenchmarkImportTasks(b *testing.B) {
+	inputs := importInputs(10000)
+	b.ReportAllocs()
+	for b.Loop() {
+		tm := NewTaskManager()
+		mustAddTask(b, tm, "Parent")
+		tm.ImportTasks(inputs)
+	}
+}
+
+func TestAddTasksBatch(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	mustAddTask(t, tm, "Existing")
+
+	inputs := []TaskInput{
+		{Title: "A"},
+		{Title: " "},
+		{Title: "C", Options: []TaskOption{WithParent(1)}},
+		{Title: "D", Options: []TaskOption{WithParent(42)}},
+	}
+	undoDepth := len(tm.undoStack)
+	tasks, err := tm.AddTasksBatch(inputs)
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Failures) != 2 || bulkErr.Failures[0].Index != 1 || bulkErr.Failures[1].Index != 3 {
+		t.Fatalf("Expected items 1 and 3 to fail, got %v", err)
+	}
+	if tasks != nil || len(tm.ListTasks(nil)) != 1 || len(tm.undoStack) != undoDepth {
+		t.Errorf("Expected nothing to be added or recorded, got %v", titles(tm.ListTasks(nil)))
+	}
+
+	inputs = slices.Delete(inputs, 3, 4)
+	inputs[1].Title = "B"
+	tasks, err = tm.AddTasksBatch(inputs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var ids []int
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	if !slices.Equal(ids, []int{2, 3, 4}) || tasks[2].ParentID != 1 {
+		t.Errorf("Expected IDs 2 to 4 in input order, got %v", ids)
+	}
+	checkIndex(t, tm, "batch")
+	if parent, _ := tm.GetTask(1); len(tm.children(parent.ID)) != 1 {
+		t.Errorf("Expected the batch to add a subtask of task 1")
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(tm.ListTasks(nil)); !slices.Equal(got, []string{"Existing"}) {
+		t.Errorf("Expected undo to remove the whole batch, got %v", got)
+	}
+}
+
+func BenchmarkAddTasksBatch(b *testing.B) {
+	inputs := importInputs(10000)
+	for i := range inputs {
+		inputs[i].Title = "Task"
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		tm := NewTaskManager()
+		mustAddTask(b, tm, "Parent")
+		if _, err := tm.AddTasksBatch(inputs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package taskmanager
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// postings maps each value of an indexed field to the IDs of the tasks
+// holding it
+type postings[K comparable] map[K]map[int]struct{}
+
+// add records that task id holds key
+func (p postings[K]) add(key K, id int) {
+	ids, ok := p[key]
+	if !ok {
+		ids = make(map[int]struct{})
+		p[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// remove forgets that task id holds key
+func (p postings[K]) remove(key K, id int) {
+	ids := p[key]
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(p, key)
+	}
+}
+
+// indexEntry is what the index last recorded about a task, so the task can
+// be taken out again after its fields have changed
+type indexEntry struct {
+	status   Status
+	priority Priority
+	assignee string
+	tags     []string
+	title    titleKey
+	created  createdKey
+}
+
+// createdKey is the place of a task in the default listing order: by
+// creation time, then by ID
+type createdKey struct {
+	at time.Time
+	id int
+}
+
+// compareCreatedKeys orders tasks as listings do by default
+func compareCreatedKeys(a, b createdKey) int {
+	if c := a.at.Compare(b.at); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// taskIndex holds inverted indexes over the active tasks for the fields
+// listings filter on most, so those listings only visit matching tasks,
+// the number of tasks of each priority, the sorted titles that
+// SuggestTitles searches by prefix, and the tasks in default listing order,
+// so listings in that order need no sorting
+type taskIndex struct {
+	entries    map[int]indexEntry
+	byStatus   postings[Status]
+	byAssignee postings[string]
+	byTag      postings[string]
+	priorities map[Priority]int
+	titles     []titleKey
+	created    []createdKey
+}
+
+// newTaskIndex returns an empty index
+func newTaskIndex() *taskIndex {
+	return &taskIndex{
+		entries:    make(map[int]indexEntry),
+		byStatus:   make(postings[Status]),
+		byAssignee: make(postings[string]),
+		byTag:      make(postings[string]),
+		priorities: make(map[Priority]int),
+	}
+}
+
+// put records the current fields of a task, replacing what was recorded before
+func (x *taskIndex) put(task *Task) {
+	x.remove(task.ID)
+	entry := x.record(task)
+	i, _ := slices.BinarySearchFunc(x.titles, entry.title, compareTitleKeys)
+	x.titles = slices.Insert(x.titles, i, entry.title)
+	// Tasks are mostly added in creation order, so this usually appends
+	i, _ = slices.BinarySearchFunc(x.created, entry.created, compareCreatedKeys)
+	x.created = slices.Insert(x.created, i, entry.created)
+}
+
+// putAll records tasks that are not in the index yet. The sorted indexes
+// are sorted once at the end rather than kept sorted task by task.
+func (x *taskIndex) putAll(tasks []*Task) {
+	x.entries = grow(x.entries, len(tasks))
+	for _, task := range tasks {
+		entry := x.record(task)
+		x.titles = append(x.titles, entry.title)
+		x.created = append(x.created, entry.created)
+	}
+	slices.SortFunc(x.titles, compareTitleKeys)
+	slices.SortFunc(x.created, compareCreatedKeys)
+}
+
+// record adds a task that is not in the index to the entries and the
+// inverted indexes, leaving the sorted indexes to the caller
+func (x *taskIndex) record(task *Task) indexEntry {
+	entry := indexEntry{
+		status:   task.Status,
+		priority: task.Priority,
+		assignee: task.AssigneeID,
+		tags:     slices.Clone(task.Tags),
+		title:    newTitleKey(task),
+		created:  createdKey{at: task.CreatedAt, id: task.ID},
+	}
+	x.entries[task.ID] = entry
+	x.byStatus.add(entry.status, task.ID)
+	x.priorities[entry.priority]++
+	x.byAssignee.add(entry.assignee, task.ID)
+	for _, tag := range entry.tags {
+		x.byTag.add(tag, task.ID)
+	}
+	return entry
+}
+
+// remove drops a task from the index
+func (x *taskIndex) remove(id int) {
+	entry, ok := x.entries[id]
+	if !ok {
+		return
+	}
+	delete(x.entries, id)
+	x.byStatus.remove(entry.status, id)
+	if x.priorities[entry.priority]--; x.priorities[entry.priority] == 0 {
+		delete(x.priorities, entry.priority)
+	}
+	x.byAssignee.remove(entry.assignee, id)
+	for _, tag := range entry.tags {
+		x.byTag.remove(tag, id)
+	}
+	if i, ok := slices.BinarySearchFunc(x.titles, entry.title, compareTitleKeys); ok {
+		x.titles = slices.Delete(x.titles, i, i+1)
+	}
+	if i, ok := slices.BinarySearchFunc(x.created, entry.created, compareCreatedKeys); ok {
+		x.created = slices.Delete(x.created, i, i+1)
+	}
+}
+
+// reindex brings the index up to date with a task after it changed, was
+// added to the active tasks or left them
+func (tm *TaskManager) reindex(task *Task) {
+	if stored, _ := tm.tasks.Get(task.ID); stored == task {
+		tm.index.put(task)
+	} else {
+		tm.index.remove(task.ID)
+	}
+}
+
+// Names of the index lookups candidates can choose
+const (
+	indexStatus   = "status"
+	indexTag      = "tag"
+	indexAssignee = "assignee"
+)
+
+// lookup is one way the index can narrow a query: the union of sets
+type lookup struct {
+	index string
+	sets  []map[int]struct{}
+}
+
+// candidates returns the IDs of the tasks that can match the query, taken
+// from the smallest index lookup the query allows, along with the name of
+// the index used. The name is empty when the query filters on no indexed
+// field and every task must be checked. The IDs are in no particular order
+// and only narrow the search; callers still check each task against the
+// query.
+func (tm *TaskManager) candidates(q *listQuery) ([]int, string) {
+	var lookups []lookup
+	if len(q.statuses) > 0 {
+		var sets []map[int]struct{}
+		for status := range q.statuses {
+			sets = append(sets, tm.index.byStatus[status])
+		}
+		lookups = append(lookups, lookup{indexStatus, sets})
+	}
+	for _, tag := range q.allTags {
+		lookups = append(lookups, lookup{indexTag, []map[int]struct{}{tm.index.byTag[tag]}})
+	}
+	if len(q.anyTags) > 0 {
+		var sets []map[int]struct{}
+		for _, tag := range q.anyTags {
+			sets = append(sets, tm.index.byTag[tag])
+		}
+		lookups = append(lookups, lookup{indexTag, sets})
+	}
+	if q.assignee != nil {
+		lookups = append(lookups, lookup{indexAssignee, []map[int]struct{}{tm.index.byAssignee[*q.assignee]}})
+	}
+	if len(lookups) == 0 {
+		return nil, ""
+	}
+
+	smallest := slices.MinFunc(lookups, func(a, b lookup) int {
+		return unionSize(a.sets) - unionSize(b.sets)
+	})
+	ids := make([]int, 0, unionSize(smallest.sets))
+	if len(smallest.sets) == 1 {
+		for id := range smallest.sets[0] {
+			ids = append(ids, id)
+		}
+		return ids, smallest.index
+	}
+	seen := make(map[int]bool)
+	for _, set := range smallest.sets {
+		for id := range set {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, smallest.index
+}
+
+// unionSize is an upper bound on the size of the union of the sets
+func unionSize(sets []map[int]struct{}) int {
+	n := 0
+	for _, set := range sets {
+		n += len(set)
+	}
+	return n
+}
+
+// scan calls fn for each active task matching the query until fn returns
+// false. It reports whether the tasks were visited in the default listing
+// order, which is the case whenever no index lookup narrowed the query;
+// otherwise they come in no particular order.
+func (tm *TaskManager) scan(q *listQuery, fn func(*Task) bool) bool {
+	tm.ops.listings.Add(1)
+	ids, index := tm.candidates(q)
+	if q.stats != nil {
+		q.stats.Index = index
+		start := time.Now()
+		defer func() { q.stats.FilterTime += time.Since(start) }()
+	}
+	if index != "" {
+		for _, id := range ids {
+			if task, _ := tm.tasks.Get(id); q.test(task) && !fn(task) {
+				break
+			}
+		}
+		return false
+	}
+	for task := range tm.inCreationOrder() {
+		if q.test(task) && !fn(task) {
+			break
+		}
+	}
+	return true
+}
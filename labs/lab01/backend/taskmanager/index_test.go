@@ -0,0 +1,144 @@
+package taskmanager
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// checkIndex fails the test unless the index matches one rebuilt from the
+// active tasks
+func checkIndex(t *testing.T, tm *TaskManager, step string) {
+	t.Helper()
+	expected := newTaskIndex()
+	for task := range tm.tasks.List() {
+		expected.put(task)
+	}
+	if !reflect.DeepEqual(tm.index, expected) {
+		t.Errorf("%s: index out of date\nexpected %+v\ngot      %+v", step, expected, tm.index)
+	}
+}
+
+func TestIndexFollowsMutations(t *testing.T) {
+	tm := NewTaskManager()
+	report := mustAddTask(t, tm, "Write report", WithTags("work"))
+	milk := mustAddTask(t, tm, "Buy milk", WithTags("home"))
+	review := mustAddTask(t, tm, "Review PR", WithTags("work", "code"))
+	checkIndex(t, tm, "add")
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"update tags", func() error { return tm.UpdateTask(milk.ID, milk.Title, "", false, WithTags("home", "errand")) }},
+		{"assign", func() error { return tm.AssignTask(report.ID, "alice") }},
+		{"transition", func() error { return tm.Transition(review.ID, StatusInProgress) }},
+		{"patch", func() error { return tm.UpdateTaskFields(review.ID, TaskPatch{Tags: ptr([]string{"code"})}) }},
+		{"delete", func() error { return tm.DeleteTask(milk.ID) }},
+		{"undo delete", tm.Undo},
+		{"redo delete", tm.Redo},
+		{"restore", func() error { return tm.RestoreTask(milk.ID) }},
+		{"merge", func() error {
+			_, err := tm.MergeTasks(report.ID, milk.ID)
+			return err
+		}},
+		{"undo merge", tm.Undo},
+		{"bulk complete", func() error { return tm.CompleteTasks([]int{report.ID, milk.ID}) }},
+		{"archive", func() error { return tm.ArchiveTask(report.ID) }},
+		{"purge", func() error {
+			if err := tm.DeleteTask(review.ID); err != nil {
+				return err
+			}
+			tm.PurgeTrash()
+			return nil
+		}},
+	}
+
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		checkIndex(t, tm, step.name)
+	}
+}
+
+func TestIndexedListings(t *testing.T) {
+	tm := NewTaskManager()
+	var work []int
+	for i := range 20 {
+		tag := "home"
+		if i%5 == 0 {
+			tag = "work"
+		}
+		task := mustAddTask(t, tm, "Task", WithTags(tag))
+		if tag == "work" {
+			work = append(work, task.ID)
+		}
+	}
+	if err := tm.AssignTask(work[0], "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	q := tm.newQuery(nil, []ListOption{FilterByAnyTag("work")})
+	ids, index := tm.candidates(q)
+	slices.Sort(ids)
+	if index != indexTag || !slices.Equal(ids, work) {
+		t.Errorf("Expected the tag index to yield %v, got %v (%q)", work, ids, index)
+	}
+
+	q = tm.newQuery(nil, []ListOption{FilterByAnyTag("home", "work"), FilterByAssignee("alice")})
+	if ids, index := tm.candidates(q); index != indexAssignee || !slices.Equal(ids, []int{work[0]}) {
+		t.Errorf("Expected the smallest lookup to be used, got %v (%q)", ids, index)
+	}
+
+	if _, index := tm.candidates(tm.newQuery(nil, []ListOption{FilterByPriority(PriorityHigh)})); index != "" {
+		t.Error("Expected no index lookup for an unindexed filter")
+	}
+
+	listed := tm.ListTasks(nil, FilterByAllTags("work"), FilterByStatus(StatusTodo))
+	var listedIDs []int
+	for _, task := range listed {
+		listedIDs = append(listedIDs, task.ID)
+	}
+	if !slices.Equal(listedIDs, work) {
+		t.Errorf("Expected %v, got %v", work, listedIDs)
+	}
+	if count, _ := tm.CountTasks(ListOptions{AnyTags: []string{"school"}}); count != 0 {
+		t.Errorf("Expected no tasks for an unused tag, got %d", count)
+	}
+}
+
+func TestOrderedIndex(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	// The clock goes back once, as it can when it is corrected, and stands
+	// still once, so the ID has to break the tie
+	for _, step := range []time.Duration{0, time.Minute, -time.Hour, 0, time.Hour} {
+		now = now.Add(step)
+		mustAddTask(t, tm, fmt.Sprintf("At %s", now.Format("15:04")))
+	}
+	checkIndex(t, tm, "add")
+
+	var stats QueryStats
+	listed := tm.ListTasks(nil, Explain(&stats))
+	var ids []int
+	for _, task := range listed {
+		ids = append(ids, task.ID)
+	}
+	if !slices.Equal(ids, []int{3, 4, 1, 2, 5}) {
+		t.Errorf("Expected creation order with ties broken by ID, got %v", ids)
+	}
+	if stats.SortTime != 0 {
+		t.Errorf("Expected the listing to need no sorting, got %v", stats.SortTime)
+	}
+
+	if err := tm.DeleteTask(4); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkIndex(t, tm, "delete")
+	if got := tm.ListTasks(nil, FilterByPriority(PriorityMedium)); len(got) != 4 || got[0].ID != 3 || got[1].ID != 1 {
+		t.Errorf("Expected filtered listings to keep creation order, got %v", titles(got))
+	}
+}
@@ -0,0 +1,97 @@
+package taskmanager
+
+import (
+	"iter"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Tasks returns the listing ListTasks would return as a sequence for range
+// loops, so callers can work through it without keeping a slice of their
+// own. The tasks are matched and ordered each time the sequence is iterated,
+// and stopping the loop early skips the remaining tasks. The stored tasks
+// are yielded even with WithDefensiveCopies and must not be modified.
+func (tm *TaskManager) Tasks(filterDone *bool, opts ...ListOption) iter.Seq[*Task] {
+	return func(yield func(*Task) bool) {
+		for _, task := range tm.matching(tm.newQuery(filterDone, opts)) {
+			if !yield(task) {
+				return
+			}
+		}
+	}
+}
+
+// ListTasksInto is ListTasks appending to dst[:0] instead of a new slice,
+// so callers listing often, such as request handlers, can reuse one buffer
+// and only allocate when it has to grow. The returned slice shares dst's
+// array and is overwritten by the next call given the same buffer. With
+// WithDefensiveCopies each task is still copied.
+func (tm *TaskManager) ListTasksInto(dst []*Task, filterDone *bool, opts ...ListOption) []*Task {
+	q := tm.newQuery(filterDone, opts)
+	return tm.exportListing(tm.matchingInto(dst[:0], q), q.omitDetails)
+}
+
+// matching returns the tasks passing the query in listing order
+func (tm *TaskManager) matching(q *listQuery) []*Task {
+	return tm.matchingInto(nil, q)
+}
+
+// matchingInto is matching appending to dst
+func (tm *TaskManager) matchingInto(dst []*Task, q *listQuery) []*Task {
+	result := dst
+	ordered := tm.scan(q, func(task *Task) bool {
+		result = append(result, task)
+		return true
+	})
+	if ordered && q.defaultOrder() {
+		return result
+	}
+	start := time.Now()
+	matched := result[len(dst):]
+	sort.Slice(matched, func(i, j int) bool {
+		return q.less(matched[i], matched[j])
+	})
+	if q.stats != nil {
+		q.stats.SortTime += time.Since(start)
+	}
+	return result
+}
+
+// ForEachTask calls fn for each task matching filter until fn returns
+// false. Without filter.Sort tasks are visited in the default listing
+// order, by creation time and then ID, straight from the store, so no
+// listing is built however many tasks there are; with it the matches are
+// gathered and sorted first. filter.Page is ignored. As with
+// Tasks, fn is given the stored tasks and must not modify them.
+func (tm *TaskManager) ForEachTask(filter ListOptions, fn func(*Task) bool) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	q := tm.newQuery(filter.Done, filter.listOptions())
+	if len(filter.Sort) > 0 {
+		for _, task := range tm.matching(q) {
+			if !fn(task) {
+				break
+			}
+		}
+		return nil
+	}
+	if ids, index := tm.candidates(q); index != "" {
+		slices.SortFunc(ids, func(a, b int) int {
+			return compareCreatedKeys(tm.index.entries[a].created, tm.index.entries[b].created)
+		})
+		for _, id := range ids {
+			if task, _ := tm.tasks.Get(id); q.matches(task) && !fn(task) {
+				break
+			}
+		}
+		return nil
+	}
+	for task := range tm.inCreationOrder() {
+		if q.matches(task) && !fn(task) {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package taskmanager
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTasks(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for i := 1; i <= 4; i++ {
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i), WithPriority(Priority(i)))
+		now = now.Add(time.Minute)
+	}
+
+	seq := tm.Tasks(nil, SortByPriority())
+	if got, expected := titles(slices.Collect(seq)), []string{"Task 4", "Task 3", "Task 2", "Task 1"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	var visited []string
+	for task := range seq {
+		visited = append(visited, task.Title)
+		if len(visited) == 2 {
+			break
+		}
+	}
+	if expected := []string{"Task 4", "Task 3"}; !slices.Equal(visited, expected) {
+		t.Errorf("Expected early stop after %v, got %v", expected, visited)
+	}
+
+	mustAddTask(t, tm, "Task 5", WithPriority(PriorityUrgent))
+	if got := titles(slices.Collect(seq)); len(got) != 5 || got[0] != "Task 4" || got[1] != "Task 5" {
+		t.Errorf("Expected the sequence to reflect the new task, got %v", got)
+	}
+
+	done := true
+	if got := slices.Collect(tm.Tasks(&done)); len(got) != 0 {
+		t.Errorf("Expected no done tasks, got %v", titles(got))
+	}
+	if got := tm.ListTasks(&done); got == nil || len(got) != 0 {
+		t.Errorf("Expected ListTasks to return an empty slice, got %#v", got)
+	}
+}
+
+func TestForEachTask(t *testing.T) {
+	tm := NewTaskManager()
+	for i := 1; i <= 5; i++ {
+		tags := []string{"odd"}
+		if i%2 == 0 {
+			tags = []string{"even"}
+		}
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i), WithTags(tags...))
+	}
+	if err := tm.DeleteTask(3); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   ListOptions
+		stop     int
+		expected []int
+	}{
+		{name: "all in id order", filter: ListOptions{}, expected: []int{1, 2, 4, 5}},
+		{name: "filtered", filter: ListOptions{Tags: []string{"odd"}}, expected: []int{1, 5}},
+		{name: "early stop", filter: ListOptions{}, stop: 2, expected: []int{1, 2}},
+		{name: "sorted", filter: ListOptions{Sort: SortSpec{{Field: SortTitle, Descending: true}}}, expected: []int{5, 4, 2, 1}},
+		{name: "sorted early stop", filter: ListOptions{Sort: SortSpec{{Field: SortTitle, Descending: true}}}, stop: 1, expected: []int{5}},
+		{name: "page ignored", filter: ListOptions{Page: Page{Limit: 1}}, expected: []int{1, 2, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var visited []int
+			err := tm.ForEachTask(tt.filter, func(task *Task) bool {
+				visited = append(visited, task.ID)
+				return len(visited) != tt.stop
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(visited, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, visited)
+			}
+		})
+	}
+
+	called := false
+	err := tm.ForEachTask(ListOptions{Sort: SortSpec{{Field: 42}}}, func(*Task) bool {
+		called = true
+		return true
+	})
+	if err != ErrInvalidSortField || called {
+		t.Errorf("Expected ErrInvalidSortField without visiting tasks, got %v (called %v)", err, called)
+	}
+}
+
+func TestListTasksInto(t *testing.T) {
+	tm := NewTaskManager()
+	for i := 1; i <= 5; i++ {
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i), WithPriority(Priority(i%4+1)))
+	}
+
+	buf := make([]*Task, 0, 8)
+	tests := []struct {
+		name string
+		opts []ListOption
+	}{
+		{name: "default"},
+		{name: "sorted", opts: []ListOption{SortByPriority()}},
+		{name: "filtered", opts: []ListOption{FilterByPriority(PriorityHigh)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf = tm.ListTasksInto(buf, nil, tt.opts...)
+			if got, expected := titles(buf), titles(tm.ListTasks(nil, tt.opts...)); !slices.Equal(got, expected) {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+			if cap(buf) != 8 {
+				t.Errorf("Expected the buffer to be reused, got capacity %d", cap(buf))
+			}
+		})
+	}
+
+	// A buffer too small grows like append
+	small := tm.ListTasksInto(make([]*Task, 1), nil)
+	if len(small) != 5 {
+		t.Errorf("Expected all 5 tasks in a grown buffer, got %d", len(small))
+	}
+
+	copies := NewTaskManager(WithDefensiveCopies())
+	stored := mustAddTask(t, copies, "Task")
+	if got := copies.ListTasksInto(buf, nil); got[0] == storedTask(copies, stored.ID) {
+		t.Error("Expected copies with WithDefensiveCopies")
+	}
+}
+
+// listingBenchmarks are the listings the listing benchmarks run against
+// 1000 tasks, a fifth of them tagged work
+var listingBenchmarks = []struct {
+	name string
+	opts []ListOption
+}{
+	{"default", nil},
+	{"indexed", []ListOption{FilterByAnyTag("work")}},
+	{"sorted", []ListOption{SortByPriority()}},
+}
+
+func benchmarkManager(b *testing.B) *TaskManager {
+	tm := NewTaskManager()
+	for i := range 1000 {
+		var opts []TaskOption
+		if i%5 == 0 {
+			opts = append(opts, WithTags("work"))
+		}
+		mustAddTask(b, tm, fmt.Sprintf("Task %d", i), append(opts, WithPriority(Priority(i%4+1)))...)
+	}
+	return tm
+}
+
+func BenchmarkListTasks(b *testing.B) {
+	tm := benchmarkManager(b)
+	for _, bm := range listingBenchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				tm.ListTasks(nil, bm.opts...)
+			}
+		})
+	}
+}
+
+func BenchmarkListTasksInto(b *testing.B) {
+	tm := benchmarkManager(b)
+	for _, bm := range listingBenchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var buf []*Task
+			b.ReportAllocs()
+			for b.Loop() {
+				buf = tm.ListTasksInto(buf, nil, bm.opts...)
+			}
+		})
+	}
+}
@@ -0,0 +1,660 @@
+package taskmanager
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidExport is returned by ImportJSON for input that is not a
+	// JSON export
+	ErrInvalidExport = errors.New("invalid export")
+	// ErrUnsupportedExportVersion is returned by ImportJSON for an export
+	// written by a newer version of the package in a layout it cannot read
+	ErrUnsupportedExportVersion = errors.New("unsupported export version")
+)
+
+// exportFormat names the files ExportJSON writes in their envelope
+const exportFormat = "taskmanager-export"
+
+// exportVersion is the version of the layout ExportJSON writes. It only
+// changes when a field changes meaning or is removed: fields are added
+// without a new version, and ImportJSON ignores fields it does not know,
+// so older versions of the package read newer exports of the same version.
+// Version 0 is the bare JSON array of Task values that predates the
+// envelope.
+const exportVersion = 1
+
+// ExportEnvelope is the layout of the files ExportJSON writes:
+//
+//	{
+//	  "format": "taskmanager-export",
+//	  "version": 1,
+//	  "generated_at": "2025-07-01T09:00:00Z",
+//	  "counts": {"tasks": 2, "by_status": {"done": 1, "todo": 1}},
+//	  "fields": [{"name": "points", "type": "number"}],
+//	  "tasks": [
+//	    {"id": 1, "title": "Write report", "status": "todo", "priority": "high", ...}
+//	  ]
+//	}
+//
+// The records are independent of Task, so changes to Task do not change
+// the files.
+type ExportEnvelope struct {
+	Format      string       `json:"format"`
+	Version     int          `json:"version"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Counts      ExportCounts `json:"counts"`
+	// Fields defines the custom fields the tasks carry. Importing defines
+	// those the manager lacks.
+	Fields []ExportedField `json:"fields,omitempty"`
+	Tasks  []ExportedTask  `json:"tasks"`
+}
+
+// ExportCounts sums up the tasks of an export
+type ExportCounts struct {
+	Tasks int `json:"tasks"`
+	// ByStatus counts the tasks by status name
+	ByStatus map[string]int `json:"by_status"`
+}
+
+// ExportedField is the definition of a custom field in an export, with
+// the type by name, such as "number"
+type ExportedField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ExportedTask is a task in an export. Status, priority and visibility are
+// given by name, such as "in progress", "high" and "shared", the estimate
+// as a duration such as "1h30m0s", and times as RFC 3339. An export holds
+// what a task is rather than what was done with it: comments, attachments,
+// history, related links and tracked time are left out, and imported
+// tasks get a new version, position and update time.
+type ExportedTask struct {
+	// ID is the ID of the task. It is exported only: imported tasks get
+	// new IDs.
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`
+	Priority    string `json:"priority"`
+	// Tags are the tags of the task, in order
+	Tags      []string    `json:"tags,omitempty"`
+	DueAt     *time.Time  `json:"due_at,omitempty"`
+	Reminders []time.Time `json:"reminders,omitempty"`
+	Assignee  string      `json:"assignee,omitempty"`
+	Owner     string      `json:"owner,omitempty"`
+	// Visibility is empty in exports older than the field, for shared
+	Visibility string `json:"visibility,omitempty"`
+	// Project is the name of the project of the task. Importing puts the
+	// task into the project of that name, ignoring case, and creates it
+	// when there is none.
+	Project string `json:"project,omitempty"`
+	// ParentID and DependsOn refer to other tasks by their ID in the
+	// export. Imported tasks refer to the tasks imported from those
+	// records instead, and references to tasks the export leaves out are
+	// dropped.
+	ParentID   int                 `json:"parent_id,omitempty"`
+	DependsOn  []int               `json:"depends_on,omitempty"`
+	Recurrence *ExportedRecurrence `json:"recurrence,omitempty"`
+	Estimate   string              `json:"estimate,omitempty"`
+	Color      string              `json:"color,omitempty"`
+	Icon       string              `json:"icon,omitempty"`
+	Location   *ExportedLocation   `json:"location,omitempty"`
+	// CustomFields holds the values of custom fields by name, with dates
+	// as RFC 3339
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+	// Checklist holds the checklist items, which get new IDs on import
+	Checklist    []ExportedItem `json:"checklist,omitempty"`
+	Pinned       bool           `json:"pinned,omitempty"`
+	Archived     bool           `json:"archived,omitempty"`
+	SnoozedUntil *time.Time     `json:"snoozed_until,omitempty"`
+	// CreatedAt is exported only: imported tasks are created at the time
+	// of the import
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ExportedItem is a checklist item in an export
+type ExportedItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// ExportedRecurrence is the recurrence rule of a task in an export, with
+// the frequency and weekdays by name, such as "weekly" and "monday"
+type ExportedRecurrence struct {
+	Frequency string     `json:"frequency"`
+	Interval  int        `json:"interval,omitempty"`
+	Weekdays  []string   `json:"weekdays,omitempty"`
+	MonthDay  int        `json:"month_day,omitempty"`
+	Count     int        `json:"count,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// ExportedLocation is the location of a task in an export
+type ExportedLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Label     string  `json:"label,omitempty"`
+}
+
+// ExportJSON writes the tasks matching the filter as an ExportEnvelope of
+// indented JSON, in the filter's order. The page and cursor of the filter
+// are ignored, so every matching task is written.
+func (tm *TaskManager) ExportJSON(w io.Writer, filter ListOptions) error {
+	tasks, err := tm.exportTasks(filter)
+	if err != nil {
+		return err
+	}
+	return writeJSONExport(w, tm.jsonExport(tasks))
+}
+
+// jsonExport returns the envelope ExportJSON writes for tasks
+func (tm *TaskManager) jsonExport(tasks []*Task) *ExportEnvelope {
+	envelope := &ExportEnvelope{
+		Format:      exportFormat,
+		Version:     exportVersion,
+		GeneratedAt: tm.now(),
+		Counts:      ExportCounts{Tasks: len(tasks), ByStatus: make(map[string]int)},
+		Tasks:       make([]ExportedTask, len(tasks)),
+	}
+	fields := make(map[string]bool)
+	for i, task := range tasks {
+		envelope.Counts.ByStatus[task.Status.String()]++
+		record := exportedTask(task)
+		if project, ok := tm.projects[task.ProjectID]; ok {
+			record.Project = project.Name
+		}
+		for name := range task.CustomFields {
+			fields[name] = true
+		}
+		envelope.Tasks[i] = record
+	}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		envelope.Fields = append(envelope.Fields, ExportedField{Name: name, Type: tm.fields[name].String()})
+	}
+	return envelope
+}
+
+// exportedTask returns the record of a task, without its project, which
+// it names by ID only
+func exportedTask(task *Task) ExportedTask {
+	record := ExportedTask{
+		ID:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       task.Status.String(),
+		Priority:     task.Priority.String(),
+		Tags:         task.Tags,
+		DueAt:        task.DueDate,
+		Reminders:    task.Reminders,
+		Assignee:     task.AssigneeID,
+		Owner:        task.OwnerID,
+		ParentID:     task.ParentID,
+		DependsOn:    task.DependsOn,
+		Recurrence:   exportedRecurrence(task.Recurrence),
+		Color:        task.Color,
+		Icon:         task.Icon,
+		CustomFields: task.CustomFields,
+		Pinned:       task.Pinned,
+		Archived:     task.Archived,
+		SnoozedUntil: task.SnoozedUntil,
+		CreatedAt:    task.CreatedAt,
+		CompletedAt:  task.CompletedAt,
+	}
+	if task.Visibility.Valid() {
+		record.Visibility = task.Visibility.String()
+	}
+	if task.Estimate > 0 {
+		record.Estimate = task.Estimate.String()
+	}
+	if loc := task.Location; loc != nil {
+		record.Location = &ExportedLocation{Latitude: loc.Latitude, Longitude: loc.Longitude, Label: loc.Label}
+	}
+	for _, item := range task.Checklist {
+		record.Checklist = append(record.Checklist, ExportedItem{Text: item.Text, Done: item.Done})
+	}
+	return record
+}
+
+// exportedRecurrence returns the record of a recurrence rule, or nil for
+// none
+func exportedRecurrence(r *Recurrence) *ExportedRecurrence {
+	if r == nil {
+		return nil
+	}
+	record := &ExportedRecurrence{
+		Frequency: strings.ToLower(r.Frequency.String()),
+		Interval:  r.Interval,
+		MonthDay:  r.MonthDay,
+		Count:     r.Count,
+		Until:     r.Until,
+	}
+	for _, day := range r.Weekdays {
+		record.Weekdays = append(record.Weekdays, strings.ToLower(day.String()))
+	}
+	return record
+}
+
+// recurrence returns the recurrence rule of a record
+func (e *ExportedRecurrence) recurrence() (Recurrence, error) {
+	frequency, err := parseFrequency(e.Frequency)
+	if err != nil {
+		return Recurrence{}, err
+	}
+	r := Recurrence{Frequency: frequency, Interval: e.Interval, MonthDay: e.MonthDay, Count: e.Count, Until: e.Until}
+	for _, name := range e.Weekdays {
+		day := slices.IndexFunc(weekdayNames[:], func(n string) bool { return strings.EqualFold(n, name) })
+		if day < 0 {
+			return Recurrence{}, fmt.Errorf("unknown weekday %q", name)
+		}
+		r.Weekdays = append(r.Weekdays, time.Weekday(day))
+	}
+	return r, nil
+}
+
+// weekdayNames are the names of the days of the week, from Sunday
+var weekdayNames = [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// writeJSONExport writes an envelope as ExportJSON does
+func writeJSONExport(w io.Writer, envelope *ExportEnvelope) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(envelope)
+}
+
+// legacyTask is a task of a version 0 export, with the fields of Task the
+// records of later versions carry, under the names encoding/json gave them.
+// It only gives the project by ID, which does not carry over to another
+// manager.
+type legacyTask struct {
+	ID           int
+	Title        string
+	Description  string
+	Status       Status
+	Priority     Priority
+	Tags         []string
+	DueDate      *time.Time
+	Reminders    []time.Time
+	AssigneeID   string
+	OwnerID      string
+	Visibility   Visibility
+	ParentID     int
+	DependsOn    []int
+	Recurrence   *Recurrence
+	Estimate     time.Duration
+	Color        string
+	Icon         string
+	Location     *Location
+	CustomFields map[string]any
+	Checklist    []ChecklistItem
+	Pinned       bool
+	Archived     bool
+	SnoozedUntil *time.Time
+	CreatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+// record converts a task of a version 0 export to the current record
+func (t legacyTask) record() ExportedTask {
+	record := exportedTask(&Task{
+		ID:           t.ID,
+		Title:        t.Title,
+		Description:  t.Description,
+		Tags:         t.Tags,
+		DueDate:      t.DueDate,
+		Reminders:    t.Reminders,
+		AssigneeID:   t.AssigneeID,
+		OwnerID:      t.OwnerID,
+		Visibility:   t.Visibility,
+		ParentID:     t.ParentID,
+		DependsOn:    t.DependsOn,
+		Recurrence:   t.Recurrence,
+		Estimate:     t.Estimate,
+		Color:        t.Color,
+		Icon:         t.Icon,
+		Location:     t.Location,
+		CustomFields: t.CustomFields,
+		Checklist:    t.Checklist,
+		Pinned:       t.Pinned,
+		Archived:     t.Archived,
+		SnoozedUntil: t.SnoozedUntil,
+		CreatedAt:    t.CreatedAt,
+		CompletedAt:  t.CompletedAt,
+	})
+	// A status or priority left out takes the default
+	record.Status, record.Priority = "", ""
+	if t.Status != 0 {
+		record.Status = t.Status.String()
+	}
+	if t.Priority != 0 {
+		record.Priority = t.Priority.String()
+	}
+	return record
+}
+
+// parseJSONExport reads an export of any version up to exportVersion,
+// converting older versions to the current records
+func parseJSONExport(r io.Reader) (*ExportEnvelope, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if data = bytes.TrimSpace(data); bytes.HasPrefix(data, []byte("[")) {
+		var legacy []legacyTask
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+		}
+		envelope := &ExportEnvelope{Tasks: make([]ExportedTask, len(legacy))}
+		for i, task := range legacy {
+			envelope.Tasks[i] = task.record()
+		}
+		return envelope, nil
+	}
+	var envelope ExportEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	if envelope.Format != exportFormat {
+		return nil, fmt.Errorf("%w: format %q", ErrInvalidExport, envelope.Format)
+	}
+	if envelope.Version > exportVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedExportVersion, envelope.Version)
+	}
+	if envelope.Version < 1 {
+		return nil, fmt.Errorf("%w: version %d", ErrInvalidExport, envelope.Version)
+	}
+	return &envelope, nil
+}
+
+// ImportJSON adds a task for each record of an export ExportJSON wrote, or
+// of a bare JSON array of tasks as encoding/json writes them, as
+// ImportTasks does, and returns the tasks added in the order of the
+// export. Fields the package does not know are ignored. Custom fields and
+// projects the manager lacks are created for the tasks that use them, and
+// parents and dependencies are pointed at the tasks imported from the
+// records they refer to. A record that fails to parse or to validate is
+// skipped, and a *BulkError reports each one by its position in the
+// export, while the other records are still imported; a parent or
+// dependency that cannot be set, such as one forming a cycle, is reported
+// in it too, while its task is imported without it. The tasks, parents
+// and dependencies are undone as one operation.
+func (tm *TaskManager) ImportJSON(r io.Reader) ([]*Task, error) {
+	envelope, err := parseJSONExport(r)
+	if err != nil {
+		return nil, err
+	}
+	return tm.importJSON(envelope)
+}
+
+// importJSON imports the records of a parsed export
+func (tm *TaskManager) importJSON(envelope *ExportEnvelope) ([]*Task, error) {
+	defer tm.beginOperation()()
+
+	records := envelope.Tasks
+	fields := maps.Clone(tm.fields)
+	for _, field := range envelope.Fields {
+		if fieldType, err := parseFieldType(field.Type); err == nil && fields[field.Name] == 0 {
+			fields[field.Name] = fieldType
+		}
+	}
+	var failed []ItemError
+	inputs := make([]TaskInput, 0, len(records))
+	// indexes holds the position in records of each input
+	indexes := make([]int, 0, len(records))
+	nextItemID := tm.nextChecklistItemID
+	projectIDs := make(map[string]int)
+	var createdProjects []int
+	var definedFields []string
+	for i, record := range records {
+		in, err := record.input(&nextItemID, fields)
+		if err == nil && record.Project != "" {
+			var id int
+			if id, err = tm.importedProject(record.Project, projectIDs, &createdProjects); err == nil {
+				in.Options = append(in.Options, WithProject(id))
+			}
+		}
+		if err != nil {
+			failed = append(failed, ItemError{Index: i, Err: err})
+			continue
+		}
+		for name := range record.CustomFields {
+			if _, ok := tm.fields[name]; !ok && fields[name] != 0 && tm.DefineField(name, fields[name]) == nil {
+				definedFields = append(definedFields, name)
+			}
+		}
+		inputs = append(inputs, in)
+		indexes = append(indexes, i)
+	}
+	tasks, err := tm.ImportTasks(inputs)
+	var bulk *BulkError
+	if errors.As(err, &bulk) {
+		for _, f := range bulk.Failures {
+			failed = append(failed, ItemError{Index: indexes[f.Index], Err: f.Err})
+		}
+	} else if err != nil {
+		tm.dropUnused(nil, createdProjects, definedFields)
+		return nil, err
+	}
+	tm.nextChecklistItemID = nextItemID
+	tm.dropUnused(tasks, createdProjects, definedFields)
+
+	failed = append(failed, tm.importRelations(records, indexes, tasks)...)
+	added := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		if stored, ok := tm.tasks.Get(task.ID); ok {
+			added = append(added, tm.export(stored))
+		}
+	}
+	if len(failed) > 0 {
+		slices.SortStableFunc(failed, func(a, b ItemError) int {
+			return cmp.Compare(a.Index, b.Index)
+		})
+		return added, &BulkError{Failures: failed}
+	}
+	return added, nil
+}
+
+// importedProject returns the ID of the project an imported record names,
+// creating the project when the manager has none of that name
+func (tm *TaskManager) importedProject(name string, ids map[string]int, created *[]int) (int, error) {
+	if id, ok := ids[name]; ok {
+		return id, nil
+	}
+	if project := tm.projectNamed(name); project != nil {
+		ids[name] = project.ID
+		return project.ID, nil
+	}
+	project, err := tm.CreateProject(name)
+	if err != nil {
+		return 0, err
+	}
+	ids[name] = project.ID
+	*created = append(*created, project.ID)
+	return project.ID, nil
+}
+
+// dropUnused removes the projects and custom fields an import created that
+// none of the tasks it added uses
+func (tm *TaskManager) dropUnused(tasks []*Task, projects []int, fields []string) {
+	usedProjects := make(map[int]bool)
+	usedFields := make(map[string]bool)
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		usedProjects[task.ProjectID] = true
+		for name := range task.CustomFields {
+			usedFields[name] = true
+		}
+	}
+	for _, id := range projects {
+		if !usedProjects[id] {
+			delete(tm.projects, id)
+		}
+	}
+	for _, name := range fields {
+		if !usedFields[name] {
+			delete(tm.fields, name)
+		}
+	}
+}
+
+// importRelations points the parents and dependencies of the imported
+// tasks at the tasks imported from the records they refer to, and returns
+// an error for each one that could not be set
+func (tm *TaskManager) importRelations(records []ExportedTask, indexes []int, tasks []*Task) []ItemError {
+	// newIDs maps the IDs of the export to those of the imported tasks
+	newIDs := make(map[int]int, len(tasks))
+	for i, task := range tasks {
+		if task != nil && records[indexes[i]].ID != 0 {
+			newIDs[records[indexes[i]].ID] = task.ID
+		}
+	}
+	var failed []ItemError
+	for i, task := range tasks {
+		if task == nil {
+			continue
+		}
+		record := records[indexes[i]]
+		if parentID, ok := newIDs[record.ParentID]; ok && record.ParentID != 0 {
+			if err := tm.UpdateTaskFields(task.ID, TaskPatch{ParentID: &parentID}); err != nil {
+				failed = append(failed, ItemError{Index: indexes[i], Err: err})
+			}
+		}
+		for _, id := range record.DependsOn {
+			if dependsOnID, ok := newIDs[id]; ok {
+				if err := tm.AddDependency(task.ID, dependsOnID); err != nil {
+					failed = append(failed, ItemError{Index: indexes[i], Err: err})
+				}
+			}
+		}
+	}
+	return failed
+}
+
+// input returns the task input of a record, numbering its checklist items
+// from next and reading custom field values as the types of fields
+func (e ExportedTask) input(next *int, fields map[string]FieldType) (TaskInput, error) {
+	in := TaskInput{Title: e.Title, Description: e.Description}
+	if e.Priority != "" {
+		priority, err := parsePriority(e.Priority)
+		if err != nil {
+			return in, err
+		}
+		in.Options = append(in.Options, WithPriority(priority))
+	}
+	status := StatusTodo
+	if e.Status != "" {
+		var err error
+		if status, err = parseStatus(e.Status); err != nil {
+			return in, err
+		}
+	}
+	in.Options = append(in.Options, WithTags(e.Tags...))
+	if e.DueAt != nil {
+		in.Options = append(in.Options, WithDueDate(*e.DueAt))
+	}
+	if len(e.Reminders) > 0 {
+		reminders := slices.SortedFunc(slices.Values(e.Reminders), time.Time.Compare)
+		in.Options = append(in.Options, func(t *Task) { t.Reminders = reminders })
+	}
+	if e.Assignee != "" {
+		assignee := e.Assignee
+		in.Options = append(in.Options, func(t *Task) { t.AssigneeID = assignee })
+	}
+	if e.Owner != "" {
+		in.Options = append(in.Options, WithOwner(e.Owner))
+	}
+	if e.Visibility != "" {
+		visibility, err := parseVisibility(e.Visibility)
+		if err != nil {
+			return in, err
+		}
+		in.Options = append(in.Options, WithVisibility(visibility))
+	}
+	if e.Recurrence != nil {
+		r, err := e.Recurrence.recurrence()
+		if err != nil {
+			return in, err
+		}
+		in.Options = append(in.Options, WithRecurrence(r))
+	}
+	if e.Estimate != "" {
+		estimate, err := time.ParseDuration(e.Estimate)
+		if err != nil {
+			return in, fmt.Errorf("%w: %q", ErrInvalidEstimate, e.Estimate)
+		}
+		in.Options = append(in.Options, WithEstimate(estimate))
+	}
+	if e.Color != "" {
+		in.Options = append(in.Options, WithColor(e.Color))
+	}
+	if e.Icon != "" {
+		in.Options = append(in.Options, WithIcon(e.Icon))
+	}
+	if loc := e.Location; loc != nil {
+		in.Options = append(in.Options, WithLocation(Location{Latitude: loc.Latitude, Longitude: loc.Longitude, Label: loc.Label}))
+	}
+	for name, value := range e.CustomFields {
+		if text, ok := value.(string); ok && fields[name] == FieldDate {
+			if at, err := time.Parse(time.RFC3339, text); err == nil {
+				value = at
+			}
+		}
+		in.Options = append(in.Options, WithCustomField(name, value))
+	}
+	if len(e.Checklist) > 0 {
+		items := make([]ChecklistItem, len(e.Checklist))
+		for i, item := range e.Checklist {
+			items[i] = ChecklistItem{Text: item.Text, Done: item.Done}
+		}
+		in.Options = append(in.Options, importedChecklist(items, next))
+	}
+	pinned, archived := e.Pinned, e.Archived
+	var snoozed *time.Time
+	if e.SnoozedUntil != nil {
+		until := *e.SnoozedUntil
+		snoozed = &until
+	}
+	in.Options = append(in.Options, func(t *Task) {
+		t.Pinned, t.Archived, t.SnoozedUntil = pinned, archived, snoozed
+	})
+	if status != StatusTodo {
+		in.Options = append(in.Options, importedStatus(status, e.CompletedAt))
+	}
+	return in, nil
+}
+
+// parseVisibility looks a visibility up by name
+func parseVisibility(name string) (Visibility, error) {
+	for _, v := range []Visibility{VisibilityShared, VisibilityPrivate} {
+		if v.String() == strings.ToLower(name) {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidVisibility, name)
+}
+
+// parseFieldType looks a custom field type up by name
+func parseFieldType(name string) (FieldType, error) {
+	for f := FieldString; f <= FieldDate; f++ {
+		if f.String() == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidFieldType, name)
+}
@@ -0,0 +1,242 @@
+package taskmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportJSON(t *testing.T) {
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return at }))
+	report := mustAddTask(t, tm, "Write report", WithTags("work"), WithPriority(PriorityHigh), WithDueDate(at.AddDate(0, 0, 1)))
+	tm.AddChecklistItem(report.ID, "Sources")
+	done := mustAddTask(t, tm, "Groceries", WithTags("home"))
+	tm.CompleteTask(done.ID, "")
+	mustAddTask(t, tm, "Other")
+
+	var b bytes.Buffer
+	if err := tm.ExportJSON(&b, ListOptions{AnyTags: []string{"work", "home"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var want map[string]any
+	json.Unmarshal([]byte(`{
+		"format": "taskmanager-export",
+		"version": 1,
+		"generated_at": "2025-07-01T09:00:00Z",
+		"counts": {"tasks": 2, "by_status": {"done": 1, "todo": 1}},
+		"tasks": [
+			{"id": 1, "title": "Write report", "status": "todo", "priority": "high", "tags": ["work"],
+				"due_at": "2025-07-02T09:00:00Z", "visibility": "shared", "color": "#9e9e9e", "icon": "task",
+				"checklist": [{"text": "Sources", "done": false}], "created_at": "2025-07-01T09:00:00Z"},
+			{"id": 2, "title": "Groceries", "status": "done", "priority": "medium", "tags": ["home"],
+				"visibility": "shared", "color": "#9e9e9e", "icon": "task",
+				"created_at": "2025-07-01T09:00:00Z", "completed_at": "2025-07-01T09:00:00Z"}
+		]
+	}`), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\n%v\ngot:\n%v", want, got)
+	}
+
+	// An export imports back into another manager
+	imported := NewTaskManager(WithClock(func() time.Time { return at }))
+	tasks, err := imported.ImportJSON(&b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(tasks); !slices.Equal(got, []string{"Write report", "Groceries"}) {
+		t.Errorf("Expected %v, got %v", []string{"Write report", "Groceries"}, got)
+	}
+	if tasks[0].Priority != PriorityHigh || tasks[1].Status != StatusDone || len(tasks[0].Checklist) != 1 {
+		t.Errorf("Expected the fields imported, got %+v", tasks)
+	}
+
+	if err := tm.ExportJSON(&b, ListOptions{Statuses: []Status{99}}); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestJSONExportFields(t *testing.T) {
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return at })
+	tm := NewTaskManager(clock)
+	course, _ := tm.CreateProject("Course")
+	tm.DefineField("points", FieldNumber)
+	tm.DefineField("handed_in", FieldDate)
+	due := at.AddDate(0, 0, 7)
+	report := mustAddTask(t, tm, "Write report", WithProject(course.ID), WithDueDate(due),
+		WithRecurrence(Recurrence{Frequency: Weekly, Interval: 2, Weekdays: []time.Weekday{time.Monday, time.Wednesday}}),
+		WithEstimate(90*time.Minute), WithColor("#ff0000"), WithIcon("book"),
+		WithLocation(Location{Latitude: 55.75, Longitude: 37.62, Label: "Library"}),
+		WithOwner("alice"), WithVisibility(VisibilityPrivate),
+		WithCustomField("points", 5), WithCustomField("handed_in", at))
+	tm.AddReminder(report.ID, due.Add(-time.Hour))
+	tm.PinTask(report.ID)
+	outline := mustAddTask(t, tm, "Outline", WithParent(report.ID))
+	tm.SnoozeTask(outline.ID, at.Add(time.Hour))
+	slides := mustAddTask(t, tm, "Slides")
+	tm.AddDependency(slides.ID, report.ID)
+	tm.ArchiveTask(slides.ID)
+	tm.AddComment(report.ID, "bob", "Left out")
+	tm.StartTimer(report.ID)
+
+	var b bytes.Buffer
+	if err := tm.ExportJSON(&b, ListOptions{IncludeArchived: true, IncludeSnoozed: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	export := b.String()
+
+	// Every field an export carries comes back, so exporting again writes
+	// the same file
+	imported := NewTaskManager(clock)
+	if _, err := imported.ImportJSON(strings.NewReader(export)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b.Reset()
+	imported.ExportJSON(&b, ListOptions{IncludeArchived: true, IncludeSnoozed: true})
+	if b.String() != export {
+		t.Errorf("Expected:\n%s\ngot:\n%s", export, b.String())
+	}
+	if want := tm.ListFields(); !reflect.DeepEqual(imported.ListFields(), want) {
+		t.Errorf("Expected fields %v, got %v", want, imported.ListFields())
+	}
+	got := storedTask(imported, report.ID)
+	if len(got.Comments) != 0 || got.TrackedTime != 0 || got.TimerStarted != nil {
+		t.Errorf("Expected comments and time tracking left out, got %+v", got)
+	}
+
+	// References follow the tasks to their new IDs, and projects are
+	// matched by name
+	shifted := NewTaskManager(clock)
+	mustAddTask(t, shifted, "Existing")
+	shifted.CreateProject("course")
+	tasks, err := shifted.ImportJSON(strings.NewReader(export))
+	if err != nil || len(tasks) != 3 {
+		t.Fatalf("Expected 3 tasks, got %d, %v", len(tasks), err)
+	}
+	if tasks[0].ID != 2 || tasks[1].ParentID != tasks[0].ID || !slices.Equal(tasks[2].DependsOn, []int{tasks[0].ID}) {
+		t.Errorf("Expected references to the new IDs, got %+v", tasks)
+	}
+	if projects := shifted.ListProjects(); len(projects) != 1 || tasks[0].ProjectID != projects[0].ID {
+		t.Errorf("Expected the existing project used, got %v", projects)
+	}
+	shifted.Undo()
+	if got := titles(shifted.ListTasks(nil)); !slices.Equal(got, []string{"Existing"}) {
+		t.Errorf("Expected the import undone as a whole, got %v", got)
+	}
+}
+
+func TestImportJSONVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+		want   []string
+		status []Status
+		err    error
+	}{
+		{
+			name:   "current",
+			export: `{"format": "taskmanager-export", "version": 1, "tasks": [{"title": "Report", "status": "in progress"}]}`,
+			want:   []string{"Report"},
+			status: []Status{StatusInProgress},
+		},
+		{
+			name: "unknown fields",
+			export: `{"format": "taskmanager-export", "version": 1, "source": "phone", "counts": {"tasks": 1, "archived": 0},
+				"tasks": [{"title": "Report", "status": "done", "energy": 3, "subtasks": [{"title": "Outline"}]}]}`,
+			want:   []string{"Report"},
+			status: []Status{StatusDone},
+		},
+		{
+			name:   "bare task list",
+			export: `[{"ID": 4, "Title": "Report", "Status": 4, "Priority": 3, "Tags": ["work"], "Pinned": true}, {"Title": "Slides"}]`,
+			want:   []string{"Report", "Slides"},
+			status: []Status{StatusDone, StatusTodo},
+		},
+		{name: "newer version", export: `{"format": "taskmanager-export", "version": 2, "tasks": []}`, err: ErrUnsupportedExportVersion},
+		{name: "other format", export: `{"format": "taskmanager-backup", "version": 1}`, err: ErrInvalidExport},
+		{name: "no version", export: `{"format": "taskmanager-export", "tasks": []}`, err: ErrInvalidExport},
+		{name: "not json", export: `title,status`, err: ErrInvalidExport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTaskManager()
+			tasks, err := tm.ImportJSON(strings.NewReader(tt.export))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			for i, task := range tasks {
+				if task.Status != tt.status[i] {
+					t.Errorf("Expected %s, got %s", tt.status[i], task.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestImportJSONSkipsBadRecords(t *testing.T) {
+	tm := NewTaskManager()
+	export := `{"format": "taskmanager-export", "version": 1, "tasks": [
+		{"title": "Good"},
+		{"title": "Bad status", "status": "someday"},
+		{"title": ""},
+		{"title": "Bad priority", "priority": "extreme"},
+		{"title": "Also good", "priority": "low"}
+	]}`
+	tasks, err := tm.ImportJSON(strings.NewReader(export))
+	if got := titles(tasks); !slices.Equal(got, []string{"Good", "Also good"}) {
+		t.Errorf("Expected %v, got %v", []string{"Good", "Also good"}, got)
+	}
+	var bulk *BulkError
+	if !errors.As(err, &bulk) {
+		t.Fatalf("Expected a *BulkError, got %v", err)
+	}
+	var indexes []int
+	for _, f := range bulk.Failures {
+		indexes = append(indexes, f.Index)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(indexes, want) {
+		t.Errorf("Expected failures at %v, got %v", want, bulk)
+	}
+	if !tm.CanUndo() {
+		t.Fatal("Expected the import to be undoable")
+	}
+	tm.Undo()
+	if got := tm.ListTasks(nil); len(got) != 0 {
+		t.Errorf("Expected the import undone as a whole, got %v", titles(got))
+	}
+}
+
+func TestImportJSONBadReferences(t *testing.T) {
+	tm := NewTaskManager()
+	export := `{"format": "taskmanager-export", "version": 1, "tasks": [
+		{"id": 7, "title": "First", "parent_id": 8},
+		{"id": 8, "title": "Second", "parent_id": 7},
+		{"id": 9, "title": "Third", "parent_id": 3, "depends_on": [9, 7]}
+	]}`
+	tasks, err := tm.ImportJSON(strings.NewReader(export))
+	var bulk *BulkError
+	if !errors.As(err, &bulk) || len(bulk.Failures) != 2 {
+		t.Fatalf("Expected the cycle and the self dependency reported, got %v", err)
+	}
+	if bulk.Failures[0].Index != 1 || !errors.Is(bulk.Failures[0].Err, ErrCyclicParent) || !errors.Is(bulk.Failures[1].Err, ErrSelfDependency) {
+		t.Errorf("Expected a cyclic parent at 1 and a self dependency at 2, got %v", bulk)
+	}
+	// The tasks are still imported, without the references that failed or
+	// point outside the export
+	if len(tasks) != 3 || tasks[0].ParentID != tasks[1].ID || tasks[1].ParentID != 0 || tasks[2].ParentID != 0 || !slices.Equal(tasks[2].DependsOn, []int{tasks[0].ID}) {
+		t.Errorf("Expected 3 tasks with only the valid references, got %+v", tasks)
+	}
+}
@@ -0,0 +1,400 @@
+package taskmanager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrUnsupportedKVLayout is returned for a key-value database laid out by a
+// newer version of the package
+var ErrUnsupportedKVLayout = errors.New("unsupported key-value layout")
+
+// KVDB is an embedded key-value database of buckets. The interfaces follow
+// bbolt's API method for method, so a bbolt database fits through an
+// adapter that only forwards calls and wraps the returned buckets and
+// cursors.
+type KVDB interface {
+	// View runs fn in a read-only transaction
+	View(fn func(tx KVTx) error) error
+	// Update runs fn in a read-write transaction, committed when fn
+	// returns nil and rolled back otherwise
+	Update(fn func(tx KVTx) error) error
+}
+
+// KVTx is a transaction of a KVDB
+type KVTx interface {
+	// Bucket returns the named bucket, or nil when there is none
+	Bucket(name []byte) KVBucket
+	// CreateBucketIfNotExists returns the named bucket, creating it first
+	// when there is none
+	CreateBucketIfNotExists(name []byte) (KVBucket, error)
+	// DeleteBucket removes the named bucket and its keys
+	DeleteBucket(name []byte) error
+	// ForEach calls fn for each bucket, in name order
+	ForEach(fn func(name []byte, b KVBucket) error) error
+}
+
+// KVBucket is a bucket of keys in a KVDB, kept in byte order. Slices it
+// returns are only valid during the transaction.
+type KVBucket interface {
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// ForEach calls fn for each key in order
+	ForEach(fn func(key, value []byte) error) error
+	Cursor() KVCursor
+}
+
+// KVCursor walks the keys of a bucket in order. Its methods return a nil
+// key past the last one.
+type KVCursor interface {
+	// Seek moves to the first key at or after key
+	Seek(key []byte) (k, v []byte)
+	Next() (k, v []byte)
+}
+
+// The buckets of a KVStore. Tasks are keyed by their ID as 8 big-endian
+// bytes, so they are kept in ID order, and each index holds a key made of
+// the indexed value, a zero byte and the task's ID for each task.
+var (
+	kvTasksBucket  = []byte("tasks")
+	kvTagsBucket   = []byte("tags")
+	kvStatusBucket = []byte("status")
+	kvMetaBucket   = []byte("meta")
+)
+
+// Keys of the meta bucket
+var (
+	kvLayoutKey = []byte("layout")
+	kvNextIDKey = []byte("next_id")
+)
+
+// kvMigrations bring a database up to date: the migration at index i moves
+// the layout from version i to version i+1. Later layouts append their
+// migration, calling RebuildKVIndexes when an index changes.
+var kvMigrations = []func(tx KVTx) error{
+	func(tx KVTx) error {
+		for _, name := range [][]byte{kvTasksBucket, kvTagsBucket, kvStatusBucket, kvMetaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// kvLayoutVersion is the version of the layout KVStore reads and writes
+var kvLayoutVersion = len(kvMigrations)
+
+// kvID encodes a task ID as a key
+func kvID(id int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+// kvIndexKey returns the key of a task in an index
+func kvIndexKey(value string, id int) []byte {
+	key := append([]byte(value), 0)
+	return binary.BigEndian.AppendUint64(key, uint64(id))
+}
+
+// kvIndexKeys returns the keys of a task in the tag and status indexes
+func kvIndexKeys(task *Task) (tags [][]byte, status []byte) {
+	for _, tag := range task.Tags {
+		tags = append(tags, kvIndexKey(tag, task.ID))
+	}
+	return tags, kvIndexKey(task.Status.String(), task.ID)
+}
+
+// MigrateKV brings the buckets of db to the layout this version of the
+// package uses, running the migrations it is missing in one transaction.
+// OpenKVStore calls it, so it is only needed to migrate a database ahead
+// of time.
+func MigrateKV(db KVDB) error {
+	return db.Update(func(tx KVTx) error {
+		version := 0
+		if meta := tx.Bucket(kvMetaBucket); meta != nil {
+			if value := meta.Get(kvLayoutKey); len(value) == 8 {
+				version = int(binary.BigEndian.Uint64(value))
+			}
+		}
+		if version > kvLayoutVersion {
+			return fmt.Errorf("%w: version %d", ErrUnsupportedKVLayout, version)
+		}
+		if version == kvLayoutVersion {
+			return nil
+		}
+		for _, migrate := range kvMigrations[version:] {
+			if err := migrate(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(kvMetaBucket).Put(kvLayoutKey, kvID(kvLayoutVersion))
+	})
+}
+
+// RebuildKVIndexes empties the tag and status buckets of db and fills them
+// again from the tasks, for migrations that change an index and to repair
+// indexes that went out of step
+func RebuildKVIndexes(db KVDB) error {
+	return db.Update(func(tx KVTx) error {
+		for _, name := range [][]byte{kvTagsBucket, kvStatusBucket} {
+			if tx.Bucket(name) != nil {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+		}
+		tags, err := tx.CreateBucketIfNotExists(kvTagsBucket)
+		if err != nil {
+			return err
+		}
+		statuses, err := tx.CreateBucketIfNotExists(kvStatusBucket)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(kvTasksBucket).ForEach(func(_, value []byte) error {
+			task := new(Task)
+			if err := json.Unmarshal(value, task); err != nil {
+				return fmt.Errorf("decoding task: %w", err)
+			}
+			return putKVIndexes(tags, statuses, task)
+		})
+	})
+}
+
+// putKVIndexes adds a task to the tag and status indexes
+func putKVIndexes(tags, statuses KVBucket, task *Task) error {
+	tagKeys, statusKey := kvIndexKeys(task)
+	for _, key := range tagKeys {
+		if err := tags.Put(key, []byte{}); err != nil {
+			return err
+		}
+	}
+	return statuses.Put(statusKey, []byte{})
+}
+
+// deleteKVIndexes removes a task from the tag and status indexes
+func deleteKVIndexes(tags, statuses KVBucket, task *Task) error {
+	tagKeys, statusKey := kvIndexKeys(task)
+	for _, key := range tagKeys {
+		if err := tags.Delete(key); err != nil {
+			return err
+		}
+	}
+	return statuses.Delete(statusKey)
+}
+
+// CompactKV copies every bucket of src into dst, which should be empty,
+// committing a transaction each time about txMaxSize bytes were copied, or
+// only once when txMaxSize is not positive. As with bbolt's Compact, the
+// copy leaves behind the free pages deletes left in src.
+func CompactKV(dst, src KVDB, txMaxSize int) error {
+	type entry struct{ bucket, key, value []byte }
+	var batch []entry
+	size := 0
+	flush := func() error {
+		err := dst.Update(func(tx KVTx) error {
+			for _, e := range batch {
+				b, err := tx.CreateBucketIfNotExists(e.bucket)
+				if err != nil {
+					return err
+				}
+				if e.key == nil {
+					continue
+				}
+				if err := b.Put(e.key, e.value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		batch, size = batch[:0], 0
+		return err
+	}
+	err := src.View(func(tx KVTx) error {
+		return tx.ForEach(func(name []byte, b KVBucket) error {
+			// An empty bucket is still copied
+			batch = append(batch, entry{bucket: bytes.Clone(name)})
+			return b.ForEach(func(key, value []byte) error {
+				batch = append(batch, entry{bytes.Clone(name), bytes.Clone(key), bytes.Clone(value)})
+				size += len(key) + len(value)
+				if txMaxSize > 0 && size >= txMaxSize {
+					return flush()
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// KVStore keeps the active tasks in memory, as a MemoryStore does, and
+// saves them to an embedded key-value database such as bbolt when given to
+// WithPersister as well, for deployments that want neither cgo nor a
+// database server. Built with the bbolt tag, OpenBoltDB opens a bbolt file
+// as its KVDB. Besides the tasks by ID, the database holds indexes of
+// the tags and statuses, which ByTag and ByStatus read without going
+// through the manager. It is an IncrementalPersister, so each save writes
+// only the tasks the manager changed since the last one, in one
+// transaction. As with FileStore, only the active tasks are saved.
+type KVStore struct {
+	*MemoryStore
+	db KVDB
+
+	// mu keeps saves from overlapping
+	mu sync.Mutex
+	// saved holds the IDs of the saved tasks
+	saved map[int]struct{}
+}
+
+// OpenKVStore migrates the buckets of db to the current layout and loads
+// the tasks saved there. The caller closes db after the store.
+func OpenKVStore(db KVDB) (*KVStore, error) {
+	if err := MigrateKV(db); err != nil {
+		return nil, err
+	}
+	s := &KVStore{MemoryStore: NewMemoryStore(), db: db, saved: make(map[int]struct{})}
+	var tasks []*Task
+	nextID := 1
+	err := db.View(func(tx KVTx) error {
+		if value := tx.Bucket(kvMetaBucket).Get(kvNextIDKey); len(value) == 8 {
+			nextID = int(binary.BigEndian.Uint64(value))
+		}
+		return tx.Bucket(kvTasksBucket).ForEach(func(_, value []byte) error {
+			task := new(Task)
+			if err := json.Unmarshal(value, task); err != nil {
+				return fmt.Errorf("decoding task: %w", err)
+			}
+			tasks = append(tasks, task)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Storing the tasks in creation order keeps the MemoryStore ordered
+	slices.SortFunc(tasks, func(a, b *Task) int {
+		return compareCreatedKeys(createdKey{at: a.CreatedAt, id: a.ID}, createdKey{at: b.CreatedAt, id: b.ID})
+	})
+	for _, task := range tasks {
+		s.Put(task)
+		s.saved[task.ID] = struct{}{}
+		nextID = max(nextID, task.ID+1)
+	}
+	s.seq = NewSequenceIDs(nextID, 1)
+	return s, nil
+}
+
+// Persist saves every task of the snapshot and deletes the saved tasks it
+// no longer holds, in one transaction
+func (s *KVStore) Persist(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persist(fullChanges(snapshot, s.saved))
+}
+
+// PersistChanges saves the changed tasks and deletes those that are gone,
+// in one transaction
+func (s *KVStore) PersistChanges(changes *Changes) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persist(changes)
+}
+
+// persist writes changes in one transaction
+func (s *KVStore) persist(changes *Changes) error {
+	err := s.db.Update(func(tx KVTx) error {
+		tasks, tags, statuses := tx.Bucket(kvTasksBucket), tx.Bucket(kvTagsBucket), tx.Bucket(kvStatusBucket)
+		// unindex removes the saved copy of a task from the indexes
+		unindex := func(key []byte) error {
+			value := tasks.Get(key)
+			if value == nil {
+				return nil
+			}
+			old := new(Task)
+			if err := json.Unmarshal(value, old); err != nil {
+				return fmt.Errorf("decoding task: %w", err)
+			}
+			return deleteKVIndexes(tags, statuses, old)
+		}
+		for _, task := range changes.Put {
+			data, err := json.Marshal(task)
+			if err != nil {
+				return err
+			}
+			key := kvID(task.ID)
+			if err := unindex(key); err != nil {
+				return err
+			}
+			if err := tasks.Put(key, data); err != nil {
+				return err
+			}
+			if err := putKVIndexes(tags, statuses, task); err != nil {
+				return err
+			}
+		}
+		for _, id := range changes.Delete {
+			key := kvID(id)
+			if err := unindex(key); err != nil {
+				return err
+			}
+			if err := tasks.Delete(key); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(kvMetaBucket).Put(kvNextIDKey, kvID(s.seq.peek()))
+	})
+	if err != nil {
+		return err
+	}
+	for _, task := range changes.Put {
+		s.saved[task.ID] = struct{}{}
+	}
+	for _, id := range changes.Delete {
+		delete(s.saved, id)
+	}
+	return nil
+}
+
+// ByTag returns the saved tasks carrying the tag, in ID order, read from
+// the tag index. Changes not saved yet are not seen.
+func (s *KVStore) ByTag(tag string) ([]*Task, error) {
+	return s.byIndex(kvTagsBucket, tag)
+}
+
+// ByStatus returns the saved tasks with the status, in ID order, read from
+// the status index. Changes not saved yet are not seen.
+func (s *KVStore) ByStatus(status Status) ([]*Task, error) {
+	return s.byIndex(kvStatusBucket, status.String())
+}
+
+// byIndex returns the saved tasks an index holds for a value
+func (s *KVStore) byIndex(bucket []byte, value string) ([]*Task, error) {
+	var found []*Task
+	prefix := append([]byte(value), 0)
+	err := s.db.View(func(tx KVTx) error {
+		tasks := tx.Bucket(kvTasksBucket)
+		c := tx.Bucket(bucket).Cursor()
+		for key, _ := c.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = c.Next() {
+			data := tasks.Get(key[len(prefix):])
+			if data == nil {
+				continue
+			}
+			task := new(Task)
+			if err := json.Unmarshal(data, task); err != nil {
+				return fmt.Errorf("decoding task: %w", err)
+			}
+			found = append(found, task)
+		}
+		return nil
+	})
+	return found, err
+}
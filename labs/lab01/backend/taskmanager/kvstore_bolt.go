@@ -0,0 +1,100 @@
+//go:build bbolt
+
+package taskmanager
+
+import (
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltDB is a KVDB over a bbolt database file, for opening a KVStore on
+// disk. It is only built with the bbolt tag, so builds without it do not
+// depend on bbolt:
+//
+//	db, err := OpenBoltDB("tasks.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//	store, err := OpenKVStore(db)
+type BoltDB struct {
+	db *bolt.DB
+}
+
+// OpenBoltDB opens the bbolt database at path, creating it when there is
+// none yet. bbolt locks the file, so opening it from a second process
+// fails after waiting a second.
+func OpenBoltDB(path string) (*BoltDB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &BoltDB{db: db}, nil
+}
+
+// NewBoltDB returns a KVDB over an open bbolt database, which the caller
+// closes
+func NewBoltDB(db *bolt.DB) *BoltDB {
+	return &BoltDB{db: db}
+}
+
+// View runs fn in a read-only bbolt transaction
+func (b *BoltDB) View(fn func(tx KVTx) error) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	})
+}
+
+// Update runs fn in a read-write bbolt transaction
+func (b *BoltDB) Update(fn func(tx KVTx) error) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	})
+}
+
+// Close closes the bbolt database
+func (b *BoltDB) Close() error {
+	return b.db.Close()
+}
+
+// boltTx is a KVTx over a bbolt transaction
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Bucket(name []byte) KVBucket {
+	// A nil *bolt.Bucket must come back as a nil interface
+	if b := t.tx.Bucket(name); b != nil {
+		return boltBucket{b}
+	}
+	return nil
+}
+
+func (t boltTx) CreateBucketIfNotExists(name []byte) (KVBucket, error) {
+	b, err := t.tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return boltBucket{b}, nil
+}
+
+func (t boltTx) DeleteBucket(name []byte) error {
+	return t.tx.DeleteBucket(name)
+}
+
+func (t boltTx) ForEach(fn func(name []byte, b KVBucket) error) error {
+	return t.tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return fn(name, boltBucket{b})
+	})
+}
+
+// boltBucket is a KVBucket over a bbolt bucket, whose cursors are
+// KVCursors as they are
+type boltBucket struct {
+	*bolt.Bucket
+}
+
+func (b boltBucket) Cursor() KVCursor {
+	return b.Bucket.Cursor()
+}
@@ -0,0 +1,97 @@
+//go:build bbolt
+
+// The tests in this file run KVStore against a bbolt database file:
+//
+//	go test -tags bbolt ./taskmanager
+package taskmanager
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// openBolt opens a new bbolt database file for the test
+func openBolt(t *testing.T, path string) *BoltDB {
+	t.Helper()
+	db, err := OpenBoltDB(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return db
+}
+
+func TestBoltKVStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	db := openBolt(t, path)
+	_, s := openKVManager(t, db)
+	first, _ := s.AddTask("Write report", "", WithTags("work", "urgent"))
+	second, _ := s.AddTask("Removed", "", WithTags("work"))
+	s.DeleteTask(second.ID)
+	s.AddTask("Read", "", WithTags("home"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The tasks and indexes are read back from the file
+	db = openBolt(t, path)
+	defer db.Close()
+	store, reopened := openKVManager(t, db)
+	defer reopened.Close()
+	want, _ := s.GetTask(first.ID)
+	if got, err := reopened.GetTask(first.ID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+	if task, _ := reopened.AddTask("Later", ""); task.ID != 4 {
+		t.Errorf("Expected IDs to carry on at 4, got %d", task.ID)
+	}
+	reopened.UpdateTaskFields(first.ID, TaskPatch{Tags: &[]string{"work"}, Status: ptr(StatusDone)})
+	if err := reopened.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tests := []struct {
+		name  string
+		query func() ([]*Task, error)
+		want  []string
+	}{
+		{"kept tag", func() ([]*Task, error) { return store.ByTag("work") }, []string{"Write report"}},
+		{"removed tag", func() ([]*Task, error) { return store.ByTag("urgent") }, nil},
+		{"new status", func() ([]*Task, error) { return store.ByStatus(StatusDone) }, []string{"Write report"}},
+		{"old status", func() ([]*Task, error) { return store.ByStatus(StatusTodo) }, []string{"Read", "Later"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := tt.query()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// A compacted copy opens as a store holding the same tasks
+	dst := openBolt(t, filepath.Join(t.TempDir(), "compacted.db"))
+	defer dst.Close()
+	if err := CompactKV(dst, db, 1024); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	compacted, err := OpenKVStore(dst)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if compacted.Len() != store.Len() {
+		t.Errorf("Expected %d tasks in the copy, got %d", store.Len(), compacted.Len())
+	}
+	if err := RebuildKVIndexes(dst); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tasks, _ := compacted.ByTag("work"); len(tasks) != 1 {
+		t.Errorf("Expected the rebuilt tag index to find 1 task, got %d", len(tasks))
+	}
+}
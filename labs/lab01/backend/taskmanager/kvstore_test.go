@@ -0,0 +1,284 @@
+package taskmanager
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memKV is a KVDB held in memory, standing in for bbolt
+type memKV struct {
+	buckets map[string]map[string][]byte
+	// updates counts the committed read-write transactions
+	updates int
+}
+
+func newMemKV() *memKV {
+	return &memKV{buckets: make(map[string]map[string][]byte)}
+}
+
+func (db *memKV) View(fn func(tx KVTx) error) error {
+	return fn(memKVTx{db.buckets})
+}
+
+func (db *memKV) Update(fn func(tx KVTx) error) error {
+	buckets := make(map[string]map[string][]byte, len(db.buckets))
+	for name, b := range db.buckets {
+		buckets[name] = maps.Clone(b)
+	}
+	if err := fn(memKVTx{buckets}); err != nil {
+		return err
+	}
+	db.buckets = buckets
+	db.updates++
+	return nil
+}
+
+type memKVTx struct {
+	buckets map[string]map[string][]byte
+}
+
+func (tx memKVTx) Bucket(name []byte) KVBucket {
+	if b, ok := tx.buckets[string(name)]; ok {
+		return memBucket(b)
+	}
+	return nil
+}
+
+func (tx memKVTx) CreateBucketIfNotExists(name []byte) (KVBucket, error) {
+	if _, ok := tx.buckets[string(name)]; !ok {
+		tx.buckets[string(name)] = make(map[string][]byte)
+	}
+	return memBucket(tx.buckets[string(name)]), nil
+}
+
+func (tx memKVTx) DeleteBucket(name []byte) error {
+	delete(tx.buckets, string(name))
+	return nil
+}
+
+func (tx memKVTx) ForEach(fn func(name []byte, b KVBucket) error) error {
+	for _, name := range slices.Sorted(maps.Keys(tx.buckets)) {
+		if err := fn([]byte(name), memBucket(tx.buckets[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memBucket map[string][]byte
+
+func (b memBucket) Get(key []byte) []byte { return b[string(key)] }
+
+func (b memBucket) Put(key, value []byte) error {
+	b[string(key)] = slices.Clone(value)
+	return nil
+}
+
+func (b memBucket) Delete(key []byte) error {
+	delete(b, string(key))
+	return nil
+}
+
+func (b memBucket) ForEach(fn func(key, value []byte) error) error {
+	for _, key := range slices.Sorted(maps.Keys(b)) {
+		if err := fn([]byte(key), b[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b memBucket) Cursor() KVCursor {
+	return &memCursor{bucket: b, keys: slices.Sorted(maps.Keys(b))}
+}
+
+type memCursor struct {
+	bucket memBucket
+	keys   []string
+	i      int
+}
+
+func (c *memCursor) Seek(key []byte) ([]byte, []byte) {
+	c.i, _ = slices.BinarySearch(c.keys, string(key))
+	return c.current()
+}
+
+func (c *memCursor) Next() ([]byte, []byte) {
+	c.i++
+	return c.current()
+}
+
+func (c *memCursor) current() ([]byte, []byte) {
+	if c.i >= len(c.keys) {
+		return nil, nil
+	}
+	return []byte(c.keys[c.i]), c.bucket[c.keys[c.i]]
+}
+
+// openKVManager opens a KVStore over db and a manager saving to it, with a
+// fixed clock so tasks compare equal once loaded
+func openKVManager(t *testing.T, db KVDB) (*KVStore, *SafeTaskManager) {
+	t.Helper()
+	store, err := OpenKVStore(db)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	return store, NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{Interval: time.Hour}), WithClock(clock))
+}
+
+func TestKVStore(t *testing.T) {
+	db := newMemKV()
+	_, s := openKVManager(t, db)
+	first, _ := s.AddTask("Write report", "", WithTags("work", "urgent"))
+	second, _ := s.AddTask("Removed", "", WithTags("work"))
+	s.DeleteTask(second.ID)
+	s.AddTask("Read", "", WithTags("home"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := len(db.buckets["tasks"]); n != 2 {
+		t.Errorf("Expected 2 saved tasks, got %d", n)
+	}
+
+	store, reopened := openKVManager(t, db)
+	defer reopened.Close()
+	want, _ := s.GetTask(first.ID)
+	if got, err := reopened.GetTask(first.ID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+	if got := titles(reopened.ListTasks(nil, FilterByAnyTag("work"))); !slices.Equal(got, []string{"Write report"}) {
+		t.Errorf("Expected the loaded tasks to be indexed, got %v", got)
+	}
+	if task, _ := reopened.AddTask("Later", ""); task.ID != 4 {
+		t.Errorf("Expected IDs to carry on at 4, got %d", task.ID)
+	}
+
+	// Saving again only writes the tasks that changed, moving them in the
+	// indexes
+	reopened.UpdateTaskFields(first.ID, TaskPatch{Tags: &[]string{"work"}, Status: ptr(StatusDone)})
+	before := maps.Clone(db.buckets["tasks"])
+	if err := reopened.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	changed := 0
+	for key, value := range db.buckets["tasks"] {
+		if !reflect.DeepEqual(before[key], value) {
+			changed++
+		}
+	}
+	if changed != 2 {
+		t.Errorf("Expected only the 2 changed tasks written, got %d", changed)
+	}
+	tests := []struct {
+		name  string
+		query func() ([]*Task, error)
+		want  []string
+	}{
+		{"kept tag", func() ([]*Task, error) { return store.ByTag("work") }, []string{"Write report"}},
+		{"removed tag", func() ([]*Task, error) { return store.ByTag("urgent") }, nil},
+		{"new status", func() ([]*Task, error) { return store.ByStatus(StatusDone) }, []string{"Write report"}},
+		{"old status", func() ([]*Task, error) { return store.ByStatus(StatusTodo) }, []string{"Read", "Later"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := tt.query()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	reopened.DeleteTask(first.ID)
+	reopened.Flush()
+	if tasks, _ := store.ByTag("work"); len(tasks) != 0 || len(db.buckets["tags"]) != 1 {
+		t.Errorf("Expected a deleted task to leave the indexes, got %v", db.buckets["tags"])
+	}
+}
+
+func TestMigrateKV(t *testing.T) {
+	db := newMemKV()
+	if err := MigrateKV(db); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, name := range []string{"tasks", "tags", "status", "meta"} {
+		if _, ok := db.buckets[name]; !ok {
+			t.Errorf("Expected the %s bucket to be created", name)
+		}
+	}
+	if got := db.buckets["meta"]["layout"]; !reflect.DeepEqual(got, kvID(kvLayoutVersion)) {
+		t.Errorf("Expected layout %d to be recorded, got %v", kvLayoutVersion, got)
+	}
+	// Migrating an up to date database keeps what it holds
+	db.buckets["tasks"]["key"] = []byte("value")
+	if err := MigrateKV(db); err != nil || db.buckets["tasks"]["key"] == nil {
+		t.Errorf("Expected the buckets kept, got %v", err)
+	}
+
+	db.buckets["meta"]["layout"] = kvID(kvLayoutVersion + 1)
+	if _, err := OpenKVStore(db); !errors.Is(err, ErrUnsupportedKVLayout) {
+		t.Errorf("Expected ErrUnsupportedKVLayout, got %v", err)
+	}
+}
+
+func TestRebuildKVIndexes(t *testing.T) {
+	db := newMemKV()
+	store, s := openKVManager(t, db)
+	s.AddTask("Write report", "", WithTags("work"))
+	s.Close()
+	clear(db.buckets["tags"])
+	delete(db.buckets, "status")
+
+	if err := RebuildKVIndexes(db); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tasks, _ := store.ByTag("work"); len(tasks) != 1 {
+		t.Errorf("Expected the tag index rebuilt, got %d tasks", len(tasks))
+	}
+	if tasks, _ := store.ByStatus(StatusTodo); len(tasks) != 1 {
+		t.Errorf("Expected the status index rebuilt, got %d tasks", len(tasks))
+	}
+}
+
+func TestCompactKV(t *testing.T) {
+	src := newMemKV()
+	_, s := openKVManager(t, src)
+	for i := range 20 {
+		s.AddTask(strings.Repeat("x", i+1), "", WithTags("work"))
+	}
+	s.Close()
+
+	tests := []struct {
+		name      string
+		txMaxSize int
+		updates   int
+	}{
+		{"one transaction", 0, 1},
+		{"batched", 1024, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newMemKV()
+			if err := CompactKV(dst, src, tt.txMaxSize); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst.buckets, src.buckets) {
+				t.Error("Expected the copy to hold every bucket and key")
+			}
+			if dst.updates < tt.updates {
+				t.Errorf("Expected at least %d transactions, got %d", tt.updates, dst.updates)
+			}
+			if tt.txMaxSize == 0 && dst.updates != 1 {
+				t.Errorf("Expected one transaction, got %d", dst.updates)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTooManyTasks is returned when adding tasks would take the manager
+	// past the limit set by WithMaxTasks
+	ErrTooManyTasks = errors.New("too many tasks")
+	// ErrTitleTooLong is returned when a title is longer than the limit set
+	// by WithMaxTitleLength
+	ErrTitleTooLong = errors.New("task title too long")
+	// ErrDescriptionTooLong is returned when a description is longer than
+	// the limit set by WithMaxDescriptionLength
+	ErrDescriptionTooLong = errors.New("task description too long")
+)
+
+// WithMaxTasks limits how many tasks the manager holds. Tasks in the trash
+// count until they are purged, since they are still in memory. Zero, the
+// default, sets no limit.
+func WithMaxTasks(n int) Option {
+	return func(tm *TaskManager) {
+		tm.maxTasks = max(n, 0)
+	}
+}
+
+// WithMaxTitleLength limits task titles to the given number of bytes. Zero,
+// the default, sets no limit.
+func WithMaxTitleLength(n int) Option {
+	return func(tm *TaskManager) {
+		tm.maxTitleLength = max(n, 0)
+	}
+}
+
+// WithMaxDescriptionLength limits task descriptions to the given number of
+// bytes, measured after sanitizing. Zero, the default, sets no limit.
+func WithMaxDescriptionLength(n int) Option {
+	return func(tm *TaskManager) {
+		tm.maxDescriptionLength = max(n, 0)
+	}
+}
+
+// reserve checks that n more tasks fit under the task limit
+func (tm *TaskManager) reserve(n int) error {
+	if tm.maxTasks == 0 || tm.tasks.Len()+len(tm.trash)+n <= tm.maxTasks {
+		return nil
+	}
+	return fmt.Errorf("%w: the limit is %d", ErrTooManyTasks, tm.maxTasks)
+}
+
+// validateLengths checks a task's title and description against the limits
+func (tm *TaskManager) validateLengths(task *Task) error {
+	if tm.maxTitleLength > 0 && len(task.Title) > tm.maxTitleLength {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrTitleTooLong, len(task.Title), tm.maxTitleLength)
+	}
+	if tm.maxDescriptionLength > 0 && len(task.Description) > tm.maxDescriptionLength {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrDescriptionTooLong, len(task.Description), tm.maxDescriptionLength)
+	}
+	return nil
+}
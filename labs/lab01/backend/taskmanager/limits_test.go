@@ -0,0 +1,150 @@
+package taskmanager
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxTasks(t *testing.T) {
+	tm := NewTaskManager(WithMaxTasks(3))
+	first := mustAddTask(t, tm, "First")
+	mustAddTask(t, tm, "Second")
+	third := mustAddTask(t, tm, "Third")
+
+	adds := []struct {
+		name string
+		run  func() error
+	}{
+		{"add", func() error {
+			_, err := tm.AddTask("Fourth", "")
+			return err
+		}},
+		{"subtask", func() error {
+			_, err := tm.AddSubtask(first.ID, "Fourth", "")
+			return err
+		}},
+		{"clone", func() error {
+			_, err := tm.CloneTask(first.ID, CloneOptions{})
+			return err
+		}},
+		{"batch", func() error {
+			_, err := tm.AddTasksBatch([]TaskInput{{Title: "Fourth"}})
+			return err
+		}},
+	}
+	for _, add := range adds {
+		if err := add.run(); !errors.Is(err, ErrTooManyTasks) {
+			t.Errorf("%s: expected ErrTooManyTasks, got %v", add.name, err)
+		}
+	}
+	if tm.tasks.Len() != 3 {
+		t.Errorf("Expected 3 tasks, got %d", tm.tasks.Len())
+	}
+
+	// Trashed tasks count until they are purged
+	if err := tm.DeleteTask(third.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.AddTask("Fourth", ""); !errors.Is(err, ErrTooManyTasks) {
+		t.Errorf("Expected ErrTooManyTasks with a task in the trash, got %v", err)
+	}
+	tm.PurgeTrash()
+	if _, err := tm.AddTask("Fourth", ""); err != nil {
+		t.Errorf("Expected room after purging, got %v", err)
+	}
+}
+
+func TestMaxTasksBulk(t *testing.T) {
+	inputs := []TaskInput{{Title: "Second"}, {Title: "Third"}, {Title: "Fourth"}}
+	newManager := func() *TaskManager {
+		tm := NewTaskManager(WithMaxTasks(3))
+		mustAddTask(t, tm, "First")
+		return tm
+	}
+
+	tm := newManager()
+	if _, err := tm.AddTasksBatch(inputs); !errors.Is(err, ErrTooManyTasks) || tm.tasks.Len() != 1 {
+		t.Errorf("Expected the whole batch to be rejected, got %v with %d tasks", err, tm.tasks.Len())
+	}
+
+	tests := []struct {
+		name string
+		add  func(*TaskManager, []TaskInput) ([]*Task, error)
+	}{
+		{"AddTasks", (*TaskManager).AddTasks},
+		{"ImportTasks", (*TaskManager).ImportTasks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := tt.add(newManager(), inputs)
+			var bulk *BulkError
+			if !errors.As(err, &bulk) || len(bulk.Failures) != 1 || bulk.Failures[0].Index != 2 || !errors.Is(bulk.Failures[0].Err, ErrTooManyTasks) {
+				t.Fatalf("Expected the last input to fail with ErrTooManyTasks, got %v", err)
+			}
+			if tasks[0] == nil || tasks[1] == nil || tasks[2] != nil {
+				t.Errorf("Expected the inputs that fit to be added, got %v", tasks)
+			}
+		})
+	}
+}
+
+func TestMaxTasksRecurrence(t *testing.T) {
+	tm := NewTaskManager(WithMaxTasks(1))
+	task := mustAddTask(t, tm, "Water plants", WithDueDate(time.Now().Add(time.Hour)), WithRecurrence(Recurrence{Frequency: Daily}))
+	history, undo := len(storedTask(tm, task.ID).History), len(tm.undoStack)
+	complete := map[string]func() error{
+		"CompleteTask":     func() error { return tm.CompleteTask(task.ID, "Watered") },
+		"Transition":       func() error { return tm.Transition(task.ID, StatusDone) },
+		"UpdateTask":       func() error { return tm.UpdateTask(task.ID, "Water plants", "", true) },
+		"UpdateTaskFields": func() error { return tm.UpdateTaskFields(task.ID, TaskPatch{Status: ptr(StatusDone)}) },
+	}
+	for name, fn := range complete {
+		t.Run(name, func(t *testing.T) {
+			if err := fn(); !errors.Is(err, ErrTooManyTasks) {
+				t.Errorf("Expected ErrTooManyTasks, got %v", err)
+			}
+			// The task is left as it was when the next occurrence does not fit
+			stored := storedTask(tm, task.ID)
+			if stored.Recurrence == nil || stored.Status != StatusTodo || stored.CompletedAt != nil || len(stored.History) != history {
+				t.Errorf("Expected the task unchanged, got %+v", stored)
+			}
+			if len(tm.undoStack) != undo || tm.tasks.Len() != 1 {
+				t.Errorf("Expected no operation recorded and one task, got %d tasks", tm.tasks.Len())
+			}
+		})
+	}
+}
+
+func TestLengthLimits(t *testing.T) {
+	tm := NewTaskManager(WithMaxTitleLength(10), WithMaxDescriptionLength(20))
+	task := mustAddTask(t, tm, "Short")
+
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		expected    error
+	}{
+		{"fits", "Ten bytes!", strings.Repeat("d", 20), nil},
+		{"surrounding space is trimmed", "  Ten bytes!  ", "", nil},
+		{"long title", "Eleven byte", "", ErrTitleTooLong},
+		{"long description", "Short", strings.Repeat("d", 21), ErrDescriptionTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tm.AddTask(tt.title, tt.description); !errors.Is(err, tt.expected) {
+				t.Errorf("AddTask: expected %v, got %v", tt.expected, err)
+			}
+			if err := tm.UpdateTask(task.ID, tt.title, tt.description, false); !errors.Is(err, tt.expected) {
+				t.Errorf("UpdateTask: expected %v, got %v", tt.expected, err)
+			}
+			_, err := tm.ImportTasks([]TaskInput{{Title: tt.title, Description: tt.description}})
+			var bulk *BulkError
+			if tt.expected == nil && err != nil || tt.expected != nil && (!errors.As(err, &bulk) || !errors.Is(bulk.Failures[0].Err, tt.expected)) {
+				t.Errorf("ImportTasks: expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package taskmanager
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrSelfLink is returned when a task is linked to itself
+	ErrSelfLink = errors.New("task cannot be linked to itself")
+	// ErrInvalidLinkType is returned when a link type is not one of the known values
+	ErrInvalidLinkType = errors.New("invalid link type")
+)
+
+// LinkType is the kind of relation between two linked tasks. Every type
+// has an inverse that describes the same link seen from the other task.
+type LinkType int
+
+const (
+	// LinkRelatesTo is a general cross-reference; it is its own inverse
+	LinkRelatesTo LinkType = iota + 1
+	// LinkDuplicates marks a task as a duplicate of the linked one
+	LinkDuplicates
+	// LinkDuplicatedBy is the inverse of LinkDuplicates
+	LinkDuplicatedBy
+	// LinkCausedBy marks a task as caused by the linked one
+	LinkCausedBy
+	// LinkCauses is the inverse of LinkCausedBy
+	LinkCauses
+)
+
+// TaskLink is a relation from a task to another task
+type TaskLink struct {
+	Type   LinkType
+	TaskID int
+}
+
+// Valid reports whether the link type is one of the known values
+func (l LinkType) Valid() bool {
+	return l >= LinkRelatesTo && l <= LinkCauses
+}
+
+// Inverse returns the type of the same link seen from the linked task
+func (l LinkType) Inverse() LinkType {
+	switch l {
+	case LinkDuplicates:
+		return LinkDuplicatedBy
+	case LinkDuplicatedBy:
+		return LinkDuplicates
+	case LinkCausedBy:
+		return LinkCauses
+	case LinkCauses:
+		return LinkCausedBy
+	default:
+		return l
+	}
+}
+
+// String returns the human-readable name of the link type
+func (l LinkType) String() string {
+	switch l {
+	case LinkRelatesTo:
+		return "relates to"
+	case LinkDuplicates:
+		return "duplicates"
+	case LinkDuplicatedBy:
+		return "duplicated by"
+	case LinkCausedBy:
+		return "caused by"
+	case LinkCauses:
+		return "causes"
+	default:
+		return "unknown"
+	}
+}
+
+// LinkTasks records a typed relation from one task to another. Linking
+// tasks that are already linked the same way, in either direction, does
+// nothing.
+func (tm *TaskManager) LinkTasks(fromID, toID int, linkType LinkType) error {
+	from, err := tm.lookup(fromID)
+	if err != nil {
+		return err
+	}
+	if _, err := tm.lookup(toID); err != nil {
+		return err
+	}
+	if fromID == toID {
+		return ErrSelfLink
+	}
+	if !linkType.Valid() {
+		return ErrInvalidLinkType
+	}
+	if owner, _ := tm.findLink(fromID, toID, linkType); owner != nil {
+		return nil
+	}
+	tm.update(from, func() {
+		from.Related = append(from.Related, TaskLink{Type: linkType, TaskID: toID})
+	})
+	return nil
+}
+
+// UnlinkTasks removes a relation between two tasks, whichever of them it
+// was recorded on
+func (tm *TaskManager) UnlinkTasks(fromID, toID int, linkType LinkType) error {
+	if _, err := tm.lookup(fromID); err != nil {
+		return err
+	}
+	owner, link := tm.findLink(fromID, toID, linkType)
+	if owner == nil {
+		return nil
+	}
+	tm.update(owner, func() {
+		owner.Related = slices.DeleteFunc(owner.Related, func(l TaskLink) bool {
+			return l == link
+		})
+	})
+	return nil
+}
+
+// ListRelated returns every relation of a task to other active tasks, both
+// the ones recorded on it and the ones recorded on the other task, seen
+// from this task. Links are ordered by type, then by task ID.
+func (tm *TaskManager) ListRelated(id int) ([]TaskLink, error) {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	var links []TaskLink
+	for _, link := range task.Related {
+		if _, ok := tm.tasks.Get(link.TaskID); ok {
+			links = append(links, link)
+		}
+	}
+	for other := range tm.tasks.List() {
+		for _, link := range other.Related {
+			if link.TaskID == id {
+				links = append(links, TaskLink{Type: link.Type.Inverse(), TaskID: other.ID})
+			}
+		}
+	}
+	slices.SortFunc(links, func(a, b TaskLink) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.TaskID, b.TaskID))
+	})
+	return slices.Compact(links), nil
+}
+
+// findLink returns the task a relation is recorded on and the link as
+// stored there, or nil if the tasks are not linked that way
+func (tm *TaskManager) findLink(fromID, toID int, linkType LinkType) (*Task, TaskLink) {
+	if from, ok := tm.tasks.Get(fromID); ok {
+		link := TaskLink{Type: linkType, TaskID: toID}
+		if slices.Contains(from.Related, link) {
+			return from, link
+		}
+	}
+	if to, ok := tm.tasks.Get(toID); ok {
+		link := TaskLink{Type: linkType.Inverse(), TaskID: fromID}
+		if slices.Contains(to.Related, link) {
+			return to, link
+		}
+	}
+	return nil, TaskLink{}
+}
+
+// removeRelated drops every link to a task that is being purged
+func (tm *TaskManager) removeRelated(id int) {
+	for task := range tm.everyTask() {
+		if !slices.ContainsFunc(task.Related, func(l TaskLink) bool { return l.TaskID == id }) {
+			continue
+		}
+		tm.update(task, func() {
+			task.Related = slices.DeleteFunc(task.Related, func(l TaskLink) bool {
+				return l.TaskID == id
+			})
+		})
+	}
+}
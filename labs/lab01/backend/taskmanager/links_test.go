@@ -0,0 +1,83 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLinkTasks(t *testing.T) {
+	tm := NewTaskManager()
+	a := mustAddTask(t, tm, "Crash on start")
+	b := mustAddTask(t, tm, "App crashes")
+
+	tests := []struct {
+		name        string
+		fromID      int
+		toID        int
+		linkType    LinkType
+		expectError error
+	}{
+		{name: "valid link", fromID: b.ID, toID: a.ID, linkType: LinkDuplicates},
+		{name: "same link again", fromID: b.ID, toID: a.ID, linkType: LinkDuplicates},
+		{name: "same link from the other side", fromID: a.ID, toID: b.ID, linkType: LinkDuplicatedBy},
+		{name: "self link", fromID: a.ID, toID: a.ID, linkType: LinkRelatesTo, expectError: ErrSelfLink},
+		{name: "invalid type", fromID: a.ID, toID: b.ID, linkType: LinkType(42), expectError: ErrInvalidLinkType},
+		{name: "missing task", fromID: a.ID, toID: 999, linkType: LinkRelatesTo, expectError: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tm.LinkTasks(tt.fromID, tt.toID, tt.linkType); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if len(a.Related)+len(b.Related) != 1 {
+		t.Errorf("Expected a single stored link, got %v and %v", a.Related, b.Related)
+	}
+}
+
+func TestListRelated(t *testing.T) {
+	tm := NewTaskManager()
+	bug := mustAddTask(t, tm, "Bug")
+	dup := mustAddTask(t, tm, "Duplicate")
+	cause := mustAddTask(t, tm, "Refactor")
+
+	for _, l := range []struct {
+		from, to int
+		linkType LinkType
+	}{
+		{dup.ID, bug.ID, LinkDuplicates},
+		{bug.ID, cause.ID, LinkCausedBy},
+		{cause.ID, dup.ID, LinkRelatesTo},
+	} {
+		if err := tm.LinkTasks(l.from, l.to, l.linkType); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	links, err := tm.ListRelated(bug.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []TaskLink{{LinkDuplicatedBy, dup.ID}, {LinkCausedBy, cause.ID}}
+	if !slices.Equal(links, expected) {
+		t.Errorf("Expected %v, got %v", expected, links)
+	}
+
+	if err := tm.UnlinkTasks(bug.ID, dup.ID, LinkDuplicatedBy); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if links, _ := tm.ListRelated(dup.ID); !slices.Equal(links, []TaskLink{{LinkRelatesTo, cause.ID}}) {
+		t.Errorf("Expected only the relates-to link to remain, got %v", links)
+	}
+
+	if err := tm.DeleteTask(cause.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tm.PurgeTrash()
+	if len(bug.Related) != 0 {
+		t.Errorf("Expected purging to drop links to the task, got %v", bug.Related)
+	}
+}
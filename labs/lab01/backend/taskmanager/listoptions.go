@@ -0,0 +1,269 @@
+package taskmanager
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTimeRange is returned when a time range ends before it starts
+var ErrInvalidTimeRange = errors.New("invalid time range")
+
+// TimeRange selects the times from From up to but excluding To. A zero
+// bound leaves that side of the range open.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// IsZero reports whether both bounds are open
+func (r TimeRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// Contains reports whether t falls within the range
+func (r TimeRange) Contains(t time.Time) bool {
+	if !r.From.IsZero() && t.Before(r.From) {
+		return false
+	}
+	return r.To.IsZero() || t.Before(r.To)
+}
+
+// Validate checks that the range does not end before it starts
+func (r TimeRange) Validate() error {
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// ListOptions describes a listing as a single value, so new filters can be
+// added without changing the signature of every caller. The zero value
+// lists every task that is neither archived nor snoozed, oldest first.
+type ListOptions struct {
+	// Done keeps only done or only open tasks when set
+	Done *bool
+	// Statuses keeps tasks with any of the given statuses
+	Statuses []Status
+	// Priorities keeps tasks with any of the given priorities
+	Priorities []Priority
+	// Tags keeps tasks carrying every one of the given tags
+	Tags []string
+	// AnyTags keeps tasks carrying at least one of the given tags
+	AnyTags []string
+	// AssigneeID keeps tasks assigned to the given assignee; an empty
+	// string keeps unassigned tasks
+	AssigneeID *string
+	// ProjectID keeps tasks in the given project; zero keeps tasks outside
+	// any project
+	ProjectID *int
+	// Due keeps tasks with a due date inside the range
+	Due TimeRange
+	// Created keeps tasks created inside the range
+	Created TimeRange
+	// Completed keeps done tasks completed inside the range
+	Completed TimeRange
+	// Query keeps tasks whose title or description contains the text,
+	// ignoring case
+	Query string
+	// Pattern keeps tasks whose title or description matches the regular
+	// expression, in the syntax of FilterByRegex
+	Pattern string
+	// IncludeArchived lists archived tasks alongside the others
+	IncludeArchived bool
+	// IncludeSnoozed lists snoozed tasks alongside the others
+	IncludeSnoozed bool
+	// Page selects the window of the listing returned
+	Page Page
+	// Cursor continues a listing after the page that returned it as
+	// TaskPage.NextCursor, in place of Page.Offset. Unlike an offset it
+	// neither skips nor repeats tasks when tasks are added or removed
+	// between pages, except in orders by title once the last task of the
+	// page is removed. Cursors hold no titles or other task text.
+	Cursor string
+	// Sort orders the listing before it is paged
+	Sort SortSpec
+	// Explain makes FindTasks report how it ran the listing in
+	// TaskPage.Stats
+	Explain bool
+	// OmitDetails returns partial tasks, as the OmitDetails option does
+	OmitDetails bool
+}
+
+// Validate checks every filter, the page and the sort order
+func (o ListOptions) Validate() error {
+	for _, s := range o.Statuses {
+		if !s.Valid() {
+			return ErrInvalidStatus
+		}
+	}
+	for _, p := range o.Priorities {
+		if !p.Valid() {
+			return ErrInvalidPriority
+		}
+	}
+	for _, r := range []TimeRange{o.Due, o.Created, o.Completed} {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	if o.Pattern != "" {
+		if _, err := compileSearchRegex(o.Pattern); err != nil {
+			return err
+		}
+	}
+	if err := o.Page.Validate(); err != nil {
+		return err
+	}
+	if err := o.Sort.Validate(); err != nil {
+		return err
+	}
+	if o.Cursor != "" {
+		if o.Page.Offset != 0 {
+			return ErrInvalidCursor
+		}
+		if _, err := decodeCursor(o.Cursor, o.Sort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOptions translates the struct into the list options ListTasks takes
+func (o ListOptions) listOptions() []ListOption {
+	var opts []ListOption
+	if len(o.Statuses) > 0 {
+		opts = append(opts, FilterByStatuses(o.Statuses...))
+	}
+	if len(o.Priorities) > 0 {
+		opts = append(opts, FilterByPriority(o.Priorities...))
+	}
+	if len(o.Tags) > 0 {
+		opts = append(opts, FilterByAllTags(o.Tags...))
+	}
+	if len(o.AnyTags) > 0 {
+		opts = append(opts, FilterByAnyTag(o.AnyTags...))
+	}
+	if o.AssigneeID != nil {
+		opts = append(opts, FilterByAssignee(*o.AssigneeID))
+	}
+	if o.ProjectID != nil {
+		opts = append(opts, FilterByProject(*o.ProjectID))
+	}
+	if !o.Due.IsZero() {
+		due := o.Due
+		opts = append(opts, func(q *listQuery) { q.due = &due })
+	}
+	if !o.Created.IsZero() {
+		opts = append(opts, FilterByCreated(o.Created))
+	}
+	if !o.Completed.IsZero() {
+		opts = append(opts, FilterByCompleted(o.Completed))
+	}
+	if text := strings.ToLower(strings.TrimSpace(o.Query)); text != "" {
+		opts = append(opts, func(q *listQuery) { q.text = text })
+	}
+	if o.Pattern != "" {
+		if filter, err := FilterByRegex(o.Pattern); err == nil {
+			opts = append(opts, filter)
+		}
+	}
+	if o.IncludeArchived {
+		opts = append(opts, IncludeArchived())
+	}
+	if o.IncludeSnoozed {
+		opts = append(opts, IncludeSnoozed())
+	}
+	if o.OmitDetails {
+		opts = append(opts, OmitDetails())
+	}
+	if len(o.Sort) > 0 {
+		opts = append(opts, SortBy(o.Sort))
+	}
+	return opts
+}
+
+// FilterByCreated limits ListTasks to tasks created inside the range
+func FilterByCreated(r TimeRange) ListOption {
+	return func(q *listQuery) {
+		q.created = &r
+	}
+}
+
+// FilterByCompleted limits ListTasks to done tasks completed inside the
+// range, such as MonthRange(2025, time.June, time.UTC)
+func FilterByCompleted(r TimeRange) ListOption {
+	return func(q *listQuery) {
+		q.completed = &r
+	}
+}
+
+// MonthRange returns the range covering a calendar month in loc
+func MonthRange(year int, month time.Month, loc *time.Location) TimeRange {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return TimeRange{From: start, To: start.AddDate(0, 1, 0)}
+}
+
+// FindTasks returns the page of tasks described by opts along with the
+// total number of matching tasks.
+func (tm *TaskManager) FindTasks(opts ListOptions) (TaskPage, error) {
+	page, err := tm.findTasks(opts)
+	tm.exportListing(page.Tasks, opts.OmitDetails)
+	return page, err
+}
+
+// findTasks is FindTasks without copying the tasks on the page
+func (tm *TaskManager) findTasks(opts ListOptions) (TaskPage, error) {
+	if err := opts.Validate(); err != nil {
+		return TaskPage{}, err
+	}
+	q := tm.newQuery(opts.Done, opts.listOptions())
+	if opts.Explain {
+		q.stats = &QueryStats{}
+	}
+	result := pageOf(tm.matching(q), q, opts)
+	result.Stats = q.stats
+	return result, nil
+}
+
+// pageOf cuts the page selected by validated opts out of the full listing
+// for q, and sets its cursor
+func pageOf(tasks []*Task, q *listQuery, opts ListOptions) TaskPage {
+	page := opts.Page
+	if page.Limit == 0 {
+		page.Limit = DefaultPageSize
+	}
+	if opts.Cursor != "" {
+		key, _ := decodeCursor(opts.Cursor, opts.Sort)
+		page.Offset = key.offset(tasks, q)
+	}
+
+	result := paginate(tasks, page)
+	if result.HasMore() {
+		result.NextCursor = encodeCursor(result.Tasks[len(result.Tasks)-1], result.Offset+len(result.Tasks), opts.Sort)
+	}
+	return result
+}
+
+// CountTasks returns the number of tasks FindTasks would match for opts
+// across all pages, without building the listing. Page and Sort are
+// ignored.
+func (tm *TaskManager) CountTasks(opts ListOptions) (int, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	q := tm.newQuery(opts.Done, opts.listOptions())
+	count := 0
+	tm.scan(q, func(*Task) bool {
+		count++
+		return true
+	})
+	return count, nil
+}
+
+// containsText reports whether the title or plain-text description contains
+// the lowercased text
+func (t *Task) containsText(text string) bool {
+	return strings.Contains(strings.ToLower(t.Title), text) ||
+		strings.Contains(strings.ToLower(t.PlainDescription()), text)
+}
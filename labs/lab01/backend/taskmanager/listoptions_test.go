@@ -0,0 +1,195 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFindTasks(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	add := func(title, description string, opts ...TaskOption) *Task {
+		t.Helper()
+		task, err := tm.AddTask(title, description, opts...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		now = now.Add(time.Minute)
+		return task
+	}
+
+	add("Write report", "Quarterly **numbers**", WithTags("work"), WithDueDate(now.Add(24*time.Hour)))
+	add("Buy milk", "", WithTags("home"), WithPriority(PriorityHigh))
+	review := add("Review PR", "", WithTags("work", "code"), WithDueDate(now.Add(72*time.Hour)))
+	add("Call mom", "About the NUMBERS", WithTags("home"))
+	if err := tm.AssignTask(review.ID, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Transition(review.ID, StatusInProgress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	done := false
+	alice := "alice"
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected []string
+	}{
+		{name: "zero value", opts: ListOptions{}, expected: []string{"Write report", "Buy milk", "Review PR", "Call mom"}},
+		{name: "open", opts: ListOptions{Done: &done}, expected: []string{"Write report", "Buy milk", "Review PR", "Call mom"}},
+		{name: "status set", opts: ListOptions{Statuses: []Status{StatusInProgress, StatusBlocked}}, expected: []string{"Review PR"}},
+		{name: "all tags", opts: ListOptions{Tags: []string{"work", "CODE"}}, expected: []string{"Review PR"}},
+		{name: "any tag", opts: ListOptions{AnyTags: []string{"home"}}, expected: []string{"Buy milk", "Call mom"}},
+		{name: "assignee", opts: ListOptions{AssigneeID: &alice}, expected: []string{"Review PR"}},
+		{name: "priority", opts: ListOptions{Priorities: []Priority{PriorityHigh}}, expected: []string{"Buy milk"}},
+		{name: "due range", opts: ListOptions{Due: TimeRange{To: now.Add(48 * time.Hour)}}, expected: []string{"Write report"}},
+		{name: "query", opts: ListOptions{Query: " numbers "}, expected: []string{"Write report", "Call mom"}},
+		{
+			name:     "sorted and paged",
+			opts:     ListOptions{Sort: SortSpec{{Field: SortTitle}}, Page: Page{Offset: 1, Limit: 2}},
+			expected: []string{"Call mom", "Review PR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := tm.FindTasks(tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(page.Tasks); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestListOptionsValidate(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		opts        ListOptions
+		expectError error
+	}{
+		{name: "zero value", opts: ListOptions{}},
+		{name: "invalid status", opts: ListOptions{Statuses: []Status{StatusTodo, 42}}, expectError: ErrInvalidStatus},
+		{name: "invalid priority", opts: ListOptions{Priorities: []Priority{42}}, expectError: ErrInvalidPriority},
+		{name: "open range", opts: ListOptions{Due: TimeRange{From: now}}},
+		{name: "reversed range", opts: ListOptions{Due: TimeRange{From: now, To: now.Add(-time.Hour)}}, expectError: ErrInvalidTimeRange},
+		{name: "invalid page", opts: ListOptions{Page: Page{Offset: -1}}, expectError: ErrInvalidPage},
+		{name: "invalid sort", opts: ListOptions{Sort: SortSpec{{Field: 42}}}, expectError: ErrInvalidSortField},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	tm := NewTaskManager()
+	if _, err := tm.FindTasks(ListOptions{Statuses: []Status{42}}); err != ErrInvalidStatus {
+		t.Errorf("Expected FindTasks to validate its options, got %v", err)
+	}
+}
+
+func TestCountTasks(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for i, tag := range []string{"home", "work", "work", "home"} {
+		task, err := tm.AddTask("Task", "", WithTags(tag), WithDueDate(now.Add(time.Duration(i-2)*time.Hour)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if i == 0 {
+			if err := tm.Transition(task.ID, StatusDone); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	}
+
+	open := false
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected int
+	}{
+		{name: "all", opts: ListOptions{}, expected: 4},
+		{name: "open", opts: ListOptions{Done: &open}, expected: 3},
+		{name: "overdue", opts: ListOptions{Done: &open, Due: TimeRange{To: now}}, expected: 1},
+		{name: "per tag", opts: ListOptions{Tags: []string{"work"}}, expected: 2},
+		{name: "page ignored", opts: ListOptions{Page: Page{Offset: 3, Limit: 1}}, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tm.CountTasks(tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+			page, _ := tm.FindTasks(tt.opts)
+			if page.Total != got {
+				t.Errorf("Expected the count to match FindTasks total %d, got %d", page.Total, got)
+			}
+		})
+	}
+
+	if _, err := tm.CountTasks(ListOptions{Priorities: []Priority{0}}); err != ErrInvalidPriority {
+		t.Errorf("Expected ErrInvalidPriority, got %v", err)
+	}
+}
+
+func TestFindTasksCreatedAndCompleted(t *testing.T) {
+	now := time.Date(2025, 5, 30, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	may := mustAddTask(t, tm, "Created in May")
+	now = now.AddDate(0, 0, 5)
+	june := mustAddTask(t, tm, "Created in June")
+	mustAddTask(t, tm, "Still open")
+	if err := tm.Transition(may.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.AddDate(0, 1, 0)
+	if err := tm.Transition(june.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected []string
+	}{
+		{name: "created in June", opts: ListOptions{Created: MonthRange(2025, time.June, time.UTC)}, expected: []string{"Created in June", "Still open"}},
+		{name: "created before June", opts: ListOptions{Created: TimeRange{To: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)}}, expected: []string{"Created in May"}},
+		{name: "completed in June", opts: ListOptions{Completed: MonthRange(2025, time.June, time.UTC)}, expected: []string{"Created in May"}},
+		{name: "completed at all", opts: ListOptions{Completed: TimeRange{From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}, expected: []string{"Created in May", "Created in June"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := tm.FindTasks(tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(page.Tasks); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	july := tm.ListTasks(nil, FilterByCompleted(MonthRange(2025, time.July, time.UTC)))
+	if got := titles(july); !slices.Equal(got, []string{"Created in June"}) {
+		t.Errorf("Expected FilterByCompleted to match the July completion, got %v", got)
+	}
+	reversed := ListOptions{Created: TimeRange{From: now, To: now.Add(-time.Hour)}}
+	if err := reversed.Validate(); err != ErrInvalidTimeRange {
+		t.Errorf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package taskmanager
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// earthRadius is the mean radius of the Earth in meters
+const earthRadius = 6371000
+
+// ErrInvalidLocation is returned when a location's coordinates are out of range
+var ErrInvalidLocation = errors.New("invalid location")
+
+// Location is a place attached to a task
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	Label     string
+}
+
+// WithLocation attaches a location to a task
+func WithLocation(loc Location) TaskOption {
+	return func(t *Task) {
+		t.Location = &loc
+	}
+}
+
+// ClearLocation removes the location from a task
+func ClearLocation() TaskOption {
+	return func(t *Task) {
+		t.Location = nil
+	}
+}
+
+// Validate checks that the coordinates are a point on Earth
+func (l Location) Validate() error {
+	if math.IsNaN(l.Latitude) || l.Latitude < -90 || l.Latitude > 90 ||
+		math.IsNaN(l.Longitude) || l.Longitude < -180 || l.Longitude > 180 {
+		return ErrInvalidLocation
+	}
+	return nil
+}
+
+// DistanceTo returns the great-circle distance between two locations in meters
+func (l Location) DistanceTo(other Location) float64 {
+	lat1, lat2 := radians(l.Latitude), radians(other.Latitude)
+	dLat := lat2 - lat1
+	dLng := radians(other.Longitude - l.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// String formats the location as its label, or as coordinates without one
+func (l Location) String() string {
+	if l.Label != "" {
+		return fmt.Sprintf("%s (%.6f,%.6f)", l.Label, l.Latitude, l.Longitude)
+	}
+	return fmt.Sprintf("%.6f,%.6f", l.Latitude, l.Longitude)
+}
+
+// ListNear returns tasks whose location lies within radius meters of the
+// given point, nearest first
+func (tm *TaskManager) ListNear(lat, lng, radius float64, opts ...ListOption) ([]*Task, error) {
+	center := Location{Latitude: lat, Longitude: lng}
+	if err := center.Validate(); err != nil {
+		return nil, err
+	}
+	distances := make(map[int]float64)
+	tasks := tm.ListTasks(nil, append(opts, where(func(task *Task) bool {
+		if task.Location == nil {
+			return false
+		}
+		d := center.DistanceTo(*task.Location)
+		distances[task.ID] = d
+		return d <= radius
+	}))...)
+	slices.SortStableFunc(tasks, func(a, b *Task) int {
+		return cmp.Compare(distances[a.ID], distances[b.ID])
+	})
+	return tasks, nil
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
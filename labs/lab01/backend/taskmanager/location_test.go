@@ -0,0 +1,62 @@
+package taskmanager
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocationDistance(t *testing.T) {
+	berlin := Location{Latitude: 52.5200, Longitude: 13.4050}
+	paris := Location{Latitude: 48.8566, Longitude: 2.3522}
+
+	d := berlin.DistanceTo(paris)
+	if math.Abs(d-877_000) > 5_000 {
+		t.Errorf("Expected about 877 km between Berlin and Paris, got %.0f m", d)
+	}
+	if berlin.DistanceTo(berlin) != 0 {
+		t.Errorf("Expected zero distance to the same point")
+	}
+}
+
+func TestWithLocationValidation(t *testing.T) {
+	tm := NewTaskManager()
+
+	tests := []struct {
+		name        string
+		loc         Location
+		expectError error
+	}{
+		{name: "valid", loc: Location{Latitude: 52.52, Longitude: 13.40, Label: "Store"}},
+		{name: "latitude out of range", loc: Location{Latitude: 91}, expectError: ErrInvalidLocation},
+		{name: "longitude out of range", loc: Location{Longitude: -181}, expectError: ErrInvalidLocation},
+		{name: "not a number", loc: Location{Latitude: math.NaN()}, expectError: ErrInvalidLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tm.AddTask("Buy milk", "", WithLocation(tt.loc)); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestListNear(t *testing.T) {
+	tm := NewTaskManager()
+	store := mustAddTask(t, tm, "Buy milk", WithLocation(Location{Latitude: 52.5205, Longitude: 13.4090, Label: "Store"}))
+	office := mustAddTask(t, tm, "Print forms", WithLocation(Location{Latitude: 52.5200, Longitude: 13.4050, Label: "Office"}))
+	mustAddTask(t, tm, "Visit Paris", WithLocation(Location{Latitude: 48.8566, Longitude: 2.3522}))
+	mustAddTask(t, tm, "No place")
+
+	near, err := tm.ListNear(52.5201, 13.4051, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(near) != 2 || near[0] != office || near[1] != store {
+		t.Errorf("Expected office then store, got %v", titles(near))
+	}
+
+	if _, err := tm.ListNear(100, 0, 1000); err != ErrInvalidLocation {
+		t.Errorf("Expected ErrInvalidLocation, got %v", err)
+	}
+}
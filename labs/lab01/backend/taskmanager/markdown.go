@@ -0,0 +1,118 @@
+package taskmanager
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	mdCode        = regexp.MustCompile("(?s)```.*?(?:```|$)|`[^`\n]*`")
+	mdUnsafeBlock = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>|<iframe\b.*?</iframe\s*>|<!--.*?-->`)
+	mdHTMLTag     = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(?:\s[^>]*)?/?>`)
+	mdUnsafeLink  = regexp.MustCompile(`!?\[([^\]]*)\]\(\s*(?i:javascript|vbscript|data):(?:[^()]|\([^()]*\))*\)`)
+
+	mdImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdRule       = regexp.MustCompile(`(?m)^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdHeading    = regexp.MustCompile(`(?m)^ {0,3}#{1,6}[ \t]+`)
+	mdQuote      = regexp.MustCompile(`(?m)^ {0,3}>[ \t]?`)
+	mdListMarker = regexp.MustCompile(`(?m)^[ \t]*(?:[-*+]|\d+[.)])[ \t]+(?:\[[ xX]\][ \t]+)?`)
+	mdEmphasis   = []struct {
+		re   *regexp.Regexp
+		repl string
+	}{
+		{regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`), "$1"},
+		{regexp.MustCompile(`__(\S(?:.*?\S)?)__`), "$1"},
+		{regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`), "$1"},
+		{regexp.MustCompile(`\*(\S(?:[^*\n]*?\S)?)\*`), "$1"},
+		// Single underscores only count at word boundaries, so snake_case
+		// identifiers survive.
+		{regexp.MustCompile(`(^|\W)_(\S(?:[^_\n]*?\S)?)_($|\W)`), "$1$2$3"},
+	}
+	mdBlankLines = regexp.MustCompile(`\n{3,}`)
+	mdURL        = regexp.MustCompile(`https?://[^\s<>()\[\]"'\x60]+`)
+)
+
+// PlainDescription returns the Markdown description as plain text for
+// matching and previews. Link and image text is kept, markup characters are
+// dropped and code is kept verbatim.
+func (t *Task) PlainDescription() string {
+	plain := replaceOutsideCode(t.Description, stripMarkdown, func(code string) string {
+		if strings.HasPrefix(code, "```") {
+			code = strings.TrimPrefix(code, "```")
+			if i := strings.IndexByte(code, '\n'); i >= 0 {
+				code = code[i+1:]
+			} else {
+				code = ""
+			}
+			return strings.TrimSuffix(code, "```")
+		}
+		return strings.Trim(code, "`")
+	})
+
+	lines := strings.Split(plain, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	plain = mdBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(plain)
+}
+
+// sanitizeMarkdown normalizes line endings and removes HTML and script links
+// from a description. Code spans and blocks are left as they are.
+func sanitizeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return replaceOutsideCode(s, func(text string) string {
+		text = mdUnsafeBlock.ReplaceAllString(text, "")
+		text = mdHTMLTag.ReplaceAllString(text, "")
+		return mdUnsafeLink.ReplaceAllString(text, "$1")
+	}, nil)
+}
+
+// extractLinks returns the web URLs in a description outside code, in order
+// of first appearance
+func extractLinks(description string) []string {
+	var links []string
+	replaceOutsideCode(description, func(text string) string {
+		for _, url := range mdURL.FindAllString(text, -1) {
+			url = strings.TrimRight(url, ".,;:!?")
+			if !slices.Contains(links, url) {
+				links = append(links, url)
+			}
+		}
+		return text
+	}, nil)
+	return links
+}
+
+// stripMarkdown removes the formatting from Markdown text that holds no code
+func stripMarkdown(text string) string {
+	text = mdImage.ReplaceAllString(text, "$1")
+	text = mdLink.ReplaceAllString(text, "$1")
+	text = mdRule.ReplaceAllString(text, "")
+	text = mdHeading.ReplaceAllString(text, "")
+	text = mdQuote.ReplaceAllString(text, "")
+	text = mdListMarker.ReplaceAllString(text, "")
+	for _, e := range mdEmphasis {
+		text = e.re.ReplaceAllString(text, e.repl)
+	}
+	return text
+}
+
+// replaceOutsideCode applies text to the parts of s outside code spans and
+// fenced blocks, and code to the code itself. A nil func keeps its parts.
+func replaceOutsideCode(s string, text, code func(string) string) string {
+	if code == nil {
+		code = func(s string) string { return s }
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range mdCode.FindAllStringIndex(s, -1) {
+		b.WriteString(text(s[last:loc[0]]))
+		b.WriteString(code(s[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(text(s[last:]))
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPlainDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{name: "plain text", description: "Buy milk", expected: "Buy milk"},
+		{name: "heading and emphasis", description: "# Plan\n\nShip **v2** and _maybe_ ~~v3~~", expected: "Plan\n\nShip v2 and maybe v3"},
+		{name: "links and images", description: "See [the docs](https://example.com) ![logo](logo.png)", expected: "See the docs logo"},
+		{name: "lists and quotes", description: "- [x] first\n* second\n1. third\n> quoted", expected: "first\nsecond\nthird\nquoted"},
+		{name: "rule", description: "above\n\n---\n\nbelow", expected: "above\n\nbelow"},
+		{name: "snake case survives", description: "rename user_id to account_id", expected: "rename user_id to account_id"},
+		{name: "inline code kept verbatim", description: "run `go test **/*`", expected: "run go test **/*"},
+		{name: "fenced code kept verbatim", description: "Steps:\n```sh\nrm -rf _build_\n```", expected: "Steps:\nrm -rf _build_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Description: tt.description}
+			if got := task.PlainDescription(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDescriptionIsSanitized(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{name: "markdown is kept", description: "**bold** [link](https://example.com)", expected: "**bold** [link](https://example.com)"},
+		{name: "line endings", description: "one\r\ntwo", expected: "one\ntwo"},
+		{name: "script removed", description: "hi<script>alert(1)</script> there", expected: "hi there"},
+		{name: "tags removed", description: "<b onclick=\"x()\">bold</b>", expected: "bold"},
+		{name: "script link", description: "[click](javascript:alert(1)) me", expected: "click me"},
+		{name: "code left alone", description: "use `<T any>` here", expected: "use `<T any>` here"},
+	}
+
+	tm := NewTaskManager()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := tm.AddTask("Task", tt.description)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if task.Description != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, task.Description)
+			}
+		})
+	}
+}
+
+func TestTaskLinks(t *testing.T) {
+	tm := NewTaskManager()
+	task, err := tm.AddTask("Read", "See https://go.dev/doc and [spec](https://go.dev/ref/spec).\n"+
+		"Again: https://go.dev/doc, <https://example.com/a>\n`https://ignored.example`")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"https://go.dev/doc", "https://go.dev/ref/spec", "https://example.com/a"}
+	if !slices.Equal(task.Links, expected) {
+		t.Errorf("Expected %v, got %v", expected, task.Links)
+	}
+
+	if err := tm.UpdateTaskFields(task.ID, TaskPatch{Description: ptr("Moved to http://new.example/page")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(task.Links, []string{"http://new.example/page"}) {
+		t.Errorf("Expected links to follow the description, got %v", task.Links)
+	}
+
+	if err := tm.UpdateTask(task.ID, task.Title, "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(task.Links) != 0 {
+		t.Errorf("Expected no links for an empty description, got %v", task.Links)
+	}
+}
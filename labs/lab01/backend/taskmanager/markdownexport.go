@@ -0,0 +1,128 @@
+package taskmanager
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MarkdownOptions tells ExportMarkdown what to render
+type MarkdownOptions struct {
+	// Filter selects the tasks and their order; its Page and Cursor are
+	// ignored
+	Filter ListOptions
+	// GroupBy puts the tasks under a heading for each bucket GroupTasks
+	// would put them in, so a task with several tags is listed under each.
+	// Zero renders a single list.
+	GroupBy GroupField
+	// Title, when set, is rendered as a heading above the list
+	Title string
+}
+
+// markdownEscaper escapes the characters of a title Markdown would format
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "~", `\~`, "|", `\|`,
+)
+
+// markdownNoGroup names the bucket of tasks without a value for a field
+var markdownNoGroup = map[GroupField]string{
+	GroupByTag:      "No tag",
+	GroupByAssignee: "Unassigned",
+	GroupByProject:  "No project",
+}
+
+// ExportMarkdown writes the tasks matching opts.Filter as a Markdown
+// checklist, ready to paste into a report:
+//
+//	## Course
+//
+//	- [ ] Write report (due 2025-07-02, overdue)
+//	- [x] Grade essays
+//
+// Done and cancelled tasks are checked, cancelled ones struck through, and
+// due dates are shown in the manager's time zone, with open tasks past
+// theirs marked overdue.
+func (tm *TaskManager) ExportMarkdown(w io.Writer, opts MarkdownOptions) error {
+	var b bytes.Buffer
+	if err := tm.renderMarkdown(&b, opts); err != nil {
+		return err
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// renderMarkdown renders the checklist of ExportMarkdown
+func (tm *TaskManager) renderMarkdown(b *bytes.Buffer, opts MarkdownOptions) error {
+	if err := opts.Filter.Validate(); err != nil {
+		return err
+	}
+	var keysOf func(*Task) []any
+	if opts.GroupBy != 0 {
+		var err error
+		if keysOf, err = tm.groupKeys(opts.GroupBy); err != nil {
+			return err
+		}
+	}
+	tasks := tm.matching(tm.newQuery(opts.Filter.Done, opts.Filter.listOptions()))
+
+	if opts.Title != "" {
+		fmt.Fprintf(b, "# %s\n\n", markdownEscaper.Replace(opts.Title))
+	}
+	if keysOf == nil {
+		tm.renderChecklist(b, tasks)
+		return nil
+	}
+	groups := make(map[any][]*Task)
+	for _, task := range tasks {
+		for _, key := range keysOf(task) {
+			groups[key] = append(groups[key], task)
+		}
+	}
+	if len(groups) == 0 {
+		tm.renderChecklist(b, nil)
+		return nil
+	}
+	for i, key := range slices.SortedFunc(maps.Keys(groups), compareGroupKeys) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		name := groupKeyName(key)
+		if name == "" {
+			name = markdownNoGroup[opts.GroupBy]
+		}
+		fmt.Fprintf(b, "## %s\n\n", markdownEscaper.Replace(name))
+		tm.renderChecklist(b, groups[key])
+	}
+	return nil
+}
+
+// renderChecklist renders tasks as the items of a checklist
+func (tm *TaskManager) renderChecklist(b *bytes.Buffer, tasks []*Task) {
+	if len(tasks) == 0 {
+		b.WriteString("_No tasks._\n")
+		return
+	}
+	now := tm.now()
+	for _, task := range tasks {
+		box, title := "[ ]", markdownEscaper.Replace(task.Title)
+		if task.Status.Closed() {
+			box = "[x]"
+		}
+		if task.Status == StatusCancelled {
+			title = "~~" + title + "~~"
+		}
+		fmt.Fprintf(b, "- %s %s", box, title)
+		if task.DueDate != nil {
+			fmt.Fprintf(b, " (due %s", task.DueDate.In(tm.timezone).Format("2006-01-02"))
+			if !task.Status.Closed() && task.DueDate.Before(now) {
+				b.WriteString(", overdue")
+			}
+			b.WriteByte(')')
+		}
+		b.WriteByte('\n')
+	}
+}
@@ -0,0 +1,91 @@
+package taskmanager
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	tm := NewTaskManager(WithClock(clock), WithTimezone(time.FixedZone("UTC+3", 3*60*60)))
+	course, _ := tm.CreateProject("Course")
+	report := mustAddTask(t, tm, "Write *report*", WithTags("work"), WithDueDate(time.Date(2025, 6, 30, 22, 0, 0, 0, time.UTC)))
+	slides := mustAddTask(t, tm, "Slides", WithTags("work", "urgent"), WithDueDate(time.Date(2025, 7, 3, 12, 0, 0, 0, time.UTC)))
+	milk := mustAddTask(t, tm, "Buy milk", WithTags("home"), WithDueDate(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)))
+	call := mustAddTask(t, tm, "Call mom")
+	setup := []error{
+		tm.UpdateTaskFields(report.ID, TaskPatch{ProjectID: &course.ID}),
+		tm.UpdateTaskFields(slides.ID, TaskPatch{ProjectID: &course.ID}),
+		tm.UpdateTaskFields(milk.ID, TaskPatch{Status: ptr(StatusDone)}),
+		tm.UpdateTaskFields(call.ID, TaskPatch{Status: ptr(StatusCancelled)}),
+	}
+	for _, err := range setup {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     MarkdownOptions
+		expected string
+	}{
+		{
+			name: "list",
+			opts: MarkdownOptions{Title: "Tasks"},
+			expected: "# Tasks\n\n" +
+				"- [ ] Write \\*report\\* (due 2025-07-01, overdue)\n" +
+				"- [ ] Slides (due 2025-07-03)\n" +
+				"- [x] Buy milk (due 2025-06-01)\n" +
+				"- [x] ~~Call mom~~\n",
+		},
+		{
+			name: "by project",
+			opts: MarkdownOptions{GroupBy: GroupByProject, Filter: ListOptions{Sort: SortSpec{{Field: SortTitle}}}},
+			expected: "## Course\n\n" +
+				"- [ ] Slides (due 2025-07-03)\n" +
+				"- [ ] Write \\*report\\* (due 2025-07-01, overdue)\n" +
+				"\n## No project\n\n" +
+				"- [x] Buy milk (due 2025-06-01)\n" +
+				"- [x] ~~Call mom~~\n",
+		},
+		{
+			name: "todo tasks by tag",
+			opts: MarkdownOptions{GroupBy: GroupByTag, Filter: ListOptions{Statuses: []Status{StatusTodo}}},
+			expected: "## urgent\n\n" +
+				"- [ ] Slides (due 2025-07-03)\n" +
+				"\n## work\n\n" +
+				"- [ ] Write \\*report\\* (due 2025-07-01, overdue)\n" +
+				"- [ ] Slides (due 2025-07-03)\n",
+		},
+		{
+			name:     "nothing matching",
+			opts:     MarkdownOptions{Title: "Home", GroupBy: GroupByTag, Filter: ListOptions{Tags: []string{"garden"}}},
+			expected: "# Home\n\n_No tasks._\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tm.ExportMarkdown(&b, tt.opts); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := b.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	var b strings.Builder
+	if err := tm.ExportMarkdown(&b, MarkdownOptions{GroupBy: 42}); err != ErrInvalidGroupField {
+		t.Errorf("Expected ErrInvalidGroupField, got %v", err)
+	}
+	if err := tm.ExportMarkdown(&b, MarkdownOptions{Filter: ListOptions{Statuses: []Status{42}}}); err != ErrInvalidStatus {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("Expected nothing written on error, got %q", b.String())
+	}
+}
@@ -0,0 +1,92 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidMerge is returned when a task would be merged into itself or into
+// one of its own subtasks
+var ErrInvalidMerge = errors.New("invalid merge")
+
+// MergeTasks folds duplicate tasks into the target and moves them to the
+// trash. Their descriptions are appended to the target's, tags are combined,
+// comments and history are interleaved by time, keeping only the target's
+// creation entry, and their subtasks and dependents move to the target.
+func (tm *TaskManager) MergeTasks(targetID int, sourceIDs ...int) (*Task, error) {
+	defer tm.beginOperation()()
+
+	target, err := tm.lookup(targetID)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]*Task, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		source, err := tm.lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		if id == targetID || slices.Contains(sources, source) || tm.isAncestor(id, target) {
+			return nil, ErrInvalidMerge
+		}
+		sources = append(sources, source)
+	}
+
+	for _, source := range sources {
+		tm.update(target, func() {
+			target.Description = mergeDescriptions(target.Description, source.Description)
+			target.Tags = normalizeTags(append(target.Tags, source.Tags...))
+			target.Comments = append(target.Comments, source.Comments...)
+			for _, entry := range source.History {
+				if entry.Field != HistoryCreated {
+					target.History = append(target.History, entry)
+				}
+			}
+		})
+		for _, child := range tm.children(source.ID) {
+			tm.update(child, func() {
+				child.ParentID = target.ID
+			})
+		}
+		tm.trashTask(source)
+		tm.moveDependents(source.ID, target.ID)
+	}
+	slices.SortStableFunc(target.Comments, func(a, b Comment) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	slices.SortStableFunc(target.History, func(a, b HistoryEntry) int {
+		return a.Time.Compare(b.Time)
+	})
+	target.UpdatedAt = tm.now()
+	return tm.export(target), nil
+}
+
+// isAncestor reports whether the task with the given ID is above task in its
+// subtask tree
+func (tm *TaskManager) isAncestor(id int, task *Task) bool {
+	for parentID := task.ParentID; parentID != 0; {
+		if parentID == id {
+			return true
+		}
+		parent, ok := tm.tasks.Get(parentID)
+		if !ok {
+			return false
+		}
+		parentID = parent.ParentID
+	}
+	return false
+}
+
+// mergeDescriptions appends a description to another, skipping empty and
+// repeated text
+func mergeDescriptions(target, source string) string {
+	source = strings.TrimSpace(source)
+	if source == "" || strings.Contains(target, source) {
+		return target
+	}
+	if strings.TrimSpace(target) == "" {
+		return source
+	}
+	return target + "\n\n" + source
+}
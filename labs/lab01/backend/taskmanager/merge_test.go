@@ -0,0 +1,139 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMergeTasks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+
+	target, _ := tm.AddTask("Fix login", "Users cannot log in", WithTags("bug"))
+	now = now.Add(time.Hour)
+	dup, _ := tm.AddTask("Login broken", "Fails on Safari", WithTags("bug", "web"))
+	child := mustAddTask(t, tm, "Reproduce", WithParent(dup.ID))
+	if _, err := tm.AddComment(dup.ID, "bob", "Seen on iOS too"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := tm.AddComment(target.ID, "alice", "Investigating"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	merged, err := tm.MergeTasks(target.ID, dup.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if merged.Description != "Users cannot log in\n\nFails on Safari" {
+		t.Errorf("Expected combined description, got %q", merged.Description)
+	}
+	if !merged.HasTag("bug") || !merged.HasTag("web") || len(merged.Tags) != 2 {
+		t.Errorf("Expected tags [bug web], got %v", merged.Tags)
+	}
+	if len(merged.Comments) != 2 || merged.Comments[0].Author != "bob" {
+		t.Errorf("Expected comments ordered by time, got %+v", merged.Comments)
+	}
+	if child.ParentID != target.ID {
+		t.Errorf("Expected subtask to move to target, got parent %d", child.ParentID)
+	}
+	if _, err := tm.GetTask(dup.ID); err != ErrTaskNotFound {
+		t.Errorf("Expected source to be trashed, got %v", err)
+	}
+	created := 0
+	for _, entry := range merged.History {
+		if entry.Field == HistoryCreated {
+			created++
+		}
+	}
+	if created != 1 || merged.History[0].Field != HistoryCreated {
+		t.Errorf("Expected only the target's creation entry, got %+v", merged.History)
+	}
+	for i := 1; i < len(merged.History); i++ {
+		if merged.History[i].Time.Before(merged.History[i-1].Time) {
+			t.Fatalf("Expected history ordered by time, got %+v", merged.History)
+		}
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.GetTask(dup.ID); err != nil {
+		t.Errorf("Expected undo to restore the source, got %v", err)
+	}
+}
+
+func TestMergeTasksDependents(t *testing.T) {
+	tm := NewTaskManager()
+	target := mustAddTask(t, tm, "Fix login")
+	dup := mustAddTask(t, tm, "Login broken")
+	release := mustAddTask(t, tm, "Release")
+	both := mustAddTask(t, tm, "Announce")
+	blocker := mustAddTask(t, tm, "Blocker")
+	tm.AddDependency(release.ID, dup.ID)
+	tm.AddDependency(both.ID, dup.ID)
+	tm.AddDependency(both.ID, target.ID)
+	tm.AddDependency(target.ID, blocker.ID)
+	tm.AddDependency(blocker.ID, dup.ID)
+
+	if _, err := tm.MergeTasks(target.ID, dup.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tests := []struct {
+		name string
+		task *Task
+		want []int
+	}{
+		{"moved to the target", release, []int{target.ID}},
+		{"already on the target", both, []int{target.ID}},
+		{"closing a cycle", blocker, nil},
+		{"target", target, []int{blocker.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.task.DependsOn, tt.want) {
+				t.Errorf("Expected dependencies %v, got %v", tt.want, tt.task.DependsOn)
+			}
+		})
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, _ := tm.GetTask(release.ID); !slices.Equal(got.DependsOn, []int{dup.ID}) {
+		t.Errorf("Expected undo to restore the dependency on the source, got %v", got.DependsOn)
+	}
+}
+
+func TestMergeTasksInvalid(t *testing.T) {
+	tm := NewTaskManager()
+	parent := mustAddTask(t, tm, "Parent")
+	child := mustAddTask(t, tm, "Child", WithParent(parent.ID))
+	other := mustAddTask(t, tm, "Other")
+
+	tests := []struct {
+		name        string
+		targetID    int
+		sourceIDs   []int
+		expectError error
+	}{
+		{name: "missing target", targetID: 999, sourceIDs: []int{other.ID}, expectError: ErrTaskNotFound},
+		{name: "missing source", targetID: other.ID, sourceIDs: []int{999}, expectError: ErrTaskNotFound},
+		{name: "merge into itself", targetID: other.ID, sourceIDs: []int{other.ID}, expectError: ErrInvalidMerge},
+		{name: "repeated source", targetID: other.ID, sourceIDs: []int{parent.ID, parent.ID}, expectError: ErrInvalidMerge},
+		{name: "merge parent into child", targetID: child.ID, sourceIDs: []int{parent.ID}, expectError: ErrInvalidMerge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tm.MergeTasks(tt.targetID, tt.sourceIDs...); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+	if len(tm.ListTrash()) != 0 {
+		t.Errorf("Expected no task to be trashed, got %d", len(tm.ListTrash()))
+	}
+}
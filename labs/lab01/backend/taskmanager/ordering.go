@@ -0,0 +1,88 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+)
+
+// positionStep is the gap left between the positions of neighbouring tasks,
+// so most moves only have to renumber the moved task
+const positionStep = 1 << 10
+
+// ErrMoveAfterSelf is returned when a task is moved after itself
+var ErrMoveAfterSelf = errors.New("cannot move a task after itself")
+
+// MoveTask places a task directly after another one in the manual order. An
+// afterID of 0 moves the task to the top. New tasks are added at the bottom.
+func (tm *TaskManager) MoveTask(id, afterID int) error {
+	defer tm.beginOperation()()
+
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if afterID == id {
+		return ErrMoveAfterSelf
+	}
+	i := 0
+	ordered := slices.DeleteFunc(tm.byPosition(), func(t *Task) bool {
+		return t == task
+	})
+	if afterID != 0 {
+		after, err := tm.lookup(afterID)
+		if err != nil {
+			return err
+		}
+		i = slices.Index(ordered, after) + 1
+	}
+
+	position, ok := positionBetween(ordered, i)
+	if !ok {
+		tm.renumber(ordered)
+		position, _ = positionBetween(ordered, i)
+	}
+	tm.touch(task.ID)
+	task.Position = position
+	tm.fieldsChanged(task)
+	tm.lastPosition = max(tm.lastPosition, position)
+	return nil
+}
+
+// SortByPosition orders ListTasks results by the manual order set with MoveTask
+func SortByPosition() ListOption {
+	return func(q *listQuery) {
+		q.sortByPosition = true
+	}
+}
+
+// positionBetween returns a position for a task inserted at index i of
+// ordered, or false if its neighbours leave no room
+func positionBetween(ordered []*Task, i int) (int, bool) {
+	switch {
+	case len(ordered) == 0:
+		return positionStep, true
+	case i == 0:
+		return ordered[0].Position - positionStep, true
+	case i == len(ordered):
+		return ordered[i-1].Position + positionStep, true
+	}
+	lo, hi := ordered[i-1].Position, ordered[i].Position
+	if hi-lo < 2 {
+		return 0, false
+	}
+	return lo + (hi-lo)/2, true
+}
+
+// renumber spreads tasks out evenly in the given order
+func (tm *TaskManager) renumber(ordered []*Task) {
+	for i, task := range ordered {
+		tm.touch(task.ID)
+		task.Position = (i + 1) * positionStep
+	}
+	tm.lastPosition = len(ordered) * positionStep
+}
+
+// byPosition returns every active task in manual order
+func (tm *TaskManager) byPosition() []*Task {
+	return tm.list(nil, IncludeArchived(), IncludeSnoozed(), SortByPosition())
+}
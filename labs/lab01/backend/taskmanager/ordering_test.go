@@ -0,0 +1,85 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+)
+
+func titlesByPosition(tm *TaskManager) []string {
+	return titles(tm.ListTasks(nil, SortByPosition()))
+}
+
+func TestMoveTask(t *testing.T) {
+	tm := NewTaskManager()
+	a := mustAddTask(t, tm, "A")
+	b := mustAddTask(t, tm, "B")
+	c := mustAddTask(t, tm, "C")
+
+	tests := []struct {
+		name        string
+		id          int
+		afterID     int
+		expected    []string
+		expectError error
+	}{
+		{name: "move to top", id: c.ID, afterID: 0, expected: []string{"C", "A", "B"}},
+		{name: "move after last", id: a.ID, afterID: b.ID, expected: []string{"C", "B", "A"}},
+		{name: "move between", id: a.ID, afterID: c.ID, expected: []string{"C", "A", "B"}},
+		{name: "after itself", id: a.ID, afterID: a.ID, expectError: ErrMoveAfterSelf},
+		{name: "missing task", id: 999, afterID: a.ID, expectError: ErrTaskNotFound},
+		{name: "missing anchor", id: a.ID, afterID: 999, expectError: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tm.MoveTask(tt.id, tt.afterID)
+			if tt.expectError != nil {
+				if err != tt.expectError {
+					t.Errorf("Expected %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titlesByPosition(tm); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected order %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	d := mustAddTask(t, tm, "D")
+	if got := titlesByPosition(tm); got[len(got)-1] != d.Title {
+		t.Errorf("Expected new task at the bottom, got %v", got)
+	}
+}
+
+func TestMoveTaskRenumbers(t *testing.T) {
+	tm := NewTaskManager()
+	a := mustAddTask(t, tm, "A")
+	mustAddTask(t, tm, "B")
+	c := mustAddTask(t, tm, "C")
+	d := mustAddTask(t, tm, "D")
+
+	// Moving C and D into the gap after A halves it each time until the
+	// positions have to be spread out again.
+	for i := 0; i < 30; i++ {
+		moved := c
+		if i%2 == 1 {
+			moved = d
+		}
+		if err := tm.MoveTask(moved.ID, a.ID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := titlesByPosition(tm); got[1] != moved.Title {
+			t.Fatalf("Expected %s right after A, got %v", moved.Title, got)
+		}
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titlesByPosition(tm); got[1] != c.Title {
+		t.Errorf("Expected undo to restore the previous order, got %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package taskmanager
+
+import "errors"
+
+const (
+	// DefaultPageSize is the number of tasks in a page when Page.Limit is zero
+	DefaultPageSize = 50
+	// MaxPageSize is the largest Page.Limit accepted
+	MaxPageSize = 500
+)
+
+// ErrInvalidPage is returned when a page has a negative offset or a limit
+// outside 0..MaxPageSize
+var ErrInvalidPage = errors.New("invalid page")
+
+// Page selects a window of a listing
+type Page struct {
+	Offset int
+	// Limit is the largest number of tasks returned; zero means DefaultPageSize
+	Limit int
+}
+
+// TaskPage is one page of a listing together with the size of the whole
+// listing
+type TaskPage struct {
+	Tasks  []*Task
+	Total  int
+	Offset int
+	Limit  int
+	// NextCursor fetches the following page when passed as
+	// ListOptions.Cursor. FindTasks sets it whenever HasMore is true.
+	NextCursor string
+	// Stats describes how the listing was run when ListOptions.Explain
+	// was set, and is nil otherwise
+	Stats *QueryStats
+}
+
+// HasMore reports whether there are tasks after this page
+func (p TaskPage) HasMore() bool {
+	return p.Offset+len(p.Tasks) < p.Total
+}
+
+// Validate checks the offset and limit of the page
+func (p Page) Validate() error {
+	if p.Offset < 0 || p.Limit < 0 || p.Limit > MaxPageSize {
+		return ErrInvalidPage
+	}
+	return nil
+}
+
+// ListTasksPage returns one page of what ListTasks would return for the
+// same arguments, along with the total number of matching tasks
+func (tm *TaskManager) ListTasksPage(filterDone *bool, page Page, opts ...ListOption) (TaskPage, error) {
+	if err := page.Validate(); err != nil {
+		return TaskPage{}, err
+	}
+	if page.Limit == 0 {
+		page.Limit = DefaultPageSize
+	}
+	q := tm.newQuery(filterDone, opts)
+	result := paginate(tm.matchingInto([]*Task{}, q), page)
+	tm.exportListing(result.Tasks, q.omitDetails)
+	return result, nil
+}
+
+// paginate cuts a page out of a full, validated listing
+func paginate(tasks []*Task, page Page) TaskPage {
+	start := min(page.Offset, len(tasks))
+	end := min(start+page.Limit, len(tasks))
+	return TaskPage{
+		Tasks:  tasks[start:end:end],
+		Total:  len(tasks),
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}
+}
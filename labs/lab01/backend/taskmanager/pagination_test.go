@@ -0,0 +1,75 @@
+package taskmanager
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListTasksPage(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for i := 1; i <= 5; i++ {
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i))
+		now = now.Add(time.Minute)
+	}
+
+	tests := []struct {
+		name        string
+		page        Page
+		expected    []string
+		hasMore     bool
+		expectError error
+	}{
+		{name: "first page", page: Page{Limit: 2}, expected: []string{"Task 1", "Task 2"}, hasMore: true},
+		{name: "middle page", page: Page{Offset: 2, Limit: 2}, expected: []string{"Task 3", "Task 4"}, hasMore: true},
+		{name: "last page", page: Page{Offset: 4, Limit: 2}, expected: []string{"Task 5"}},
+		{name: "past the end", page: Page{Offset: 10, Limit: 2}, expected: []string{}},
+		{name: "default limit", page: Page{}, expected: []string{"Task 1", "Task 2", "Task 3", "Task 4", "Task 5"}},
+		{name: "negative offset", page: Page{Offset: -1}, expectError: ErrInvalidPage},
+		{name: "limit too large", page: Page{Limit: MaxPageSize + 1}, expectError: ErrInvalidPage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := tm.ListTasksPage(nil, tt.page)
+			if err != tt.expectError {
+				t.Fatalf("Expected %v, got %v", tt.expectError, err)
+			}
+			if err != nil {
+				return
+			}
+			if got := titles(page.Tasks); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+			if page.Total != 5 || page.HasMore() != tt.hasMore {
+				t.Errorf("Expected total 5 and more=%v, got %d and %v", tt.hasMore, page.Total, page.HasMore())
+			}
+		})
+	}
+
+	page, _ := tm.ListTasksPage(nil, Page{Limit: 1}, FilterByStatus(StatusDone))
+	if page.Total != 0 || len(page.Tasks) != 0 {
+		t.Errorf("Expected list options to apply before paging, got %+v", page)
+	}
+}
+
+func TestListTasksPageCopiesPage(t *testing.T) {
+	tm := NewTaskManager(WithDefensiveCopies())
+	for i := 1; i <= 500; i++ {
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i), WithTags("work"))
+	}
+	page, _ := tm.ListTasksPage(nil, Page{Limit: 1})
+	page.Tasks[0].Title = "Changed"
+	if got, _ := tm.GetTask(1); got.Title != "Task 1" {
+		t.Errorf("Expected the stored task to be unchanged, got %q", got.Title)
+	}
+	// Only the tasks on the page are copied, not the whole listing
+	allocs := testing.AllocsPerRun(10, func() {
+		tm.ListTasksPage(nil, Page{Limit: 1})
+	})
+	if allocs > 100 {
+		t.Errorf("Expected only the page to be copied, got %.0f allocations", allocs)
+	}
+}
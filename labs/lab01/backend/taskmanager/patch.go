@@ -0,0 +1,165 @@
+package taskmanager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskPatch lists the fields to change with UpdateTaskFields. Nil fields are
+// left as they are.
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	Status      *Status
+	Priority    *Priority
+	Color       *string
+	Icon        *string
+	// DueDate sets the due date; ClearDueDate removes it and wins over DueDate
+	DueDate      *time.Time
+	ClearDueDate bool
+	Tags         *[]string
+	// AssigneeID assigns the task; an empty ID unassigns it
+	AssigneeID *string
+	ProjectID  *int
+	ParentID   *int
+	Estimate   *time.Duration
+	// Version rejects the patch with ErrConflict unless the task is still
+	// at this version
+	Version *int
+}
+
+// FieldError reports which field of a TaskPatch was rejected
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the validation error of the field
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// patchStep applies one field of a patch and checks the result
+type patchStep struct {
+	field string
+	apply func(*Task)
+	check func(*Task) error
+}
+
+// UpdateTaskFields changes only the fields set in the patch, so concurrent
+// edits to other fields are kept. Each field is validated on its own and a
+// rejected field is reported as a *FieldError; nothing is changed then.
+func (tm *TaskManager) UpdateTaskFields(id int, patch TaskPatch) error {
+	defer tm.beginOperation()()
+
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if patch.Version != nil && *patch.Version != task.Version {
+		return ErrConflict
+	}
+
+	updated := *task
+	for _, step := range tm.patchSteps(task, patch) {
+		step.apply(&updated)
+		if step.check == nil {
+			continue
+		}
+		if err := step.check(&updated); err != nil {
+			return &FieldError{Field: step.field, Err: err}
+		}
+	}
+	if err := tm.validate(&updated); err != nil {
+		return err
+	}
+	next, err := tm.nextOccurrence(&updated, task.Status, updated.Status)
+	if err != nil {
+		return err
+	}
+
+	previous := task.Status
+	tm.update(task, func() {
+		*task = updated
+		tm.statusChanged(task, previous, next)
+	})
+	return nil
+}
+
+// patchSteps turns the set fields of a patch into steps, in the order of
+// the Task struct
+func (tm *TaskManager) patchSteps(task *Task, patch TaskPatch) []patchStep {
+	var steps []patchStep
+	if patch.Title != nil {
+		title := strings.TrimSpace(*patch.Title)
+		steps = append(steps, patchStep{"title", func(t *Task) { t.Title = title }, func(t *Task) error {
+			if t.Title == "" {
+				return ErrEmptyTitle
+			}
+			return nil
+		}})
+	}
+	if patch.Description != nil {
+		description := sanitizeMarkdown(*patch.Description)
+		steps = append(steps, patchStep{"description", func(t *Task) { t.Description = description }, nil})
+	}
+	if patch.Status != nil {
+		steps = append(steps, patchStep{"status", func(t *Task) { t.Status = *patch.Status }, func(t *Task) error {
+			if !t.Status.Valid() {
+				return ErrInvalidStatus
+			}
+			if !task.Status.CanTransition(t.Status) {
+				return &TransitionError{From: task.Status, To: t.Status}
+			}
+			return nil
+		}})
+	}
+	if patch.Priority != nil {
+		steps = append(steps, patchStep{"priority", WithPriority(*patch.Priority), func(t *Task) error {
+			if !t.Priority.Valid() {
+				return ErrInvalidPriority
+			}
+			return nil
+		}})
+	}
+	if patch.Color != nil {
+		steps = append(steps, patchStep{"color", WithColor(*patch.Color), validateAppearance})
+	}
+	if patch.Icon != nil {
+		steps = append(steps, patchStep{"icon", WithIcon(*patch.Icon), validateAppearance})
+	}
+	switch {
+	case patch.ClearDueDate:
+		steps = append(steps, patchStep{"due_date", ClearDueDate(), nil})
+	case patch.DueDate != nil:
+		steps = append(steps, patchStep{"due_date", WithDueDate(*patch.DueDate), validateReminders})
+	}
+	if patch.Tags != nil {
+		steps = append(steps, patchStep{"tags", WithTags(*patch.Tags...), nil})
+	}
+	if patch.ParentID != nil {
+		steps = append(steps, patchStep{"parent_id", WithParent(*patch.ParentID), tm.validateParent})
+	}
+	if patch.ProjectID != nil {
+		steps = append(steps, patchStep{"project_id", WithProject(*patch.ProjectID), tm.validateProject})
+	}
+	if patch.AssigneeID != nil {
+		assigneeID := strings.TrimSpace(*patch.AssigneeID)
+		steps = append(steps, patchStep{"assignee_id", func(t *Task) { t.AssigneeID = assigneeID }, nil})
+	}
+	if patch.Estimate != nil {
+		steps = append(steps, patchStep{"estimate", WithEstimate(*patch.Estimate), func(t *Task) error {
+			if t.Estimate < 0 {
+				return ErrInvalidEstimate
+			}
+			return nil
+		}})
+	}
+	return steps
+}
@@ -0,0 +1,90 @@
+package taskmanager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUpdateTaskFields(t *testing.T) {
+	tm := NewTaskManager()
+	due := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	task := mustAddTask(t, tm, "Report", WithTags("work"), WithDueDate(due), WithPriority(PriorityHigh))
+
+	err := tm.UpdateTaskFields(task.ID, TaskPatch{
+		Title:      ptr(" Quarterly report "),
+		Status:     ptr(StatusInProgress),
+		AssigneeID: ptr("alice"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Title != "Quarterly report" || task.Status != StatusInProgress || task.AssigneeID != "alice" {
+		t.Errorf("Expected patched fields to change, got %+v", task)
+	}
+	if !task.HasTag("work") || !task.DueDate.Equal(due) || task.Priority != PriorityHigh {
+		t.Errorf("Expected unset fields to be kept, got %+v", task)
+	}
+
+	if err := tm.UpdateTaskFields(task.ID, TaskPatch{ClearDueDate: true, Tags: ptr([]string{})}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.DueDate != nil || len(task.Tags) != 0 {
+		t.Errorf("Expected due date and tags to be cleared, got %+v", task)
+	}
+
+	if err := tm.UpdateTaskFields(task.ID, TaskPatch{Status: ptr(StatusDone)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.CompletedAt == nil {
+		t.Errorf("Expected completing through a patch to set CompletedAt")
+	}
+}
+
+func TestUpdateTaskFieldsInvalid(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Report")
+	child := mustAddTask(t, tm, "Child", WithParent(task.ID))
+
+	tests := []struct {
+		name          string
+		patch         TaskPatch
+		expectedField string
+		expectError   error
+	}{
+		{name: "empty title", patch: TaskPatch{Title: ptr(" ")}, expectedField: "title", expectError: ErrEmptyTitle},
+		{name: "invalid status", patch: TaskPatch{Status: ptr(Status(42))}, expectedField: "status", expectError: ErrInvalidStatus},
+		{name: "invalid priority", patch: TaskPatch{Status: ptr(StatusBlocked), Priority: ptr(Priority(0))}, expectedField: "priority", expectError: ErrInvalidPriority},
+		{name: "missing project", patch: TaskPatch{ProjectID: ptr(7)}, expectedField: "project_id", expectError: ErrProjectNotFound},
+		{name: "cyclic parent", patch: TaskPatch{ParentID: ptr(child.ID)}, expectedField: "parent_id", expectError: ErrCyclicParent},
+		{name: "negative estimate", patch: TaskPatch{Title: ptr("Kept?"), Estimate: ptr(-time.Hour)}, expectedField: "estimate", expectError: ErrInvalidEstimate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tm.UpdateTaskFields(task.ID, tt.patch)
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != tt.expectedField || !errors.Is(err, tt.expectError) {
+				t.Errorf("Expected %s: %v, got %v", tt.expectedField, tt.expectError, err)
+			}
+		})
+	}
+
+	if task.Title != "Report" || task.Status != StatusTodo {
+		t.Errorf("Expected rejected patches to change nothing, got %+v", task)
+	}
+	if err := tm.Transition(child.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err := tm.UpdateTaskFields(child.ID, TaskPatch{Status: ptr(StatusInProgress)})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+	if err := tm.UpdateTaskFields(999, TaskPatch{}); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
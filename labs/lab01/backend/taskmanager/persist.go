@@ -0,0 +1,257 @@
+package taskmanager
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultFlushInterval is how long changes wait to be persisted unless the
+// FlushPolicy says otherwise
+const DefaultFlushInterval = time.Second
+
+// Persister saves the state of a manager, such as to a file or a database
+type Persister interface {
+	// Persist saves the tasks in the snapshot, replacing what was saved
+	// before
+	Persist(snapshot *Snapshot) error
+}
+
+// Changes are the tasks a manager changed since its last save, which a
+// SafeTaskManager hands an IncrementalPersister instead of a snapshot of
+// every task
+type Changes struct {
+	// Put holds copies of the tasks added or changed, in ID order
+	Put []*Task
+	// Delete holds the IDs of the tasks no longer active, whether trashed
+	// or removed for good, in ID order
+	Delete []int
+	// snapshot takes a snapshot of the manager the changes are of
+	snapshot func() *Snapshot
+}
+
+// Snapshot returns a snapshot of every task of the manager the changes are
+// of, for persisters that save every task from time to time. It is taken
+// when called, so it may hold writes made after the changes.
+func (c *Changes) Snapshot() *Snapshot {
+	return c.snapshot()
+}
+
+// IncrementalPersister is a Persister that can save just the changes since
+// its last save, at a cost in proportion to them rather than to the number
+// of tasks. A SafeTaskManager records which tasks each write changes when
+// given one, and calls PersistChanges instead of Persist.
+type IncrementalPersister interface {
+	Persister
+	// PersistChanges saves the changes since the last save
+	PersistChanges(changes *Changes) error
+}
+
+// fullChanges returns the changes that bring a store holding the saved
+// tasks to the tasks of the snapshot: every task of the snapshot, and the
+// saved tasks it no longer holds
+func fullChanges(snapshot *Snapshot, saved map[int]struct{}) *Changes {
+	changes := &Changes{Put: slices.Collect(snapshot.tm.tasks.List())}
+	for id := range saved {
+		if _, ok := snapshot.tm.tasks.Get(id); !ok {
+			changes.Delete = append(changes.Delete, id)
+		}
+	}
+	slices.Sort(changes.Delete)
+	changes.snapshot = func() *Snapshot { return snapshot }
+	return changes
+}
+
+// markChanged records that the task with the given ID was added, changed
+// or removed, for the next save of an IncrementalPersister
+func (tm *TaskManager) markChanged(id int) {
+	if tm.changed != nil {
+		tm.changed[id] = struct{}{}
+	}
+}
+
+// takeChanges returns the changes recorded since the last call and starts
+// recording anew
+func (tm *TaskManager) takeChanges() *Changes {
+	ids := slices.Sorted(maps.Keys(tm.changed))
+	clear(tm.changed)
+	changes := &Changes{}
+	for _, id := range ids {
+		if task, ok := tm.tasks.Get(id); ok {
+			changes.Put = append(changes.Put, task.clone())
+		} else {
+			changes.Delete = append(changes.Delete, id)
+		}
+	}
+	return changes
+}
+
+// requeueChanges records the tasks of changes that failed to save as
+// changed again
+func (tm *TaskManager) requeueChanges(changes *Changes) {
+	for _, task := range changes.Put {
+		tm.markChanged(task.ID)
+	}
+	for _, id := range changes.Delete {
+		tm.markChanged(id)
+	}
+}
+
+// FlushPolicy decides how soon a SafeTaskManager persists its changes
+type FlushPolicy struct {
+	// Interval is the longest a change waits before it is persisted. Zero
+	// means DefaultFlushInterval.
+	Interval time.Duration
+	// MaxBatch persists as soon as this many writes are waiting, without
+	// waiting for the interval. Zero waits for the interval.
+	MaxBatch int
+	// OnError, when set, is called with the error of each failed
+	// background flush. The changes stay waiting and are tried again at
+	// the next flush.
+	OnError func(error)
+}
+
+// WithPersister makes a SafeTaskManager persist its state with p. Rather
+// than after every write, which is too slow for stores that rewrite a whole
+// file, the writes made close together are persisted as one from a
+// background goroutine, as the policy allows. Flush persists straight away
+// and Close persists one last time and stops the goroutine. Each manager
+// needs its own Persister, so the option must not be given to
+// NewShardedTaskManager. A TaskManager used on its own ignores the option.
+func WithPersister(p Persister, policy FlushPolicy) Option {
+	return func(tm *TaskManager) {
+		tm.persister = p
+		tm.flushPolicy = policy
+	}
+}
+
+// flusher persists the changes of a SafeTaskManager in the background
+type flusher struct {
+	persister Persister
+	policy    FlushPolicy
+	// pending counts the writes made since the last successful flush
+	pending atomic.Int64
+	// kick asks the goroutine to flush before the interval is up
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+	// mu keeps flushes from overlapping, so snapshots are persisted in the
+	// order they were taken
+	mu sync.Mutex
+}
+
+// startFlusher starts persisting the manager in the background when a
+// Persister was given
+func (s *SafeTaskManager) startFlusher() {
+	if s.tm.persister == nil {
+		return
+	}
+	f := &flusher{
+		persister: s.tm.persister,
+		policy:    s.tm.flushPolicy,
+		kick:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if f.policy.Interval <= 0 {
+		f.policy.Interval = DefaultFlushInterval
+	}
+	if _, ok := f.persister.(IncrementalPersister); ok {
+		s.tm.changed = make(map[int]struct{})
+	}
+	s.flusher = f
+	go s.runFlusher()
+}
+
+// changed records a write. The caller must hold the write lock, so the
+// snapshot of the next flush includes the write.
+func (f *flusher) changed() {
+	if n := f.pending.Add(1); f.policy.MaxBatch > 0 && n >= int64(f.policy.MaxBatch) {
+		select {
+		case f.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// runFlusher flushes at every interval, and whenever a full batch is
+// waiting, until Close
+func (s *SafeTaskManager) runFlusher() {
+	f := s.flusher
+	defer close(f.done)
+	ticker := time.NewTicker(f.policy.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+		case <-f.kick:
+		}
+		if err := s.flush(); err != nil && f.policy.OnError != nil {
+			f.policy.OnError(err)
+		}
+	}
+}
+
+// flush persists a snapshot if any write is waiting
+func (s *SafeTaskManager) flush() error {
+	f := s.flusher
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.pending.Swap(0)
+	if n == 0 {
+		return nil
+	}
+	if err := s.persist(); err != nil {
+		f.pending.Add(n)
+		return err
+	}
+	return nil
+}
+
+// persist saves the changes recorded since the last save to an
+// IncrementalPersister, and a snapshot to any other Persister
+func (s *SafeTaskManager) persist() error {
+	p, ok := s.flusher.persister.(IncrementalPersister)
+	if !ok {
+		return s.flusher.persister.Persist(s.Snapshot())
+	}
+	s.mu.Lock()
+	changes := s.tm.takeChanges()
+	s.mu.Unlock()
+	changes.snapshot = s.Snapshot
+	if err := p.PersistChanges(changes); err != nil {
+		s.mu.Lock()
+		s.tm.requeueChanges(changes)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Flush persists the writes still waiting, if any, and returns once they
+// are saved. It does nothing without WithPersister.
+func (s *SafeTaskManager) Flush() error {
+	if s.flusher == nil {
+		return nil
+	}
+	return s.flush()
+}
+
+// Close stops persisting in the background and flushes the writes still
+// waiting. Writes made after Close are only persisted by explicit calls to
+// Flush. Close may be called more than once.
+func (s *SafeTaskManager) Close() error {
+	if s.flusher == nil {
+		return nil
+	}
+	s.flusher.once.Do(func() {
+		close(s.flusher.stop)
+		<-s.flusher.done
+	})
+	return s.flush()
+}
@@ -0,0 +1,146 @@
+package taskmanager
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingPersister remembers the number of tasks in each snapshot it is
+// given, failing while fail is set
+type recordingPersister struct {
+	mu     sync.Mutex
+	saved  []int
+	fail   error
+	notify chan struct{}
+}
+
+func newRecordingPersister() *recordingPersister {
+	return &recordingPersister{notify: make(chan struct{}, 10)}
+}
+
+func (p *recordingPersister) Persist(snapshot *Snapshot) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail != nil {
+		return p.fail
+	}
+	p.saved = append(p.saved, snapshot.Len())
+	p.notify <- struct{}{}
+	return nil
+}
+
+func (p *recordingPersister) snapshots() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.saved...)
+}
+
+// wait fails the test unless a snapshot is persisted within a second
+func (p *recordingPersister) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-p.notify:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a snapshot to be persisted")
+	}
+}
+
+func TestFlushMaxBatch(t *testing.T) {
+	p := newRecordingPersister()
+	s := NewSafeTaskManager(WithPersister(p, FlushPolicy{Interval: time.Hour, MaxBatch: 3}))
+	defer s.Close()
+
+	for range 3 {
+		s.AddTask("Task", "")
+	}
+	p.wait(t)
+	if got := p.snapshots(); len(got) != 1 || got[0] != 3 {
+		t.Errorf("Expected the batch of 3 writes persisted together, got %v", got)
+	}
+
+	s.AddTask("Task", "")
+	s.AddTask("Task", "")
+	if got := p.snapshots(); len(got) != 1 {
+		t.Errorf("Expected writes below the batch size to wait, got %v", got)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := p.snapshots(); len(got) != 2 || got[1] != 5 {
+		t.Errorf("Expected Flush to persist the waiting writes, got %v", got)
+	}
+	if err := s.Flush(); err != nil || len(p.snapshots()) != 2 {
+		t.Errorf("Expected nothing to persist with no writes waiting, got %v (%v)", p.snapshots(), err)
+	}
+}
+
+func TestFlushInterval(t *testing.T) {
+	p := newRecordingPersister()
+	s := NewSafeTaskManager(WithPersister(p, FlushPolicy{Interval: 5 * time.Millisecond}))
+	defer s.Close()
+
+	s.AddTask("Task", "")
+	p.wait(t)
+	if got := p.snapshots(); got[0] != 1 {
+		t.Errorf("Expected the write persisted after the interval, got %v", got)
+	}
+}
+
+func TestFlushErrors(t *testing.T) {
+	p := newRecordingPersister()
+	p.fail = errors.New("disk full")
+	failures := make(chan error, 10)
+	s := NewSafeTaskManager(WithPersister(p, FlushPolicy{
+		Interval: time.Hour,
+		MaxBatch: 1,
+		OnError:  func(err error) { failures <- err },
+	}))
+	defer s.Close()
+
+	s.AddTask("Task", "")
+	select {
+	case err := <-failures:
+		if err != p.fail {
+			t.Errorf("Expected the persister's error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the background flush to report its error")
+	}
+	if err := s.Flush(); err != p.fail {
+		t.Errorf("Expected Flush to fail too, got %v", err)
+	}
+
+	p.mu.Lock()
+	p.fail = nil
+	p.mu.Unlock()
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := p.snapshots(); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Expected the failed write to be persisted on retry, got %v", got)
+	}
+}
+
+func TestCloseFlushes(t *testing.T) {
+	p := newRecordingPersister()
+	s := NewSafeTaskManager(WithPersister(p, FlushPolicy{Interval: time.Hour}))
+	s.AddTask("Task", "")
+	s.AddTask("Task", "")
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := p.snapshots(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected Close to persist the waiting writes, got %v", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Expected a second Close to do nothing, got %v", err)
+	}
+
+	plain := NewSafeTaskManager()
+	if plain.Flush() != nil || plain.Close() != nil {
+		t.Error("Expected Flush and Close to do nothing without a persister")
+	}
+}
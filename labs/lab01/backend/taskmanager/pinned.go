@@ -0,0 +1,30 @@
+package taskmanager
+
+// PinTask marks a task as pinned so listings can surface it first
+func (tm *TaskManager) PinTask(id int) error {
+	return tm.setPinned(id, true)
+}
+
+// UnpinTask removes the pinned mark from a task
+func (tm *TaskManager) UnpinTask(id int) error {
+	return tm.setPinned(id, false)
+}
+
+// PinnedFirst lists pinned tasks ahead of the others. Within each group the
+// other sort options still apply.
+func PinnedFirst() ListOption {
+	return func(q *listQuery) {
+		q.pinnedFirst = true
+	}
+}
+
+func (tm *TaskManager) setPinned(id int, pinned bool) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.Pinned = pinned
+	})
+	return nil
+}
@@ -0,0 +1,52 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPinnedFirst(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	parent := mustAddTask(t, tm, "Parent", WithPriority(PriorityLow))
+	now = now.Add(time.Minute)
+	mustAddTask(t, tm, "Urgent", WithPriority(PriorityUrgent), WithDueDate(now.Add(-2*time.Hour)), WithParent(parent.ID))
+	now = now.Add(time.Minute)
+	low := mustAddTask(t, tm, "Low", WithPriority(PriorityLow), WithDueDate(now.Add(-time.Hour)), WithParent(parent.ID))
+	if err := tm.PinTask(low.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	children, _ := tm.ListChildren(parent.ID, PinnedFirst())
+	tests := []struct {
+		name     string
+		got      []*Task
+		expected []string
+	}{
+		{name: "default order", got: tm.ListTasks(nil), expected: []string{"Parent", "Urgent", "Low"}},
+		{name: "pinned first", got: tm.ListTasks(nil, PinnedFirst()), expected: []string{"Low", "Parent", "Urgent"}},
+		{name: "pinned before priority", got: tm.ListTasks(nil, PinnedFirst(), SortByPriority()), expected: []string{"Low", "Urgent", "Parent"}},
+		{name: "overdue", got: tm.ListOverdue(), expected: []string{"Urgent", "Low"}},
+		{name: "overdue pinned first", got: tm.ListOverdue(PinnedFirst()), expected: []string{"Low", "Urgent"}},
+		{name: "children pinned first", got: children, expected: []string{"Low", "Urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tt.got); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if err := tm.UnpinTask(low.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(tm.ListTasks(nil, PinnedFirst())); got[0] != "Parent" {
+		t.Errorf("Expected unpinned task to lose its place, got %v", got)
+	}
+	if err := tm.PinTask(999); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
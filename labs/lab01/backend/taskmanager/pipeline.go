@@ -0,0 +1,207 @@
+package taskmanager
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"iter"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ImportOptions configures ImportRecords
+type ImportOptions struct {
+	// Workers is the number of goroutines parsing and validating records.
+	// Zero means one per CPU.
+	Workers int
+	// BatchSize is the number of records a worker takes at a time, and the
+	// most tasks added under one write lock. Zero means 512.
+	BatchSize int
+	// Progress, when set, is called after each batch is added, from the
+	// goroutine running ImportRecords
+	Progress func(ImportProgress)
+}
+
+// ImportProgress counts the records an import has been through
+type ImportProgress struct {
+	// Processed is the number of records added or failed so far
+	Processed int
+	Added     int
+	Failed    int
+}
+
+// importBatch is a run of consecutive records and, once a worker has been
+// through them, the inputs they parsed to and the records that failed
+type importBatch[R any] struct {
+	seq     int
+	start   int
+	records []R
+	inputs  []TaskInput
+	// indexes holds the record index of each input
+	indexes  []int
+	failures []ItemError
+}
+
+// ImportRecords adds a task for each record, which parse turns into the
+// task's input. Records are parsed, sanitized and validated by a bounded
+// pool of worker goroutines, so parse must be safe for concurrent use, while
+// the tasks are added in record order, each batch with ImportTasks under
+// the write lock and undone as one operation. Records that fail to parse or
+// validate are reported in a *BulkError, indexed by their position in
+// records.
+//
+// Cancelling ctx stops the import after the batch being added. The tasks
+// added until then are kept and counted in the returned progress, and the
+// error is ctx.Err().
+func ImportRecords[R any](ctx context.Context, s *SafeTaskManager, records iter.Seq[R], parse func(R) (TaskInput, error), opts ImportOptions) (ImportProgress, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	size := opts.BatchSize
+	if size <= 0 {
+		size = importSlabSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Batches can finish out of order; bounding the batches in flight
+	// bounds how many wait their turn in memory
+	inflight := make(chan struct{}, 2*workers)
+	batches := make(chan *importBatch[R])
+	results := make(chan *importBatch[R], workers)
+
+	go func() {
+		defer close(batches)
+		batch := &importBatch[R]{}
+		send := func() bool {
+			select {
+			case inflight <- struct{}{}:
+			case <-ctx.Done():
+				return false
+			}
+			select {
+			case batches <- batch:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		n := 0
+		for record := range records {
+			batch.records = append(batch.records, record)
+			n++
+			if len(batch.records) == size {
+				if !send() {
+					return
+				}
+				batch = &importBatch[R]{seq: batch.seq + 1, start: n}
+			}
+		}
+		if len(batch.records) > 0 {
+			send()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				prepareBatch(s.tm, batch, parse)
+				select {
+				case results <- batch:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var progress ImportProgress
+	var failures []ItemError
+	pending := make(map[int]*importBatch[R])
+	next := 0
+	for batch := range results {
+		if ctx.Err() != nil {
+			continue
+		}
+		pending[batch.seq] = batch
+		for ctx.Err() == nil {
+			batch, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			batchFailures := addBatch(s, batch)
+			failures = append(failures, batchFailures...)
+			progress.Processed += len(batch.records)
+			progress.Failed += len(batchFailures)
+			progress.Added += len(batch.records) - len(batchFailures)
+			<-inflight
+			if opts.Progress != nil {
+				opts.Progress(progress)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return progress, err
+	}
+	return progress, bulkError(failures)
+}
+
+// prepareBatch parses the records of a batch and checks the resulting
+// tasks as far as that can be done without the manager's lock: the checks
+// that depend on other tasks, projects or the task limit are left to
+// ImportTasks. Only settings fixed when tm was created are read.
+func prepareBatch[R any](tm *TaskManager, batch *importBatch[R], parse func(R) (TaskInput, error)) {
+	for i, record := range batch.records {
+		index := batch.start + i
+		in, err := parse(record)
+		if err == nil {
+			in.Description = sanitizeMarkdown(in.Description)
+			var probe Task
+			initTask(&probe, in.Title, in.Description, time.Time{}, in.Options)
+			if err = validateTask(&probe); err == nil {
+				err = tm.validateLengths(&probe)
+			}
+		}
+		if err != nil {
+			batch.failures = append(batch.failures, ItemError{Index: index, Err: err})
+			continue
+		}
+		batch.inputs = append(batch.inputs, in)
+		batch.indexes = append(batch.indexes, index)
+	}
+}
+
+// addBatch adds the inputs of a prepared batch under the write lock and
+// returns every failure in the batch in record order
+func addBatch[R any](s *SafeTaskManager, batch *importBatch[R]) []ItemError {
+	failures := batch.failures
+	if len(batch.inputs) == 0 {
+		return failures
+	}
+	unlock := s.lock()
+	_, err := s.tm.importTasks(batch.inputs, func(description string) string { return description })
+	unlock()
+
+	var bulk *BulkError
+	if errors.As(err, &bulk) {
+		for _, f := range bulk.Failures {
+			failures = append(failures, ItemError{Index: batch.indexes[f.Index], Err: f.Err})
+		}
+		slices.SortFunc(failures, func(a, b ItemError) int {
+			return cmp.Compare(a.Index, b.Index)
+		})
+	}
+	return failures
+}
@@ -0,0 +1,116 @@
+package taskmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseRecord turns "title" or "title|priority" into a task input
+func parseRecord(record string) (TaskInput, error) {
+	title, priority, ok := strings.Cut(record, "|")
+	if !ok {
+		return TaskInput{Title: record}, nil
+	}
+	p, err := strconv.Atoi(priority)
+	if err != nil {
+		return TaskInput{}, fmt.Errorf("bad priority %q", priority)
+	}
+	return TaskInput{Title: title, Options: []TaskOption{WithPriority(Priority(p))}}, nil
+}
+
+func TestImportRecords(t *testing.T) {
+	s := NewSafeTaskManager(WithMaxTasks(95))
+	var records, expected []string
+	for i := range 100 {
+		record := fmt.Sprintf("Task %03d", i)
+		switch {
+		case i == 10:
+			record += "|x"
+		case i == 20:
+			record = ""
+		default:
+			expected = append(expected, record)
+		}
+		records = append(records, record)
+	}
+
+	var updates []ImportProgress
+	progress, err := ImportRecords(context.Background(), s, slices.Values(records), parseRecord, ImportOptions{
+		Workers:   4,
+		BatchSize: 8,
+		Progress:  func(p ImportProgress) { updates = append(updates, p) },
+	})
+
+	var bulk *BulkError
+	if !errors.As(err, &bulk) {
+		t.Fatalf("Expected a *BulkError, got %v", err)
+	}
+	var indexes []int
+	for _, f := range bulk.Failures {
+		indexes = append(indexes, f.Index)
+	}
+	// The parse error, the empty title, and the three records past the
+	// task limit
+	if !slices.Equal(indexes, []int{10, 20, 97, 98, 99}) {
+		t.Errorf("Expected failures at 10, 20, 97, 98 and 99, got %v", indexes)
+	}
+	if !errors.Is(bulk.Failures[1].Err, ErrEmptyTitle) || !errors.Is(bulk.Failures[4].Err, ErrTooManyTasks) {
+		t.Errorf("Expected validation errors to be reported, got %v", bulk.Failures)
+	}
+
+	if progress != (ImportProgress{Processed: 100, Added: 95, Failed: 5}) {
+		t.Errorf("Expected 95 added and 5 failed, got %+v", progress)
+	}
+	if len(updates) != 13 || updates[len(updates)-1] != progress {
+		t.Errorf("Expected a progress update per batch ending with %+v, got %v", progress, updates)
+	}
+	for i := 1; i < len(updates); i++ {
+		if updates[i].Processed <= updates[i-1].Processed {
+			t.Fatalf("Expected progress to grow, got %v", updates)
+		}
+	}
+
+	// Tasks are added in record order whatever order the workers finish in
+	listed := titles(s.ListTasks(nil))
+	if !slices.Equal(listed, expected[:95]) {
+		t.Errorf("Expected the tasks in record order, got %v", listed)
+	}
+}
+
+func TestImportRecordsCancel(t *testing.T) {
+	s := NewSafeTaskManager()
+	records := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	parse := func(i int) (TaskInput, error) {
+		return TaskInput{Title: fmt.Sprintf("Task %d", i)}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	progress, err := ImportRecords(ctx, s, records, parse, ImportOptions{
+		BatchSize: 10,
+		Progress: func(p ImportProgress) {
+			if p.Processed >= 30 {
+				cancel()
+			}
+		},
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if progress.Added != 30 {
+		t.Errorf("Expected the import to stop after the batch in progress, got %+v", progress)
+	}
+	if count := s.Stats().Tasks; count != progress.Added {
+		t.Errorf("Expected %d tasks, got %d", progress.Added, count)
+	}
+}
@@ -0,0 +1,67 @@
+package taskmanager
+
+import "errors"
+
+// ErrInvalidPriority is returned when a task priority is not one of the known levels
+var ErrInvalidPriority = errors.New("invalid task priority")
+
+// Priority represents how urgent a task is
+type Priority int
+
+const (
+	// PriorityLow is for tasks that can wait
+	PriorityLow Priority = iota + 1
+	// PriorityMedium is the default priority for new tasks
+	PriorityMedium
+	// PriorityHigh is for tasks that should be done soon
+	PriorityHigh
+	// PriorityUrgent is for tasks that need attention first
+	PriorityUrgent
+)
+
+// Valid reports whether the priority is one of the known levels
+func (p Priority) Valid() bool {
+	return p >= PriorityLow && p <= PriorityUrgent
+}
+
+// String returns a human-readable name for the priority
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityMedium:
+		return "medium"
+	case PriorityHigh:
+		return "high"
+	case PriorityUrgent:
+		return "urgent"
+	default:
+		return "unknown"
+	}
+}
+
+// WithPriority sets the priority of a task
+func WithPriority(p Priority) TaskOption {
+	return func(t *Task) {
+		t.Priority = p
+	}
+}
+
+// FilterByPriority limits ListTasks to tasks with one of the given priorities
+func FilterByPriority(priorities ...Priority) ListOption {
+	return func(q *listQuery) {
+		if q.priorities == nil {
+			q.priorities = make(map[Priority]bool)
+		}
+		for _, p := range priorities {
+			q.priorities[p] = true
+		}
+	}
+}
+
+// SortByPriority orders ListTasks results from most to least urgent
+func SortByPriority() ListOption {
+	return func(q *listQuery) {
+		q.sortByPriority = true
+	}
+}
@@ -0,0 +1,102 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestAddTaskPriority(t *testing.T) {
+	tm := NewTaskManager()
+	tests := []struct {
+		name        string
+		opts        []TaskOption
+		expected    Priority
+		expectError bool
+	}{
+		{
+			name:     "default priority",
+			expected: PriorityMedium,
+		},
+		{
+			name:     "urgent priority",
+			opts:     []TaskOption{WithPriority(PriorityUrgent)},
+			expected: PriorityUrgent,
+		},
+		{
+			name:        "invalid priority",
+			opts:        []TaskOption{WithPriority(Priority(42))},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := tm.AddTask("Test Task", "Description", tt.opts...)
+
+			if tt.expectError {
+				if err != ErrInvalidPriority {
+					t.Errorf("Expected ErrInvalidPriority, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if task.Priority != tt.expected {
+				t.Errorf("Expected priority %v, got %v", tt.expected, task.Priority)
+			}
+		})
+	}
+}
+
+func TestUpdateTaskPriority(t *testing.T) {
+	tm := NewTaskManager()
+	task, err := tm.AddTask("Test Task", "Description", WithPriority(PriorityHigh))
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	if err := tm.UpdateTask(task.ID, "Test Task", "Description", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Priority != PriorityHigh {
+		t.Errorf("Expected priority to be kept as %v, got %v", PriorityHigh, task.Priority)
+	}
+
+	err = tm.UpdateTask(task.ID, "Test Task", "Description", false, WithPriority(0))
+	if err != ErrInvalidPriority {
+		t.Errorf("Expected ErrInvalidPriority, got %v", err)
+	}
+	if task.Priority != PriorityHigh {
+		t.Errorf("Invalid update should not change priority, got %v", task.Priority)
+	}
+}
+
+func TestListTasksByPriority(t *testing.T) {
+	tm := NewTaskManager()
+	low := mustAddTask(t, tm, "Low", WithPriority(PriorityLow))
+	urgent := mustAddTask(t, tm, "Urgent", WithPriority(PriorityUrgent))
+	high := mustAddTask(t, tm, "High", WithPriority(PriorityHigh))
+
+	sorted := tm.ListTasks(nil, SortByPriority())
+	expected := []*Task{urgent, high, low}
+	if len(sorted) != len(expected) {
+		t.Fatalf("Expected %d tasks, got %d", len(expected), len(sorted))
+	}
+	for i, task := range expected {
+		if sorted[i] != task {
+			t.Errorf("Position %d: expected %q, got %q", i, task.Title, sorted[i].Title)
+		}
+	}
+
+	filtered := tm.ListTasks(nil, FilterByPriority(PriorityHigh, PriorityUrgent))
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(filtered))
+	}
+	for _, task := range filtered {
+		if task == low {
+			t.Error("Low priority task should have been filtered out")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package taskmanager
+
+// Progress returns how far a task is along, from 0 to 1. Done tasks count as
+// complete; otherwise every checklist item and every subtask that is not
+// cancelled weighs the same, with subtasks contributing their own progress.
+// The value is kept up to date in Task.Progress.
+func (tm *TaskManager) Progress(id int) (float64, error) {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return 0, err
+	}
+	return task.Progress, nil
+}
+
+// refreshProgress recomputes the cached progress of a task and of every task
+// above it
+func (tm *TaskManager) refreshProgress(id int) {
+	for id != 0 {
+		task, ok := tm.tasks.Get(id)
+		if !ok {
+			return
+		}
+		if progress := tm.progressOf(task); progress != task.Progress {
+			task.Progress = progress
+			tm.markChanged(id)
+		}
+		id = task.ParentID
+	}
+}
+
+// progressOf computes the progress of a task from its subtasks' cached values
+func (tm *TaskManager) progressOf(task *Task) float64 {
+	return progressFrom(task, tm.children(task.ID))
+}
+
+// progressFrom computes the progress of a task from its checklist and the
+// cached values of the given subtasks
+func progressFrom(task *Task, children []*Task) float64 {
+	if task.Status == StatusDone {
+		return 1
+	}
+	var total, done float64
+	for _, item := range task.Checklist {
+		total++
+		if item.Done {
+			done++
+		}
+	}
+	for _, child := range children {
+		if child.Status == StatusCancelled {
+			continue
+		}
+		total++
+		done += child.Progress
+	}
+	if total == 0 {
+		return 0
+	}
+	return done / total
+}
@@ -0,0 +1,71 @@
+package taskmanager
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tm := NewTaskManager()
+	root := mustAddTask(t, tm, "Release")
+	build := mustAddTask(t, tm, "Build", WithParent(root.ID))
+	docs := mustAddTask(t, tm, "Docs", WithParent(root.ID))
+	item, err := tm.AddChecklistItem(build.ID, "Compile")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.AddChecklistItem(build.ID, "Package"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	steps := []struct {
+		name     string
+		run      func() error
+		expected float64
+	}{
+		{name: "nothing done", run: func() error { return nil }, expected: 0},
+		{name: "checklist item", run: func() error { return tm.ToggleChecklistItem(build.ID, item.ID) }, expected: 0.25},
+		{name: "subtask done", run: func() error { return tm.Transition(docs.ID, StatusDone) }, expected: 0.75},
+		{name: "subtask reopened", run: func() error { return tm.Transition(docs.ID, StatusTodo) }, expected: 0.25},
+		{name: "cancelled subtask ignored", run: func() error { return tm.Transition(docs.ID, StatusCancelled) }, expected: 0.5},
+		{name: "subtask deleted", run: func() error { return tm.DeleteTask(build.ID) }, expected: 0},
+		{name: "undo delete", run: tm.Undo, expected: 0.5},
+		{name: "root done", run: func() error { return tm.Transition(root.ID, StatusDone) }, expected: 1},
+	}
+
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		got, err := tm.Progress(root.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if math.Abs(got-step.expected) > 1e-9 || root.Progress != got {
+			t.Errorf("%s: expected progress %v, got %v", step.name, step.expected, got)
+		}
+	}
+}
+
+func TestProgressFollowsParentChange(t *testing.T) {
+	tm := NewTaskManager()
+	a := mustAddTask(t, tm, "A")
+	b := mustAddTask(t, tm, "B")
+	child := mustAddTask(t, tm, "Child", WithParent(a.ID))
+	if err := tm.Transition(child.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Progress != 1 {
+		t.Fatalf("Expected A at full progress, got %v", a.Progress)
+	}
+
+	if err := tm.UpdateTaskFields(child.ID, TaskPatch{ParentID: ptr(b.ID)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Progress != 0 || b.Progress != 1 {
+		t.Errorf("Expected progress to move with the subtask, got A=%v B=%v", a.Progress, b.Progress)
+	}
+	if _, err := tm.Progress(999); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
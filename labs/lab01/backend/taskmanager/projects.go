@@ -0,0 +1,160 @@
+package taskmanager
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrProjectNotFound is returned when a project is not found
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrEmptyProjectName is returned when a project name is empty
+	ErrEmptyProjectName = errors.New("project name cannot be empty")
+	// ErrProjectExists is returned when another project already has the same name
+	ErrProjectExists = errors.New("project with this name already exists")
+)
+
+// Project groups related tasks into a list
+type Project struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+}
+
+// WithProject places the task in the given project. A project ID of zero
+// removes the task from its project.
+func WithProject(projectID int) TaskOption {
+	return func(t *Task) {
+		t.ProjectID = projectID
+	}
+}
+
+// CreateProject adds a new project
+func (tm *TaskManager) CreateProject(name string) (*Project, error) {
+	name, err := tm.checkProjectName(0, name)
+	if err != nil {
+		return nil, err
+	}
+	project := &Project{
+		ID:        tm.nextProjectID,
+		Name:      name,
+		CreatedAt: tm.now(),
+	}
+	tm.nextProjectID++
+	tm.projects[project.ID] = project
+	return project, nil
+}
+
+// GetProject retrieves a project by ID
+func (tm *TaskManager) GetProject(id int) (*Project, error) {
+	project, ok := tm.projects[id]
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+	return project, nil
+}
+
+// ListProjects returns all projects, oldest first
+func (tm *TaskManager) ListProjects() []*Project {
+	result := make([]*Project, 0, len(tm.projects))
+	for _, project := range tm.projects {
+		result = append(result, project)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// RenameProject changes the name of a project
+func (tm *TaskManager) RenameProject(id int, name string) error {
+	project, err := tm.GetProject(id)
+	if err != nil {
+		return err
+	}
+	name, err = tm.checkProjectName(id, name)
+	if err != nil {
+		return err
+	}
+	project.Name = name
+	return nil
+}
+
+// DeleteProject removes a project. Its tasks are kept and no longer belong
+// to any project.
+func (tm *TaskManager) DeleteProject(id int) error {
+	if _, err := tm.GetProject(id); err != nil {
+		return err
+	}
+	for task := range tm.tasks.List() {
+		if task.ProjectID == id {
+			tm.update(task, func() {
+				task.ProjectID = 0
+			})
+		}
+	}
+	delete(tm.projects, id)
+	return nil
+}
+
+// MoveTaskToProject moves a task into a project, or out of any project when
+// projectID is zero
+func (tm *TaskManager) MoveTaskToProject(taskID, projectID int) error {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return err
+	}
+	if projectID != 0 {
+		if _, err := tm.GetProject(projectID); err != nil {
+			return err
+		}
+	}
+	tm.update(task, func() {
+		task.ProjectID = projectID
+	})
+	return nil
+}
+
+// ListProjectTasks returns the tasks of a project
+func (tm *TaskManager) ListProjectTasks(projectID int, opts ...ListOption) ([]*Task, error) {
+	if _, err := tm.GetProject(projectID); err != nil {
+		return nil, err
+	}
+	return tm.ListTasks(nil, append(opts, FilterByProject(projectID))...), nil
+}
+
+// FilterByProject limits ListTasks to tasks in the given project, or to
+// tasks outside any project when projectID is zero
+func FilterByProject(projectID int) ListOption {
+	return func(q *listQuery) {
+		q.project = &projectID
+	}
+}
+
+// checkProjectName trims a project name and checks it is non-empty and not
+// used by a project other than id
+func (tm *TaskManager) checkProjectName(id int, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrEmptyProjectName
+	}
+	for _, project := range tm.projects {
+		if project.ID != id && strings.EqualFold(project.Name, name) {
+			return "", ErrProjectExists
+		}
+	}
+	return name, nil
+}
+
+// validateProject checks that the task's project exists
+func (tm *TaskManager) validateProject(task *Task) error {
+	if task.ProjectID == 0 {
+		return nil
+	}
+	if _, ok := tm.projects[task.ProjectID]; !ok {
+		return ErrProjectNotFound
+	}
+	return nil
+}
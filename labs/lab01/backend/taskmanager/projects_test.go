@@ -0,0 +1,109 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestCreateProject(t *testing.T) {
+	tm := NewTaskManager()
+	if _, err := tm.CreateProject("Home"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		projectName string
+		expectError error
+	}{
+		{name: "valid project", projectName: "Work"},
+		{name: "empty name", projectName: "  ", expectError: ErrEmptyProjectName},
+		{name: "duplicate name", projectName: "home", expectError: ErrProjectExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := tm.CreateProject(tt.projectName)
+
+			if tt.expectError != nil {
+				if err != tt.expectError {
+					t.Errorf("Expected %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if project.Name != tt.projectName {
+				t.Errorf("Expected name %s, got %s", tt.projectName, project.Name)
+			}
+		})
+	}
+
+	if got := tm.ListProjects(); len(got) != 2 {
+		t.Errorf("Expected 2 projects, got %d", len(got))
+	}
+}
+
+func TestRenameAndDeleteProject(t *testing.T) {
+	tm := NewTaskManager()
+	home, _ := tm.CreateProject("Home")
+	work, _ := tm.CreateProject("Work")
+	task := mustAddTask(t, tm, "Clean", WithProject(home.ID))
+
+	if err := tm.RenameProject(home.ID, "Work"); err != ErrProjectExists {
+		t.Errorf("Expected ErrProjectExists, got %v", err)
+	}
+	if err := tm.RenameProject(home.ID, "House"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if home.Name != "House" {
+		t.Errorf("Expected name House, got %s", home.Name)
+	}
+
+	if err := tm.DeleteProject(home.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.ProjectID != 0 {
+		t.Errorf("Expected task to leave the deleted project, got %d", task.ProjectID)
+	}
+	if _, err := tm.GetProject(home.ID); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound, got %v", err)
+	}
+	if _, err := tm.AddTask("Orphan", "", WithProject(home.ID)); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound, got %v", err)
+	}
+	if _, err := tm.AddTask("Report", "", WithProject(work.ID)); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMoveTaskToProject(t *testing.T) {
+	tm := NewTaskManager()
+	home, _ := tm.CreateProject("Home")
+	work, _ := tm.CreateProject("Work")
+	task := mustAddTask(t, tm, "Report", WithProject(home.ID))
+	mustAddTask(t, tm, "Inbox item")
+
+	if err := tm.MoveTaskToProject(task.ID, work.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.MoveTaskToProject(task.ID, 999); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound, got %v", err)
+	}
+
+	workTasks, err := tm.ListProjectTasks(work.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(workTasks) != 1 || workTasks[0] != task {
+		t.Errorf("Expected the report in the work project, got %v", workTasks)
+	}
+	homeTasks, _ := tm.ListProjectTasks(home.ID)
+	if len(homeTasks) != 0 {
+		t.Errorf("Expected no tasks left in home, got %v", homeTasks)
+	}
+	if inbox := tm.ListTasks(nil, FilterByProject(0)); len(inbox) != 1 {
+		t.Errorf("Expected 1 task outside projects, got %d", len(inbox))
+	}
+}
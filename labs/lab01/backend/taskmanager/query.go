@@ -0,0 +1,273 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidQuery is returned when a query string cannot be parsed
+var ErrInvalidQuery = errors.New("invalid query")
+
+// queryDateLayout is the format of dates in a query
+const queryDateLayout = "2006-01-02"
+
+// QueryError describes where and why a query string failed to parse
+type QueryError struct {
+	// Pos is the byte offset of the offending term in the query
+	Pos int
+	Msg string
+}
+
+// Error implements the error interface
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%v at position %d: %s", ErrInvalidQuery, e.Pos, e.Msg)
+}
+
+// Is makes errors.Is(err, ErrInvalidQuery) match a QueryError
+func (e *QueryError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// ParseQuery compiles a search bar query such as
+//
+//	status:done tag:home due<2025-07-01 "report"
+//
+// into ListOptions. Terms are separated by spaces and values may be quoted
+// to include spaces. The filters understood are:
+//
+//	status:in_progress   one of the statuses, repeatable
+//	priority:high        one of the priorities, repeatable
+//	tag:home             carries the tag, repeatable
+//	assignee:alice       assigned to alice
+//	project:3            in project 3
+//	is:done, is:open     done or open tasks
+//	due:2025-07-01       due on that day; also due<, due<=, due> and due>=
+//	created>=2025-07-01  created in that range, with the same operators
+//	completed:2025-07-01 completed in that range, with the same operators
+//	regex:"^pay\b"       title or description matches, as in FilterByRegex
+//	sort:-priority       the order, as accepted by ParseSortSpec
+//
+// Dates are whole days in UTC. Any other words and quoted phrases are
+// joined into the text query; quote a word such as "http://example.com" to
+// keep it from being read as an unknown filter. Errors are *QueryError
+// values.
+func ParseQuery(query string) (ListOptions, error) {
+	terms, err := splitQuery(query)
+	if err != nil {
+		return ListOptions{}, err
+	}
+
+	var opts ListOptions
+	var text []string
+	for _, term := range terms {
+		key, op, value, ok := cutQueryTerm(term.text)
+		if !ok {
+			text = append(text, unquote(term.text))
+			continue
+		}
+		if err := opts.applyQueryFilter(key, op, unquote(value)); err != nil {
+			return ListOptions{}, &QueryError{Pos: term.pos, Msg: err.Error()}
+		}
+	}
+	opts.Query = strings.Join(text, " ")
+	if err := opts.Validate(); err != nil {
+		return ListOptions{}, &QueryError{Pos: 0, Msg: err.Error()}
+	}
+	return opts, nil
+}
+
+// applyQueryFilter adds a single key/value filter from a query
+func (o *ListOptions) applyQueryFilter(key, op, value string) error {
+	if op != ":" && !dateFilters[key] {
+		return fmt.Errorf("%s does not support %q", key, op)
+	}
+	if value == "" {
+		return fmt.Errorf("missing value for %s", key)
+	}
+
+	switch key {
+	case "status":
+		status, err := parseStatus(value)
+		if err != nil {
+			return err
+		}
+		o.Statuses = append(o.Statuses, status)
+	case "priority":
+		priority, err := parsePriority(value)
+		if err != nil {
+			return err
+		}
+		o.Priorities = append(o.Priorities, priority)
+	case "tag":
+		o.Tags = append(o.Tags, value)
+	case "assignee":
+		o.AssigneeID = &value
+	case "project":
+		id, err := strconv.Atoi(value)
+		if err != nil || id < 0 {
+			return fmt.Errorf("invalid project %q", value)
+		}
+		o.ProjectID = &id
+	case "is":
+		var done bool
+		switch value {
+		case "done":
+			done = true
+		case "open":
+			done = false
+		default:
+			return fmt.Errorf("unknown state %q", value)
+		}
+		o.Done = &done
+	case "due", "created", "completed":
+		day, err := time.Parse(queryDateLayout, value)
+		if err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+		}
+		r := o.dateRange(key)
+		*r = r.narrow(dayRange(day, op))
+	case "regex":
+		if _, err := compileSearchRegex(value); err != nil {
+			return err
+		}
+		o.Pattern = value
+	case "sort":
+		spec, err := ParseSortSpec(value)
+		if err != nil {
+			return err
+		}
+		o.Sort = spec
+	default:
+		return fmt.Errorf("unknown filter %q", key)
+	}
+	return nil
+}
+
+// dateFilters are the query keys compared with dates
+var dateFilters = map[string]bool{"due": true, "created": true, "completed": true}
+
+// dateRange returns the range a date filter of the query narrows
+func (o *ListOptions) dateRange(key string) *TimeRange {
+	switch key {
+	case "created":
+		return &o.Created
+	case "completed":
+		return &o.Completed
+	default:
+		return &o.Due
+	}
+}
+
+// dayRange returns the times selected by comparing a date with the whole
+// day starting at day
+func dayRange(day time.Time, op string) TimeRange {
+	next := day.AddDate(0, 0, 1)
+	switch op {
+	case "<":
+		return TimeRange{To: day}
+	case "<=":
+		return TimeRange{To: next}
+	case ">":
+		return TimeRange{From: next}
+	case ">=":
+		return TimeRange{From: day}
+	default:
+		return TimeRange{From: day, To: next}
+	}
+}
+
+// narrow returns the overlap of two ranges
+func (r TimeRange) narrow(other TimeRange) TimeRange {
+	if r.From.IsZero() || other.From.After(r.From) {
+		r.From = other.From
+	}
+	if r.To.IsZero() || (!other.To.IsZero() && other.To.Before(r.To)) {
+		r.To = other.To
+	}
+	return r
+}
+
+// parseStatus looks a status up by name, accepting "_" or "-" for spaces
+func parseStatus(name string) (Status, error) {
+	normalized := strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(name))
+	for s := StatusTodo; s.Valid(); s++ {
+		if s.String() == normalized {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown status %q", name)
+}
+
+// parsePriority looks a priority up by name
+func parsePriority(name string) (Priority, error) {
+	for p := PriorityLow; p.Valid(); p++ {
+		if p.String() == strings.ToLower(name) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown priority %q", name)
+}
+
+// queryTerm is one space-separated term of a query and its byte offset
+type queryTerm struct {
+	pos  int
+	text string
+}
+
+// splitQuery splits a query at spaces outside double quotes
+func splitQuery(query string) ([]queryTerm, error) {
+	var terms []queryTerm
+	start, quote := -1, -1
+	for i, r := range query {
+		switch {
+		case r == '"':
+			if quote < 0 {
+				quote = i
+			} else {
+				quote = -1
+			}
+			if start < 0 {
+				start = i
+			}
+		case unicode.IsSpace(r) && quote < 0:
+			if start >= 0 {
+				terms = append(terms, queryTerm{pos: start, text: query[start:i]})
+				start = -1
+			}
+		case start < 0:
+			start = i
+		}
+	}
+	if quote >= 0 {
+		return nil, &QueryError{Pos: quote, Msg: "unterminated quote"}
+	}
+	if start >= 0 {
+		terms = append(terms, queryTerm{pos: start, text: query[start:]})
+	}
+	return terms, nil
+}
+
+// cutQueryTerm splits a key:value or key<value term. Terms that do not
+// start with a lowercase key followed by an operator are plain text.
+func cutQueryTerm(term string) (key, op, value string, ok bool) {
+	i := strings.IndexFunc(term, func(r rune) bool { return r < 'a' || r > 'z' })
+	if i <= 0 {
+		return "", "", "", false
+	}
+	key, rest := term[:i], term[i:]
+	for _, op := range []string{"<=", ">=", ":", "<", ">"} {
+		if value, ok := strings.CutPrefix(rest, op); ok {
+			return key, op, value, true
+		}
+	}
+	return "", "", "", false
+}
+
+// unquote removes the double quotes from a term
+func unquote(s string) string {
+	return strings.ReplaceAll(s, `"`, "")
+}
@@ -0,0 +1,118 @@
+package taskmanager
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 7, d, 0, 0, 0, 0, time.UTC) }
+	done, open := true, false
+	alice := "alice smith"
+	project := 3
+
+	tests := []struct {
+		name     string
+		query    string
+		expected ListOptions
+	}{
+		{name: "empty", query: "  ", expected: ListOptions{}},
+		{
+			name:  "example",
+			query: `status:done tag:home due<2025-07-01 "report"`,
+			expected: ListOptions{
+				Statuses: []Status{StatusDone},
+				Tags:     []string{"home"},
+				Due:      TimeRange{To: day(1)},
+				Query:    "report",
+			},
+		},
+		{name: "status names", query: "status:in_progress status:Blocked", expected: ListOptions{Statuses: []Status{StatusInProgress, StatusBlocked}}},
+		{name: "priority", query: "priority:HIGH priority:urgent", expected: ListOptions{Priorities: []Priority{PriorityHigh, PriorityUrgent}}},
+		{name: "quoted value", query: `assignee:"alice smith" project:3`, expected: ListOptions{AssigneeID: &alice, ProjectID: &project}},
+		{name: "done", query: "is:done", expected: ListOptions{Done: &done}},
+		{name: "open", query: "is:open", expected: ListOptions{Done: &open}},
+		{name: "due on a day", query: "due:2025-07-04", expected: ListOptions{Due: TimeRange{From: day(4), To: day(5)}}},
+		{name: "due between", query: "due>=2025-07-02 due<=2025-07-09", expected: ListOptions{Due: TimeRange{From: day(2), To: day(10)}}},
+		{name: "due after", query: "due>2025-07-02", expected: ListOptions{Due: TimeRange{From: day(3)}}},
+		{name: "tightest range wins", query: "due<2025-07-09 due<2025-07-05", expected: ListOptions{Due: TimeRange{To: day(5)}}},
+		{name: "created and completed", query: "created>=2025-07-01 completed<2025-07-04", expected: ListOptions{Created: TimeRange{From: day(1)}, Completed: TimeRange{To: day(4)}}},
+		{name: "sort", query: "sort:-priority,due_date", expected: ListOptions{Sort: SortSpec{{Field: SortPriority, Descending: true}, {Field: SortDueDate}}}},
+		{name: "words joined", query: `quarterly "sales report" tag:work`, expected: ListOptions{Tags: []string{"work"}, Query: "quarterly sales report"}},
+		{name: "quoted filter is text", query: `"http://example.com"`, expected: ListOptions{Query: "http://example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		pos   int
+	}{
+		{name: "unknown filter", query: "report colour:red", pos: 7},
+		{name: "unknown status", query: "status:later", pos: 0},
+		{name: "unknown priority", query: "tag:a priority:max", pos: 6},
+		{name: "bad date", query: "due<tomorrow", pos: 0},
+		{name: "bad created date", query: "tag:a created>=soon", pos: 6},
+		{name: "operator not supported", query: "tag>home", pos: 0},
+		{name: "missing value", query: "tag:", pos: 0},
+		{name: "bad project", query: "project:abc", pos: 0},
+		{name: "bad sort", query: "sort:color", pos: 0},
+		{name: "unterminated quote", query: `tag:home "report`, pos: 9},
+		{name: "reversed range", query: "due>2025-07-09 due<2025-07-01", pos: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			var qe *QueryError
+			if !errors.As(err, &qe) || !errors.Is(err, ErrInvalidQuery) {
+				t.Fatalf("Expected a QueryError, got %v", err)
+			}
+			if qe.Pos != tt.pos {
+				t.Errorf("Expected position %d, got %d (%v)", tt.pos, qe.Pos, err)
+			}
+		})
+	}
+}
+
+func TestParseQueryFindTasks(t *testing.T) {
+	now := time.Date(2025, 6, 28, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	report, err := tm.AddTask("Write report", "", WithTags("home"), WithDueDate(now))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.AddTask("Write report", "", WithTags("work"), WithDueDate(now)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Transition(report.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	opts, err := ParseQuery(`status:done tag:home due<2025-07-01 "report"`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	page, err := tm.FindTasks(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != report.ID {
+		t.Errorf("Expected only task %d, got %+v", report.ID, page.Tasks)
+	}
+}
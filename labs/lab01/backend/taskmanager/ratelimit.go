@@ -0,0 +1,249 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a caller has used up its rate limit
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitError describes a call refused by a RateLimiter
+type RateLimitError struct {
+	Caller string
+	// RetryAfter is how long until the caller may make the next call
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v for %q: retry after %v", ErrRateLimited, e.Caller, e.RetryAfter)
+}
+
+// Is makes errors.Is(err, ErrRateLimited) match a RateLimitError
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimit is a token bucket: each caller may make Burst calls at once,
+// and gets back Rate calls per second after that
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiter keeps a token bucket per caller. A caller is any string the
+// application identifies clients by, such as a user ID or an address. It is
+// safe for concurrent use, so an HTTP layer can call Allow for each request
+// while RateLimitedTaskManager does the same for calls made in process.
+type RateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	// pruneAt is the number of buckets at which full ones are dropped
+	pruneAt int
+}
+
+// tokenBucket is what a caller has left of its rate limit
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// minPruneSize is the smallest number of buckets a RateLimiter prunes at
+const minPruneSize = 1024
+
+// NewRateLimiter creates a RateLimiter giving every caller the same limit
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+		pruneAt: minPruneSize,
+	}
+}
+
+// Allow takes one call from the caller's bucket, or returns a
+// *RateLimitError when the bucket is empty
+func (l *RateLimiter) Allow(caller string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[caller]
+	if !ok {
+		if len(l.buckets) >= l.pruneAt {
+			l.prune(now)
+		}
+		b = &tokenBucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[caller] = b
+	}
+	l.refill(b, now)
+	if b.tokens < 1 {
+		// A bucket that never refills has no time to retry after
+		var retry time.Duration
+		if l.limit.Rate > 0 {
+			retry = time.Duration(float64(time.Second) * (1 - b.tokens) / l.limit.Rate)
+		}
+		return &RateLimitError{Caller: caller, RetryAfter: retry}
+	}
+	b.tokens--
+	return nil
+}
+
+// refill adds the tokens earned since the bucket was last used
+func (l *RateLimiter) refill(b *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*l.limit.Rate, float64(l.limit.Burst))
+		b.last = now
+	}
+}
+
+// prune drops the buckets that have filled up again, which behave like
+// new ones, so callers that come and go do not grow the limiter forever.
+// The next prune waits until the remaining buckets have doubled.
+func (l *RateLimiter) prune(now time.Time) {
+	for caller, b := range l.buckets {
+		if l.refill(b, now); b.tokens >= float64(l.limit.Burst) {
+			delete(l.buckets, caller)
+		}
+	}
+	l.pruneAt = max(2*len(l.buckets), minPruneSize)
+}
+
+// RateLimitedTaskManager is a SafeTaskManager as seen by one caller, with
+// every call counted against the caller's rate limit. It offers the core
+// task operations; for the others, call the limiter's Allow before using
+// the SafeTaskManager directly.
+type RateLimitedTaskManager struct {
+	tm      *SafeTaskManager
+	limiter *RateLimiter
+	caller  string
+}
+
+// NewRateLimitedTaskManager wraps tm for caller. Wrappers for different
+// callers can share the manager and the limiter.
+func NewRateLimitedTaskManager(tm *SafeTaskManager, limiter *RateLimiter, caller string) *RateLimitedTaskManager {
+	return &RateLimitedTaskManager{tm: tm, limiter: limiter, caller: caller}
+}
+
+// AddTask is TaskManager.AddTask within the caller's rate limit
+func (r *RateLimitedTaskManager) AddTask(title, description string, opts ...TaskOption) (*Task, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return nil, err
+	}
+	return r.tm.AddTask(title, description, opts...)
+}
+
+// AddTasksBatch is TaskManager.AddTasksBatch within the caller's rate
+// limit. The batch counts as one call.
+func (r *RateLimitedTaskManager) AddTasksBatch(inputs []TaskInput) ([]*Task, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return nil, err
+	}
+	return r.tm.AddTasksBatch(inputs)
+}
+
+// AddSubtask is TaskManager.AddSubtask within the caller's rate limit
+func (r *RateLimitedTaskManager) AddSubtask(parentID int, title, description string, opts ...TaskOption) (*Task, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return nil, err
+	}
+	return r.tm.AddSubtask(parentID, title, description, opts...)
+}
+
+// GetTask is TaskManager.GetTask within the caller's rate limit
+func (r *RateLimitedTaskManager) GetTask(id int) (*Task, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return nil, err
+	}
+	return r.tm.GetTask(id)
+}
+
+// UpdateTask is TaskManager.UpdateTask within the caller's rate limit
+func (r *RateLimitedTaskManager) UpdateTask(id int, title, description string, done bool, opts ...TaskOption) error {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return err
+	}
+	return r.tm.UpdateTask(id, title, description, done, opts...)
+}
+
+// UpdateTaskFields is TaskManager.UpdateTaskFields within the caller's rate
+// limit
+func (r *RateLimitedTaskManager) UpdateTaskFields(id int, patch TaskPatch) error {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return err
+	}
+	return r.tm.UpdateTaskFields(id, patch)
+}
+
+// Transition is TaskManager.Transition within the caller's rate limit
+func (r *RateLimitedTaskManager) Transition(id int, status Status) error {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return err
+	}
+	return r.tm.Transition(id, status)
+}
+
+// CompleteTask is TaskManager.CompleteTask within the caller's rate limit
+func (r *RateLimitedTaskManager) CompleteTask(id int, note string) error {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return err
+	}
+	return r.tm.CompleteTask(id, note)
+}
+
+// DeleteTask is TaskManager.DeleteTask within the caller's rate limit
+func (r *RateLimitedTaskManager) DeleteTask(id int) error {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return err
+	}
+	return r.tm.DeleteTask(id)
+}
+
+// RestoreTask is TaskManager.RestoreTask within the caller's rate limit
+func (r *RateLimitedTaskManager) RestoreTask(id int) error {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return err
+	}
+	return r.tm.RestoreTask(id)
+}
+
+// ListTasks is TaskManager.ListTasks within the caller's rate limit. Unlike
+// TaskManager.ListTasks it returns an error, the *RateLimitError when the
+// call is refused.
+func (r *RateLimitedTaskManager) ListTasks(filterDone *bool, opts ...ListOption) ([]*Task, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return nil, err
+	}
+	return r.tm.ListTasks(filterDone, opts...), nil
+}
+
+// FindTasks is TaskManager.FindTasks within the caller's rate limit
+func (r *RateLimitedTaskManager) FindTasks(opts ListOptions) (TaskPage, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return TaskPage{}, err
+	}
+	return r.tm.FindTasks(opts)
+}
+
+// CountTasks is TaskManager.CountTasks within the caller's rate limit
+func (r *RateLimitedTaskManager) CountTasks(opts ListOptions) (int, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return 0, err
+	}
+	return r.tm.CountTasks(opts)
+}
+
+// Search is TaskManager.Search within the caller's rate limit, returning
+// an error like ListTasks does
+func (r *RateLimitedTaskManager) Search(query string, opts ...ListOption) ([]*Task, error) {
+	if err := r.limiter.Allow(r.caller); err != nil {
+		return nil, err
+	}
+	return r.tm.Search(query, opts...), nil
+}
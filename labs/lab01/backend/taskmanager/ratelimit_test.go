@@ -0,0 +1,113 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(RateLimit{Rate: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	for i := range 3 {
+		if err := l.Allow("alice"); err != nil {
+			t.Fatalf("Call %d: expected the burst to be allowed, got %v", i, err)
+		}
+	}
+	err := l.Allow("alice")
+	var limited *RateLimitError
+	if !errors.As(err, &limited) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected a *RateLimitError, got %v", err)
+	}
+	if limited.Caller != "alice" || limited.RetryAfter != 500*time.Millisecond {
+		t.Errorf("Expected alice to retry after 500ms, got %+v", limited)
+	}
+
+	if err := l.Allow("bob"); err != nil {
+		t.Errorf("Expected callers to have their own buckets, got %v", err)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if err := l.Allow("alice"); err != nil {
+		t.Errorf("Expected a call to be earned back after 500ms, got %v", err)
+	}
+	if err := l.Allow("alice"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+
+	// A long pause refills the bucket up to the burst and no further
+	now = now.Add(time.Hour)
+	for i := range 4 {
+		err := l.Allow("alice")
+		if i < 3 && err != nil || i == 3 && err == nil {
+			t.Errorf("Call %d after a pause: got %v", i, err)
+		}
+	}
+}
+
+func TestRateLimiterPrune(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(RateLimit{Rate: 1, Burst: 1})
+	l.now = func() time.Time { return now }
+	for i := range minPruneSize {
+		if err := l.Allow(fmt.Sprintf("caller %d", i)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	now = now.Add(time.Second)
+	if err := l.Allow("newcomer"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(l.buckets) != 1 {
+		t.Errorf("Expected refilled buckets to be pruned, got %d buckets", len(l.buckets))
+	}
+}
+
+func TestRateLimitedTaskManager(t *testing.T) {
+	s := NewSafeTaskManager()
+	l := NewRateLimiter(RateLimit{Rate: 1, Burst: 2})
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	alice := NewRateLimitedTaskManager(s, l, "alice")
+	bob := NewRateLimitedTaskManager(s, l, "bob")
+
+	task, err := alice.AddTask("Write report", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := alice.GetTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	calls := map[string]func() error{
+		"AddTask": func() error {
+			_, err := alice.AddTask("Another", "")
+			return err
+		},
+		"ListTasks": func() error {
+			_, err := alice.ListTasks(nil)
+			return err
+		},
+		"CompleteTask": func() error { return alice.CompleteTask(task.ID, "") },
+		"FindTasks": func() error {
+			_, err := alice.FindTasks(ListOptions{})
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("%s: expected ErrRateLimited, got %v", name, err)
+		}
+	}
+	if count := s.Stats().Tasks; count != 1 || storedTask(s.tm, task.ID).IsDone() {
+		t.Error("Expected refused calls to leave the manager alone")
+	}
+
+	if tasks, err := bob.ListTasks(nil); err != nil || len(tasks) != 1 {
+		t.Errorf("Expected bob to see alice's task, got %v (%v)", tasks, err)
+	}
+}
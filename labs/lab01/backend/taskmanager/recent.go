@@ -0,0 +1,17 @@
+package taskmanager
+
+import "time"
+
+// RecentlyModified returns tasks changed at or after since, most recently
+// changed first, for a "Recent" view. Adding a comment counts as a change.
+// A limit of zero or less returns every such task.
+func (tm *TaskManager) RecentlyModified(since time.Time, limit int, opts ...ListOption) []*Task {
+	result := tm.ListTasks(nil, append(opts,
+		SortBy(SortSpec{{Field: SortUpdatedAt, Descending: true}}),
+		where(func(task *Task) bool { return !task.UpdatedAt.Before(since) }),
+	)...)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit:limit]
+	}
+	return result
+}
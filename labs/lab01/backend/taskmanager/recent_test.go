@@ -0,0 +1,52 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecentlyModified(t *testing.T) {
+	start := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	report := mustAddTask(t, tm, "Write report")
+	now = now.Add(time.Hour)
+	milk := mustAddTask(t, tm, "Buy milk")
+	now = now.Add(time.Hour)
+	call := mustAddTask(t, tm, "Call mom")
+	now = now.Add(time.Hour)
+	if err := tm.UpdateTask(report.ID, "Write the report", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := tm.AddComment(milk.ID, "bob", "Oat milk please"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		since    time.Time
+		limit    int
+		opts     []ListOption
+		expected []string
+	}{
+		{name: "all", since: start, expected: []string{"Buy milk", "Write the report", "Call mom"}},
+		{name: "limited", since: start, limit: 2, expected: []string{"Buy milk", "Write the report"}},
+		{name: "since is inclusive", since: start.Add(3 * time.Hour), expected: []string{"Buy milk", "Write the report"}},
+		{name: "nothing since", since: now.Add(time.Second), expected: []string{}},
+		{name: "list options apply", since: start, opts: []ListOption{FilterByStatus(StatusDone)}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tm.RecentlyModified(tt.since, tt.limit, tt.opts...)); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if !call.UpdatedAt.Equal(call.CreatedAt) {
+		t.Errorf("Expected an unchanged task to be updated when created, got %v", call.UpdatedAt)
+	}
+}
@@ -0,0 +1,338 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRecurrence is returned when a recurrence rule cannot be used
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// Frequency is the base unit a recurring task repeats in
+type Frequency int
+
+const (
+	// Daily repeats every Interval days
+	Daily Frequency = iota + 1
+	// Weekly repeats every Interval weeks, optionally on specific weekdays
+	Weekly
+	// Monthly repeats every Interval months on the same day of the month
+	Monthly
+	// Yearly repeats every Interval years on the same date
+	Yearly
+)
+
+// Recurrence describes when a recurring task repeats. It mirrors the
+// FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL parts of an RFC 5545 RRULE.
+type Recurrence struct {
+	Frequency Frequency
+	// Interval is the number of frequency units between occurrences; zero means 1
+	Interval int
+	// Weekdays restricts weekly rules to the given days of the week
+	Weekdays []time.Weekday
+	// MonthDay is the day of the month monthly and yearly rules aim for,
+	// clamped to shorter months; zero means the day of the previous occurrence
+	MonthDay int
+	// Count is the number of occurrences left including the current one; zero means unlimited
+	Count int
+	// Until is the last moment an occurrence may be due; nil means no end
+	Until *time.Time
+}
+
+// WithRecurrence makes the task repeat according to the rule
+func WithRecurrence(r Recurrence) TaskOption {
+	return func(t *Task) {
+		t.Recurrence = &r
+	}
+}
+
+// ClearRecurrence stops the task from repeating
+func ClearRecurrence() TaskOption {
+	return func(t *Task) {
+		t.Recurrence = nil
+	}
+}
+
+// Validate checks that the recurrence rule is complete and consistent
+func (r *Recurrence) Validate() error {
+	if r.Frequency < Daily || r.Frequency > Yearly {
+		return ErrInvalidRecurrence
+	}
+	if r.Interval < 0 || r.Count < 0 {
+		return ErrInvalidRecurrence
+	}
+	if len(r.Weekdays) > 0 && r.Frequency != Weekly {
+		return ErrInvalidRecurrence
+	}
+	for _, day := range r.Weekdays {
+		if day < time.Sunday || day > time.Saturday {
+			return ErrInvalidRecurrence
+		}
+	}
+	if r.MonthDay < 0 || r.MonthDay > 31 || (r.MonthDay > 0 && r.Frequency < Monthly) {
+		return ErrInvalidRecurrence
+	}
+	return nil
+}
+
+// Next returns the first occurrence after the given one. The second result
+// is false when the rule has no further occurrences.
+func (r *Recurrence) Next(after time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+	next := r.step(after)
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Upcoming returns up to n occurrences following the given one
+func (r *Recurrence) Upcoming(after time.Time, n int) []time.Time {
+	var result []time.Time
+	rule := r.pinned(after)
+	for len(result) < n {
+		next, ok := rule.Next(after)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		after = next
+		if rule.Count > 0 {
+			rule.Count--
+		}
+	}
+	return result
+}
+
+// step advances one occurrence without considering Count or Until
+func (r *Recurrence) step(from time.Time) time.Time {
+	interval := max(r.Interval, 1)
+	switch r.Frequency {
+	case Daily:
+		return from.AddDate(0, 0, interval)
+	case Weekly:
+		if len(r.Weekdays) == 0 {
+			return from.AddDate(0, 0, 7*interval)
+		}
+		return r.nextWeekday(from, interval)
+	case Monthly:
+		return addMonthsClamped(from, interval, r.MonthDay)
+	default:
+		return addMonthsClamped(from, 12*interval, r.MonthDay)
+	}
+}
+
+// pinned returns a copy of the rule with MonthDay fixed to the day of the
+// given occurrence, so a series starting on the 31st keeps returning to the
+// end of the month instead of drifting after February
+func (r *Recurrence) pinned(occurrence time.Time) Recurrence {
+	rule := *r
+	if rule.MonthDay == 0 && rule.Frequency >= Monthly {
+		rule.MonthDay = occurrence.Day()
+	}
+	return rule
+}
+
+// nextWeekday finds the next allowed weekday, skipping weeks outside the interval
+func (r *Recurrence) nextWeekday(from time.Time, interval int) time.Time {
+	weekStart := from.AddDate(0, 0, -int(from.Weekday()))
+	for day := 1; ; day++ {
+		candidate := from.AddDate(0, 0, day)
+		weeks := daysBetween(weekStart, candidate) / 7
+		if weeks%interval == 0 && slices.Contains(r.Weekdays, candidate.Weekday()) {
+			return candidate
+		}
+	}
+}
+
+// daysBetween counts the calendar days from the date of from to the date
+// of to, so a day made shorter or longer by a clock change still counts as
+// one
+func daysBetween(from, to time.Time) int {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// addMonthsClamped adds months and moves to the given day (or the day of t
+// when zero), clamped to the end of a shorter month, so January 31 plus one
+// month is the last day of February
+func addMonthsClamped(t time.Time, months, day int) time.Time {
+	if day == 0 {
+		day = t.Day()
+	}
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	target := first.AddDate(0, months, 0)
+	lastDay := target.AddDate(0, 1, -1).Day()
+	return target.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// ParseRRule parses the FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL parts of an
+// RFC 5545 recurrence rule, with or without the leading "RRULE:"
+func ParseRRule(rule string) (Recurrence, error) {
+	var r Recurrence
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrence, part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Frequency, err = parseFrequency(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "BYDAY":
+			r.Weekdays, err = parseWeekdays(value)
+		case "BYMONTHDAY":
+			r.MonthDay, err = strconv.Atoi(value)
+		case "UNTIL":
+			var until time.Time
+			until, err = parseRRuleTime(value)
+			r.Until = &until
+		default:
+			err = fmt.Errorf("unsupported part %q", key)
+		}
+		if err != nil {
+			return Recurrence{}, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+		}
+	}
+	if err := r.Validate(); err != nil {
+		return Recurrence{}, err
+	}
+	return r, nil
+}
+
+// String formats the recurrence as an RFC 5545 RRULE value
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Frequency.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		days := make([]string, len(r.Weekdays))
+		for i, day := range r.Weekdays {
+			days[i] = weekdayCodes[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.MonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.MonthDay))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(rruleTimeLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String returns the RRULE name of the frequency
+func (f Frequency) String() string {
+	switch f {
+	case Daily:
+		return "DAILY"
+	case Weekly:
+		return "WEEKLY"
+	case Monthly:
+		return "MONTHLY"
+	case Yearly:
+		return "YEARLY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+const rruleTimeLayout = "20060102T150405Z"
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func parseFrequency(value string) (Frequency, error) {
+	for f := Daily; f <= Yearly; f++ {
+		if strings.EqualFold(value, f.String()) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported frequency %q", value)
+}
+
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, code := range strings.Split(value, ",") {
+		day := slices.Index(weekdayCodes[:], strings.ToUpper(code))
+		if day < 0 {
+			return nil, fmt.Errorf("unsupported weekday %q", code)
+		}
+		days = append(days, time.Weekday(day))
+	}
+	return days, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	if t, err := time.Parse(rruleTimeLayout, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("20060102", value)
+}
+
+// UpcomingOccurrences previews the next n due dates of a recurring task
+func (tm *TaskManager) UpcomingOccurrences(id int, n int) ([]time.Time, error) {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Recurrence == nil {
+		return nil, nil
+	}
+	return task.Recurrence.Upcoming(tm.occurrenceAnchor(task), n), nil
+}
+
+// nextOccurrence returns the follow-up task of a recurring task moving from
+// one status to another, or nil when the move does not complete the task
+// or its rule has no occurrence left. task is the task as it will be apart
+// from its status. The follow-up is checked before the task changes, so a
+// task that cannot repeat yet keeps its status and rule.
+func (tm *TaskManager) nextOccurrence(task *Task, from, to Status) (*Task, error) {
+	if task.Recurrence == nil || to != StatusDone || from == StatusDone {
+		return nil, nil
+	}
+	anchor := tm.occurrenceAnchor(task)
+	rule := task.Recurrence.pinned(anchor)
+	due, ok := rule.Next(anchor)
+	if !ok {
+		return nil, nil
+	}
+	if err := tm.reserve(1); err != nil {
+		return nil, err
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+	next := tm.freshCopy(task, true)
+	next.Reminders = shiftReminders(next.Reminders, due.Sub(anchor))
+	next.DueDate = &due
+	next.Recurrence = &rule
+	if err := tm.validate(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// occurrenceAnchor is the time the next occurrence is computed from: the
+// due date when set, otherwise the current time
+func (tm *TaskManager) occurrenceAnchor(task *Task) time.Time {
+	if task.DueDate != nil {
+		return *task.DueDate
+	}
+	return tm.now()
+}
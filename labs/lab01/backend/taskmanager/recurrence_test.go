@@ -0,0 +1,159 @@
+package taskmanager
+
+import (
+	"errors"
+	"testing"
+	"time"
+	// The test needs a zone with clock changes wherever it runs
+	_ "time/tzdata"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        string
+		expected    string
+		expectError bool
+	}{
+		{name: "daily", rule: "FREQ=DAILY", expected: "FREQ=DAILY"},
+		{name: "with prefix", rule: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{name: "count and until", rule: "FREQ=MONTHLY;COUNT=3;UNTIL=20251231T000000Z", expected: "FREQ=MONTHLY;COUNT=3;UNTIL=20251231T000000Z"},
+		{name: "month day", rule: "FREQ=MONTHLY;BYMONTHDAY=15", expected: "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{name: "unknown frequency", rule: "FREQ=HOURLY", expectError: true},
+		{name: "byday on daily", rule: "FREQ=DAILY;BYDAY=MO", expectError: true},
+		{name: "unsupported part", rule: "FREQ=DAILY;BYHOUR=9", expectError: true},
+		{name: "malformed", rule: "FREQ", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+
+			if tt.expectError {
+				if !errors.Is(err, ErrInvalidRecurrence) {
+					t.Errorf("Expected ErrInvalidRecurrence, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if r.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, r.String())
+			}
+		})
+	}
+}
+
+func TestRecurrenceUpcoming(t *testing.T) {
+	// 2025-07-07 is a Monday
+	start := time.Date(2025, 7, 7, 9, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC)
+	endOfJan := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Clocks in New York go forward on 2025-03-09, a Sunday
+	beforeDST := time.Date(2025, 3, 2, 9, 0, 0, 0, newYork)
+
+	tests := []struct {
+		name     string
+		rule     Recurrence
+		from     time.Time
+		expected []string
+	}{
+		{
+			name:     "every other day",
+			rule:     Recurrence{Frequency: Daily, Interval: 2},
+			from:     start,
+			expected: []string{"2025-07-09", "2025-07-11", "2025-07-13"},
+		},
+		{
+			name:     "weekdays every other week",
+			rule:     Recurrence{Frequency: Weekly, Interval: 2, Weekdays: []time.Weekday{time.Monday, time.Friday}},
+			from:     start,
+			expected: []string{"2025-07-11", "2025-07-21", "2025-07-25"},
+		},
+		{
+			name:     "every other week across a clock change",
+			rule:     Recurrence{Frequency: Weekly, Interval: 2, Weekdays: []time.Weekday{time.Sunday}},
+			from:     beforeDST,
+			expected: []string{"2025-03-16", "2025-03-30", "2025-04-13"},
+		},
+		{
+			name:     "monthly clamps to end of month",
+			rule:     Recurrence{Frequency: Monthly},
+			from:     endOfJan,
+			expected: []string{"2025-02-28", "2025-03-31", "2025-04-30"},
+		},
+		{
+			name:     "count limits occurrences",
+			rule:     Recurrence{Frequency: Daily, Count: 2},
+			from:     start,
+			expected: []string{"2025-07-08"},
+		},
+		{
+			name:     "until limits occurrences",
+			rule:     Recurrence{Frequency: Weekly, Until: &until},
+			from:     start,
+			expected: []string{"2025-07-14"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rule.Upcoming(tt.from, 3)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %d occurrences, got %v", len(tt.expected), got)
+			}
+			for i, date := range tt.expected {
+				if got[i].Format("2006-01-02") != date {
+					t.Errorf("Occurrence %d: expected %s, got %s", i, date, got[i].Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestCompleteRecurringTask(t *testing.T) {
+	tm := NewTaskManager()
+	due := time.Date(2025, 7, 7, 9, 0, 0, 0, time.UTC)
+	task := mustAddTask(t, tm, "Water plants",
+		WithTags("home"),
+		WithDueDate(due),
+		WithRecurrence(Recurrence{Frequency: Weekly, Count: 2}),
+	)
+
+	upcoming, err := tm.UpcomingOccurrences(task.ID, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(upcoming) != 1 {
+		t.Errorf("Expected 1 upcoming occurrence, got %v", upcoming)
+	}
+
+	if err := tm.UpdateTask(task.ID, task.Title, "", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	done := false
+	open := tm.ListTasks(&done)
+	if len(open) != 1 {
+		t.Fatalf("Expected the next occurrence to be created, got %d open tasks", len(open))
+	}
+	next := open[0]
+	if !next.DueDate.Equal(due.AddDate(0, 0, 7)) {
+		t.Errorf("Expected next due date a week later, got %v", next.DueDate)
+	}
+	if !next.HasTag("home") || next.Recurrence == nil || next.Recurrence.Count != 1 {
+		t.Errorf("Expected next occurrence to carry tags and remaining count, got %+v", next)
+	}
+
+	if err := tm.UpdateTask(next.ID, next.Title, "", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if open := tm.ListTasks(&done); len(open) != 0 {
+		t.Errorf("Expected the series to end after its last occurrence, got %d open tasks", len(open))
+	}
+}
@@ -0,0 +1,74 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+)
+
+const (
+	// MaxRegexLength is the longest pattern FilterByRegex accepts, in bytes
+	MaxRegexLength = 256
+	// maxRegexInsts caps the size of a compiled pattern, which bounds the
+	// work done per byte of text matched
+	maxRegexInsts = 2000
+	// maxRegexInput is how much of a title or description is matched
+	// against a pattern, in bytes
+	maxRegexInput = 64 << 10
+)
+
+// ErrInvalidRegex is returned when a search pattern does not compile or is
+// too long or complex
+var ErrInvalidRegex = errors.New("invalid regular expression")
+
+// FilterByRegex limits ListTasks to tasks whose title or plain-text
+// description matches the pattern, in Go's RE2 syntax; use (?i) to ignore
+// case. Errors wrap ErrInvalidRegex and say why the pattern was rejected.
+// Only the first 64 KiB of a description are searched.
+func FilterByRegex(pattern string) (ListOption, error) {
+	re, err := compileSearchRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return func(q *listQuery) {
+		q.pattern = re
+	}, nil
+}
+
+// compileSearchRegex compiles a search pattern within the size limits
+func compileSearchRegex(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > MaxRegexLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidRegex, MaxRegexLength)
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	}
+	if len(prog.Inst) > maxRegexInsts {
+		return nil, fmt.Errorf("%w: too complex", ErrInvalidRegex)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	}
+	return re, nil
+}
+
+// matchesPattern reports whether the title or plain-text description
+// matches the pattern
+func (t *Task) matchesPattern(re *regexp.Regexp) bool {
+	return re.MatchString(truncateInput(t.Title)) || re.MatchString(truncateInput(t.PlainDescription()))
+}
+
+// truncateInput cuts text to the part searched by patterns
+func truncateInput(text string) string {
+	if len(text) > maxRegexInput {
+		return text[:maxRegexInput]
+	}
+	return text
+}
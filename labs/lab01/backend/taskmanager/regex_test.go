@@ -0,0 +1,85 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterByRegex(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for _, task := range [][2]string{
+		{"Pay rent", ""},
+		{"Repay loan", ""},
+		{"Fix bug #123", "Crash in **module** 42"},
+		{"Call mom", "Ask about PAYMENT"},
+	} {
+		if _, err := tm.AddTask(task[0], task[1]); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{name: "anchored", pattern: `^Pay\b`, expected: []string{"Pay rent"}},
+		{name: "case sensitive", pattern: `pay`, expected: []string{"Repay loan"}},
+		{name: "ignore case", pattern: `(?i)\bpay`, expected: []string{"Pay rent", "Call mom"}},
+		{name: "numbers", pattern: `#\d+`, expected: []string{"Fix bug #123"}},
+		{name: "plain description", pattern: `module 42`, expected: []string{"Fix bug #123"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := FilterByRegex(tt.pattern)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(tm.ListTasks(nil, filter)); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+			page, err := tm.FindTasks(ListOptions{Pattern: tt.pattern})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(page.Tasks); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected ListOptions.Pattern to give %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	opts, err := ParseQuery(`regex:"^Pay\b"`)
+	if err != nil || opts.Pattern != `^Pay\b` {
+		t.Errorf("Expected the query language to set the pattern, got %+v, %v", opts, err)
+	}
+}
+
+func TestFilterByRegexErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		reason  string
+	}{
+		{name: "does not compile", pattern: `(unclosed`, reason: "missing closing )"},
+		{name: "too long", pattern: strings.Repeat("a", MaxRegexLength+1), reason: "longer than"},
+		{name: "too complex", pattern: `(\w{1,40}){1,25}`, reason: "too complex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FilterByRegex(tt.pattern)
+			if !errors.Is(err, ErrInvalidRegex) || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("Expected ErrInvalidRegex mentioning %q, got %v", tt.reason, err)
+			}
+			if err := (ListOptions{Pattern: tt.pattern}).Validate(); !errors.Is(err, ErrInvalidRegex) {
+				t.Errorf("Expected Validate to reject the pattern, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrReminderAfterDue is returned when a reminder is set later than the task's due date
+	ErrReminderAfterDue = errors.New("reminder is after the due date")
+	// ErrReminderExists is returned when a task already has a reminder at the given time
+	ErrReminderExists = errors.New("reminder already exists")
+	// ErrReminderNotFound is returned when a task has no reminder at the given time
+	ErrReminderNotFound = errors.New("reminder not found")
+)
+
+// Reminder is a reminder time of a task, as returned by ListUpcomingReminders
+type Reminder struct {
+	TaskID int
+	Title  string
+	At     time.Time
+}
+
+// AddReminder adds a reminder time to a task. Reminders may not be later than
+// the task's due date.
+func (tm *TaskManager) AddReminder(taskID int, at time.Time) error {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(task.Reminders, at.Equal) {
+		return ErrReminderExists
+	}
+	if task.DueDate != nil && at.After(*task.DueDate) {
+		return ErrReminderAfterDue
+	}
+	tm.update(task, func() {
+		task.Reminders = insertReminder(slices.Clone(task.Reminders), at)
+	})
+	return nil
+}
+
+// RemoveReminder removes the reminder set at the given time from a task
+func (tm *TaskManager) RemoveReminder(taskID int, at time.Time) error {
+	task, err := tm.lookup(taskID)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(task.Reminders, at.Equal)
+	if i < 0 {
+		return ErrReminderNotFound
+	}
+	tm.update(task, func() {
+		task.Reminders = slices.Delete(slices.Clone(task.Reminders), i, i+1)
+	})
+	return nil
+}
+
+// ListUpcomingReminders returns the reminders of open tasks that fall within
+// the window starting now, earliest first
+func (tm *TaskManager) ListUpcomingReminders(window time.Duration) []Reminder {
+	now := tm.now()
+	end := now.Add(window)
+	var result []Reminder
+	for task := range tm.tasks.List() {
+		if task.Status.Closed() {
+			continue
+		}
+		for _, at := range task.Reminders {
+			if !at.Before(now) && !at.After(end) {
+				result = append(result, Reminder{TaskID: task.ID, Title: task.Title, At: at})
+			}
+		}
+	}
+	slices.SortFunc(result, func(a, b Reminder) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		return a.TaskID - b.TaskID
+	})
+	return result
+}
+
+// validateReminders checks that no reminder is later than the due date
+func validateReminders(task *Task) error {
+	if task.DueDate == nil || len(task.Reminders) == 0 {
+		return nil
+	}
+	if task.Reminders[len(task.Reminders)-1].After(*task.DueDate) {
+		return ErrReminderAfterDue
+	}
+	return nil
+}
+
+// insertReminder adds a reminder time keeping the list sorted
+func insertReminder(reminders []time.Time, at time.Time) []time.Time {
+	i, _ := slices.BinarySearchFunc(reminders, at, time.Time.Compare)
+	return slices.Insert(reminders, i, at)
+}
+
+// shiftReminders moves every reminder by d
+func shiftReminders(reminders []time.Time, d time.Duration) []time.Time {
+	shifted := make([]time.Time, len(reminders))
+	for i, at := range reminders {
+		shifted[i] = at.Add(d)
+	}
+	return shifted
+}
@@ -0,0 +1,106 @@
+package taskmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddReminder(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Call dentist", WithDueDate(now.Add(48*time.Hour)))
+
+	tests := []struct {
+		name        string
+		taskID      int
+		at          time.Time
+		expectError error
+	}{
+		{name: "before due date", taskID: task.ID, at: now.Add(24 * time.Hour)},
+		{name: "at due date", taskID: task.ID, at: now.Add(48 * time.Hour)},
+		{name: "earlier reminder", taskID: task.ID, at: now.Add(time.Hour)},
+		{name: "duplicate", taskID: task.ID, at: now.Add(time.Hour), expectError: ErrReminderExists},
+		{name: "after due date", taskID: task.ID, at: now.Add(72 * time.Hour), expectError: ErrReminderAfterDue},
+		{name: "missing task", taskID: 999, at: now, expectError: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tm.AddReminder(tt.taskID, tt.at); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if len(task.Reminders) != 3 || !task.Reminders[0].Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected 3 sorted reminders, got %v", task.Reminders)
+	}
+
+	err := tm.UpdateTask(task.ID, task.Title, task.Description, false, WithDueDate(now.Add(2*time.Hour)))
+	if err != ErrReminderAfterDue {
+		t.Errorf("Expected moving the due date before a reminder to fail, got %v", err)
+	}
+
+	if err := tm.RemoveReminder(task.ID, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.RemoveReminder(task.ID, now.Add(24*time.Hour)); err != ErrReminderNotFound {
+		t.Errorf("Expected ErrReminderNotFound, got %v", err)
+	}
+}
+
+func TestListUpcomingReminders(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	a := mustAddTask(t, tm, "A")
+	b := mustAddTask(t, tm, "B")
+	done := mustAddTask(t, tm, "Done")
+
+	for _, r := range []struct {
+		id int
+		at time.Time
+	}{
+		{a.ID, now.Add(3 * time.Hour)},
+		{b.ID, now.Add(time.Hour)},
+		{a.ID, now.Add(-time.Hour)},
+		{b.ID, now.Add(48 * time.Hour)},
+		{done.ID, now.Add(time.Hour)},
+	} {
+		if err := tm.AddReminder(r.id, r.at); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := tm.Transition(done.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reminders := tm.ListUpcomingReminders(24 * time.Hour)
+	if len(reminders) != 2 {
+		t.Fatalf("Expected 2 upcoming reminders, got %+v", reminders)
+	}
+	if reminders[0].TaskID != b.ID || reminders[1].TaskID != a.ID {
+		t.Errorf("Expected reminders earliest first, got %+v", reminders)
+	}
+}
+
+func TestRecurringTaskShiftsReminders(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	due := now.Add(24 * time.Hour)
+	task := mustAddTask(t, tm, "Water plants", WithDueDate(due), WithRecurrence(Recurrence{Frequency: Weekly, Interval: 1}))
+	if err := tm.AddReminder(task.ID, due.Add(-time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Transition(task.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	open := tm.ListTasks(nil, FilterByStatus(StatusTodo))
+	if len(open) != 1 || len(open[0].Reminders) != 1 {
+		t.Fatalf("Expected one next occurrence with a reminder, got %v", open)
+	}
+	expected := due.AddDate(0, 0, 7).Add(-time.Hour)
+	if !open[0].Reminders[0].Equal(expected) {
+		t.Errorf("Expected reminder %v, got %v", expected, open[0].Reminders[0])
+	}
+}
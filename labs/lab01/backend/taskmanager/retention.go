@@ -0,0 +1,116 @@
+package taskmanager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RetentionPolicy decides when finished tasks leave the default listings
+// and when they leave the manager altogether
+type RetentionPolicy struct {
+	// ArchiveAfter is how long a done or cancelled task stays unarchived.
+	// Zero disables automatic archiving.
+	ArchiveAfter time.Duration
+	// EvictAfter is how long a done or cancelled task is kept at all. Once
+	// it has passed, the task is no longer found or listed, and the next
+	// ApplyRetentionPolicy removes it for good without going through the
+	// trash. Zero keeps closed tasks until they are deleted.
+	EvictAfter time.Duration
+}
+
+// WithRetentionPolicy sets the policy applied by ApplyRetentionPolicy
+func WithRetentionPolicy(policy RetentionPolicy) Option {
+	return func(tm *TaskManager) {
+		tm.retention = policy
+	}
+}
+
+// ApplyRetentionPolicy archives or evicts every closed task that was
+// completed or cancelled longer ago than the policy allows, and returns how
+// many it archived or evicted. Subtasks of an evicted task become top-level
+// tasks. Evicting cannot be undone, clears the undo history and clears the
+// evicted tasks callers still hold, as purging the trash does.
+func (tm *TaskManager) ApplyRetentionPolicy() int {
+	if tm.retention.ArchiveAfter <= 0 && tm.retention.EvictAfter <= 0 {
+		return 0
+	}
+	var evicted []*Task
+	count := 0
+	for task := range tm.tasks.List() {
+		if tm.expired(task) {
+			evicted = append(evicted, task)
+			continue
+		}
+		if tm.retention.ArchiveAfter <= 0 || task.Archived || !task.closedBefore(tm.now().Add(-tm.retention.ArchiveAfter)) {
+			continue
+		}
+		tm.update(task, func() {
+			task.Archived = true
+		})
+		count++
+	}
+	if len(evicted) > 0 {
+		tm.clearUndo()
+	}
+	for _, task := range evicted {
+		tm.evictTask(task)
+	}
+	return count + len(evicted)
+}
+
+// expired reports whether the retention policy evicts a task
+func (tm *TaskManager) expired(task *Task) bool {
+	return tm.retention.EvictAfter > 0 && task.closedBefore(tm.now().Add(-tm.retention.EvictAfter))
+}
+
+// evictTask permanently removes an active task and drops every reference
+// to it
+func (tm *TaskManager) evictTask(task *Task) {
+	for _, child := range tm.children(task.ID) {
+		tm.update(child, func() {
+			child.ParentID = 0
+		})
+	}
+	tm.markChanged(task.ID)
+	tm.tasks.Delete(task.ID)
+	tm.index.remove(task.ID)
+	tm.refreshProgress(task.ParentID)
+	tm.removeDependents(task.ID)
+	tm.removeRelated(task.ID)
+	tm.releaseAttachments(task)
+	forgetTask(task)
+	tm.ops.evicted.Add(1)
+}
+
+// RunRetentionPolicy applies the retention policy every interval until ctx
+// is cancelled. It is meant to run in its own goroutine; mu must be the lock
+// that guards every other use of the manager.
+func (tm *TaskManager) RunRetentionPolicy(ctx context.Context, interval time.Duration, mu sync.Locker) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mu.Lock()
+			tm.ApplyRetentionPolicy()
+			mu.Unlock()
+		}
+	}
+}
+
+// closedBefore reports whether a task was closed before cutoff
+func (t *Task) closedBefore(cutoff time.Time) bool {
+	return t.Status.Closed() && t.closedAt().Before(cutoff)
+}
+
+// closedAt returns when a closed task was completed. Cancelled tasks have no
+// completion time, so their last update is used instead.
+func (t *Task) closedAt() time.Time {
+	if t.CompletedAt != nil {
+		return *t.CompletedAt
+	}
+	return t.UpdatedAt
+}
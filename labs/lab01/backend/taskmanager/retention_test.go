@@ -0,0 +1,177 @@
+package taskmanager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApplyRetentionPolicy(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(
+		WithClock(func() time.Time { return now }),
+		WithRetentionPolicy(RetentionPolicy{ArchiveAfter: 30 * 24 * time.Hour}),
+	)
+	old := mustAddTask(t, tm, "Old")
+	cancelled := mustAddTask(t, tm, "Cancelled")
+	open := mustAddTask(t, tm, "Open")
+	if err := tm.Transition(old.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Transition(cancelled.ID, StatusCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now = now.Add(20 * 24 * time.Hour)
+	recent := mustAddTask(t, tm, "Recent")
+	if err := tm.Transition(recent.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := tm.ApplyRetentionPolicy(); n != 0 {
+		t.Errorf("Expected nothing to be archived yet, got %d", n)
+	}
+
+	now = now.Add(11 * 24 * time.Hour)
+	if n := tm.ApplyRetentionPolicy(); n != 2 {
+		t.Errorf("Expected 2 tasks to be archived, got %d", n)
+	}
+	if !old.Archived || !cancelled.Archived || recent.Archived || open.Archived {
+		t.Errorf("Expected only tasks closed over 30 days ago to be archived")
+	}
+	if n := tm.ApplyRetentionPolicy(); n != 0 {
+		t.Errorf("Expected archived tasks to be skipped, got %d", n)
+	}
+}
+
+func TestApplyRetentionPolicyDisabled(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Done")
+	if err := tm.Transition(task.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := tm.ApplyRetentionPolicy(); n != 0 || task.Archived {
+		t.Errorf("Expected no policy to archive nothing, got %d", n)
+	}
+}
+
+func TestRunRetentionPolicy(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(
+		WithClock(func() time.Time { return now }),
+		WithRetentionPolicy(RetentionPolicy{ArchiveAfter: time.Hour}),
+	)
+	task := mustAddTask(t, tm, "Done")
+	if err := tm.Transition(task.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+
+	var mu sync.Mutex
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tm.RunRetentionPolicy(ctx, time.Millisecond, &mu)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for archived := false; !archived; {
+		select {
+		case <-deadline:
+			t.Fatal("Expected the runner to archive the task")
+		case <-time.After(time.Millisecond):
+		}
+		mu.Lock()
+		archived = task.Archived
+		mu.Unlock()
+	}
+	cancel()
+	<-done
+}
+
+func TestEvictAfter(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(
+		WithClock(func() time.Time { return now }),
+		WithUndoDepth(10),
+		WithRetentionPolicy(RetentionPolicy{ArchiveAfter: time.Hour, EvictAfter: 24 * time.Hour}),
+	)
+	parent := mustAddTask(t, tm, "Parent")
+	child, err := tm.AddSubtask(parent.ID, "Child", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	open := mustAddTask(t, tm, "Open")
+	if err := tm.AddDependency(open.ID, parent.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.CompleteTask(parent.ID, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n := tm.ApplyRetentionPolicy(); n != 1 || !parent.Archived {
+		t.Errorf("Expected the done task to be archived first, got %d", n)
+	}
+
+	// Expired tasks are hidden before the policy is applied again
+	now = now.Add(23 * time.Hour)
+	if _, err := tm.GetTask(parent.ID); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound for an expired task, got %v", err)
+	}
+	if got := titles(tm.ListTasks(nil, IncludeArchived())); len(got) != 2 {
+		t.Errorf("Expected the expired task to be left out of listings, got %v", got)
+	}
+	if err := tm.UpdateTask(parent.ID, "Parent", "", true); err != ErrTaskNotFound {
+		t.Errorf("Expected updates to an expired task to fail, got %v", err)
+	}
+
+	if n := tm.ApplyRetentionPolicy(); n != 1 {
+		t.Errorf("Expected 1 task to be evicted, got %d", n)
+	}
+	if _, ok := tm.tasks.Get(parent.ID); ok || len(tm.trash) > 0 {
+		t.Error("Expected the evicted task to be gone, trash included")
+	}
+	if parent.Title != "" || parent.History != nil {
+		t.Errorf("Expected the evicted task cleared, got %+v", parent)
+	}
+	if child.ParentID != 0 || len(open.DependsOn) != 0 {
+		t.Errorf("Expected references to the evicted task to be dropped, got parent %d and dependencies %v", child.ParentID, open.DependsOn)
+	}
+	if tm.CanUndo() {
+		t.Error("Expected eviction to clear the undo history")
+	}
+	if evicted := tm.Stats().Ops.Evicted; evicted != 1 {
+		t.Errorf("Expected 1 eviction to be counted, got %d", evicted)
+	}
+	checkIndex(t, tm, "evict")
+}
+
+func TestRunRetentionPolicyEvicts(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	s := NewSafeTaskManager(
+		WithClock(func() time.Time { return now }),
+		WithRetentionPolicy(RetentionPolicy{EvictAfter: time.Hour}),
+	)
+	task, _ := s.AddTask("Done", "")
+	if err := s.CompleteTask(task.ID, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s.mu.Lock()
+	now = now.Add(2 * time.Hour)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.RunRetentionPolicy(ctx, time.Millisecond)
+
+	deadline := time.After(time.Second)
+	for s.Stats().Tasks > 0 {
+		select {
+		case <-deadline:
+			t.Fatal("Expected the runner to evict the task")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,1070 @@
+package taskmanager
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SafeTaskManager is a TaskManager that is safe for concurrent use, such as
+// from HTTP handlers. Each method has the behavior of the TaskManager method
+// of the same name and holds a read or write lock for its duration, so
+// readers run in parallel while writers run one at a time. WithSnapshotReads
+// lets the most common reads skip the lock altogether.
+//
+// Tasks, projects and saved filters are always returned as copies, since a
+// stored one could be changed by another goroutine while the caller reads
+// it. The clock given with WithClock and the BlobStore given with
+// WithBlobStore are called under the lock and must not use the manager.
+type SafeTaskManager struct {
+	mu       sync.RWMutex
+	tm       *TaskManager
+	snapshot atomic.Pointer[Snapshot]
+	flusher  *flusher
+}
+
+// NewSafeTaskManager creates a SafeTaskManager. WithDefensiveCopies is
+// always applied. A manager given WithPersister must be closed with Close.
+func NewSafeTaskManager(opts ...Option) *SafeTaskManager {
+	s := &SafeTaskManager{tm: NewTaskManager(append(opts, WithDefensiveCopies())...)}
+	s.publish()
+	s.startFlusher()
+	return s
+}
+
+// writeLock is the lock writers take. Releasing it publishes the new state
+// of the manager to snapshot readers.
+type writeLock SafeTaskManager
+
+// Lock takes the write lock
+func (l *writeLock) Lock() {
+	l.mu.Lock()
+}
+
+// Unlock publishes a snapshot when snapshot reads are enabled, records a
+// change to persist when a Persister was given, and releases the write lock
+func (l *writeLock) Unlock() {
+	(*SafeTaskManager)(l).publish()
+	if l.flusher != nil {
+		l.flusher.changed()
+	}
+	l.mu.Unlock()
+}
+
+// lock takes the write lock and returns the function that releases it
+func (s *SafeTaskManager) lock() func() {
+	l := (*writeLock)(s)
+	l.Lock()
+	return l.Unlock
+}
+
+// Tasks returns the listing ListTasks would return as a sequence. The
+// listing is taken under the read lock when the sequence is first used, so
+// the loop body may call the manager.
+func (s *SafeTaskManager) Tasks(filterDone *bool, opts ...ListOption) iter.Seq[*Task] {
+	return func(yield func(*Task) bool) {
+		for _, task := range s.ListTasks(filterDone, opts...) {
+			if !yield(task) {
+				return
+			}
+		}
+	}
+}
+
+// ForEachTask calls fn with a copy of each task TaskManager.ForEachTask
+// would visit. The tasks are gathered under the read lock before fn is
+// first called, so fn may call the manager.
+func (s *SafeTaskManager) ForEachTask(filter ListOptions, fn func(*Task) bool) error {
+	var tasks []*Task
+	s.mu.RLock()
+	err := s.tm.ForEachTask(filter, func(task *Task) bool {
+		tasks = append(tasks, task.clone())
+		return true
+	})
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if !fn(task) {
+			break
+		}
+	}
+	return nil
+}
+
+// RunRetentionPolicy applies the retention policy every interval until ctx
+// is cancelled, taking the write lock each time. It is meant to run in its
+// own goroutine.
+func (s *SafeTaskManager) RunRetentionPolicy(ctx context.Context, interval time.Duration) {
+	s.tm.RunRetentionPolicy(ctx, interval, (*writeLock)(s))
+}
+
+// Snapshot returns a consistent view of the tasks as they are now. With
+// WithSnapshotReads the latest published snapshot is returned without
+// copying anything again.
+func (s *SafeTaskManager) Snapshot() *Snapshot {
+	if snapshot := s.snapshot.Load(); snapshot != nil {
+		return snapshot
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.Snapshot()
+}
+
+// CreateProject is TaskManager.CreateProject under the write lock
+func (s *SafeTaskManager) CreateProject(name string) (*Project, error) {
+	defer s.lock()()
+	project, err := s.tm.CreateProject(name)
+	if err != nil {
+		return nil, err
+	}
+	copied := *project
+	return &copied, nil
+}
+
+// GetProject is TaskManager.GetProject under the read lock
+func (s *SafeTaskManager) GetProject(id int) (*Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	project, err := s.tm.GetProject(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *project
+	return &copied, nil
+}
+
+// ListProjects is TaskManager.ListProjects under the read lock
+func (s *SafeTaskManager) ListProjects() []*Project {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	projects := s.tm.ListProjects()
+	for i, project := range projects {
+		copied := *project
+		projects[i] = &copied
+	}
+	return projects
+}
+
+// SaveFilter is TaskManager.SaveFilter under the write lock
+func (s *SafeTaskManager) SaveFilter(name string, opts ListOptions) (*SavedFilter, error) {
+	defer s.lock()()
+	f, err := s.tm.SaveFilter(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return f.clone(), nil
+}
+
+// GetFilter is TaskManager.GetFilter under the read lock
+func (s *SafeTaskManager) GetFilter(name string) (*SavedFilter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, err := s.tm.GetFilter(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.clone(), nil
+}
+
+// ListFilters is TaskManager.ListFilters under the read lock
+func (s *SafeTaskManager) ListFilters() []*SavedFilter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	filters := s.tm.ListFilters()
+	for i, f := range filters {
+		filters[i] = f.clone()
+	}
+	return filters
+}
+
+// AddAttachment is TaskManager.AddAttachment under the write lock
+func (s *SafeTaskManager) AddAttachment(taskID int, a Attachment) (Attachment, error) {
+	defer s.lock()()
+	return s.tm.AddAttachment(taskID, a)
+}
+
+// AddChecklistItem is TaskManager.AddChecklistItem under the write lock
+func (s *SafeTaskManager) AddChecklistItem(taskID int, text string) (ChecklistItem, error) {
+	defer s.lock()()
+	return s.tm.AddChecklistItem(taskID, text)
+}
+
+// AddComment is TaskManager.AddComment under the write lock
+func (s *SafeTaskManager) AddComment(taskID int, author, body string) (Comment, error) {
+	defer s.lock()()
+	return s.tm.AddComment(taskID, author, body)
+}
+
+// AddDependency is TaskManager.AddDependency under the write lock
+func (s *SafeTaskManager) AddDependency(taskID, dependsOnID int) error {
+	defer s.lock()()
+	return s.tm.AddDependency(taskID, dependsOnID)
+}
+
+// AddReminder is TaskManager.AddReminder under the write lock
+func (s *SafeTaskManager) AddReminder(taskID int, at time.Time) error {
+	defer s.lock()()
+	return s.tm.AddReminder(taskID, at)
+}
+
+// AddSubtask is TaskManager.AddSubtask under the write lock
+func (s *SafeTaskManager) AddSubtask(parentID int, title, description string, opts ...TaskOption) (*Task, error) {
+	defer s.lock()()
+	return s.tm.AddSubtask(parentID, title, description, opts...)
+}
+
+// AddTask is TaskManager.AddTask under the write lock
+func (s *SafeTaskManager) AddTask(title, description string, opts ...TaskOption) (*Task, error) {
+	defer s.lock()()
+	return s.tm.AddTask(title, description, opts...)
+}
+
+// AddTasksBatch is TaskManager.AddTasksBatch under the write lock
+func (s *SafeTaskManager) AddTasksBatch(inputs []TaskInput) ([]*Task, error) {
+	defer s.lock()()
+	return s.tm.AddTasksBatch(inputs)
+}
+
+// AddTasks is TaskManager.AddTasks under the write lock
+func (s *SafeTaskManager) AddTasks(inputs []TaskInput) ([]*Task, error) {
+	defer s.lock()()
+	return s.tm.AddTasks(inputs)
+}
+
+// ApplyRetentionPolicy is TaskManager.ApplyRetentionPolicy under the write lock
+func (s *SafeTaskManager) ApplyRetentionPolicy() int {
+	defer s.lock()()
+	return s.tm.ApplyRetentionPolicy()
+}
+
+// ArchiveTask is TaskManager.ArchiveTask under the write lock
+func (s *SafeTaskManager) ArchiveTask(id int) error {
+	defer s.lock()()
+	return s.tm.ArchiveTask(id)
+}
+
+// AssignTask is TaskManager.AssignTask under the write lock
+func (s *SafeTaskManager) AssignTask(id int, assigneeID string) error {
+	defer s.lock()()
+	return s.tm.AssignTask(id, assigneeID)
+}
+
+// Backup is TaskManager.Backup with the data encoded under the read lock
+// and the archive written after it is released
+func (s *SafeTaskManager) Backup(w io.Writer) error {
+	s.mu.RLock()
+	files, err := s.tm.backupFiles()
+	now := s.tm.now()
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return writeBackup(w, files, now)
+}
+
+// CanRedo is TaskManager.CanRedo under the read lock
+func (s *SafeTaskManager) CanRedo() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.CanRedo()
+}
+
+// CanUndo is TaskManager.CanUndo under the read lock
+func (s *SafeTaskManager) CanUndo() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.CanUndo()
+}
+
+// CloneTask is TaskManager.CloneTask under the write lock
+func (s *SafeTaskManager) CloneTask(id int, opts CloneOptions) (*Task, error) {
+	defer s.lock()()
+	return s.tm.CloneTask(id, opts)
+}
+
+// CompleteTask is TaskManager.CompleteTask under the write lock
+func (s *SafeTaskManager) CompleteTask(id int, note string) error {
+	defer s.lock()()
+	return s.tm.CompleteTask(id, note)
+}
+
+// CompleteTasks is TaskManager.CompleteTasks under the write lock
+func (s *SafeTaskManager) CompleteTasks(ids []int) error {
+	defer s.lock()()
+	return s.tm.CompleteTasks(ids)
+}
+
+// CountTasks is TaskManager.CountTasks under the read lock
+func (s *SafeTaskManager) CountTasks(opts ListOptions) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.CountTasks(opts)
+}
+
+// CreateFromTemplate is TaskManager.CreateFromTemplate under the write lock
+func (s *SafeTaskManager) CreateFromTemplate(templateID int, overrides TemplateOverrides) (*Task, error) {
+	defer s.lock()()
+	return s.tm.CreateFromTemplate(templateID, overrides)
+}
+
+// CreateTemplate is TaskManager.CreateTemplate under the write lock
+func (s *SafeTaskManager) CreateTemplate(t Template) (*Template, error) {
+	defer s.lock()()
+	template, err := s.tm.CreateTemplate(t)
+	if err != nil {
+		return nil, err
+	}
+	return template.clone(), nil
+}
+
+// DefineField is TaskManager.DefineField under the write lock
+func (s *SafeTaskManager) DefineField(name string, fieldType FieldType) error {
+	defer s.lock()()
+	return s.tm.DefineField(name, fieldType)
+}
+
+// DeleteComment is TaskManager.DeleteComment under the write lock
+func (s *SafeTaskManager) DeleteComment(taskID, commentID int) error {
+	defer s.lock()()
+	return s.tm.DeleteComment(taskID, commentID)
+}
+
+// DeleteFilter is TaskManager.DeleteFilter under the write lock
+func (s *SafeTaskManager) DeleteFilter(name string) error {
+	defer s.lock()()
+	return s.tm.DeleteFilter(name)
+}
+
+// DeleteProject is TaskManager.DeleteProject under the write lock
+func (s *SafeTaskManager) DeleteProject(id int) error {
+	defer s.lock()()
+	return s.tm.DeleteProject(id)
+}
+
+// DeleteTask is TaskManager.DeleteTask under the write lock
+func (s *SafeTaskManager) DeleteTask(id int) error {
+	defer s.lock()()
+	return s.tm.DeleteTask(id)
+}
+
+// DeleteTaskCascade is TaskManager.DeleteTaskCascade under the write lock
+func (s *SafeTaskManager) DeleteTaskCascade(id int, mode CascadeMode) error {
+	defer s.lock()()
+	return s.tm.DeleteTaskCascade(id, mode)
+}
+
+// DeleteTasks is TaskManager.DeleteTasks under the write lock
+func (s *SafeTaskManager) DeleteTasks(ids []int) error {
+	defer s.lock()()
+	return s.tm.DeleteTasks(ids)
+}
+
+// DeleteTemplate is TaskManager.DeleteTemplate under the write lock
+func (s *SafeTaskManager) DeleteTemplate(id int) error {
+	defer s.lock()()
+	return s.tm.DeleteTemplate(id)
+}
+
+// DueThisWeek is TaskManager.DueThisWeek under the read lock
+func (s *SafeTaskManager) DueThisWeek(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.DueThisWeek(opts...)
+}
+
+// DueToday is TaskManager.DueToday under the read lock
+func (s *SafeTaskManager) DueToday(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.DueToday(opts...)
+}
+
+// EditComment is TaskManager.EditComment under the write lock
+func (s *SafeTaskManager) EditComment(taskID, commentID int, body string) error {
+	defer s.lock()()
+	return s.tm.EditComment(taskID, commentID, body)
+}
+
+// ExportCSV is TaskManager.ExportCSV with the tasks copied under the read
+// lock and written after it is released, so a slow writer does not hold up
+// writes
+func (s *SafeTaskManager) ExportCSV(w io.Writer, filter ListOptions) error {
+	s.mu.RLock()
+	tasks, err := s.tm.exportTasks(filter)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return writeCSV(w, tasks)
+}
+
+// ExportICS is TaskManager.ExportICS with the tasks copied under the read
+// lock and written after it is released
+func (s *SafeTaskManager) ExportICS(w io.Writer, opts ICSOptions) error {
+	s.mu.RLock()
+	tasks, err := s.tm.exportTasks(opts.Filter)
+	now := s.tm.now()
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return writeICS(w, tasks, opts, now)
+}
+
+// ExportJSON is TaskManager.ExportJSON with the tasks copied under the read
+// lock and written after it is released
+func (s *SafeTaskManager) ExportJSON(w io.Writer, filter ListOptions) error {
+	s.mu.RLock()
+	tasks, err := s.tm.exportTasks(filter)
+	var envelope *ExportEnvelope
+	if err == nil {
+		envelope = s.tm.jsonExport(tasks)
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return writeJSONExport(w, envelope)
+}
+
+// ExportMarkdown is TaskManager.ExportMarkdown with the checklist rendered
+// under the read lock and written after it is released
+func (s *SafeTaskManager) ExportMarkdown(w io.Writer, opts MarkdownOptions) error {
+	var b bytes.Buffer
+	s.mu.RLock()
+	err := s.tm.renderMarkdown(&b, opts)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = b.WriteTo(w)
+	return err
+}
+
+// FindSimilar is TaskManager.FindSimilar under the read lock
+func (s *SafeTaskManager) FindSimilar(title string, opts SimilarOptions) ([]SimilarTask, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.FindSimilar(title, opts)
+}
+
+// FindSummaries is TaskManager.FindSummaries under the read lock
+func (s *SafeTaskManager) FindSummaries(opts ListOptions) (SummaryPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.FindSummaries(opts)
+}
+
+// FindTasks is TaskManager.FindTasks under the read lock, or on the latest
+// snapshot without locking when snapshot reads are enabled
+func (s *SafeTaskManager) FindTasks(opts ListOptions) (TaskPage, error) {
+	if snapshot := s.snapshot.Load(); snapshot != nil {
+		return snapshot.FindTasks(opts)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.FindTasks(opts)
+}
+
+// FuzzySearch is TaskManager.FuzzySearch under the read lock
+func (s *SafeTaskManager) FuzzySearch(query string, opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.FuzzySearch(query, opts...)
+}
+
+// GetActivity is TaskManager.GetActivity under the read lock
+func (s *SafeTaskManager) GetActivity(id int) ([]ActivityEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.GetActivity(id)
+}
+
+// GetTask is TaskManager.GetTask under the read lock, or on the latest
+// snapshot without locking when snapshot reads are enabled
+func (s *SafeTaskManager) GetTask(id int) (*Task, error) {
+	if snapshot := s.snapshot.Load(); snapshot != nil {
+		return snapshot.GetTask(id)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.GetTask(id)
+}
+
+// GetTaskHistory is TaskManager.GetTaskHistory under the read lock
+func (s *SafeTaskManager) GetTaskHistory(id int) ([]HistoryEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.GetTaskHistory(id)
+}
+
+// GetTemplate is TaskManager.GetTemplate under the read lock
+func (s *SafeTaskManager) GetTemplate(id int) (*Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	template, err := s.tm.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	return template.clone(), nil
+}
+
+// GroupTasks is TaskManager.GroupTasks under the read lock
+func (s *SafeTaskManager) GroupTasks(by GroupField, opts GroupOptions) ([]Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.GroupTasks(by, opts)
+}
+
+// ImportCSV is TaskManager.ImportCSV with the sheet read before the write
+// lock is taken
+func (s *SafeTaskManager) ImportCSV(r io.Reader, opts CSVImportOptions) ([]*Task, error) {
+	sheet, err := parseCSV(r, opts, s.tm.timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer s.lock()()
+	return s.tm.importCSV(sheet)
+}
+
+// ImportJSON is TaskManager.ImportJSON with the export read before the
+// write lock is taken
+func (s *SafeTaskManager) ImportJSON(r io.Reader) ([]*Task, error) {
+	envelope, err := parseJSONExport(r)
+	if err != nil {
+		return nil, err
+	}
+	defer s.lock()()
+	return s.tm.importJSON(envelope)
+}
+
+// ImportTasks is TaskManager.ImportTasks under the write lock
+func (s *SafeTaskManager) ImportTasks(inputs []TaskInput) ([]*Task, error) {
+	defer s.lock()()
+	return s.tm.ImportTasks(inputs)
+}
+
+// ImportTodoist is TaskManager.ImportTodoist with the export read before
+// the lock is taken, and only the read lock taken for a dry run
+func (s *SafeTaskManager) ImportTodoist(r io.Reader, opts BoardImportOptions) (*BoardImport, error) {
+	export, err := parseTodoist(r, opts, s.tm.timezone)
+	if err != nil {
+		return nil, err
+	}
+	return s.importBoard(export, opts.DryRun)
+}
+
+// ImportTrello is TaskManager.ImportTrello with the export read before the
+// lock is taken, and only the read lock taken for a dry run
+func (s *SafeTaskManager) ImportTrello(r io.Reader, opts BoardImportOptions) (*BoardImport, error) {
+	export, err := parseTrello(r, opts, s.tm.timezone)
+	if err != nil {
+		return nil, err
+	}
+	return s.importBoard(export, opts.DryRun)
+}
+
+// importBoard imports a parsed export under the lock it needs
+func (s *SafeTaskManager) importBoard(export *boardExport, dryRun bool) (*BoardImport, error) {
+	if dryRun {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	} else {
+		defer s.lock()()
+	}
+	return s.tm.importBoard(export, dryRun)
+}
+
+// Hydrate is TaskManager.Hydrate under the read lock
+func (s *SafeTaskManager) Hydrate(task *Task) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.Hydrate(task)
+}
+
+// IsBlocked is TaskManager.IsBlocked under the read lock
+func (s *SafeTaskManager) IsBlocked(task *Task) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.IsBlocked(task)
+}
+
+// LinkTasks is TaskManager.LinkTasks under the write lock
+func (s *SafeTaskManager) LinkTasks(fromID, toID int, linkType LinkType) error {
+	defer s.lock()()
+	return s.tm.LinkTasks(fromID, toID, linkType)
+}
+
+// ListActionable is TaskManager.ListActionable under the read lock
+func (s *SafeTaskManager) ListActionable(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListActionable(opts...)
+}
+
+// ListArchived is TaskManager.ListArchived under the read lock
+func (s *SafeTaskManager) ListArchived(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListArchived(opts...)
+}
+
+// ListAttachments is TaskManager.ListAttachments under the read lock
+func (s *SafeTaskManager) ListAttachments(taskID int) ([]Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListAttachments(taskID)
+}
+
+// ListBlocked is TaskManager.ListBlocked under the read lock
+func (s *SafeTaskManager) ListBlocked(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListBlocked(opts...)
+}
+
+// ListByAssignee is TaskManager.ListByAssignee under the read lock
+func (s *SafeTaskManager) ListByAssignee(assigneeID string, opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListByAssignee(assigneeID, opts...)
+}
+
+// ListByFilter is TaskManager.ListByFilter under the read lock
+func (s *SafeTaskManager) ListByFilter(name string) (TaskPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListByFilter(name)
+}
+
+// ListByTag is TaskManager.ListByTag under the read lock
+func (s *SafeTaskManager) ListByTag(tag string, opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListByTag(tag, opts...)
+}
+
+// ListChildren is TaskManager.ListChildren under the read lock
+func (s *SafeTaskManager) ListChildren(id int, opts ...ListOption) ([]*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListChildren(id, opts...)
+}
+
+// ListComments is TaskManager.ListComments under the read lock
+func (s *SafeTaskManager) ListComments(taskID int) ([]Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListComments(taskID)
+}
+
+// ListDueBefore is TaskManager.ListDueBefore under the read lock
+func (s *SafeTaskManager) ListDueBefore(before time.Time, opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListDueBefore(before, opts...)
+}
+
+// ListFields is TaskManager.ListFields under the read lock
+func (s *SafeTaskManager) ListFields() []FieldDefinition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListFields()
+}
+
+// ListNear is TaskManager.ListNear under the read lock
+func (s *SafeTaskManager) ListNear(lat, lng, radius float64, opts ...ListOption) ([]*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListNear(lat, lng, radius, opts...)
+}
+
+// ListOverdue is TaskManager.ListOverdue under the read lock
+func (s *SafeTaskManager) ListOverdue(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListOverdue(opts...)
+}
+
+// ListProjectTasks is TaskManager.ListProjectTasks under the read lock
+func (s *SafeTaskManager) ListProjectTasks(projectID int, opts ...ListOption) ([]*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListProjectTasks(projectID, opts...)
+}
+
+// ListRelated is TaskManager.ListRelated under the read lock
+func (s *SafeTaskManager) ListRelated(id int) ([]TaskLink, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListRelated(id)
+}
+
+// ListSnoozed is TaskManager.ListSnoozed under the read lock
+func (s *SafeTaskManager) ListSnoozed(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListSnoozed(opts...)
+}
+
+// ListSummaries is TaskManager.ListSummaries under the read lock
+func (s *SafeTaskManager) ListSummaries(filterDone *bool, opts ...ListOption) []TaskSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListSummaries(filterDone, opts...)
+}
+
+// ListTasks is TaskManager.ListTasks under the read lock, or on the latest
+// snapshot without locking when snapshot reads are enabled
+func (s *SafeTaskManager) ListTasks(filterDone *bool, opts ...ListOption) []*Task {
+	if snapshot := s.snapshot.Load(); snapshot != nil {
+		return snapshot.ListTasks(filterDone, opts...)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListTasks(filterDone, opts...)
+}
+
+// ListTasksInto is TaskManager.ListTasksInto under the read lock, or on the
+// latest snapshot without locking when snapshot reads are enabled
+func (s *SafeTaskManager) ListTasksInto(dst []*Task, filterDone *bool, opts ...ListOption) []*Task {
+	if snapshot := s.snapshot.Load(); snapshot != nil {
+		return snapshot.ListTasksInto(dst, filterDone, opts...)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListTasksInto(dst, filterDone, opts...)
+}
+
+// ListTasksPage is TaskManager.ListTasksPage under the read lock
+func (s *SafeTaskManager) ListTasksPage(filterDone *bool, page Page, opts ...ListOption) (TaskPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListTasksPage(filterDone, page, opts...)
+}
+
+// ListTemplates is TaskManager.ListTemplates under the read lock
+func (s *SafeTaskManager) ListTemplates() []*Template {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	templates := s.tm.ListTemplates()
+	for i, template := range templates {
+		templates[i] = template.clone()
+	}
+	return templates
+}
+
+// ListTrash is TaskManager.ListTrash under the read lock
+func (s *SafeTaskManager) ListTrash() []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListTrash()
+}
+
+// ListUpcomingReminders is TaskManager.ListUpcomingReminders under the read lock
+func (s *SafeTaskManager) ListUpcomingReminders(window time.Duration) []Reminder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.ListUpcomingReminders(window)
+}
+
+// MergeTasks is TaskManager.MergeTasks under the write lock
+func (s *SafeTaskManager) MergeTasks(targetID int, sourceIDs ...int) (*Task, error) {
+	defer s.lock()()
+	return s.tm.MergeTasks(targetID, sourceIDs...)
+}
+
+// MoveChecklistItem is TaskManager.MoveChecklistItem under the write lock
+func (s *SafeTaskManager) MoveChecklistItem(taskID, itemID, position int) error {
+	defer s.lock()()
+	return s.tm.MoveChecklistItem(taskID, itemID, position)
+}
+
+// MoveTask is TaskManager.MoveTask under the write lock
+func (s *SafeTaskManager) MoveTask(id, afterID int) error {
+	defer s.lock()()
+	return s.tm.MoveTask(id, afterID)
+}
+
+// MoveTaskToProject is TaskManager.MoveTaskToProject under the write lock
+func (s *SafeTaskManager) MoveTaskToProject(taskID, projectID int) error {
+	defer s.lock()()
+	return s.tm.MoveTaskToProject(taskID, projectID)
+}
+
+// Overdue is TaskManager.Overdue under the read lock
+func (s *SafeTaskManager) Overdue(opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.Overdue(opts...)
+}
+
+// PinTask is TaskManager.PinTask under the write lock
+func (s *SafeTaskManager) PinTask(id int) error {
+	defer s.lock()()
+	return s.tm.PinTask(id)
+}
+
+// Progress is TaskManager.Progress under the read lock
+func (s *SafeTaskManager) Progress(id int) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.Progress(id)
+}
+
+// PurgeTrash is TaskManager.PurgeTrash under the write lock
+func (s *SafeTaskManager) PurgeTrash() int {
+	defer s.lock()()
+	return s.tm.PurgeTrash()
+}
+
+// RecentlyModified is TaskManager.RecentlyModified under the read lock
+func (s *SafeTaskManager) RecentlyModified(since time.Time, limit int, opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.RecentlyModified(since, limit, opts...)
+}
+
+// Redo is TaskManager.Redo under the write lock
+func (s *SafeTaskManager) Redo() error {
+	defer s.lock()()
+	return s.tm.Redo()
+}
+
+// RemoveAttachment is TaskManager.RemoveAttachment under the write lock
+func (s *SafeTaskManager) RemoveAttachment(taskID, attachmentID int) error {
+	defer s.lock()()
+	return s.tm.RemoveAttachment(taskID, attachmentID)
+}
+
+// RemoveChecklistItem is TaskManager.RemoveChecklistItem under the write lock
+func (s *SafeTaskManager) RemoveChecklistItem(taskID, itemID int) error {
+	defer s.lock()()
+	return s.tm.RemoveChecklistItem(taskID, itemID)
+}
+
+// RemoveDependency is TaskManager.RemoveDependency under the write lock
+func (s *SafeTaskManager) RemoveDependency(taskID, dependsOnID int) error {
+	defer s.lock()()
+	return s.tm.RemoveDependency(taskID, dependsOnID)
+}
+
+// RemoveField is TaskManager.RemoveField under the write lock
+func (s *SafeTaskManager) RemoveField(name string) error {
+	defer s.lock()()
+	return s.tm.RemoveField(name)
+}
+
+// RemoveReminder is TaskManager.RemoveReminder under the write lock
+func (s *SafeTaskManager) RemoveReminder(taskID int, at time.Time) error {
+	defer s.lock()()
+	return s.tm.RemoveReminder(taskID, at)
+}
+
+// RenameProject is TaskManager.RenameProject under the write lock
+func (s *SafeTaskManager) RenameProject(id int, name string) error {
+	defer s.lock()()
+	return s.tm.RenameProject(id, name)
+}
+
+// Restore is TaskManager.Restore with the archive read and checked before
+// the write lock is taken
+func (s *SafeTaskManager) Restore(r io.Reader, opts RestoreOptions) (*RestoreResult, error) {
+	b, err := readBackup(r)
+	if err != nil {
+		return nil, err
+	}
+	defer s.lock()()
+	return s.tm.restore(b, opts)
+}
+
+// RestoreTask is TaskManager.RestoreTask under the write lock
+func (s *SafeTaskManager) RestoreTask(id int) error {
+	defer s.lock()()
+	return s.tm.RestoreTask(id)
+}
+
+// RolledUpEstimate is TaskManager.RolledUpEstimate under the read lock
+func (s *SafeTaskManager) RolledUpEstimate(id int) (time.Duration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.RolledUpEstimate(id)
+}
+
+// RolledUpRemaining is TaskManager.RolledUpRemaining under the read lock
+func (s *SafeTaskManager) RolledUpRemaining(id int) (time.Duration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.RolledUpRemaining(id)
+}
+
+// SampleTasks is TaskManager.SampleTasks under the write lock
+func (s *SafeTaskManager) SampleTasks(n int, filter ListOptions, weight SampleWeight) ([]*Task, error) {
+	defer s.lock()()
+	return s.tm.SampleTasks(n, filter, weight)
+}
+
+// Search is TaskManager.Search under the read lock
+func (s *SafeTaskManager) Search(query string, opts ...ListOption) []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.Search(query, opts...)
+}
+
+// SetCustomField is TaskManager.SetCustomField under the write lock
+func (s *SafeTaskManager) SetCustomField(id int, name string, value any) error {
+	defer s.lock()()
+	return s.tm.SetCustomField(id, name, value)
+}
+
+// SnoozeTask is TaskManager.SnoozeTask under the write lock
+func (s *SafeTaskManager) SnoozeTask(id int, until time.Time) error {
+	defer s.lock()()
+	return s.tm.SnoozeTask(id, until)
+}
+
+// StartTimer is TaskManager.StartTimer under the write lock
+func (s *SafeTaskManager) StartTimer(id int) error {
+	defer s.lock()()
+	return s.tm.StartTimer(id)
+}
+
+// Stats is TaskManager.Stats under the read lock. Reads served from a
+// published snapshot are not counted.
+func (s *SafeTaskManager) Stats() ManagerStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.Stats()
+}
+
+// StopTimer is TaskManager.StopTimer under the write lock
+func (s *SafeTaskManager) StopTimer(id int) error {
+	defer s.lock()()
+	return s.tm.StopTimer(id)
+}
+
+// SuggestTitles is TaskManager.SuggestTitles under the read lock
+func (s *SafeTaskManager) SuggestTitles(prefix string, limit int) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.SuggestTitles(prefix, limit)
+}
+
+// TaskCounts is TaskManager.TaskCounts under the read lock
+func (s *SafeTaskManager) TaskCounts() TaskCounts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.TaskCounts()
+}
+
+// TimeReport is TaskManager.TimeReport under the read lock
+func (s *SafeTaskManager) TimeReport(opts ...ListOption) TimeReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.TimeReport(opts...)
+}
+
+// ToggleChecklistItem is TaskManager.ToggleChecklistItem under the write lock
+func (s *SafeTaskManager) ToggleChecklistItem(taskID, itemID int) error {
+	defer s.lock()()
+	return s.tm.ToggleChecklistItem(taskID, itemID)
+}
+
+// TotalEstimate is TaskManager.TotalEstimate under the read lock
+func (s *SafeTaskManager) TotalEstimate(opts ...ListOption) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.TotalEstimate(opts...)
+}
+
+// TrackedTime is TaskManager.TrackedTime under the read lock
+func (s *SafeTaskManager) TrackedTime(id int) (time.Duration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.TrackedTime(id)
+}
+
+// Transition is TaskManager.Transition under the write lock
+func (s *SafeTaskManager) Transition(id int, status Status) error {
+	defer s.lock()()
+	return s.tm.Transition(id, status)
+}
+
+// UnarchiveTask is TaskManager.UnarchiveTask under the write lock
+func (s *SafeTaskManager) UnarchiveTask(id int) error {
+	defer s.lock()()
+	return s.tm.UnarchiveTask(id)
+}
+
+// UnassignTask is TaskManager.UnassignTask under the write lock
+func (s *SafeTaskManager) UnassignTask(id int) error {
+	defer s.lock()()
+	return s.tm.UnassignTask(id)
+}
+
+// Undo is TaskManager.Undo under the write lock
+func (s *SafeTaskManager) Undo() error {
+	defer s.lock()()
+	return s.tm.Undo()
+}
+
+// UnlinkTasks is TaskManager.UnlinkTasks under the write lock
+func (s *SafeTaskManager) UnlinkTasks(fromID, toID int, linkType LinkType) error {
+	defer s.lock()()
+	return s.tm.UnlinkTasks(fromID, toID, linkType)
+}
+
+// UnpinTask is TaskManager.UnpinTask under the write lock
+func (s *SafeTaskManager) UnpinTask(id int) error {
+	defer s.lock()()
+	return s.tm.UnpinTask(id)
+}
+
+// UnsnoozeTask is TaskManager.UnsnoozeTask under the write lock
+func (s *SafeTaskManager) UnsnoozeTask(id int) error {
+	defer s.lock()()
+	return s.tm.UnsnoozeTask(id)
+}
+
+// UpcomingOccurrences is TaskManager.UpcomingOccurrences under the read lock
+func (s *SafeTaskManager) UpcomingOccurrences(id int, n int) ([]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tm.UpcomingOccurrences(id, n)
+}
+
+// UpdateFilter is TaskManager.UpdateFilter under the write lock
+func (s *SafeTaskManager) UpdateFilter(name string, opts ListOptions) error {
+	defer s.lock()()
+	return s.tm.UpdateFilter(name, opts)
+}
+
+// UpdateTask is TaskManager.UpdateTask under the write lock
+func (s *SafeTaskManager) UpdateTask(id int, title, description string, done bool, opts ...TaskOption) error {
+	defer s.lock()()
+	return s.tm.UpdateTask(id, title, description, done, opts...)
+}
+
+// UpdateTaskFields is TaskManager.UpdateTaskFields under the write lock
+func (s *SafeTaskManager) UpdateTaskFields(id int, patch TaskPatch) error {
+	defer s.lock()()
+	return s.tm.UpdateTaskFields(id, patch)
+}
+
+// UpdateTasks is TaskManager.UpdateTasks under the write lock
+func (s *SafeTaskManager) UpdateTasks(updates []TaskUpdate) error {
+	defer s.lock()()
+	return s.tm.UpdateTasks(updates)
+}
+
+// UploadAttachment is TaskManager.UploadAttachment under the write lock
+func (s *SafeTaskManager) UploadAttachment(taskID int, name, mimeType string, r io.Reader) (Attachment, error) {
+	defer s.lock()()
+	return s.tm.UploadAttachment(taskID, name, mimeType, r)
+}
@@ -0,0 +1,246 @@
+package taskmanager
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSafeTaskManagerCoversTaskManager(t *testing.T) {
+	safe := reflect.TypeFor[*SafeTaskManager]()
+	unsafe := reflect.TypeFor[*TaskManager]()
+	for i := range unsafe.NumMethod() {
+		method := unsafe.Method(i)
+		if _, ok := safe.MethodByName(method.Name); !ok {
+			t.Errorf("Expected SafeTaskManager to provide %s", method.Name)
+		}
+	}
+}
+
+// safeOps exercises every method of SafeTaskManager on task id. Errors are
+// expected, since other goroutines delete and change the same tasks.
+var safeOps = map[string]func(s *SafeTaskManager, id int){
+	"tasks": func(s *SafeTaskManager, id int) {
+		s.AddTask("Task", "Description", WithTags("work"))
+		s.AddTasks([]TaskInput{{Title: "Bulk"}})
+		s.AddTasksBatch([]TaskInput{{Title: "Batch"}, {Title: "Batch"}})
+		s.ImportTasks([]TaskInput{{Title: "Imported"}, {Title: ""}})
+		s.ImportCSV(strings.NewReader("title,tags\nFrom CSV,work\n,\n"), CSVImportOptions{})
+		s.ImportJSON(strings.NewReader(`{"format": "taskmanager-export", "version": 1, "tasks": [{"title": "From JSON", "checklist": [{"text": "Step"}]}]}`))
+		s.ImportTodoist(strings.NewReader(`{"items": [{"id": 1, "content": "From Todoist"}]}`), BoardImportOptions{})
+		s.ImportTrello(strings.NewReader(`{"cards": [{"id": "c1", "name": "From Trello"}]}`), BoardImportOptions{DryRun: true})
+		s.ExportCSV(io.Discard, ListOptions{Tags: []string{"work"}})
+		s.ExportJSON(io.Discard, ListOptions{Tags: []string{"work"}})
+		s.ExportMarkdown(io.Discard, MarkdownOptions{GroupBy: GroupByProject})
+		s.ExportICS(io.Discard, ICSOptions{Component: ICSEvent})
+		s.AddSubtask(id, "Subtask", "")
+		s.UpdateTask(id, "Renamed", "", false)
+		s.UpdateTasks([]TaskUpdate{{ID: id, Title: "Bulk renamed"}})
+		s.UpdateTaskFields(id, TaskPatch{Tags: ptr([]string{"home"})})
+		s.Transition(id, StatusInProgress)
+		s.CompleteTask(id, "done")
+		s.CompleteTasks([]int{id})
+		s.CloneTask(id, CloneOptions{IncludeChecklist: true})
+		s.MergeTasks(id, id+1)
+		s.MoveTask(id, id+1)
+		s.DeleteTask(id)
+		s.DeleteTasks([]int{id + 1})
+		s.DeleteTaskCascade(id+2, CascadeRecursive)
+		s.RestoreTask(id)
+		s.ListTrash()
+		s.PurgeTrash()
+		// Backups hold every task, so only some calls take one
+		if id%4 == 0 {
+			var backup bytes.Buffer
+			if s.Backup(&backup) == nil {
+				s.Restore(&backup, RestoreOptions{})
+			}
+		}
+		s.Undo()
+		s.Redo()
+		s.CanUndo()
+		s.CanRedo()
+	},
+	"fields": func(s *SafeTaskManager, id int) {
+		s.ArchiveTask(id)
+		s.UnarchiveTask(id)
+		s.AssignTask(id, "alice")
+		s.UnassignTask(id)
+		s.PinTask(id)
+		s.UnpinTask(id)
+		s.SnoozeTask(id, time.Now().Add(time.Hour))
+		s.UnsnoozeTask(id)
+		s.AddReminder(id, time.Now().Add(time.Minute))
+		s.RemoveReminder(id, time.Now())
+		s.DefineField("points", FieldNumber)
+		s.SetCustomField(id, "points", 3.0)
+		s.RemoveField("points")
+		s.ListFields()
+		s.AddDependency(id, id+1)
+		s.RemoveDependency(id, id+1)
+		s.LinkTasks(id, id+1, LinkRelatesTo)
+		s.UnlinkTasks(id, id+1, LinkRelatesTo)
+		s.ListRelated(id)
+		s.StartTimer(id)
+		s.StopTimer(id)
+		s.TrackedTime(id)
+		s.Progress(id)
+		s.RolledUpEstimate(id)
+		s.RolledUpRemaining(id)
+		s.UpcomingOccurrences(id, 3)
+		s.GetTaskHistory(id)
+		s.GetActivity(id)
+	},
+	"items": func(s *SafeTaskManager, id int) {
+		item, _ := s.AddChecklistItem(id, "Step")
+		s.ToggleChecklistItem(id, item.ID)
+		s.MoveChecklistItem(id, item.ID, 0)
+		s.RemoveChecklistItem(id, item.ID)
+		comment, _ := s.AddComment(id, "alice", "Looks good")
+		s.EditComment(id, comment.ID, "Looks great")
+		s.ListComments(id)
+		s.DeleteComment(id, comment.ID)
+		attachment, _ := s.AddAttachment(id, Attachment{Name: "a.txt", Size: 1})
+		s.UploadAttachment(id, "b.txt", "text/plain", strings.NewReader("b"))
+		s.ListAttachments(id)
+		s.RemoveAttachment(id, attachment.ID)
+	},
+	"collections": func(s *SafeTaskManager, id int) {
+		project, err := s.CreateProject("Project")
+		if err == nil {
+			s.RenameProject(project.ID, "Renamed project")
+			s.MoveTaskToProject(id, project.ID)
+			s.GetProject(project.ID)
+			s.ListProjectTasks(project.ID)
+			s.ListProjects()
+			s.DeleteProject(project.ID)
+		}
+		s.SaveFilter("work", ListOptions{Tags: []string{"work"}})
+		s.UpdateFilter("work", ListOptions{AnyTags: []string{"work", "home"}})
+		s.GetFilter("work")
+		s.ListFilters()
+		s.ListByFilter("work")
+		s.DeleteFilter("work")
+		template, err := s.CreateTemplate(Template{Name: "Weekly", TitlePattern: "Weekly review"})
+		if err == nil {
+			s.GetTemplate(template.ID)
+			s.CreateFromTemplate(template.ID, TemplateOverrides{})
+			s.ListTemplates()
+			s.DeleteTemplate(template.ID)
+		}
+		s.ApplyRetentionPolicy()
+	},
+	"listings": func(s *SafeTaskManager, id int) {
+		task, err := s.GetTask(id)
+		if err == nil {
+			s.IsBlocked(task)
+		}
+		s.ListTasks(nil, SortByPriority())
+		s.ListTasksInto(nil, nil)
+		s.Hydrate(&Task{ID: id})
+		s.ListTasksPage(nil, Page{Limit: 2})
+		for range s.Tasks(nil) {
+			s.GetTask(id)
+		}
+		s.ForEachTask(ListOptions{}, func(task *Task) bool {
+			s.UpdateTask(task.ID, task.Title, "", false)
+			return false
+		})
+		s.FindTasks(ListOptions{Tags: []string{"work"}, Explain: true})
+		s.CountTasks(ListOptions{})
+		s.FindSummaries(ListOptions{})
+		s.ListSummaries(nil)
+		s.GroupTasks(GroupByTag, GroupOptions{})
+		s.SampleTasks(2, ListOptions{}, SampleByAge)
+		s.RecentlyModified(time.Time{}, 5)
+		s.ListArchived()
+		s.ListByAssignee("alice")
+		s.ListByTag("work")
+		s.ListBlocked()
+		s.ListActionable()
+		s.ListChildren(id)
+		s.ListSnoozed()
+		s.ListNear(0, 0, 10)
+		s.ListUpcomingReminders(time.Hour)
+		s.DueToday()
+		s.DueThisWeek()
+		s.Overdue()
+		s.ListOverdue()
+		s.ListDueBefore(time.Now())
+		s.TotalEstimate()
+		s.TimeReport()
+		s.Stats()
+		s.TaskCounts()
+	},
+	"search": func(s *SafeTaskManager, id int) {
+		s.Search("task")
+		s.FuzzySearch("tsak")
+		s.FindSimilar("Task", SimilarOptions{})
+		s.SuggestTitles("ta", 5)
+	},
+}
+
+func TestSafeTaskManagerConcurrentUse(t *testing.T) {
+	s := NewSafeTaskManager(WithUndoDepth(10))
+	for range 10 {
+		s.AddTask("Task", "")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.RunRetentionPolicy(ctx, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for _, op := range safeOps {
+		for g := range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range 20 {
+					op(s, 1+(g*7+i)%12)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	task, err := s.AddTask("Last", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, err := s.GetTask(task.ID); err != nil || got.Title != "Last" {
+		t.Errorf("Expected the manager to still work after concurrent use, got %v, %v", got, err)
+	}
+}
+
+func TestSafeTaskManagerReturnsCopies(t *testing.T) {
+	s := NewSafeTaskManager()
+	task, _ := s.AddTask("Task", "")
+	project, _ := s.CreateProject("Home")
+	filter, _ := s.SaveFilter("open", ListOptions{Tags: []string{"work"}})
+	template, _ := s.CreateTemplate(Template{Name: "Weekly", TitlePattern: "Weekly review", Tags: []string{"work"}, Checklist: []string{"Inbox"}})
+
+	task.Title = "Changed"
+	project.Name = "Changed"
+	filter.Options.Tags[0] = "changed"
+	template.Tags[0] = "changed"
+	fetched, _ := s.GetTemplate(template.ID)
+	fetched.Checklist[0] = "Changed"
+	s.ListTemplates()[0].Name = "Changed"
+	if got, _ := s.GetTask(task.ID); got.Title != "Task" {
+		t.Errorf("Expected the stored task to be unchanged, got %q", got.Title)
+	}
+	if got, _ := s.GetProject(project.ID); got.Name != "Home" {
+		t.Errorf("Expected the stored project to be unchanged, got %q", got.Name)
+	}
+	if got, _ := s.GetFilter("open"); got.Options.Tags[0] != "work" {
+		t.Errorf("Expected the stored filter to be unchanged, got %v", got.Options.Tags)
+	}
+	if got, _ := s.GetTemplate(template.ID); got.Name != "Weekly" || got.Tags[0] != "work" || got.Checklist[0] != "Inbox" {
+		t.Errorf("Expected the stored template to be unchanged, got %+v", got)
+	}
+}
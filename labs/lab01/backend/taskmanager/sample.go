@@ -0,0 +1,85 @@
+package taskmanager
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrInvalidSampleSize is returned when a negative number of tasks is sampled
+	ErrInvalidSampleSize = errors.New("invalid sample size")
+	// ErrInvalidSampleWeight is returned when a sample weighting is not one of the known values
+	ErrInvalidSampleWeight = errors.New("invalid sample weight")
+)
+
+// SampleWeight decides how likely each task is to be sampled
+type SampleWeight int
+
+const (
+	// SampleUniform gives every task the same chance
+	SampleUniform SampleWeight = iota
+	// SampleByAge favours tasks in proportion to how long ago they were created
+	SampleByAge
+	// SampleByPriority favours tasks in proportion to their priority, so an
+	// urgent task is four times as likely as a low one
+	SampleByPriority
+)
+
+// WithRand sets the random source used by SampleTasks, so tests can seed
+// it. The default is seeded randomly.
+func WithRand(r *rand.Rand) Option {
+	return func(tm *TaskManager) {
+		tm.rand = r
+	}
+}
+
+// SampleTasks returns up to n distinct tasks matching filter, picked at
+// random with the given weighting, for reviewing forgotten backlog items.
+// When fewer than n tasks match, all of them are returned in random order.
+// filter.Page and filter.Sort are ignored.
+func (tm *TaskManager) SampleTasks(n int, filter ListOptions, weight SampleWeight) ([]*Task, error) {
+	if n < 0 {
+		return nil, ErrInvalidSampleSize
+	}
+	if weight < SampleUniform || weight > SampleByPriority {
+		return nil, ErrInvalidSampleWeight
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	var tasks []*Task
+	tm.scan(tm.newQuery(filter.Done, filter.listOptions()), func(task *Task) bool {
+		tasks = append(tasks, task)
+		return true
+	})
+	// Draw in ID order so a seeded source always picks the same tasks
+	slices.SortFunc(tasks, func(a, b *Task) int { return cmp.Compare(a.ID, b.ID) })
+
+	// Each task gets an exponentially distributed key with rate equal to
+	// its weight; the n smallest keys are a weighted sample without
+	// replacement
+	now := tm.now()
+	keys := make(map[int]float64, len(tasks))
+	for _, task := range tasks {
+		keys[task.ID] = tm.rand.ExpFloat64() / sampleWeight(task, weight, now)
+	}
+	slices.SortFunc(tasks, func(a, b *Task) int { return cmp.Compare(keys[a.ID], keys[b.ID]) })
+	return tm.exportAll(tasks[:min(n, len(tasks))]), nil
+}
+
+// sampleWeight returns the relative chance of the task being sampled
+func sampleWeight(task *Task, weight SampleWeight, now time.Time) float64 {
+	switch weight {
+	case SampleByAge:
+		return math.Max(now.Sub(task.CreatedAt).Hours(), 0) + 1
+	case SampleByPriority:
+		return float64(task.Priority)
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,102 @@
+package taskmanager
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSampleTasks(t *testing.T) {
+	newManager := func(seed uint64) *TaskManager {
+		now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+		tm := NewTaskManager(
+			WithClock(func() time.Time { return now }),
+			WithRand(rand.New(rand.NewPCG(seed, 0))),
+		)
+		for i := 1; i <= 10; i++ {
+			tag := "home"
+			if i > 8 {
+				tag = "work"
+			}
+			mustAddTask(t, tm, fmt.Sprintf("Task %d", i), WithTags(tag))
+		}
+		return tm
+	}
+
+	tm := newManager(1)
+	sample, err := tm.SampleTasks(3, ListOptions{}, SampleUniform)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sample) != 3 {
+		t.Fatalf("Expected 3 tasks, got %v", titles(sample))
+	}
+	if got := titles(sample); len(slices.Compact(slices.Sorted(slices.Values(got)))) != 3 {
+		t.Errorf("Expected distinct tasks, got %v", got)
+	}
+
+	again, _ := newManager(1).SampleTasks(3, ListOptions{}, SampleUniform)
+	if !slices.Equal(titles(again), titles(sample)) {
+		t.Errorf("Expected the same seed to give the same sample, got %v and %v", titles(sample), titles(again))
+	}
+
+	work, _ := tm.SampleTasks(5, ListOptions{Tags: []string{"work"}}, SampleUniform)
+	if got := titles(work); len(got) != 2 || !slices.Contains(got, "Task 9") || !slices.Contains(got, "Task 10") {
+		t.Errorf("Expected both work tasks when asking for more, got %v", got)
+	}
+	if none, _ := tm.SampleTasks(0, ListOptions{}, SampleUniform); len(none) != 0 {
+		t.Errorf("Expected an empty sample, got %v", titles(none))
+	}
+
+	if _, err := tm.SampleTasks(-1, ListOptions{}, SampleUniform); err != ErrInvalidSampleSize {
+		t.Errorf("Expected ErrInvalidSampleSize, got %v", err)
+	}
+	if _, err := tm.SampleTasks(1, ListOptions{}, 42); err != ErrInvalidSampleWeight {
+		t.Errorf("Expected ErrInvalidSampleWeight, got %v", err)
+	}
+	if _, err := tm.SampleTasks(1, ListOptions{Statuses: []Status{42}}, SampleUniform); err != ErrInvalidStatus {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestSampleTasksWeighted(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(
+		WithClock(func() time.Time { return now }),
+		WithRand(rand.New(rand.NewPCG(7, 7))),
+	)
+	old := mustAddTask(t, tm, "Old", WithPriority(PriorityLow))
+	now = now.Add(99 * time.Hour)
+	urgent := mustAddTask(t, tm, "Urgent", WithPriority(PriorityUrgent))
+
+	tests := []struct {
+		name   string
+		weight SampleWeight
+		likely *Task
+	}{
+		// Old is 100 times as heavy as the brand-new task
+		{name: "by age", weight: SampleByAge, likely: old},
+		// Urgent is 4 times as heavy as low
+		{name: "by priority", weight: SampleByPriority, likely: urgent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picks := 0
+			for range 1000 {
+				sample, err := tm.SampleTasks(1, ListOptions{}, tt.weight)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if sample[0] == tt.likely {
+					picks++
+				}
+			}
+			if picks < 700 {
+				t.Errorf("Expected %s to be picked most of the time, got %d of 1000", tt.likely.Title, picks)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package taskmanager
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+)
+
+// kdfParams are the scrypt costs a key is derived at: 2^logN blocks of
+// 128*r bytes each, mixed p times
+type kdfParams struct {
+	logN, r, p uint8
+}
+
+// memory returns the bytes scrypt holds while deriving a key
+func (k kdfParams) memory() int {
+	return 128 * int(k.r) << k.logN
+}
+
+// scryptKey derives a key of keyLen bytes from passphrase and salt with
+// scrypt, as RFC 7914 specifies it
+func scryptKey(passphrase string, salt []byte, params kdfParams, keyLen int) ([]byte, error) {
+	r, p := int(params.r), int(params.p)
+	blockSize := 128 * r
+	b, err := pbkdf2.Key(sha256.New, passphrase, salt, 1, p*blockSize)
+	if err != nil {
+		return nil, err
+	}
+	x := make([]uint32, 32*r)
+	y := make([]uint32, 32*r)
+	v := make([]uint32, 32*r<<params.logN)
+	for i := range p {
+		roMix(b[i*blockSize:(i+1)*blockSize], r, params.logN, x, y, v)
+	}
+	return pbkdf2.Key(sha256.New, passphrase, b, 1, keyLen)
+}
+
+// roMix is scryptROMix: it fills v with successive mixes of block, then
+// mixes block with entries of v picked by its own contents
+func roMix(block []byte, r int, logN uint8, x, y, v []uint32) {
+	n := 1 << logN
+	words := 32 * r
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+	for i := range n {
+		copy(v[i*words:], x)
+		blockMix(x, y, r)
+	}
+	for range n {
+		j := int(x[words-16]) & (n - 1)
+		for k, w := range v[j*words : (j+1)*words] {
+			x[k] ^= w
+		}
+		blockMix(x, y, r)
+	}
+	for i, w := range x {
+		binary.LittleEndian.PutUint32(block[4*i:], w)
+	}
+}
+
+// blockMix is scryptBlockMix over the 2*r 64-byte blocks of b, using y as
+// scratch space
+func blockMix(b, y []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], b[(2*r-1)*16:])
+	for i := range 2 * r {
+		for k := range x {
+			x[k] ^= b[i*16+k]
+		}
+		salsa208(&x)
+		// Even blocks go to the first half of the output, odd ones to the
+		// second
+		copy(y[(i/2+i%2*r)*16:], x[:])
+	}
+	copy(b, y)
+}
+
+// salsa208 applies the Salsa20/8 core to b
+func salsa208(b *[16]uint32) {
+	x := *b
+	for range 4 {
+		// Columns
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+		// Rows
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+	for i := range b {
+		b[i] += x[i]
+	}
+}
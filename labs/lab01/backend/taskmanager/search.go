@@ -0,0 +1,82 @@
+package taskmanager
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Scores awarded to a task for each query term, by where the term matched
+const (
+	scoreTitleWord         = 8
+	scoreTitlePrefix       = 4
+	scoreDescriptionWord   = 2
+	scoreDescriptionPrefix = 1
+)
+
+// Search returns the tasks whose title or description contains every word
+// of the query, most relevant first. Words are compared ignoring case and a
+// query word also matches the start of a longer word, so "rep" finds
+// "report". Matches in the title rank above matches in the description and
+// whole words above prefixes; equally relevant tasks keep the order
+// ListTasks would give them. A query without words matches nothing.
+func (tm *TaskManager) Search(query string, opts ...ListOption) []*Task {
+	terms := slices.Compact(slices.Sorted(slices.Values(tokenize(query))))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]int)
+	result := tm.ListTasks(nil, append(opts, where(func(task *Task) bool {
+		score := searchScore(task, terms)
+		scores[task.ID] = score
+		return score > 0
+	}))...)
+	slices.SortStableFunc(result, func(a, b *Task) int {
+		return cmp.Compare(scores[b.ID], scores[a.ID])
+	})
+	return result
+}
+
+// searchScore returns the relevance of the task for the query terms, or
+// zero when some term matches neither the title nor the description
+func searchScore(task *Task, terms []string) int {
+	title := tokenize(task.Title)
+	description := tokenize(task.PlainDescription())
+
+	total := 0
+	for _, term := range terms {
+		score := max(
+			termScore(title, term, scoreTitleWord, scoreTitlePrefix),
+			termScore(description, term, scoreDescriptionWord, scoreDescriptionPrefix),
+		)
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
+
+// termScore returns word when the term is one of the words, prefix when it
+// starts one of them and zero otherwise
+func termScore(words []string, term string, word, prefix int) int {
+	score := 0
+	for _, w := range words {
+		if w == term {
+			return word
+		}
+		if strings.HasPrefix(w, term) {
+			score = prefix
+		}
+	}
+	return score
+}
+
+// tokenize splits text into lowercased runs of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
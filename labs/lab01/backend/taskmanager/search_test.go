@@ -0,0 +1,53 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	add := func(title, description string, opts ...TaskOption) {
+		t.Helper()
+		if _, err := tm.AddTask(title, description, opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	add("Prepare slides", "Use the quarterly report numbers")
+	add("Quarterly report", "Send to finance")
+	add("Reporting dashboard", "")
+	add("Buy groceries", "Milk, eggs and *bread*", WithTags("home"))
+	add("Fix login bug", "Users report `timeouts` on login")
+
+	tests := []struct {
+		name     string
+		query    string
+		opts     []ListOption
+		expected []string
+	}{
+		{name: "title beats description", query: "report", expected: []string{"Quarterly report", "Reporting dashboard", "Prepare slides", "Fix login bug"}},
+		{name: "every word must match", query: "quarterly report", expected: []string{"Quarterly report", "Prepare slides"}},
+		{name: "case folding", query: "BREAD", expected: []string{"Buy groceries"}},
+		{name: "punctuation ignored", query: "milk,eggs!", expected: []string{"Buy groceries"}},
+		{name: "prefix", query: "groc", expected: []string{"Buy groceries"}},
+		{name: "code in description", query: "timeouts", expected: []string{"Fix login bug"}},
+		{name: "no match", query: "holiday", expected: []string{}},
+		{name: "list options apply", query: "report", opts: []ListOption{FilterByAnyTag("home")}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tm.Search(tt.query, tt.opts...)); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if got := tm.Search("  ?! "); got != nil {
+		t.Errorf("Expected no results for an empty query, got %v", titles(got))
+	}
+}
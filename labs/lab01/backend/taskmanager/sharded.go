@@ -0,0 +1,294 @@
+package taskmanager
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShardedTaskManager spreads tasks over several SafeTaskManager shards, each
+// with its own lock, so requests for tasks on different shards do not wait
+// for each other. The shard holding a task follows from its ID. Listings
+// visit every shard and merge the results in listing order.
+//
+// Each shard is a separate manager: undo history, projects, templates,
+// saved filters and custom field definitions are per shard, and tasks can
+// only reference tasks on their own shard as parents, dependencies, links
+// or merge sources. AddSubtask keeps subtasks with their parent; use Shard
+// for the other per-task operations. Options are applied to every shard, so
+// a clock, blob store or random source given to them must be safe for
+// concurrent use, and WithMaxTasks limits each shard on its own. Large
+// listings filter the shards in parallel, as WithListParallelism allows.
+type ShardedTaskManager struct {
+	shards      []*SafeTaskManager
+	next        atomic.Uint64
+	parallelism int
+}
+
+// minParallelListing is the fewest tasks, over all shards, for which
+// listings filter the shards in parallel. Smaller listings are done
+// sooner than goroutines can be started for them.
+const minParallelListing = 1024
+
+// WithListParallelism sets how many shards of a ShardedTaskManager are
+// filtered at once for a listing or count. Zero, the default, means one per
+// CPU, and 1 filters the shards one after another. A TaskManager used on
+// its own ignores the option.
+func WithListParallelism(n int) Option {
+	return func(tm *TaskManager) {
+		tm.listParallelism = n
+	}
+}
+
+// NewShardedTaskManager creates a ShardedTaskManager with n shards. An n
+// below 1 is treated as 1.
+func NewShardedTaskManager(n int, opts ...Option) *ShardedTaskManager {
+	n = max(n, 1)
+	s := &ShardedTaskManager{shards: make([]*SafeTaskManager, n)}
+	for i := range s.shards {
+		s.shards[i] = NewSafeTaskManager(append(opts, WithIDGenerator(NewSequenceIDs(i+1, n)))...)
+	}
+	s.parallelism = s.shards[0].tm.listParallelism
+	if s.parallelism <= 0 {
+		s.parallelism = runtime.GOMAXPROCS(0)
+	}
+	return s
+}
+
+// Shard returns the shard that holds, or would hold, the task with the
+// given ID
+func (s *ShardedTaskManager) Shard(id int) *SafeTaskManager {
+	if id <= 0 {
+		// Any shard rejects the ID the same way
+		return s.shards[0]
+	}
+	return s.shards[(id-1)%len(s.shards)]
+}
+
+// AddTask adds a task to the next shard in turn
+func (s *ShardedTaskManager) AddTask(title, description string, opts ...TaskOption) (*Task, error) {
+	return s.nextShard().AddTask(title, description, opts...)
+}
+
+// AddTasksBatch adds a whole batch to the next shard in turn, so the batch
+// takes a single lock
+func (s *ShardedTaskManager) AddTasksBatch(inputs []TaskInput) ([]*Task, error) {
+	return s.nextShard().AddTasksBatch(inputs)
+}
+
+// AddSubtask adds a subtask on the shard of its parent
+func (s *ShardedTaskManager) AddSubtask(parentID int, title, description string, opts ...TaskOption) (*Task, error) {
+	return s.Shard(parentID).AddSubtask(parentID, title, description, opts...)
+}
+
+// GetTask is TaskManager.GetTask on the shard holding the task
+func (s *ShardedTaskManager) GetTask(id int) (*Task, error) {
+	return s.Shard(id).GetTask(id)
+}
+
+// UpdateTask is TaskManager.UpdateTask on the shard holding the task
+func (s *ShardedTaskManager) UpdateTask(id int, title, description string, done bool, opts ...TaskOption) error {
+	return s.Shard(id).UpdateTask(id, title, description, done, opts...)
+}
+
+// UpdateTaskFields is TaskManager.UpdateTaskFields on the shard holding the
+// task
+func (s *ShardedTaskManager) UpdateTaskFields(id int, patch TaskPatch) error {
+	return s.Shard(id).UpdateTaskFields(id, patch)
+}
+
+// Transition is TaskManager.Transition on the shard holding the task
+func (s *ShardedTaskManager) Transition(id int, status Status) error {
+	return s.Shard(id).Transition(id, status)
+}
+
+// CompleteTask is TaskManager.CompleteTask on the shard holding the task
+func (s *ShardedTaskManager) CompleteTask(id int, note string) error {
+	return s.Shard(id).CompleteTask(id, note)
+}
+
+// DeleteTask is TaskManager.DeleteTask on the shard holding the task
+func (s *ShardedTaskManager) DeleteTask(id int) error {
+	return s.Shard(id).DeleteTask(id)
+}
+
+// RestoreTask is TaskManager.RestoreTask on the shard holding the task
+func (s *ShardedTaskManager) RestoreTask(id int) error {
+	return s.Shard(id).RestoreTask(id)
+}
+
+// ListTasks returns what TaskManager.ListTasks would return for the tasks
+// of all shards together. Explain adds up the counts and timings of the
+// shards, with the time spent merging them counted as sorting, and names
+// the index the first shard used.
+func (s *ShardedTaskManager) ListTasks(filterDone *bool, opts ...ListOption) []*Task {
+	defer s.readLockAll()()
+	tasks, q := s.merged(filterDone, opts)
+	for i, task := range tasks {
+		tasks[i] = exportMerged(task, q)
+	}
+	return tasks
+}
+
+// FindTasks returns what TaskManager.FindTasks would return for the tasks
+// of all shards together. Stats are not reported.
+func (s *ShardedTaskManager) FindTasks(opts ListOptions) (TaskPage, error) {
+	if err := opts.Validate(); err != nil {
+		return TaskPage{}, err
+	}
+	defer s.readLockAll()()
+	tasks, q := s.merged(opts.Done, opts.listOptions())
+	page := pageOf(tasks, q, opts)
+	for i, task := range page.Tasks {
+		page.Tasks[i] = exportMerged(task, q)
+	}
+	return page, nil
+}
+
+// exportMerged returns the copy of a task from a merged listing for q
+func exportMerged(task *Task, q *listQuery) *Task {
+	if q.omitDetails {
+		return task.partialCopy()
+	}
+	return task.clone()
+}
+
+// CountTasks returns the number of tasks FindTasks would match for opts
+// across all shards
+func (s *ShardedTaskManager) CountTasks(opts ListOptions) (int, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	defer s.readLockAll()()
+	counts := make([]int, len(s.shards))
+	s.eachShard(func(i int, tm *TaskManager) {
+		// opts is valid, so no shard fails
+		counts[i], _ = tm.CountTasks(opts)
+	})
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+// TaskCounts returns the task counts of all shards added together
+func (s *ShardedTaskManager) TaskCounts() TaskCounts {
+	counts := TaskCounts{
+		ByStatus:   make(map[Status]int),
+		ByPriority: make(map[Priority]int),
+		ByTag:      make(map[string]int),
+	}
+	for _, shard := range s.shards {
+		counts.add(shard.TaskCounts())
+	}
+	return counts
+}
+
+// nextShard returns the shard to add to next, going round the shards
+func (s *ShardedTaskManager) nextShard() *SafeTaskManager {
+	return s.shards[(s.next.Add(1)-1)%uint64(len(s.shards))]
+}
+
+// readLockAll takes the read lock of every shard, always in the same
+// order, and returns the function that releases them
+func (s *ShardedTaskManager) readLockAll() func() {
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+	}
+	return func() {
+		for _, shard := range s.shards {
+			shard.mu.RUnlock()
+		}
+	}
+}
+
+// eachShard calls fn with the index and manager of every shard, on up to
+// s.parallelism shards at once when the shards hold enough tasks to make
+// that worthwhile. The caller must hold every shard's read lock.
+func (s *ShardedTaskManager) eachShard(fn func(i int, tm *TaskManager)) {
+	workers := min(s.parallelism, len(s.shards))
+	if workers > 1 {
+		size := 0
+		for _, shard := range s.shards {
+			size += shard.tm.tasks.Len()
+		}
+		if size < minParallelListing {
+			workers = 1
+		}
+	}
+	if workers <= 1 {
+		for i, shard := range s.shards {
+			fn(i, shard.tm)
+		}
+		return
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(s.shards); i = int(next.Add(1) - 1) {
+				fn(i, s.shards[i].tm)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// merged returns the stored tasks of all shards matching the options in
+// listing order, and the query giving that order. The queries of the
+// shards are built before any shard is filtered, each with stats of its
+// own when Explain is given, and their stats are added up into those of
+// the returned query. The caller must hold every shard's read lock.
+func (s *ShardedTaskManager) merged(filterDone *bool, opts []ListOption) ([]*Task, *listQuery) {
+	queries := make([]*listQuery, len(s.shards))
+	stats := make([]QueryStats, len(s.shards))
+	for i, shard := range s.shards {
+		queries[i] = shard.tm.newQuery(filterDone, opts)
+		if queries[i].stats != nil {
+			queries[i].stats = &stats[i]
+		}
+	}
+	lists := make([][]*Task, len(s.shards))
+	s.eachShard(func(i int, tm *TaskManager) {
+		lists[i] = tm.matching(queries[i])
+	})
+	q := &listQuery{done: filterDone}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if q.stats == nil {
+		return mergeSorted(lists, q.less), q
+	}
+	start := time.Now()
+	tasks := mergeSorted(lists, q.less)
+	q.stats.SortTime = time.Since(start)
+	for _, shard := range stats {
+		q.stats.add(shard)
+	}
+	return tasks, q
+}
+
+// mergeSorted merges lists that are each sorted by less into one sorted
+// list
+func mergeSorted(lists [][]*Task, less func(a, b *Task) bool) []*Task {
+	total := 0
+	for _, list := range lists {
+		total += len(list)
+	}
+	result := make([]*Task, 0, total)
+	for len(result) < total {
+		best := -1
+		for i, list := range lists {
+			if len(list) > 0 && (best < 0 || less(list[0], lists[best][0])) {
+				best = i
+			}
+		}
+		result = append(result, lists[best][0])
+		lists[best] = lists[best][1:]
+	}
+	return result
+}
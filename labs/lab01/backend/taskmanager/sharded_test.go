@@ -0,0 +1,239 @@
+package taskmanager
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedTaskManager(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	s := NewShardedTaskManager(3, WithClock(clock))
+	single := NewTaskManager(WithClock(clock))
+	priorities := []Priority{PriorityLow, PriorityHigh, PriorityMedium}
+	for i := range 10 {
+		title := fmt.Sprintf("Task %d", i+1)
+		task, err := s.AddTask(title, "", WithPriority(priorities[i%3]))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		mustAddTask(t, single, title, WithPriority(priorities[i%3]))
+		if task.ID != i+1 {
+			t.Errorf("Expected round-robin IDs to follow each other, got %d for task %d", task.ID, i+1)
+		}
+		if s.Shard(task.ID) != s.shards[i%3] {
+			t.Errorf("Expected task %d on shard %d", task.ID, i%3)
+		}
+		if i%4 == 0 {
+			now = now.Add(time.Minute)
+		}
+	}
+
+	for _, opts := range [][]ListOption{nil, {SortByPriority()}, {SortBy(SortSpec{{Field: SortTitle, Descending: true}})}} {
+		if got, expected := titles(s.ListTasks(nil, opts...)), titles(single.ListTasks(nil, opts...)); !slices.Equal(got, expected) {
+			t.Errorf("Expected the merged listing %v, got %v", expected, got)
+		}
+	}
+
+	var seen []string
+	opts := ListOptions{Sort: SortSpec{{Field: SortPriority, Descending: true}}, Page: Page{Limit: 4}}
+	for {
+		page, err := s.FindTasks(opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if page.Total != 10 {
+			t.Errorf("Expected a total of 10, got %d", page.Total)
+		}
+		seen = append(seen, titles(page.Tasks)...)
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if expected := titles(single.ListTasks(nil, SortBy(opts.Sort))); !slices.Equal(seen, expected) {
+		t.Errorf("Expected pages to cover %v, got %v", expected, seen)
+	}
+
+	child, err := s.AddSubtask(2, "Child", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Shard(child.ID) != s.Shard(2) {
+		t.Errorf("Expected subtask %d on the shard of its parent", child.ID)
+	}
+	if err := s.Transition(child.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	done := true
+	if got := titles(s.ListTasks(&done)); !slices.Equal(got, []string{"Child"}) {
+		t.Errorf("Expected only the finished subtask, got %v", got)
+	}
+	if count, _ := s.CountTasks(ListOptions{Done: &done}); count != 1 {
+		t.Errorf("Expected 1 finished task, got %d", count)
+	}
+	if _, err := s.GetTask(0); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+	if _, err := s.FindTasks(ListOptions{Page: Page{Limit: -1}}); err != ErrInvalidPage {
+		t.Errorf("Expected ErrInvalidPage, got %v", err)
+	}
+}
+
+func TestShardedTaskManagerConcurrentUse(t *testing.T) {
+	s := NewShardedTaskManager(4)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 25 {
+				task, err := s.AddTask(fmt.Sprintf("Task %d-%d", g, i), "")
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+					return
+				}
+				s.UpdateTask(task.ID, task.Title+" updated", "", i%2 == 0)
+				s.UpdateTaskFields(task.ID, TaskPatch{Priority: ptr(PriorityHigh)})
+				if i%5 == 0 {
+					s.DeleteTask(task.ID)
+				}
+				s.ListTasks(nil, SortByPriority())
+				s.FindTasks(ListOptions{Page: Page{Limit: 10}})
+			}
+		}()
+	}
+	wg.Wait()
+
+	tasks := s.ListTasks(nil)
+	if len(tasks) != 8*20 {
+		t.Fatalf("Expected %d tasks, got %d", 8*20, len(tasks))
+	}
+	ids := make(map[int]bool)
+	for _, task := range tasks {
+		if ids[task.ID] {
+			t.Errorf("Expected unique IDs, got %d twice", task.ID)
+		}
+		ids[task.ID] = true
+	}
+}
+
+func TestShardedTaskCounts(t *testing.T) {
+	s := NewShardedTaskManager(3)
+	for _, tag := range []string{"work", "home", "work", "work"} {
+		if _, err := s.AddTask("Task", "", WithTags(tag)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	counts := s.TaskCounts()
+	if counts.Total != 4 || counts.ByTag["work"] != 3 || counts.ByPriority[PriorityMedium] != 4 {
+		t.Errorf("Expected the shards' counts added together, got %+v", counts)
+	}
+}
+
+func TestShardedOmitDetails(t *testing.T) {
+	s := NewShardedTaskManager(2)
+	for range 3 {
+		if _, err := s.AddTask("Task", "Details"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, task := range s.ListTasks(nil, OmitDetails()) {
+		if !task.IsPartial() || task.Description != "" {
+			t.Fatalf("Expected partial tasks, got %+v", task)
+		}
+		if err := s.Shard(task.ID).Hydrate(task); err != nil || task.Description != "Details" {
+			t.Errorf("Expected the owning shard to hydrate the task, got %q (%v)", task.Description, err)
+		}
+	}
+}
+
+func TestShardedParallelListing(t *testing.T) {
+	serial := NewShardedTaskManager(4, WithListParallelism(1))
+	parallel := NewShardedTaskManager(4, WithListParallelism(4))
+	if parallel.parallelism != 4 || serial.parallelism != 1 {
+		t.Fatalf("Expected parallelism 4 and 1, got %d and %d", parallel.parallelism, serial.parallelism)
+	}
+	if s := NewShardedTaskManager(2); s.parallelism < 1 {
+		t.Errorf("Expected a default parallelism of at least 1, got %d", s.parallelism)
+	}
+
+	inputs := make([]TaskInput, 2*minParallelListing)
+	for i := range inputs {
+		inputs[i] = TaskInput{Title: fmt.Sprintf("Task %d", i), Options: []TaskOption{WithPriority(Priority(i%4 + 1))}}
+		if i%3 == 0 {
+			inputs[i].Options = append(inputs[i].Options, WithTags("work"))
+		}
+	}
+	for _, s := range []*ShardedTaskManager{serial, parallel} {
+		for start := 0; start < len(inputs); start += 256 {
+			if _, err := s.AddTasksBatch(inputs[start : start+256]); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts []ListOption
+	}{
+		{"default", nil},
+		{"indexed", []ListOption{FilterByAnyTag("work")}},
+		{"sorted", []ListOption{SortByPriority()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := titles(serial.ListTasks(nil, tt.opts...))
+			got := titles(parallel.ListTasks(nil, tt.opts...))
+			if !slices.Equal(got, want) {
+				t.Errorf("Expected parallel listings to match serial ones, got %d tasks, want %d", len(got), len(want))
+			}
+		})
+	}
+
+	// Each shard explains its part of the listing, and the parts add up
+	var stats QueryStats
+	work := parallel.ListTasks(nil, FilterByAnyTag("work"), Explain(&stats))
+	if stats.Index != indexTag || stats.Scanned != len(work) || stats.Matched != len(work) || stats.FilterTime <= 0 {
+		t.Errorf("Expected the tag index to find %d tasks, got %+v", len(work), stats)
+	}
+	parallel.ListTasks(nil, FilterByPriority(PriorityHigh), Explain(&stats))
+	if stats.Index != "" || stats.Scanned != len(inputs) || stats.Matched != len(inputs)/4 {
+		t.Errorf("Expected %d tasks scanned, got %+v", len(inputs), stats)
+	}
+
+	count, err := parallel.CountTasks(ListOptions{Tags: []string{"work"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (len(inputs) + 2) / 3; count != want {
+		t.Errorf("Expected %d work tasks, got %d", want, count)
+	}
+	if _, err := parallel.CountTasks(ListOptions{Page: Page{Limit: -1}}); err != ErrInvalidPage {
+		t.Errorf("Expected ErrInvalidPage, got %v", err)
+	}
+}
+
+func BenchmarkShardedListTasks(b *testing.B) {
+	for _, parallelism := range []int{1, 4} {
+		s := NewShardedTaskManager(4, WithListParallelism(parallelism))
+		inputs := make([]TaskInput, 100_000)
+		for i := range inputs {
+			inputs[i] = TaskInput{Title: fmt.Sprintf("Task %d", i), Options: []TaskOption{WithPriority(Priority(i%4 + 1))}}
+		}
+		for start := 0; start < len(inputs); start += 1000 {
+			if _, err := s.AddTasksBatch(inputs[start : start+1000]); err != nil {
+				b.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for b.Loop() {
+				s.CountTasks(ListOptions{Query: "task 9"})
+				s.ListTasks(nil, FilterByPriority(PriorityHigh))
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package taskmanager
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+)
+
+// DefaultSimilarityThreshold is the lowest score FindSimilar reports when
+// SimilarOptions.Threshold is zero
+const DefaultSimilarityThreshold = 0.5
+
+// ErrInvalidSimilarOptions is returned when similarity options are out of range
+var ErrInvalidSimilarOptions = errors.New("invalid similarity options")
+
+// SimilarityMeasure decides how the words of two titles are scored
+type SimilarityMeasure int
+
+const (
+	// SimilarityJaccard scores the shared words against all words of both
+	// titles, so extra words on either side lower the score
+	SimilarityJaccard SimilarityMeasure = iota
+	// SimilarityOverlap scores the shared words against the shorter title,
+	// so "Pay rent" fully matches "Pay rent for July"
+	SimilarityOverlap
+)
+
+// SimilarOptions tunes FindSimilar
+type SimilarOptions struct {
+	// Threshold is the lowest score reported, from 0 to 1; zero means
+	// DefaultSimilarityThreshold
+	Threshold float64
+	// Limit is the largest number of tasks returned; zero means no limit
+	Limit int
+	// Measure is how titles are scored
+	Measure SimilarityMeasure
+	// IncludeClosed also compares against done and cancelled tasks
+	IncludeClosed bool
+}
+
+// SimilarTask is a task found by FindSimilar and how similar its title is,
+// from 0 to 1
+type SimilarTask struct {
+	Task  *Task
+	Score float64
+}
+
+// FindSimilar returns open tasks whose titles share words with title, most
+// similar first, so a client can warn before a duplicate is added. Words
+// are compared ignoring case and punctuation; equally similar tasks come in
+// the order ListTasks gives them.
+func (tm *TaskManager) FindSimilar(title string, opts SimilarOptions) ([]SimilarTask, error) {
+	if opts.Threshold < 0 || opts.Threshold > 1 || opts.Limit < 0 ||
+		opts.Measure < SimilarityJaccard || opts.Measure > SimilarityOverlap {
+		return nil, ErrInvalidSimilarOptions
+	}
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+	words := wordSet(title)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	scores := make(map[int]float64)
+	var listOpts []ListOption
+	if !opts.IncludeClosed {
+		listOpts = append(listOpts, where(func(task *Task) bool { return !task.Status.Closed() }))
+	}
+	tasks := tm.ListTasks(nil, append(listOpts, where(func(task *Task) bool {
+		score := opts.Measure.score(words, wordSet(task.Title))
+		scores[task.ID] = score
+		return score >= threshold
+	}))...)
+
+	result := make([]SimilarTask, len(tasks))
+	for i, task := range tasks {
+		result[i] = SimilarTask{Task: task, Score: scores[task.ID]}
+	}
+	slices.SortStableFunc(result, func(a, b SimilarTask) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if opts.Limit > 0 && len(result) > opts.Limit {
+		result = result[:opts.Limit]
+	}
+	return result, nil
+}
+
+// score compares two sets of words
+func (m SimilarityMeasure) score(a, b map[string]bool) float64 {
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	if shared == 0 {
+		return 0
+	}
+	if m == SimilarityOverlap {
+		return float64(shared) / float64(min(len(a), len(b)))
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// wordSet returns the distinct words of a title
+func wordSet(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range tokenize(title) {
+		words[word] = true
+	}
+	return words
+}
@@ -0,0 +1,75 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFindSimilar(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for _, title := range []string{"Pay rent", "Pay the rent for July", "Rent a car", "Call mom", "pay RENT!"} {
+		mustAddTask(t, tm, title)
+		now = now.Add(time.Minute)
+	}
+	paid := mustAddTask(t, tm, "Pay rent")
+	if err := tm.Transition(paid.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     SimilarOptions
+		expected []string
+		scores   []float64
+	}{
+		{name: "default", expected: []string{"Pay rent", "pay RENT!"}, scores: []float64{1, 1}},
+		{
+			name:     "threshold",
+			opts:     SimilarOptions{Threshold: 0.4},
+			expected: []string{"Pay rent", "pay RENT!", "Pay the rent for July"},
+			scores:   []float64{1, 1, 0.4},
+		},
+		{name: "limit", opts: SimilarOptions{Limit: 1}, expected: []string{"Pay rent"}, scores: []float64{1}},
+		{
+			name:     "overlap",
+			opts:     SimilarOptions{Measure: SimilarityOverlap, Threshold: 1},
+			expected: []string{"Pay rent", "Pay the rent for July", "pay RENT!"},
+			scores:   []float64{1, 1, 1},
+		},
+		{
+			name:     "closed tasks",
+			opts:     SimilarOptions{Threshold: 1, IncludeClosed: true},
+			expected: []string{"Pay rent", "pay RENT!", "Pay rent"},
+			scores:   []float64{1, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			similar, err := tm.FindSimilar("Pay rent", tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got []string
+			var scores []float64
+			for _, s := range similar {
+				got = append(got, s.Task.Title)
+				scores = append(scores, s.Score)
+			}
+			if !slices.Equal(got, tt.expected) || !slices.Equal(scores, tt.scores) {
+				t.Errorf("Expected %v with scores %v, got %v with %v", tt.expected, tt.scores, got, scores)
+			}
+		})
+	}
+
+	if similar, _ := tm.FindSimilar("?!", SimilarOptions{}); similar != nil {
+		t.Errorf("Expected nothing for a title without words, got %v", similar)
+	}
+	for _, opts := range []SimilarOptions{{Threshold: 1.5}, {Threshold: -1}, {Limit: -1}, {Measure: 42}} {
+		if _, err := tm.FindSimilar("Pay rent", opts); err != ErrInvalidSimilarOptions {
+			t.Errorf("Expected ErrInvalidSimilarOptions for %+v, got %v", opts, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package taskmanager
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Snapshot is a view of the active tasks of a manager at one point in
+// time. It never changes, whatever happens to the manager afterwards, so
+// exports and reports that read it over a while see a consistent state
+// while writers carry on. A Snapshot is safe for concurrent use, and every
+// task it returns is a copy the caller may change.
+type Snapshot struct {
+	tm      *TaskManager
+	takenAt time.Time
+}
+
+// Snapshot copies the active tasks into a Snapshot. It takes time and
+// memory in proportion to the number of tasks.
+func (tm *TaskManager) Snapshot() *Snapshot {
+	return &Snapshot{tm: tm.freeze(), takenAt: tm.now()}
+}
+
+// TakenAt returns the time the snapshot was taken, by the manager's clock
+func (s *Snapshot) TakenAt() time.Time {
+	return s.takenAt
+}
+
+// Len returns the number of tasks in the snapshot
+func (s *Snapshot) Len() int {
+	return s.tm.tasks.Len()
+}
+
+// GetTask is TaskManager.GetTask on the snapshot
+func (s *Snapshot) GetTask(id int) (*Task, error) {
+	return s.tm.GetTask(id)
+}
+
+// ListTasks is TaskManager.ListTasks on the snapshot
+func (s *Snapshot) ListTasks(filterDone *bool, opts ...ListOption) []*Task {
+	return s.tm.ListTasks(filterDone, opts...)
+}
+
+// ListTasksInto is TaskManager.ListTasksInto on the snapshot
+func (s *Snapshot) ListTasksInto(dst []*Task, filterDone *bool, opts ...ListOption) []*Task {
+	return s.tm.ListTasksInto(dst, filterDone, opts...)
+}
+
+// Tasks returns the listing ListTasks would return as a sequence
+func (s *Snapshot) Tasks(filterDone *bool, opts ...ListOption) iter.Seq[*Task] {
+	return func(yield func(*Task) bool) {
+		for task := range s.tm.Tasks(filterDone, opts...) {
+			if !yield(task.clone()) {
+				return
+			}
+		}
+	}
+}
+
+// FindTasks is TaskManager.FindTasks on the snapshot
+func (s *Snapshot) FindTasks(opts ListOptions) (TaskPage, error) {
+	return s.tm.FindTasks(opts)
+}
+
+// CountTasks is TaskManager.CountTasks on the snapshot
+func (s *Snapshot) CountTasks(opts ListOptions) (int, error) {
+	return s.tm.CountTasks(opts)
+}
+
+// Search is TaskManager.Search on the snapshot
+func (s *Snapshot) Search(query string, opts ...ListOption) []*Task {
+	return s.tm.Search(query, opts...)
+}
+
+// GroupTasks is TaskManager.GroupTasks on the snapshot
+func (s *Snapshot) GroupTasks(by GroupField, opts GroupOptions) ([]Group, error) {
+	return s.tm.GroupTasks(by, opts)
+}
+
+// WithSnapshotReads makes a SafeTaskManager serve GetTask, ListTasks and
+// FindTasks from an immutable snapshot of the tasks without taking any
+// lock, so those reads never wait for writers. Every write copies all
+// tasks into a new snapshot, so this suits workloads with many more reads
+// than writes. Reads may briefly miss a write that is still in progress.
+// A TaskManager used on its own ignores the option.
+func WithSnapshotReads() Option {
+	return func(tm *TaskManager) {
+		tm.snapshotReads = true
+	}
+}
+
+// publish replaces the snapshot readers use with the current state of the
+// manager. The caller must hold the write lock, or be the constructor.
+func (s *SafeTaskManager) publish() {
+	if s.tm.snapshotReads {
+		s.snapshot.Store(s.tm.Snapshot())
+	}
+}
+
+// freeze returns a manager holding copies of the active tasks and the
+// index over them that is never changed afterwards, so any number of
+// goroutines can run the Snapshot methods on it at once. It shares nothing
+// the original manager changes.
+func (tm *TaskManager) freeze() *TaskManager {
+	tasks := NewMemoryStore()
+	tasks.grow(tm.tasks.Len())
+	for task := range tm.tasks.List() {
+		tasks.Put(task.clone())
+	}
+	return &TaskManager{
+		tasks:     tasks,
+		trash:     make(map[int]*Task),
+		now:       tm.now,
+		timezone:  tm.timezone,
+		retention: tm.retention,
+		index:     tm.index.clone(),
+		copies:    true,
+	}
+}
+
+// clone returns a copy of the index that shares nothing put and remove
+// change
+func (x *taskIndex) clone() *taskIndex {
+	return &taskIndex{
+		entries:    maps.Clone(x.entries),
+		byStatus:   x.byStatus.clone(),
+		byAssignee: x.byAssignee.clone(),
+		byTag:      x.byTag.clone(),
+		priorities: maps.Clone(x.priorities),
+		titles:     slices.Clone(x.titles),
+		created:    slices.Clone(x.created),
+	}
+}
+
+// clone returns a copy of the postings with their own ID sets
+func (p postings[K]) clone() postings[K] {
+	c := make(postings[K], len(p))
+	for key, ids := range p {
+		c[key] = maps.Clone(ids)
+	}
+	return c
+}
@@ -0,0 +1,155 @@
+package taskmanager
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotReads(t *testing.T) {
+	s := NewSafeTaskManager(WithSnapshotReads())
+	task, err := s.AddTask("Write report", "", WithTags("work"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.UpdateTask(task.ID, "Write the report", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, _ := s.GetTask(task.ID); got.Title != "Write the report" {
+		t.Errorf("Expected reads to see a finished write, got %q", got.Title)
+	}
+
+	// Readers must not need the lock: with a writer holding it, a locking
+	// read would never return
+	s.mu.Lock()
+	got, err := s.GetTask(task.ID)
+	listed := s.ListTasks(nil, FilterByAllTags("work"))
+	page, pageErr := s.FindTasks(ListOptions{Tags: []string{"work"}})
+	s.mu.Unlock()
+	if err != nil || pageErr != nil {
+		t.Fatalf("Unexpected errors: %v, %v", err, pageErr)
+	}
+	if len(listed) != 1 || page.Total != 1 {
+		t.Errorf("Expected the indexed listings to find the task, got %d and %d", len(listed), page.Total)
+	}
+
+	got.Title = "Changed"
+	if again, _ := s.GetTask(task.ID); again.Title != "Write the report" {
+		t.Errorf("Expected callers not to change the snapshot, got %q", again.Title)
+	}
+	if err := s.DeleteTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := s.GetTask(task.ID); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound after the delete, got %v", err)
+	}
+}
+
+func TestSnapshotReadsConcurrentUse(t *testing.T) {
+	s := NewSafeTaskManager(WithSnapshotReads())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 50 {
+			task, _ := s.AddTask("Task", "", WithTags("work"))
+			s.UpdateTaskFields(task.ID, TaskPatch{Tags: ptr([]string{"home"})})
+			if i%3 == 0 {
+				s.DeleteTask(task.ID)
+			}
+		}
+	}()
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				for _, task := range s.ListTasks(nil, FilterByAnyTag("work", "home")) {
+					s.GetTask(task.ID)
+				}
+				s.FindTasks(ListOptions{Sort: SortSpec{{Field: SortTitle}}})
+			}
+		}()
+	}
+	wg.Wait()
+
+	var ids []int
+	for _, task := range s.ListTasks(nil, FilterByAllTags("home")) {
+		ids = append(ids, task.ID)
+	}
+	if len(ids) != 33 || !slices.IsSorted(ids) {
+		t.Errorf("Expected the 33 remaining tasks in order, got %v", ids)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	mustAddTask(t, tm, "Write report", WithTags("work"))
+	mustAddTask(t, tm, "Buy milk", WithTags("home"))
+	snapshot := tm.Snapshot()
+
+	now = now.Add(time.Hour)
+	if err := tm.UpdateTask(1, "Write the report", "", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteTask(2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mustAddTask(t, tm, "Call mom")
+
+	if snapshot.Len() != 2 || !snapshot.TakenAt().Equal(now.Add(-time.Hour)) {
+		t.Errorf("Expected 2 tasks taken an hour ago, got %d at %v", snapshot.Len(), snapshot.TakenAt())
+	}
+	if got := titles(snapshot.ListTasks(nil)); !slices.Equal(got, []string{"Write report", "Buy milk"}) {
+		t.Errorf("Expected the tasks as they were, got %v", got)
+	}
+	if task, err := snapshot.GetTask(1); err != nil || task.Status != StatusTodo {
+		t.Errorf("Expected task 1 still open, got %v, %v", task, err)
+	}
+	if count, _ := snapshot.CountTasks(ListOptions{Tags: []string{"home"}}); count != 1 {
+		t.Errorf("Expected the tag index of the snapshot to find 1 task, got %d", count)
+	}
+	if got := titles(snapshot.Search("milk")); !slices.Equal(got, []string{"Buy milk"}) {
+		t.Errorf("Expected search on the snapshot to find Buy milk, got %v", got)
+	}
+	groups, err := snapshot.GroupTasks(GroupByTag, GroupOptions{})
+	if err != nil || len(groups) != 2 {
+		t.Errorf("Expected 2 tag groups, got %v, %v", groups, err)
+	}
+	for task := range snapshot.Tasks(nil) {
+		task.Title = "Changed"
+	}
+	if page, _ := snapshot.FindTasks(ListOptions{}); titles(page.Tasks)[0] != "Write report" {
+		t.Errorf("Expected callers not to change the snapshot, got %v", titles(page.Tasks))
+	}
+}
+
+func TestSafeSnapshotConcurrentUse(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSnapshotReads()}} {
+		s := NewSafeTaskManager(opts...)
+		for range 20 {
+			s.AddTask("Task", "")
+		}
+		snapshot := s.Snapshot()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 20 {
+				s.UpdateTask(i+1, "Renamed", "", true)
+				s.AddTask("New", "")
+			}
+		}()
+		for task := range snapshot.Tasks(nil) {
+			if task.Title != "Task" || task.Status != StatusTodo {
+				t.Errorf("Expected the snapshot to stay as taken, got %q (%v)", task.Title, task.Status)
+			}
+		}
+		wg.Wait()
+		if snapshot.Len() != 20 || s.Snapshot().Len() != 40 {
+			t.Errorf("Expected 20 tasks in the old snapshot and 40 in a new one, got %d and %d", snapshot.Len(), s.Snapshot().Len())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package taskmanager
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidSnooze is returned when a task is snoozed until a time that has
+// already passed
+var ErrInvalidSnooze = errors.New("snooze time must be in the future")
+
+// SnoozeTask hides a task from listings until the given time. Pass
+// IncludeSnoozed to list it anyway.
+func (tm *TaskManager) SnoozeTask(id int, until time.Time) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if !until.After(tm.now()) {
+		return ErrInvalidSnooze
+	}
+	tm.update(task, func() {
+		task.SnoozedUntil = &until
+	})
+	return nil
+}
+
+// UnsnoozeTask makes a snoozed task show up in listings again right away
+func (tm *TaskManager) UnsnoozeTask(id int) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	tm.update(task, func() {
+		task.SnoozedUntil = nil
+	})
+	return nil
+}
+
+// IsSnoozed reports whether the task is hidden by a snooze at the given time
+func (t *Task) IsSnoozed(now time.Time) bool {
+	return t.SnoozedUntil != nil && now.Before(*t.SnoozedUntil)
+}
+
+// ListSnoozed returns the tasks that are snoozed right now, the ones waking
+// up first at the front
+func (tm *TaskManager) ListSnoozed(opts ...ListOption) []*Task {
+	now := tm.now()
+	return tm.ListTasks(nil, append(opts, IncludeSnoozed(), sortBySnooze(), where(func(task *Task) bool {
+		return task.IsSnoozed(now)
+	}))...)
+}
+
+// IncludeSnoozed makes ListTasks return snoozed tasks alongside the others
+func IncludeSnoozed() ListOption {
+	return func(q *listQuery) {
+		q.includeSnoozed = true
+	}
+}
+
+// sortBySnooze orders tasks by the time their snooze ends. Every listed task
+// must be snoozed.
+func sortBySnooze() ListOption {
+	return func(q *listQuery) {
+		q.sortBySnooze = true
+	}
+}
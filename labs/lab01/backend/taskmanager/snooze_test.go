@@ -0,0 +1,64 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSnoozeTask(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	a := mustAddTask(t, tm, "A")
+	now = now.Add(time.Minute)
+	b := mustAddTask(t, tm, "B")
+	now = now.Add(time.Minute)
+	mustAddTask(t, tm, "C")
+
+	tests := []struct {
+		name        string
+		id          int
+		until       time.Time
+		expectError error
+	}{
+		{name: "valid snooze", id: a.ID, until: now.Add(2 * time.Hour)},
+		{name: "shorter snooze", id: b.ID, until: now.Add(time.Hour)},
+		{name: "time in the past", id: b.ID, until: now.Add(-time.Hour), expectError: ErrInvalidSnooze},
+		{name: "missing task", id: 999, until: now.Add(time.Hour), expectError: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tm.SnoozeTask(tt.id, tt.until); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if got := titles(tm.ListTasks(nil)); !slices.Equal(got, []string{"C"}) {
+		t.Errorf("Expected snoozed tasks to be hidden, got %v", got)
+	}
+	if got := titles(tm.ListSnoozed()); !slices.Equal(got, []string{"B", "A"}) {
+		t.Errorf("Expected snoozed tasks waking first at the front, got %v", got)
+	}
+	if got := tm.ListTasks(nil, IncludeSnoozed()); len(got) != 3 {
+		t.Errorf("Expected IncludeSnoozed to list all 3 tasks, got %v", titles(got))
+	}
+
+	now = now.Add(90 * time.Minute)
+	if got := titles(tm.ListTasks(nil)); !slices.Equal(got, []string{"B", "C"}) {
+		t.Errorf("Expected B to wake up, got %v", got)
+	}
+
+	if err := tm.UnsnoozeTask(a.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tm.ListTasks(nil)) != 3 {
+		t.Errorf("Expected unsnoozed task to be listed again")
+	}
+
+	history, _ := tm.GetTaskHistory(a.ID)
+	if last := history[len(history)-1]; last.Field != "snoozed_until" || last.NewValue != "" {
+		t.Errorf("Expected the snooze to be recorded in history, got %+v", last)
+	}
+}
@@ -0,0 +1,148 @@
+package taskmanager
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSortField is returned when a sort key names an unknown field
+var ErrInvalidSortField = errors.New("invalid sort field")
+
+// SortField is a task field listings can be ordered by
+type SortField int
+
+const (
+	// SortCreatedAt orders by creation time
+	SortCreatedAt SortField = iota + 1
+	// SortDueDate orders by due date; tasks without one come last
+	SortDueDate
+	// SortPriority orders by priority, lowest first
+	SortPriority
+	// SortTitle orders by title, ignoring case
+	SortTitle
+	// SortUpdatedAt orders by the time of the last change
+	SortUpdatedAt
+)
+
+// sortFieldNames are the names used by String and ParseSortSpec
+var sortFieldNames = map[SortField]string{
+	SortCreatedAt: "created_at",
+	SortDueDate:   "due_date",
+	SortPriority:  "priority",
+	SortTitle:     "title",
+	SortUpdatedAt: "updated_at",
+}
+
+// String returns the name of the sort field
+func (f SortField) String() string {
+	if name, ok := sortFieldNames[f]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// SortKey is one level of a sort order
+type SortKey struct {
+	Field      SortField
+	Descending bool
+}
+
+// SortSpec orders tasks by each key in turn, falling back to creation time
+// and then ID when every key ties
+type SortSpec []SortKey
+
+// SortBy orders ListTasks results by the given spec. Pinned tasks still come
+// first when PinnedFirst is also given. Keys with an unknown field are
+// ignored; call Validate to reject them instead.
+func SortBy(spec SortSpec) ListOption {
+	return func(q *listQuery) {
+		q.sort = spec
+	}
+}
+
+// ParseSortSpec parses a comma-separated list of field names, each
+// optionally prefixed with "-" for descending order, such as
+// "-priority,due_date"
+func ParseSortSpec(s string) (SortSpec, error) {
+	var spec SortSpec
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			key.Descending = true
+			part = name
+		}
+		for field, name := range sortFieldNames {
+			if name == part {
+				key.Field = field
+			}
+		}
+		if key.Field == 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSortField, part)
+		}
+		spec = append(spec, key)
+	}
+	return spec, nil
+}
+
+// Validate checks that every key names a known field
+func (s SortSpec) Validate() error {
+	for _, key := range s {
+		if _, ok := sortFieldNames[key.Field]; !ok {
+			return ErrInvalidSortField
+		}
+	}
+	return nil
+}
+
+// String formats the spec in the form accepted by ParseSortSpec
+func (s SortSpec) String() string {
+	parts := make([]string, len(s))
+	for i, key := range s {
+		parts[i] = key.Field.String()
+		if key.Descending {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// compare orders two tasks by the spec, returning zero when every key ties
+func (s SortSpec) compare(a, b *Task) int {
+	for _, key := range s {
+		c := 0
+		switch key.Field {
+		case SortCreatedAt:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		case SortDueDate:
+			// Tasks without a due date stay last in either direction.
+			switch {
+			case a.DueDate == nil && b.DueDate == nil:
+			case a.DueDate == nil:
+				return 1
+			case b.DueDate == nil:
+				return -1
+			default:
+				c = a.DueDate.Compare(*b.DueDate)
+			}
+		case SortPriority:
+			c = cmp.Compare(a.Priority, b.Priority)
+		case SortTitle:
+			c = cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case SortUpdatedAt:
+			c = a.UpdatedAt.Compare(b.UpdatedAt)
+		}
+		if key.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSortBy(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	mustAddTask(t, tm, "banana", WithPriority(PriorityHigh), WithDueDate(now.Add(48*time.Hour)))
+	now = now.Add(time.Minute)
+	cherry := mustAddTask(t, tm, "Cherry", WithPriority(PriorityLow))
+	now = now.Add(time.Minute)
+	mustAddTask(t, tm, "apple", WithPriority(PriorityHigh), WithDueDate(now.Add(24*time.Hour)))
+	now = now.Add(time.Minute)
+	if err := tm.AssignTask(cherry.ID, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		spec     SortSpec
+		expected []string
+	}{
+		{name: "no spec", expected: []string{"banana", "Cherry", "apple"}},
+		{name: "title ignores case", spec: SortSpec{{Field: SortTitle}}, expected: []string{"apple", "banana", "Cherry"}},
+		{name: "created descending", spec: SortSpec{{Field: SortCreatedAt, Descending: true}}, expected: []string{"apple", "Cherry", "banana"}},
+		{name: "due date missing last", spec: SortSpec{{Field: SortDueDate}}, expected: []string{"apple", "banana", "Cherry"}},
+		{name: "due date descending missing last", spec: SortSpec{{Field: SortDueDate, Descending: true}}, expected: []string{"banana", "apple", "Cherry"}},
+		{name: "priority then title", spec: SortSpec{{Field: SortPriority, Descending: true}, {Field: SortTitle}}, expected: []string{"apple", "banana", "Cherry"}},
+		{name: "updated descending", spec: SortSpec{{Field: SortUpdatedAt, Descending: true}}, expected: []string{"Cherry", "apple", "banana"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tm.ListTasks(nil, SortBy(tt.spec))); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSortTiesByID(t *testing.T) {
+	// Every task is created at the same moment, so only the ID separates
+	// tasks that tie on the sort keys
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	due := now.Add(24 * time.Hour)
+	for i := range 12 {
+		var opts []TaskOption
+		if i%3 == 0 {
+			opts = append(opts, WithDueDate(due))
+		}
+		if i%2 == 0 {
+			opts = append(opts, WithPriority(PriorityHigh))
+		}
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i+1), opts...)
+	}
+
+	spec := SortSpec{{Field: SortPriority, Descending: true}, {Field: SortDueDate}, {Field: SortCreatedAt}}
+	expected := []string{
+		"Task 1", "Task 7", "Task 3", "Task 5", "Task 9", "Task 11",
+		"Task 4", "Task 10", "Task 2", "Task 6", "Task 8", "Task 12",
+	}
+	for range 5 {
+		if got := titles(tm.ListTasks(nil, SortBy(spec))); !slices.Equal(got, expected) {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+	page, err := tm.FindTasks(ListOptions{Sort: spec})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := titles(page.Tasks); !slices.Equal(got, expected) {
+		t.Errorf("Expected FindTasks to agree with ListTasks, got %v", got)
+	}
+}
+
+func TestParseSortSpec(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    SortSpec
+		expectError error
+	}{
+		{input: "", expected: nil},
+		{input: "-priority, due_date", expected: SortSpec{{SortPriority, true}, {SortDueDate, false}}},
+		{input: "title,-updated_at,created_at", expected: SortSpec{{SortTitle, false}, {SortUpdatedAt, true}, {SortCreatedAt, false}}},
+		{input: "colour", expectError: ErrInvalidSortField},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			spec, err := ParseSortSpec(tt.input)
+			if !errors.Is(err, tt.expectError) {
+				t.Fatalf("Expected %v, got %v", tt.expectError, err)
+			}
+			if !slices.Equal(spec, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, spec)
+			}
+			if err == nil && spec.String() != strings.ReplaceAll(tt.input, " ", "") {
+				t.Errorf("Expected String to round-trip %q, got %q", tt.input, spec.String())
+			}
+		})
+	}
+
+	if err := (SortSpec{{Field: SortField(42)}}).Validate(); err != ErrInvalidSortField {
+		t.Errorf("Expected ErrInvalidSortField, got %v", err)
+	}
+}
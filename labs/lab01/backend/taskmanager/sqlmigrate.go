@@ -0,0 +1,146 @@
+package taskmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnsupportedSQLSchema is returned for a database migrated by a newer
+// version of the package, and for a version to migrate to that the package
+// does not know
+var ErrUnsupportedSQLSchema = errors.New("unsupported SQL schema")
+
+// sqlMigration is one step of the schema SQLStore keeps tasks in: up moves
+// a database from the previous version to this one and down moves it back
+type sqlMigration struct {
+	name     string
+	up, down []string
+}
+
+// sqlMigrations are the steps of the schema, in order; the version of a
+// database is the number of steps it has run. Changing the schema appends a
+// migration and never edits one already released. The first uses IF NOT
+// EXISTS so databases created before versions were recorded adopt it.
+// Each task is saved whole as JSON, next to the columns queries filter and
+// sort on; times are Unix nanoseconds.
+var sqlMigrations = []sqlMigration{
+	{
+		name: "create tasks",
+		up: []string{
+			`CREATE TABLE IF NOT EXISTS tasks (
+	id BIGINT PRIMARY KEY,
+	status TEXT NOT NULL,
+	priority INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	due_at BIGINT,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	data TEXT NOT NULL
+)`,
+			`CREATE INDEX IF NOT EXISTS tasks_status ON tasks (status)`,
+			`CREATE INDEX IF NOT EXISTS tasks_priority ON tasks (priority)`,
+			`CREATE INDEX IF NOT EXISTS tasks_title ON tasks (lower(title))`,
+			`CREATE INDEX IF NOT EXISTS tasks_due_at ON tasks (due_at)`,
+			`CREATE INDEX IF NOT EXISTS tasks_created_at ON tasks (created_at, id)`,
+			`CREATE INDEX IF NOT EXISTS tasks_updated_at ON tasks (updated_at)`,
+			`CREATE TABLE IF NOT EXISTS task_tags (
+	task_id BIGINT NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (task_id, tag)
+)`,
+			`CREATE INDEX IF NOT EXISTS task_tags_tag ON task_tags (tag)`,
+			`CREATE TABLE IF NOT EXISTS task_store_meta (
+	name TEXT PRIMARY KEY,
+	value BIGINT NOT NULL
+)`,
+		},
+		down: []string{
+			`DROP TABLE IF EXISTS task_store_meta`,
+			`DROP TABLE IF EXISTS task_tags`,
+			`DROP TABLE IF EXISTS tasks`,
+		},
+	},
+}
+
+// Statements recording the migrations a database has run
+const (
+	sqlCreateSchemaVersion = `CREATE TABLE IF NOT EXISTS schema_version (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at BIGINT NOT NULL
+)`
+	sqlSchemaVersion = `SELECT MAX(version) FROM schema_version`
+	sqlInsertVersion = `INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`
+	sqlDeleteVersion = `DELETE FROM schema_version WHERE version = ?`
+)
+
+// MigrateSQL brings the schema of db to the version this package uses,
+// running the migrations it is missing. OpenSQLStore calls it, so it is
+// only needed to migrate a database ahead of time.
+func MigrateSQL(ctx context.Context, db *sql.DB, dialect SQLDialect) error {
+	return migrateSQL(ctx, db, dialect, sqlMigrations, len(sqlMigrations))
+}
+
+// MigrateSQLTo moves the schema of db up or down to the given version,
+// where 0 drops every table but schema_version. Stores must not be open on
+// the database meanwhile.
+func MigrateSQLTo(ctx context.Context, db *sql.DB, dialect SQLDialect, version int) error {
+	if version < 0 || version > len(sqlMigrations) {
+		return fmt.Errorf("%w: version %d", ErrUnsupportedSQLSchema, version)
+	}
+	return migrateSQL(ctx, db, dialect, sqlMigrations, version)
+}
+
+// migrateSQL runs the migrations between the version of db and target, up
+// or down. Each runs in its own transaction with the row recording it, so
+// a failed migration leaves the database at the version before it, and two
+// processes migrating at once cannot both record the same version.
+func migrateSQL(ctx context.Context, db *sql.DB, dialect SQLDialect, migrations []sqlMigration, target int) error {
+	if _, err := db.ExecContext(ctx, sqlCreateSchemaVersion); err != nil {
+		return fmt.Errorf("creating schema_version: %w", err)
+	}
+	var current sql.NullInt64
+	if err := db.QueryRowContext(ctx, sqlSchemaVersion).Scan(&current); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	version := int(current.Int64)
+	if version > len(migrations) {
+		return fmt.Errorf("%w: version %d", ErrUnsupportedSQLSchema, version)
+	}
+	for ; version < target; version++ {
+		m := migrations[version]
+		err := runSQLMigration(ctx, db, m.up, dialect.rebind(sqlInsertVersion), version+1, m.name, time.Now().UnixNano())
+		if err != nil {
+			return fmt.Errorf("migrating to version %d (%s): %w", version+1, m.name, err)
+		}
+	}
+	for ; version > target; version-- {
+		m := migrations[version-1]
+		if err := runSQLMigration(ctx, db, m.down, dialect.rebind(sqlDeleteVersion), version); err != nil {
+			return fmt.Errorf("reverting version %d (%s): %w", version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// runSQLMigration runs the statements of a migration and the statement
+// recording it in one transaction
+func runSQLMigration(ctx context.Context, db *sql.DB, statements []string, record string, args ...any) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,145 @@
+package taskmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMigrateSQL(t *testing.T) {
+	ctx := context.Background()
+	db, fake := openFakeSQL(t)
+	if err := MigrateSQL(ctx, db, SQLite); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := map[int64]string{1: "create tasks"}; !maps.Equal(fake.versions, want) {
+		t.Errorf("Expected %v, got %v", want, fake.versions)
+	}
+
+	// Opening a store on a migrated database runs nothing again
+	_, s := openSQLManager(t, db)
+	s.AddTask("Write report", "")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := fake.execs["INSERT INTO schema_version"]; n != 1 {
+		t.Errorf("Expected 1 recorded migration, got %d", n)
+	}
+	if len(fake.tasks) != 1 {
+		t.Errorf("Expected the saved task kept, got %v", fake.tasks)
+	}
+
+	// Migrating down to 0 drops the tables, and opening a store again
+	// creates them
+	if err := MigrateSQLTo(ctx, db, SQLite, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fake.versions) != 0 || len(fake.tasks) != 0 {
+		t.Errorf("Expected the schema reverted, got %v and %v", fake.versions, fake.tasks)
+	}
+	_, reopened := openSQLManager(t, db)
+	if got := reopened.ListTasks(nil); len(got) != 0 {
+		t.Errorf("Expected no tasks, got %v", got)
+	}
+	if len(fake.versions) != 1 {
+		t.Errorf("Expected the store to migrate the database, got %v", fake.versions)
+	}
+}
+
+func TestMigrateSQLSteps(t *testing.T) {
+	ctx := context.Background()
+	migrations := []sqlMigration{
+		{name: "one", up: []string{"CREATE TABLE one (id BIGINT)"}, down: []string{"DROP TABLE IF EXISTS task_store_meta"}},
+		{name: "two", up: []string{"CREATE INDEX two ON one (id)"}, down: []string{"DROP TABLE IF EXISTS task_tags"}},
+		{name: "three", up: []string{"CREATE INDEX three ON one (id)"}, down: []string{"DROP TABLE IF EXISTS tasks"}},
+	}
+	tests := []struct {
+		name    string
+		targets []int
+		want    []int64
+		downs   int
+	}{
+		{"up to latest", []int{3}, []int64{1, 2, 3}, 0},
+		{"up in steps", []int{1, 3}, []int64{1, 2, 3}, 0},
+		{"down one", []int{3, 2}, []int64{1, 2}, 1},
+		{"down to zero", []int{3, 0}, nil, 3},
+		{"down and up again", []int{3, 1, 3}, []int64{1, 2, 3}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := openFakeSQL(t)
+			for _, target := range tt.targets {
+				if err := migrateSQL(ctx, db, SQLite, migrations, target); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if got := slices.Sorted(maps.Keys(fake.versions)); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected versions %v, got %v", tt.want, got)
+			}
+			if got := fake.execs["DROP TABLE IF"]; got != tt.downs {
+				t.Errorf("Expected %d down steps, got %d", tt.downs, got)
+			}
+		})
+	}
+}
+
+func TestMigrateSQLErrors(t *testing.T) {
+	ctx := context.Background()
+	broken := append(slices.Clone(sqlMigrations), sqlMigration{
+		name: "add column",
+		up:   []string{"CREATE INDEX broken ON tasks (id)", "ALTER TABLE tasks ADD COLUMN notes TEXT"},
+	})
+	tests := []struct {
+		name     string
+		versions map[int64]string
+		migrate  func(db *sql.DB) error
+		want     error
+		after    map[int64]string
+	}{
+		{
+			name:     "newer database",
+			versions: map[int64]string{1: "create tasks", 2: "from the future"},
+			migrate:  func(db *sql.DB) error { return MigrateSQL(ctx, db, SQLite) },
+			want:     ErrUnsupportedSQLSchema,
+			after:    map[int64]string{1: "create tasks", 2: "from the future"},
+		},
+		{
+			name:    "unknown target",
+			migrate: func(db *sql.DB) error { return MigrateSQLTo(ctx, db, SQLite, len(sqlMigrations)+1) },
+			want:    ErrUnsupportedSQLSchema,
+			after:   map[int64]string{},
+		},
+		{
+			name:     "store on a newer database",
+			versions: map[int64]string{1: "create tasks", 2: "from the future"},
+			migrate: func(db *sql.DB) error {
+				_, err := OpenSQLStore(ctx, db, SQLite)
+				return err
+			},
+			want:  ErrUnsupportedSQLSchema,
+			after: map[int64]string{1: "create tasks", 2: "from the future"},
+		},
+		{
+			name:    "failed migration",
+			migrate: func(db *sql.DB) error { return migrateSQL(ctx, db, SQLite, broken, len(broken)) },
+			after:   map[int64]string{1: "create tasks"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := openFakeSQL(t)
+			maps.Copy(fake.versions, tt.versions)
+			err := tt.migrate(db)
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, err)
+			}
+			if !reflect.DeepEqual(fake.versions, tt.after) {
+				t.Errorf("Expected versions %v, got %v", tt.after, fake.versions)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package taskmanager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLDialect describes what SQLStore's statements need that differs
+// between databases
+type SQLDialect struct {
+	Name string
+	// Placeholder returns the placeholder of the n-th argument of a
+	// statement, counting from 1
+	Placeholder func(n int) string
+}
+
+// SQLite is the dialect of SQLite databases
+var SQLite = SQLDialect{
+	Name:        "sqlite",
+	Placeholder: func(int) string { return "?" },
+}
+
+// Postgres is the dialect of PostgreSQL databases, which number their
+// placeholders
+var Postgres = SQLDialect{
+	Name:        "postgres",
+	Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+}
+
+// SQLPool sets how many connections a *sql.DB keeps to its database. Zero
+// fields leave the database/sql defaults.
+type SQLPool struct {
+	// MaxOpen caps the connections in use and idle
+	MaxOpen int
+	// MaxIdle caps the idle connections kept for reuse
+	MaxIdle int
+	// MaxLifetime closes connections once they are that old
+	MaxLifetime time.Duration
+	// MaxIdleTime closes connections idle for that long
+	MaxIdleTime time.Duration
+}
+
+// PostgresPool is a pool suited to a task API serving from several
+// machines against one PostgreSQL server: a few connections per process,
+// recycled so the server's connections spread out again after a failover
+var PostgresPool = SQLPool{
+	MaxOpen:     10,
+	MaxIdle:     5,
+	MaxLifetime: 30 * time.Minute,
+	MaxIdleTime: 5 * time.Minute,
+}
+
+// Apply configures the pool of db. A SQLite database allows one writer at
+// a time, so it is best given a pool with MaxOpen set to 1.
+func (p SQLPool) Apply(db *sql.DB) {
+	if p.MaxOpen > 0 {
+		db.SetMaxOpenConns(p.MaxOpen)
+	}
+	if p.MaxIdle > 0 {
+		db.SetMaxIdleConns(p.MaxIdle)
+	}
+	if p.MaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.MaxLifetime)
+	}
+	if p.MaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.MaxIdleTime)
+	}
+}
+
+// rebind replaces the ? placeholders of a statement with the dialect's
+func (d SQLDialect) rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Statements SQLStore prepares when it is opened
+const (
+	sqlUpsertTask = `INSERT INTO tasks (id, status, priority, title, due_at, created_at, updated_at, data)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET status = excluded.status, priority = excluded.priority,
+title = excluded.title, due_at = excluded.due_at, created_at = excluded.created_at,
+updated_at = excluded.updated_at, data = excluded.data`
+	sqlDeleteTask = `DELETE FROM tasks WHERE id = ?`
+	sqlDeleteTags = `DELETE FROM task_tags WHERE task_id = ?`
+	sqlInsertTag  = `INSERT INTO task_tags (task_id, tag) VALUES (?, ?)`
+	sqlSetMeta    = `INSERT INTO task_store_meta (name, value) VALUES (?, ?)
+ON CONFLICT (name) DO UPDATE SET value = excluded.value`
+	sqlGetMeta   = `SELECT value FROM task_store_meta WHERE name = ?`
+	sqlLoadTasks = `SELECT data FROM tasks ORDER BY created_at, id`
+)
+
+// SQLStore keeps the active tasks in memory, as a MemoryStore does, and
+// saves them to a SQL database through database/sql when given to
+// WithPersister as well. Any driver for SQLite or PostgreSQL works, given
+// with the matching dialect; the caller opens the *sql.DB, sets its pool
+// with SQLPool and closes it after the store. It is an
+// IncrementalPersister, so each save writes only the tasks the manager
+// changed since the last one, in one transaction, with statements prepared
+// when the store is opened; values are always passed as arguments, never
+// spliced into the SQL. Query reads tasks from the
+// database by status, tag and due date without going through the manager.
+// As with FileStore, only the active tasks are saved, and a store assumes
+// it is the only one saving to its database: processes sharing one should
+// write through a single store and read with Query.
+type SQLStore struct {
+	*MemoryStore
+	db      *sql.DB
+	dialect SQLDialect
+
+	// mu keeps saves from overlapping
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+	// saved holds the IDs of the saved tasks
+	saved map[int]struct{}
+}
+
+// OpenSQLStore migrates the schema of db to the version this package uses,
+// creating the tables if they do not exist yet, prepares the store's
+// statements and loads the tasks saved there
+func OpenSQLStore(ctx context.Context, db *sql.DB, dialect SQLDialect) (*SQLStore, error) {
+	s := &SQLStore{
+		MemoryStore: NewMemoryStore(),
+		db:          db,
+		dialect:     dialect,
+		stmts:       make(map[string]*sql.Stmt),
+		saved:       make(map[int]struct{}),
+	}
+	if err := MigrateSQL(ctx, db, dialect); err != nil {
+		return nil, err
+	}
+	for _, query := range []string{sqlUpsertTask, sqlDeleteTask, sqlDeleteTags, sqlInsertTag, sqlSetMeta} {
+		stmt, err := db.PrepareContext(ctx, dialect.rebind(query))
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.stmts[query] = stmt
+	}
+	if err := s.load(ctx); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads every saved task into memory
+func (s *SQLStore) load(ctx context.Context) error {
+	tasks, err := s.queryTasks(ctx, sqlLoadTasks)
+	if err != nil {
+		return err
+	}
+	nextID := 1
+	err = s.db.QueryRowContext(ctx, s.dialect.rebind(sqlGetMeta), "next_id").Scan(&nextID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	for _, task := range tasks {
+		s.Put(task)
+		s.saved[task.ID] = struct{}{}
+		nextID = max(nextID, task.ID+1)
+	}
+	s.seq = NewSequenceIDs(nextID, 1)
+	return nil
+}
+
+// queryTasks decodes the tasks a query for the data column returns
+func (s *SQLStore) queryTasks(ctx context.Context, query string, args ...any) ([]*Task, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tasks []*Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		task := new(Task)
+		if err := json.Unmarshal([]byte(data), task); err != nil {
+			return nil, fmt.Errorf("decoding task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// Persist saves every task of the snapshot and deletes the saved tasks it
+// no longer holds, in one transaction
+func (s *SQLStore) Persist(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persist(fullChanges(snapshot, s.saved))
+}
+
+// PersistChanges saves the changed tasks and deletes those that are gone,
+// in one transaction
+func (s *SQLStore) PersistChanges(changes *Changes) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persist(changes)
+}
+
+// persist writes changes in one transaction
+func (s *SQLStore) persist(changes *Changes) error {
+	ctx := context.Background()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt := func(query string) *sql.Stmt {
+		return tx.StmtContext(ctx, s.stmts[query])
+	}
+
+	for _, task := range changes.Put {
+		data, err := json.Marshal(task)
+		if err != nil {
+			return err
+		}
+		var due any
+		if task.DueDate != nil {
+			due = task.DueDate.UnixNano()
+		}
+		_, err = stmt(sqlUpsertTask).ExecContext(ctx, task.ID, task.Status.String(), int(task.Priority), task.Title,
+			due, task.CreatedAt.UnixNano(), task.UpdatedAt.UnixNano(), string(data))
+		if err != nil {
+			return err
+		}
+		if _, err := stmt(sqlDeleteTags).ExecContext(ctx, task.ID); err != nil {
+			return err
+		}
+		for _, tag := range task.Tags {
+			if _, err := stmt(sqlInsertTag).ExecContext(ctx, task.ID, tag); err != nil {
+				return err
+			}
+		}
+	}
+	for _, id := range changes.Delete {
+		if _, err := stmt(sqlDeleteTags).ExecContext(ctx, id); err != nil {
+			return err
+		}
+		if _, err := stmt(sqlDeleteTask).ExecContext(ctx, id); err != nil {
+			return err
+		}
+	}
+	if _, err := stmt(sqlSetMeta).ExecContext(ctx, "next_id", s.seq.peek()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, task := range changes.Put {
+		s.saved[task.ID] = struct{}{}
+	}
+	for _, id := range changes.Delete {
+		delete(s.saved, id)
+	}
+	return nil
+}
+
+// StoreFilter selects saved tasks for SQLStore.Query. Unset fields match
+// every task.
+type StoreFilter struct {
+	// Statuses keeps tasks with any of the given statuses
+	Statuses []Status
+	// Tags keeps tasks carrying every one of the given tags
+	Tags []string
+	// DueFrom and DueBefore keep tasks due at or after DueFrom and before
+	// DueBefore; either keeps only tasks with a due date
+	DueFrom   time.Time
+	DueBefore time.Time
+	// Sort orders the tasks as SortBy does, ignoring keys with an unknown
+	// field, before the default listing order
+	Sort SortSpec
+	// Limit keeps at most that many tasks when positive
+	Limit int
+}
+
+// sqlSortColumns are the columns SortSpec fields order by
+var sqlSortColumns = map[SortField]string{
+	SortCreatedAt: "created_at",
+	SortDueDate:   "due_at",
+	SortPriority:  "priority",
+	SortTitle:     "lower(title)",
+	SortUpdatedAt: "updated_at",
+}
+
+// where returns the WHERE clause selecting the filter's tasks and its
+// arguments, or an empty clause for a filter matching every task
+func (f StoreFilter) where() (string, []any) {
+	var conditions []string
+	var args []any
+	if len(f.Statuses) > 0 {
+		marks := strings.TrimSuffix(strings.Repeat("?, ", len(f.Statuses)), ", ")
+		conditions = append(conditions, "status IN ("+marks+")")
+		for _, status := range f.Statuses {
+			args = append(args, status.String())
+		}
+	}
+	for _, tag := range f.Tags {
+		conditions = append(conditions, "id IN (SELECT task_id FROM task_tags WHERE tag = ?)")
+		args = append(args, tag)
+	}
+	if !f.DueFrom.IsZero() {
+		conditions = append(conditions, "due_at >= ?")
+		args = append(args, f.DueFrom.UnixNano())
+	}
+	if !f.DueBefore.IsZero() {
+		conditions = append(conditions, "due_at < ?")
+		args = append(args, f.DueBefore.UnixNano())
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// orderBy returns the ORDER BY clause of the filter's sort, ending with the
+// default listing order, and the LIMIT clause with its argument if any
+func (f StoreFilter) orderBy() (string, []any) {
+	var terms []string
+	for _, key := range f.Sort {
+		column, ok := sqlSortColumns[key.Field]
+		if !ok {
+			continue
+		}
+		if key.Field == SortDueDate {
+			// Tasks without a due date stay last in either direction.
+			terms = append(terms, "due_at IS NULL")
+		}
+		if key.Descending {
+			column += " DESC"
+		}
+		terms = append(terms, column)
+	}
+	clause := " ORDER BY " + strings.Join(append(terms, "created_at", "id"), ", ")
+	if f.Limit > 0 {
+		return clause + " LIMIT ?", []any{f.Limit}
+	}
+	return clause, nil
+}
+
+// Query returns the saved tasks matching the filter in the filter's order,
+// read from the database with the filter, sort and limit evaluated there,
+// using the indexes on the filtered and sorted columns. Changes not saved
+// yet are not seen, but changes other processes saved to the same database
+// are.
+func (s *SQLStore) Query(ctx context.Context, filter StoreFilter) ([]*Task, error) {
+	where, args := filter.where()
+	order, limit := filter.orderBy()
+	return s.queryTasks(ctx, "SELECT data FROM tasks"+where+order, append(args, limit...)...)
+}
+
+// Close releases the store's prepared statements. It does not close the
+// database.
+func (s *SQLStore) Close() error {
+	var first error
+	for _, stmt := range s.stmts {
+		if err := stmt.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
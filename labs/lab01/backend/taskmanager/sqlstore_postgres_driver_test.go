@@ -0,0 +1,7 @@
+//go:build postgres
+
+package taskmanager
+
+// The pgx driver registers itself with database/sql as "pgx", the driver
+// the Postgres tests open unless TASKMANAGER_POSTGRES_DRIVER names another
+import _ "github.com/jackc/pgx/v5/stdlib"
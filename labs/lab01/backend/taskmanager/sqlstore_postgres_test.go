@@ -0,0 +1,152 @@
+//go:build postgres
+
+// The tests in this file run SQLStore against a real PostgreSQL server:
+//
+//	TASKMANAGER_POSTGRES_DSN=postgres://localhost/tasks_test go test -tags postgres ./taskmanager
+//
+// They drop the store's tables first, so the database must be a scratch
+// one, and are skipped when TASKMANAGER_POSTGRES_DSN is not set. The pgx
+// driver is linked in under the postgres tag only, so other builds do not
+// depend on it; TASKMANAGER_POSTGRES_DRIVER names another registered
+// driver to use instead.
+package taskmanager
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// openPostgres connects to the test server and drops the store's tables
+func openPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TASKMANAGER_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TASKMANAGER_POSTGRES_DSN is not set")
+	}
+	name := os.Getenv("TASKMANAGER_POSTGRES_DRIVER")
+	if name == "" {
+		name = "pgx"
+	}
+	if !slices.Contains(sql.Drivers(), name) {
+		t.Fatalf("Expected the %s driver to be linked in, got %v", name, sql.Drivers())
+	}
+	db, err := sql.Open(name, dsn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	PostgresPool.Apply(db)
+	for _, table := range []string{"task_tags", "tasks", "task_store_meta", "schema_version"} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return db
+}
+
+// openPostgresManager opens a store over db and a manager saving to it
+func openPostgresManager(t *testing.T, db *sql.DB) (*SQLStore, *SafeTaskManager) {
+	t.Helper()
+	store, err := OpenSQLStore(context.Background(), db, Postgres)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	return store, NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{Interval: time.Hour}), WithClock(clock))
+}
+
+func TestPostgresStore(t *testing.T) {
+	db := openPostgres(t)
+	_, s := openPostgresManager(t, db)
+	first, _ := s.AddTask("Write report", "", WithTags("work", "urgent"), WithPriority(PriorityHigh))
+	second, _ := s.AddTask("Removed", "")
+	s.DeleteTask(second.ID)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, reopened := openPostgresManager(t, db)
+	defer reopened.Close()
+	want, _ := s.GetTask(first.ID)
+	if got, err := reopened.GetTask(first.ID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+	if _, err := reopened.GetTask(second.ID); err == nil {
+		t.Error("Expected the deleted task to stay deleted")
+	}
+	if task, _ := reopened.AddTask("Later", ""); task.ID != 3 {
+		t.Errorf("Expected IDs to carry on at 3, got %d", task.ID)
+	}
+}
+
+func TestPostgresStoreQuery(t *testing.T) {
+	db := openPostgres(t)
+	store, s := openPostgresManager(t, db)
+	defer s.Close()
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	s.AddTask("Report", "", WithTags("work"), WithDueDate(day))
+	s.AddTask("Slides", "", WithTags("work", "urgent"), WithDueDate(day.AddDate(0, 0, 2)), WithPriority(PriorityHigh))
+	groceries, _ := s.AddTask("Groceries", "", WithTags("home"))
+	s.CompleteTask(groceries.ID, "")
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter StoreFilter
+		want   []string
+	}{
+		{"all", StoreFilter{}, []string{"Report", "Slides", "Groceries"}},
+		{"status", StoreFilter{Statuses: []Status{StatusDone}}, []string{"Groceries"}},
+		{"tags", StoreFilter{Tags: []string{"work", "urgent"}}, []string{"Slides"}},
+		{"due range", StoreFilter{DueFrom: day.AddDate(0, 0, 1), DueBefore: day.AddDate(0, 0, 7)}, []string{"Slides"}},
+		{"by due date descending", StoreFilter{Sort: SortSpec{{Field: SortDueDate, Descending: true}}}, []string{"Slides", "Report", "Groceries"}},
+		{"by title", StoreFilter{Sort: SortSpec{{Field: SortTitle}}}, []string{"Groceries", "Report", "Slides"}},
+		{"limit", StoreFilter{Sort: SortSpec{{Field: SortPriority, Descending: true}}, Limit: 1}, []string{"Slides"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := store.Query(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPostgresStoreConcurrentQueries(t *testing.T) {
+	db := openPostgres(t)
+	store, s := openPostgresManager(t, db)
+	defer s.Close()
+	for i := range 50 {
+		s.AddTask("Task", "", WithPriority(PriorityLow+Priority(i%3)))
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// More queries than the pool has connections wait for one to free up
+	var wg sync.WaitGroup
+	for range PostgresPool.MaxOpen * 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tasks, err := store.Query(context.Background(), StoreFilter{Statuses: []Status{StatusTodo}})
+			if err != nil || len(tasks) != 50 {
+				t.Errorf("Expected 50 tasks, got %d, %v", len(tasks), err)
+			}
+		}()
+	}
+	wg.Wait()
+}
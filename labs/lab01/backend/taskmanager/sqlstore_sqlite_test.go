@@ -0,0 +1,183 @@
+//go:build sqlite
+
+// The tests in this file run SQLStore and its migrations against a real
+// SQLite database, so its DDL, upserts and queries are parsed by the
+// database rather than by the fake driver:
+//
+//	go test -tags sqlite ./taskmanager
+//
+// Each test gets a database file of its own. The pure Go modernc driver is
+// linked in under the sqlite tag only, so other builds do not depend on it.
+package taskmanager
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite opens a new SQLite database file for the test
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	SQLPool{MaxOpen: 1}.Apply(db)
+	return db
+}
+
+// sqliteStrings returns the first column of the rows a query returns
+func sqliteStrings(t *testing.T, db *sql.DB, query string, args ...any) []string {
+	t.Helper()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer rows.Close()
+	var result []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+func TestSQLiteStore(t *testing.T) {
+	db := openSQLite(t)
+	_, s := openSQLManager(t, db)
+	first, _ := s.AddTask("Write report", "", WithTags("work", "urgent"), WithPriority(PriorityHigh))
+	second, _ := s.AddTask("Removed", "")
+	s.DeleteTask(second.ID)
+	s.AddTask("Read", "", WithTags("home"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := sqliteStrings(t, db, "SELECT title FROM tasks ORDER BY id"); !slices.Equal(got, []string{"Write report", "Read"}) {
+		t.Errorf("Expected 2 saved tasks, got %v", got)
+	}
+
+	store, reopened := openSQLManager(t, db)
+	want, _ := s.GetTask(first.ID)
+	if got, err := reopened.GetTask(first.ID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+	if task, _ := reopened.AddTask("Later", ""); task.ID != 4 {
+		t.Errorf("Expected IDs to carry on at 4, got %d", task.ID)
+	}
+
+	// A saved task is updated in place and its removed tags are deleted
+	reopened.UpdateTaskFields(first.ID, TaskPatch{Title: ptr("Final report"), Tags: &[]string{"work"}})
+	if err := reopened.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := sqliteStrings(t, db, "SELECT tag FROM task_tags WHERE task_id = ? ORDER BY tag", first.ID); !slices.Equal(got, []string{"work"}) {
+		t.Errorf("Expected only the work tag left, got %v", got)
+	}
+	if got := sqliteStrings(t, db, "SELECT title FROM tasks WHERE id = ?", first.ID); !slices.Equal(got, []string{"Final report"}) {
+		t.Errorf("Expected the title column updated, got %v", got)
+	}
+	if err := reopened.DeleteTask(first.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := reopened.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := sqliteStrings(t, db, "SELECT CAST(task_id AS TEXT) FROM task_tags WHERE task_id = ?", first.ID); len(got) != 0 {
+		t.Errorf("Expected a deleted task's tags to leave the database, got %v", got)
+	}
+	if got := sqliteStrings(t, db, "SELECT title FROM tasks ORDER BY id"); !slices.Equal(got, []string{"Read", "Later"}) {
+		t.Errorf("Expected the deleted task to leave the database, got %v", got)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected 2 tasks in the store, got %d", store.Len())
+	}
+}
+
+func TestSQLiteStoreQuery(t *testing.T) {
+	db := openSQLite(t)
+	store, s := openSQLManager(t, db)
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	s.AddTask("Report", "", WithTags("work"), WithDueDate(day))
+	s.AddTask("Slides", "", WithTags("work", "urgent"), WithDueDate(day.AddDate(0, 0, 2)), WithPriority(PriorityHigh))
+	groceries, _ := s.AddTask("Groceries", "", WithTags("home"))
+	s.CompleteTask(groceries.ID, "")
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter StoreFilter
+		want   []string
+	}{
+		{"all", StoreFilter{}, []string{"Report", "Slides", "Groceries"}},
+		{"status", StoreFilter{Statuses: []Status{StatusDone, StatusCancelled}}, []string{"Groceries"}},
+		{"tags", StoreFilter{Tags: []string{"work", "urgent"}}, []string{"Slides"}},
+		{"due from", StoreFilter{DueFrom: day.AddDate(0, 0, 1)}, []string{"Slides"}},
+		{"due before", StoreFilter{DueBefore: day.AddDate(0, 0, 1)}, []string{"Report"}},
+		{"combined", StoreFilter{Statuses: []Status{StatusTodo}, Tags: []string{"work"}, DueBefore: day.AddDate(0, 0, 7)}, []string{"Report", "Slides"}},
+		{"by due date", StoreFilter{Sort: SortSpec{{Field: SortDueDate}}}, []string{"Report", "Slides", "Groceries"}},
+		{"by due date descending", StoreFilter{Sort: SortSpec{{Field: SortDueDate, Descending: true}}}, []string{"Slides", "Report", "Groceries"}},
+		{"by title", StoreFilter{Sort: SortSpec{{Field: SortTitle}}}, []string{"Groceries", "Report", "Slides"}},
+		{"limit", StoreFilter{Sort: SortSpec{{Field: SortPriority, Descending: true}}, Limit: 1}, []string{"Slides"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := store.Query(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSQLiteMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	tables := func() []string {
+		return sqliteStrings(t, db, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+	}
+	if err := MigrateSQL(ctx, db, SQLite); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := MigrateSQL(ctx, db, SQLite); err != nil {
+		t.Fatalf("Expected migrating twice to do nothing, got %v", err)
+	}
+	if got := sqliteStrings(t, db, "SELECT name FROM schema_version"); !slices.Equal(got, []string{"create tasks"}) {
+		t.Errorf("Expected 1 recorded migration, got %v", got)
+	}
+	if want := []string{"schema_version", "task_store_meta", "task_tags", "tasks"}; !slices.Equal(tables(), want) {
+		t.Errorf("Expected tables %v, got %v", want, tables())
+	}
+
+	// Migrating down to 0 drops the tables, and opening a store again
+	// creates them
+	if err := MigrateSQLTo(ctx, db, SQLite, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := tables(); !slices.Equal(got, []string{"schema_version"}) {
+		t.Errorf("Expected only schema_version left, got %v", got)
+	}
+	_, s := openSQLManager(t, db)
+	s.AddTask("Write report", "")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := sqliteStrings(t, db, "SELECT title FROM tasks"); !slices.Equal(got, []string{"Write report"}) {
+		t.Errorf("Expected the task saved in the recreated tables, got %v", got)
+	}
+}
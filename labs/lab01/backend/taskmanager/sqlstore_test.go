@@ -0,0 +1,361 @@
+package taskmanager
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSQL is a database/sql driver that understands exactly the statements
+// SQLStore runs to save and load tasks and to migrate its schema. The tests
+// tagged sqlite run the store against a real database; the fake is for what
+// those cannot show, such as which statements a save ran, and for making
+// statements fail. Each data source name is its own database and outlives
+// its connections.
+type fakeSQL struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeDB
+}
+
+// fakeDB is the state of one fake database
+type fakeDB struct {
+	mu    sync.Mutex
+	tasks map[int64]fakeRow
+	tags  map[int64]map[string]bool
+	meta  map[string]int64
+	// versions holds the rows of schema_version, by version
+	versions map[int64]string
+	// execs counts the statements executed, by their first words
+	execs map[string]int
+	// failing makes the statements starting with it fail
+	failing string
+}
+
+// fakeRow is a row of the tasks table, with the columns tasks are loaded
+// by
+type fakeRow struct {
+	id      int64
+	created int64
+	data    string
+}
+
+func (db *fakeDB) clone() *fakeDB {
+	c := &fakeDB{
+		tasks:    maps.Clone(db.tasks),
+		tags:     make(map[int64]map[string]bool),
+		meta:     maps.Clone(db.meta),
+		versions: maps.Clone(db.versions),
+		execs:    db.execs,
+		failing:  db.failing,
+	}
+	for id, tags := range db.tags {
+		c.tags[id] = maps.Clone(tags)
+	}
+	return c
+}
+
+var fakeDriver = &fakeSQL{dbs: make(map[string]*fakeDB)}
+
+func init() {
+	sql.Register("taskfake", fakeDriver)
+}
+
+func (d *fakeSQL) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[name]
+	if !ok {
+		db = &fakeDB{
+			tasks:    make(map[int64]fakeRow),
+			tags:     make(map[int64]map[string]bool),
+			meta:     make(map[string]int64),
+			versions: make(map[int64]string),
+			execs:    make(map[string]int),
+		}
+		d.dbs[name] = db
+	}
+	return &fakeConn{db: db}, nil
+}
+
+// openFakeSQL opens a new fake database for the test
+func openFakeSQL(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	name := t.Name()
+	db, err := sql.Open("taskfake", name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return db, fakeDriver.dbs[name]
+}
+
+type fakeConn struct {
+	db *fakeDB
+	// backup is the state to go back to when a transaction rolls back
+	backup *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	c.backup = c.db.clone()
+	c.db.mu.Unlock()
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.backup = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.tasks, c.db.tags, c.db.meta, c.db.versions = c.backup.tasks, c.backup.tags, c.backup.meta, c.backup.versions
+	c.backup = nil
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	words := strings.Fields(s.query)
+	db.execs[strings.Join(words[:min(3, len(words))], " ")]++
+	if db.failing != "" && strings.HasPrefix(s.query, db.failing) {
+		return nil, fmt.Errorf("fake driver: %q failed", db.failing)
+	}
+	switch {
+	case strings.HasPrefix(s.query, "CREATE"):
+	case strings.HasPrefix(s.query, "INSERT INTO tasks "):
+		db.tasks[args[0].(int64)] = fakeRow{id: args[0].(int64), created: args[5].(int64), data: args[7].(string)}
+	case strings.HasPrefix(s.query, "INSERT INTO task_tags "):
+		id := args[0].(int64)
+		if db.tags[id] == nil {
+			db.tags[id] = make(map[string]bool)
+		}
+		db.tags[id][args[1].(string)] = true
+	case strings.HasPrefix(s.query, "INSERT INTO task_store_meta "):
+		db.meta[args[0].(string)] = args[1].(int64)
+	case strings.HasPrefix(s.query, "DELETE FROM tasks "):
+		delete(db.tasks, args[0].(int64))
+	case strings.HasPrefix(s.query, "DELETE FROM task_tags "):
+		delete(db.tags, args[0].(int64))
+	case strings.HasPrefix(s.query, "INSERT INTO schema_version "):
+		if _, ok := db.versions[args[0].(int64)]; ok {
+			return nil, fmt.Errorf("fake driver: version %d already recorded", args[0])
+		}
+		db.versions[args[0].(int64)] = args[1].(string)
+	case strings.HasPrefix(s.query, "DELETE FROM schema_version "):
+		delete(db.versions, args[0].(int64))
+	case s.query == "DROP TABLE IF EXISTS tasks":
+		clear(db.tasks)
+	case s.query == "DROP TABLE IF EXISTS task_tags":
+		clear(db.tags)
+	case s.query == "DROP TABLE IF EXISTS task_store_meta":
+		clear(db.meta)
+	default:
+		return nil, fmt.Errorf("fake driver cannot exec %q", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if s.query == "SELECT MAX(version) FROM schema_version" {
+		var version driver.Value
+		for v := range db.versions {
+			if version == nil || v > version.(int64) {
+				version = v
+			}
+		}
+		return &fakeRows{column: "max", values: []driver.Value{version}}, nil
+	}
+	if s.query == "SELECT value FROM task_store_meta WHERE name = ?" {
+		rows := &fakeRows{column: "value"}
+		if value, ok := db.meta[args[0].(string)]; ok {
+			rows.values = []driver.Value{value}
+		}
+		return rows, nil
+	}
+	if s.query != sqlLoadTasks {
+		return nil, fmt.Errorf("fake driver cannot query %q", s.query)
+	}
+	rows := &fakeRows{column: "data"}
+	for _, row := range slices.SortedFunc(maps.Values(db.tasks), func(a, b fakeRow) int {
+		return cmp.Or(cmp.Compare(a.created, b.created), cmp.Compare(a.id, b.id))
+	}) {
+		rows.values = append(rows.values, row.data)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	column string
+	values []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{r.column} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+// openSQLManager opens a SQLStore over db and a manager saving to it, with
+// a fixed clock so tasks compare equal once loaded
+func openSQLManager(t *testing.T, db *sql.DB) (*SQLStore, *SafeTaskManager) {
+	t.Helper()
+	store, err := OpenSQLStore(context.Background(), db, SQLite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	return store, NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{Interval: time.Hour}), WithClock(clock))
+}
+
+func TestSQLStore(t *testing.T) {
+	db, fake := openFakeSQL(t)
+	_, s := openSQLManager(t, db)
+	first, _ := s.AddTask("Write report", "", WithTags("work", "urgent"))
+	second, _ := s.AddTask("Removed", "")
+	s.DeleteTask(second.ID)
+	s.AddTask("Read", "", WithTags("home"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fake.tasks) != 2 || !fake.tags[int64(first.ID)]["urgent"] {
+		t.Errorf("Expected 2 saved tasks with their tags, got %v and %v", fake.tasks, fake.tags)
+	}
+
+	// Saving again only writes the tasks that changed
+	store, reopened := openSQLManager(t, db)
+	want, _ := s.GetTask(first.ID)
+	if got, err := reopened.GetTask(first.ID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+	if got := titles(reopened.ListTasks(nil, FilterByAnyTag("work"))); !slices.Equal(got, []string{"Write report"}) {
+		t.Errorf("Expected the loaded tasks to be indexed, got %v", got)
+	}
+	if task, _ := reopened.AddTask("Later", ""); task.ID != 4 {
+		t.Errorf("Expected IDs to carry on at 4, got %d", task.ID)
+	}
+	reopened.UpdateTaskFields(first.ID, TaskPatch{Tags: &[]string{"work"}})
+	before := fake.execs["INSERT INTO tasks"]
+	if err := reopened.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if writes := fake.execs["INSERT INTO tasks"] - before; writes != 2 {
+		t.Errorf("Expected only the 2 changed tasks written, got %d", writes)
+	}
+	if fake.tags[int64(first.ID)]["urgent"] {
+		t.Error("Expected removed tags to be deleted")
+	}
+	if err := reopened.DeleteTask(first.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reopened.Flush()
+	if _, ok := fake.tasks[int64(first.ID)]; ok || fake.tags[int64(first.ID)] != nil {
+		t.Error("Expected a deleted task to leave the database")
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected 2 tasks in the store, got %d", store.Len())
+	}
+}
+
+func TestSQLStoreFailedSave(t *testing.T) {
+	db, fake := openFakeSQL(t)
+	_, s := openSQLManager(t, db)
+	defer s.Close()
+	first, _ := s.AddTask("Write report", "", WithTags("work"))
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A save failing part way is rolled back, and its changes are saved by
+	// the next one
+	fake.failing = "INSERT INTO task_tags"
+	s.UpdateTaskFields(first.ID, TaskPatch{Tags: &[]string{"home"}})
+	second, _ := s.AddTask("Read", "")
+	if err := s.Flush(); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+	if _, ok := fake.tasks[int64(second.ID)]; ok || !fake.tags[int64(first.ID)]["work"] {
+		t.Errorf("Expected the failed save rolled back, got %v and %v", fake.tasks, fake.tags)
+	}
+	fake.failing = ""
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := fake.tasks[int64(second.ID)]; !ok || !fake.tags[int64(first.ID)]["home"] || fake.tags[int64(first.ID)]["work"] {
+		t.Errorf("Expected the changes saved on the next flush, got %v and %v", fake.tasks, fake.tags)
+	}
+}
+
+func TestSQLDialectRebind(t *testing.T) {
+	filter := StoreFilter{Statuses: []Status{StatusTodo, StatusDone}, Tags: []string{"work"}, Limit: 10}
+	where, args := filter.where()
+	order, limit := filter.orderBy()
+	tests := []struct {
+		dialect SQLDialect
+		want    string
+	}{
+		{SQLite, "SELECT data FROM tasks WHERE status IN (?, ?) AND id IN (SELECT task_id FROM task_tags WHERE tag = ?) ORDER BY created_at, id LIMIT ?"},
+		{Postgres, "SELECT data FROM tasks WHERE status IN ($1, $2) AND id IN (SELECT task_id FROM task_tags WHERE tag = $3) ORDER BY created_at, id LIMIT $4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name, func(t *testing.T) {
+			if got := tt.dialect.rebind("SELECT data FROM tasks" + where + order); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+	if got := append(args, limit...); !reflect.DeepEqual(got, []any{"todo", "done", "work", 10}) {
+		t.Errorf("Expected the arguments in placeholder order, got %v", got)
+	}
+}
+
+func TestSQLPool(t *testing.T) {
+	db, _ := openFakeSQL(t)
+	PostgresPool.Apply(db)
+	if got := db.Stats().MaxOpenConnections; got != PostgresPool.MaxOpen {
+		t.Errorf("Expected %d open connections at most, got %d", PostgresPool.MaxOpen, got)
+	}
+	SQLPool{}.Apply(db)
+	if got := db.Stats().MaxOpenConnections; got != PostgresPool.MaxOpen {
+		t.Errorf("Expected a zero pool to keep the settings, got %d", got)
+	}
+}
@@ -0,0 +1,190 @@
+package taskmanager
+
+import (
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// ManagerStats describes the contents and activity of a manager, for sizing
+// a service and feeding metrics
+type ManagerStats struct {
+	// Tasks is the number of active tasks, archived ones included
+	Tasks int
+	// Trashed is the number of deleted tasks waiting in the trash
+	Trashed      int
+	Projects     int
+	Templates    int
+	SavedFilters int
+	// UndoOperations is the number of operations Undo can revert
+	UndoOperations int
+	Index          IndexStats
+	// ApproxBytes estimates the memory held by the tasks, the trash and
+	// the index. Attachment contents live in the BlobStore and are not
+	// included.
+	ApproxBytes int64
+	Ops         OpCounts
+}
+
+// IndexStats gives the sizes of the task index
+type IndexStats struct {
+	// Statuses, Tags and Assignees are the numbers of distinct values in
+	// each inverted index
+	Statuses  int
+	Tags      int
+	Assignees int
+	// Postings is the number of task references across the inverted
+	// indexes
+	Postings int
+	// Titles is the number of entries in the sorted title index
+	Titles int
+}
+
+// OpCounts counts what a manager has done since it was created
+type OpCounts struct {
+	Added    uint64
+	Updated  uint64
+	Deleted  uint64
+	Restored uint64
+	Purged   uint64
+	Evicted  uint64
+	Undone   uint64
+	Redone   uint64
+	// Lookups counts GetTask calls
+	Lookups uint64
+	// Listings counts the listings run, whatever method ran them
+	Listings uint64
+}
+
+// opCounters are the counters behind OpCounts. They are atomic because
+// lookups and listings are counted by methods that only read the manager,
+// which SafeTaskManager runs in parallel.
+type opCounters struct {
+	added, updated, deleted, restored, purged, evicted, undone, redone atomic.Uint64
+	lookups, listings                                                  atomic.Uint64
+}
+
+// count returns the current values of the counters
+func (c *opCounters) count() OpCounts {
+	return OpCounts{
+		Added:    c.added.Load(),
+		Updated:  c.updated.Load(),
+		Deleted:  c.deleted.Load(),
+		Restored: c.restored.Load(),
+		Purged:   c.purged.Load(),
+		Evicted:  c.evicted.Load(),
+		Undone:   c.undone.Load(),
+		Redone:   c.redone.Load(),
+		Lookups:  c.lookups.Load(),
+		Listings: c.listings.Load(),
+	}
+}
+
+// Stats returns the current sizes and operation counts of the manager. It
+// visits every task to estimate the memory in use.
+func (tm *TaskManager) Stats() ManagerStats {
+	stats := ManagerStats{
+		Tasks:          tm.tasks.Len(),
+		Trashed:        len(tm.trash),
+		Projects:       len(tm.projects),
+		Templates:      len(tm.templates),
+		SavedFilters:   len(tm.filters),
+		UndoOperations: len(tm.undoStack),
+		Index:          tm.index.stats(),
+		Ops:            tm.ops.count(),
+	}
+	for task := range tm.tasks.List() {
+		stats.ApproxBytes += task.approxSize() + mapEntrySize
+	}
+	for _, task := range tm.trash {
+		stats.ApproxBytes += task.approxSize() + mapEntrySize
+	}
+	stats.ApproxBytes += tm.index.approxSize()
+	return stats
+}
+
+// stats returns the sizes of the index
+func (x *taskIndex) stats() IndexStats {
+	s := IndexStats{
+		Statuses:  len(x.byStatus),
+		Tags:      len(x.byTag),
+		Assignees: len(x.byAssignee),
+		Titles:    len(x.titles),
+	}
+	for _, ids := range x.byStatus {
+		s.Postings += len(ids)
+	}
+	for _, ids := range x.byTag {
+		s.Postings += len(ids)
+	}
+	for _, ids := range x.byAssignee {
+		s.Postings += len(ids)
+	}
+	return s
+}
+
+// mapEntrySize is a rough cost of one map entry beyond its key and value
+const mapEntrySize = 16
+
+// approxSize estimates the bytes held by the index
+func (x *taskIndex) approxSize() int64 {
+	s := x.stats()
+	size := int64(len(x.entries)) * int64(unsafe.Sizeof(indexEntry{})+mapEntrySize)
+	size += int64(s.Postings) * (int64(unsafe.Sizeof(0)) + mapEntrySize)
+	for _, entry := range x.entries {
+		size += stringsSize(entry.tags) + int64(len(entry.title.folded))
+	}
+	size += int64(cap(x.titles)) * int64(unsafe.Sizeof(titleKey{}))
+	size += int64(cap(x.created)) * int64(unsafe.Sizeof(createdKey{}))
+	return size
+}
+
+// approxSize estimates the bytes held by the task, counting each string and
+// slice it refers to
+func (t *Task) approxSize() int64 {
+	size := int64(unsafe.Sizeof(*t))
+	size += int64(len(t.Title) + len(t.Description) + len(t.Color) + len(t.Icon) + len(t.AssigneeID) + len(t.OwnerID))
+	size += stringsSize(t.Links) + stringsSize(t.Tags)
+	size += int64(cap(t.Reminders))*int64(unsafe.Sizeof(t.Reminders[0])) +
+		int64(cap(t.DependsOn))*int64(unsafe.Sizeof(0)) +
+		int64(cap(t.Related))*int64(unsafe.Sizeof(TaskLink{}))
+	for _, p := range []*time.Time{t.DueDate, t.TimerStarted, t.SnoozedUntil, t.CompletedAt, t.DeletedAt} {
+		if p != nil {
+			size += int64(unsafe.Sizeof(*p))
+		}
+	}
+	if t.Location != nil {
+		size += int64(unsafe.Sizeof(*t.Location)) + int64(len(t.Location.Label))
+	}
+	if t.Recurrence != nil {
+		size += int64(unsafe.Sizeof(*t.Recurrence))
+	}
+	for name, value := range t.CustomFields {
+		size += int64(len(name)) + int64(unsafe.Sizeof(value)) + mapEntrySize
+		if s, ok := value.(string); ok {
+			size += int64(len(s))
+		}
+	}
+	for _, item := range t.Checklist {
+		size += int64(unsafe.Sizeof(item)) + int64(len(item.Text))
+	}
+	for _, a := range t.Attachments {
+		size += int64(unsafe.Sizeof(a)) + int64(len(a.Name)+len(a.MIMEType)+len(a.StorageKey))
+	}
+	for _, c := range t.Comments {
+		size += int64(unsafe.Sizeof(c)) + int64(len(c.Author)+len(c.Body))
+	}
+	for _, h := range t.History {
+		size += int64(unsafe.Sizeof(h)) + int64(len(h.Field)+len(h.OldValue)+len(h.NewValue))
+	}
+	return size
+}
+
+// stringsSize estimates the bytes held by a slice of strings
+func stringsSize(s []string) int64 {
+	size := int64(cap(s)) * int64(unsafe.Sizeof(""))
+	for _, v := range s {
+		size += int64(len(v))
+	}
+	return size
+}
@@ -0,0 +1,68 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	tm := NewTaskManager(WithUndoDepth(10))
+	report := mustAddTask(t, tm, "Write report", WithTags("work", "writing"))
+	milk := mustAddTask(t, tm, "Buy milk", WithTags("home"))
+	mustAddTask(t, tm, "Review PR", WithTags("work"))
+
+	empty := NewTaskManager().Stats()
+	if empty.Tasks != 0 || empty.ApproxBytes != 0 || empty.Ops != (OpCounts{}) {
+		t.Errorf("Expected an empty manager to report nothing, got %+v", empty)
+	}
+
+	before := tm.Stats()
+	if err := tm.UpdateTask(report.ID, "Write the report", "A much longer description than before", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteTask(milk.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Redo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.GetTask(report.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tm.ListTasks(nil)
+	tm.ListTasks(nil, FilterByAnyTag("work"))
+
+	stats := tm.Stats()
+	if stats.Tasks != 2 || stats.Trashed != 1 || stats.UndoOperations != 5 {
+		t.Errorf("Expected 2 tasks, 1 trashed and 5 operations to undo, got %+v", stats)
+	}
+	expectedIndex := IndexStats{Statuses: 1, Tags: 2, Assignees: 1, Postings: 7, Titles: 2}
+	if stats.Index != expectedIndex {
+		t.Errorf("Expected %+v, got %+v", expectedIndex, stats.Index)
+	}
+	if stats.ApproxBytes <= before.ApproxBytes {
+		t.Errorf("Expected the estimate to grow with the description and history, got %d then %d", before.ApproxBytes, stats.ApproxBytes)
+	}
+	if stats.Ops.Added != 3 || stats.Ops.Deleted != 1 || stats.Ops.Undone != 1 || stats.Ops.Redone != 1 {
+		t.Errorf("Expected the write counters to follow the operations, got %+v", stats.Ops)
+	}
+	if stats.Ops.Updated == 0 {
+		t.Error("Expected the update to be counted")
+	}
+	if stats.Ops.Lookups != 1 || stats.Ops.Listings != 2 {
+		t.Errorf("Expected 1 lookup and 2 listings, got %+v", stats.Ops)
+	}
+
+	if err := tm.RestoreTask(milk.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteTask(milk.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tm.PurgeTrash()
+	if ops := tm.Stats().Ops; ops.Restored != 1 || ops.Purged != 1 {
+		t.Errorf("Expected 1 restore and 1 purge, got %+v", ops)
+	}
+}
@@ -0,0 +1,229 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidStatus is returned when a task status is not one of the known values
+	ErrInvalidStatus = errors.New("invalid task status")
+	// ErrInvalidTransition is returned when a status change is not allowed
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// Status is the stage of a task's lifecycle
+type Status int
+
+const (
+	// StatusTodo is the status of a task that has not been started
+	StatusTodo Status = iota + 1
+	// StatusInProgress is the status of a task being worked on
+	StatusInProgress
+	// StatusBlocked is the status of a task that cannot move forward for now
+	StatusBlocked
+	// StatusDone is the status of a finished task
+	StatusDone
+	// StatusCancelled is the status of a task that will not be done
+	StatusCancelled
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[Status][]Status{
+	StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusCancelled},
+	StatusBlocked:    {StatusTodo, StatusInProgress, StatusCancelled},
+	StatusDone:       {StatusTodo},
+	StatusCancelled:  {StatusTodo},
+}
+
+// TransitionError describes a status change that the transition table forbids
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+// Error implements the error interface
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%v: %v to %v", ErrInvalidTransition, e.From, e.To)
+}
+
+// Is makes errors.Is(err, ErrInvalidTransition) match a TransitionError
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// Valid reports whether the status is one of the known values
+func (s Status) Valid() bool {
+	return s >= StatusTodo && s <= StatusCancelled
+}
+
+// Closed reports whether the status ends the task's lifecycle
+func (s Status) Closed() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+// CanTransition reports whether a task may move from s to next. Staying in
+// the same status is always allowed.
+func (s Status) CanTransition(next Status) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a human-readable name for the status
+func (s Status) String() string {
+	switch s {
+	case StatusTodo:
+		return "todo"
+	case StatusInProgress:
+		return "in progress"
+	case StatusBlocked:
+		return "blocked"
+	case StatusDone:
+		return "done"
+	case StatusCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// IsDone reports whether the task has been finished
+func (t *Task) IsDone() bool {
+	return t.Status == StatusDone
+}
+
+// ActiveStatuses are the statuses of work that has not been closed or
+// blocked, for the "active work" view
+var ActiveStatuses = []Status{StatusTodo, StatusInProgress}
+
+// FilterByStatus limits ListTasks to tasks with the given status
+func FilterByStatus(status Status) ListOption {
+	return FilterByStatuses(status)
+}
+
+// FilterByStatuses limits ListTasks to tasks with any of the given statuses,
+// such as FilterByStatuses(ActiveStatuses...). It replaces any status
+// filter given before it; with no statuses it keeps every status.
+func FilterByStatuses(statuses ...Status) ListOption {
+	return func(q *listQuery) {
+		q.statuses = nil
+		if len(statuses) == 0 {
+			return
+		}
+		q.statuses = make(map[Status]bool, len(statuses))
+		for _, s := range statuses {
+			q.statuses[s] = true
+		}
+	}
+}
+
+// Transition moves a task to a new status, returning a *TransitionError when
+// the move is not allowed
+func (tm *TaskManager) Transition(id int, status Status) error {
+	defer tm.beginOperation()()
+
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if !status.Valid() {
+		return ErrInvalidStatus
+	}
+	if !task.Status.CanTransition(status) {
+		return &TransitionError{From: task.Status, To: status}
+	}
+
+	next, err := tm.nextOccurrence(task, task.Status, status)
+	if err != nil {
+		return err
+	}
+	previous := task.Status
+	tm.update(task, func() {
+		task.Status = status
+		tm.statusChanged(task, previous, next)
+	})
+	return nil
+}
+
+// CompleteTask marks a task done and records the note in its history, as a
+// single operation. An empty note records nothing besides the status change.
+func (tm *TaskManager) CompleteTask(id int, note string) error {
+	defer tm.beginOperation()()
+
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if !task.Status.CanTransition(StatusDone) {
+		return &TransitionError{From: task.Status, To: StatusDone}
+	}
+
+	next, err := tm.nextOccurrence(task, task.Status, StatusDone)
+	if err != nil {
+		return err
+	}
+	previous := task.Status
+	tm.update(task, func() {
+		task.Status = StatusDone
+		tm.statusChanged(task, previous, next)
+	})
+	if note = strings.TrimSpace(note); note != "" {
+		task.History = append(task.History, HistoryEntry{
+			Time:     tm.now(),
+			Field:    HistoryCompletionNote,
+			NewValue: note,
+		})
+	}
+	return nil
+}
+
+// statusAfterUpdate maps the done flag of UpdateTask onto a status change
+func statusAfterUpdate(current Status, done bool) (Status, error) {
+	next := current
+	switch {
+	case done:
+		next = StatusDone
+	case current == StatusDone:
+		next = StatusTodo
+	}
+	if !current.CanTransition(next) {
+		return current, &TransitionError{From: current, To: next}
+	}
+	return next, nil
+}
+
+// statusChanged runs the side effects of a task entering a new status.
+// next is the follow-up nextOccurrence returned for the change; the
+// recurrence moves to it so completing the task again does not create
+// duplicates.
+func (tm *TaskManager) statusChanged(task *Task, previous Status, next *Task) {
+	if task.Status == previous {
+		return
+	}
+	if task.Status == StatusDone {
+		now := tm.now()
+		task.CompletedAt = &now
+	} else {
+		task.CompletedAt = nil
+	}
+	if !task.Status.Closed() {
+		task.Archived = false
+	} else {
+		tm.stopTimer(task)
+	}
+	if task.Status == StatusDone && task.Recurrence != nil {
+		task.Recurrence = nil
+		if next != nil {
+			tm.insert(next)
+		}
+	}
+}
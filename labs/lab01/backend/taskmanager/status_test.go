@@ -0,0 +1,197 @@
+package taskmanager
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        []Status
+		expectError bool
+	}{
+		{name: "start and finish", path: []Status{StatusInProgress, StatusDone}},
+		{name: "block and unblock", path: []Status{StatusBlocked, StatusInProgress, StatusDone}},
+		{name: "reopen", path: []Status{StatusDone, StatusTodo}},
+		{name: "cancel", path: []Status{StatusCancelled}},
+		{name: "same status", path: []Status{StatusTodo}},
+		{name: "finish while blocked", path: []Status{StatusBlocked, StatusDone}, expectError: true},
+		{name: "restart cancelled", path: []Status{StatusCancelled, StatusInProgress}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTaskManager()
+			task := mustAddTask(t, tm, "Task")
+
+			var err error
+			for _, status := range tt.path {
+				if err = tm.Transition(task.ID, status); err != nil {
+					break
+				}
+			}
+
+			if tt.expectError {
+				var transitionErr *TransitionError
+				if !errors.As(err, &transitionErr) {
+					t.Fatalf("Expected *TransitionError, got %v", err)
+				}
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Error("Expected error to match ErrInvalidTransition")
+				}
+				if transitionErr.To != tt.path[len(tt.path)-1] {
+					t.Errorf("Expected error target %v, got %v", tt.path[len(tt.path)-1], transitionErr.To)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if task.Status != tt.path[len(tt.path)-1] {
+				t.Errorf("Expected status %v, got %v", tt.path[len(tt.path)-1], task.Status)
+			}
+		})
+	}
+}
+
+func TestTransitionInvalid(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Task")
+
+	if err := tm.Transition(999, StatusDone); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if err := tm.Transition(task.ID, Status(42)); err != ErrInvalidStatus {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestUpdateTaskStatus(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Task")
+	if err := tm.Transition(task.ID, StatusInProgress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.UpdateTask(task.ID, "Renamed", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Status != StatusInProgress {
+		t.Errorf("Expected update without done to keep %v, got %v", StatusInProgress, task.Status)
+	}
+
+	if err := tm.Transition(task.ID, StatusBlocked); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.UpdateTask(task.ID, "Renamed", "", true); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+
+	blocked := tm.ListTasks(nil, FilterByStatus(StatusBlocked))
+	if len(blocked) != 1 {
+		t.Errorf("Expected 1 blocked task, got %d", len(blocked))
+	}
+}
+
+func TestFilterByStatuses(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for _, status := range []Status{StatusTodo, StatusInProgress, StatusBlocked, StatusDone, StatusInProgress} {
+		task := mustAddTask(t, tm, status.String())
+		if err := tm.Transition(task.ID, status); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ListOption
+		expected []string
+	}{
+		{name: "active work", opts: []ListOption{FilterByStatuses(ActiveStatuses...)}, expected: []string{"todo", "in progress", "in progress"}},
+		{name: "closed or blocked", opts: []ListOption{FilterByStatuses(StatusBlocked, StatusDone)}, expected: []string{"blocked", "done"}},
+		{name: "replaces single status", opts: []ListOption{FilterByStatus(StatusDone), FilterByStatuses(StatusTodo)}, expected: []string{"todo"}},
+		{name: "no statuses", opts: []ListOption{FilterByStatus(StatusDone), FilterByStatuses()}, expected: []string{"todo", "in progress", "blocked", "done", "in progress"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tm.ListTasks(nil, tt.opts...)); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompletedAt(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Report")
+
+	now = now.Add(time.Hour)
+	if err := tm.Transition(task.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.CompletedAt == nil || !task.CompletedAt.Equal(now) {
+		t.Errorf("Expected CompletedAt %v, got %v", now, task.CompletedAt)
+	}
+
+	if err := tm.UpdateTask(task.ID, task.Title, task.Description, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.CompletedAt != nil {
+		t.Errorf("Expected reopening to clear CompletedAt, got %v", task.CompletedAt)
+	}
+}
+
+func TestCompleteTask(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Report")
+	cancelled := mustAddTask(t, tm, "Dropped")
+	if err := tm.Transition(cancelled.ID, StatusCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		id          int
+		note        string
+		expectError error
+	}{
+		{name: "with note", id: task.ID, note: " Sent to the board "},
+		{name: "cancelled task", id: cancelled.ID, note: "x", expectError: ErrInvalidTransition},
+		{name: "missing task", id: 999, expectError: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tm.CompleteTask(tt.id, tt.note); !errors.Is(err, tt.expectError) {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if task.Status != StatusDone || task.CompletedAt == nil {
+		t.Errorf("Expected task to be done with CompletedAt set, got %+v", task)
+	}
+	last := task.History[len(task.History)-1]
+	if last.Field != HistoryCompletionNote || last.NewValue != "Sent to the board" {
+		t.Errorf("Expected completion note in history, got %+v", last)
+	}
+	if len(cancelled.History) != 2 {
+		t.Errorf("Expected a rejected completion to record nothing, got %+v", cancelled.History)
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Status != StatusTodo || task.CompletedAt != nil {
+		t.Errorf("Expected undo to reopen the task, got %+v", task)
+	}
+}
@@ -0,0 +1,271 @@
+package taskmanager
+
+import (
+	"iter"
+	"slices"
+)
+
+// TaskStore holds the active tasks of a TaskManager; trashed tasks are
+// kept by the manager itself. Put is called when a task joins the active
+// tasks, whether it was just added or comes back from the trash, and
+// Delete when it leaves them. The manager changes stored tasks in place
+// between those calls and hands them out to callers, so a store must keep
+// the very pointers it is given; to save tasks anywhere else, pair the
+// store with a Persister. A store is only used under the manager's lock.
+type TaskStore interface {
+	// Get returns the task with the given ID
+	Get(id int) (*Task, bool)
+	// Put stores a task, replacing any task with the same ID
+	Put(task *Task)
+	// Delete removes the task with the given ID, if there is one. Tasks
+	// may be deleted while ranging over List.
+	Delete(id int)
+	// List yields every stored task, in no particular order
+	List() iter.Seq[*Task]
+	// Len returns the number of stored tasks
+	Len() int
+	// NextID returns the ID of the next task, unless the manager was
+	// given WithIDGenerator
+	NextID() int
+}
+
+// WithTaskStore makes the manager keep its active tasks in store instead of
+// a MemoryStore. Tasks the store already holds are indexed when the manager
+// is created. Each manager needs its own store, so the option must not be
+// given to NewShardedTaskManager.
+func WithTaskStore(store TaskStore) Option {
+	return func(tm *TaskManager) {
+		tm.tasks = store
+	}
+}
+
+// loadStore indexes the tasks a store held before the manager was created
+// and moves the manager's counters past the positions and IDs they use, so
+// comments, checklist items and attachments added afterwards get IDs of
+// their own. Stores do not keep projects, so references to them are
+// cleared as RestoreTask clears them.
+func (tm *TaskManager) loadStore() {
+	if tm.tasks.Len() == 0 {
+		return
+	}
+	loaded := slices.Collect(tm.tasks.List())
+	for _, task := range loaded {
+		if _, ok := tm.projects[task.ProjectID]; task.ProjectID != 0 && !ok {
+			task.ProjectID = 0
+			tm.markChanged(task.ID)
+		}
+		tm.lastPosition = max(tm.lastPosition, task.Position)
+		for _, c := range task.Comments {
+			tm.nextCommentID = max(tm.nextCommentID, c.ID+1)
+		}
+		for _, a := range task.Attachments {
+			tm.nextAttachmentID = max(tm.nextAttachmentID, a.ID+1)
+		}
+		for _, item := range task.Checklist {
+			tm.nextChecklistItemID = max(tm.nextChecklistItemID, item.ID+1)
+		}
+	}
+	tm.index.putAll(loaded)
+}
+
+// storeChunkSize is the number of tasks a MemoryStore allocates at a time
+const storeChunkSize = 256
+
+// minStoreCompaction is the fewest removed slots a MemoryStore compacts
+// away
+const minStoreCompaction = 64
+
+// MemoryStore is the TaskStore managers use by default. It keeps the tasks
+// as a dense slice of slots with an ID index into it, so scans walk the
+// tasks in order instead of hopping through a map, and allocates the tasks
+// the manager adds one at a time in chunks of Task structs, filled in the
+// order tasks are added, as bulk adds do with their slabs. A task never
+// moves: deleting one only clears its slot, which a task put back with the
+// same ID, as restoring and undoing do, takes again, and the cleared slots
+// are compacted away once they make up half of the store. IDs count up
+// from 1.
+type MemoryStore struct {
+	slots []*Task
+	// ids holds the slot of each task, including the cleared slots of
+	// deleted tasks until the store is compacted
+	ids   map[int]int
+	count int
+	holes int
+	// chunk holds the Task structs not handed out by next yet
+	chunk []Task
+	// ordered reports whether the slots are in the default listing order,
+	// which they are while tasks are stored in creation order
+	ordered bool
+	last    createdKey
+	seq     *SequenceIDs
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ids: make(map[int]int), ordered: true, seq: NewSequenceIDs(1, 1)}
+}
+
+// Len returns the number of stored tasks
+func (s *MemoryStore) Len() int {
+	return s.count
+}
+
+// Get returns the task with the given ID
+func (s *MemoryStore) Get(id int) (*Task, bool) {
+	i, ok := s.ids[id]
+	if !ok || s.slots[i] == nil {
+		return nil, false
+	}
+	return s.slots[i], true
+}
+
+// NextID returns the next ID of the store's sequence
+func (s *MemoryStore) NextID() int {
+	return s.seq.NextID()
+}
+
+// advanceIDs skips the store's sequence ahead to next
+func (s *MemoryStore) advanceIDs(next int) {
+	s.seq.advanceIDs(next)
+}
+
+// ReserveIDs returns the next n IDs of the store's sequence
+func (s *MemoryStore) ReserveIDs(n int) []int {
+	return s.seq.ReserveIDs(n)
+}
+
+// next returns a zeroed task from the current chunk. It stays the next
+// task until it is put in the store, so a task that fails validation
+// leaves its struct for the next one.
+func (s *MemoryStore) next() *Task {
+	if len(s.chunk) == 0 {
+		s.chunk = make([]Task, storeChunkSize)
+	}
+	task := &s.chunk[0]
+	*task = Task{}
+	return task
+}
+
+// Put stores a task in the slot of the task with the same ID, if one was
+// stored since the last compaction, or else in a new slot
+func (s *MemoryStore) Put(task *Task) {
+	if i, ok := s.ids[task.ID]; ok {
+		if s.slots[i] == nil {
+			s.holes--
+			s.count++
+		}
+		s.slots[i] = task
+		return
+	}
+	if len(s.chunk) > 0 && task == &s.chunk[0] {
+		s.chunk = s.chunk[1:]
+	}
+	if s.holes >= minStoreCompaction && s.holes > len(s.slots)/2 {
+		s.compact()
+	}
+	key := createdKey{at: task.CreatedAt, id: task.ID}
+	if len(s.slots) > 0 && compareCreatedKeys(key, s.last) < 0 {
+		s.ordered = false
+	}
+	s.last = key
+	s.ids[task.ID] = len(s.slots)
+	s.slots = append(s.slots, task)
+	s.count++
+}
+
+// Delete clears the slot of a task. It never moves other tasks, so it is
+// safe while ranging over List.
+func (s *MemoryStore) Delete(id int) {
+	i, ok := s.ids[id]
+	if !ok || s.slots[i] == nil {
+		return
+	}
+	s.slots[i] = nil
+	s.count--
+	s.holes++
+}
+
+// compact drops the cleared slots and puts the others back in the default
+// listing order
+func (s *MemoryStore) compact() {
+	s.slots = slices.DeleteFunc(s.slots, func(task *Task) bool { return task == nil })
+	if !s.ordered {
+		slices.SortFunc(s.slots, func(a, b *Task) int {
+			return compareCreatedKeys(createdKey{at: a.CreatedAt, id: a.ID}, createdKey{at: b.CreatedAt, id: b.ID})
+		})
+		s.ordered = true
+	}
+	clear(s.ids)
+	for i, task := range s.slots {
+		s.ids[task.ID] = i
+	}
+	if n := len(s.slots); n > 0 {
+		s.last = createdKey{at: s.slots[n-1].CreatedAt, id: s.slots[n-1].ID}
+	}
+	s.holes = 0
+}
+
+// grow makes room for n more tasks
+func (s *MemoryStore) grow(n int) {
+	s.slots = slices.Grow(s.slots, n)
+	s.ids = grow(s.ids, n)
+}
+
+// List yields the stored tasks in slot order, which is the order they were
+// first stored in
+func (s *MemoryStore) List() iter.Seq[*Task] {
+	return func(yield func(*Task) bool) {
+		for i := 0; i < len(s.slots); i++ {
+			if task := s.slots[i]; task != nil && !yield(task) {
+				return
+			}
+		}
+	}
+}
+
+// newTask returns a zeroed task for addTask to fill in, from the store's
+// chunks when it is a MemoryStore
+func (tm *TaskManager) newTask() *Task {
+	if s, ok := tm.tasks.(*MemoryStore); ok {
+		return s.next()
+	}
+	return new(Task)
+}
+
+// growStore makes room for n more tasks when the store can
+func (tm *TaskManager) growStore(n int) {
+	if s, ok := tm.tasks.(*MemoryStore); ok {
+		s.grow(n)
+	}
+}
+
+// inCreationOrder yields the active tasks in the default listing order,
+// straight from the slots of a MemoryStore when they are in that order
+func (tm *TaskManager) inCreationOrder() iter.Seq[*Task] {
+	if s, ok := tm.tasks.(*MemoryStore); ok && s.ordered {
+		return s.List()
+	}
+	return func(yield func(*Task) bool) {
+		for _, key := range tm.index.created {
+			if task, _ := tm.tasks.Get(key.id); !yield(task) {
+				return
+			}
+		}
+	}
+}
+
+// everyTask yields the active tasks and then the trashed ones
+func (tm *TaskManager) everyTask() iter.Seq[*Task] {
+	return func(yield func(*Task) bool) {
+		for task := range tm.tasks.List() {
+			if !yield(task) {
+				return
+			}
+		}
+		for _, task := range tm.trash {
+			if !yield(task) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package taskmanager
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storedTask returns the stored task with the given ID, not a copy
+func storedTask(tm *TaskManager, id int) *Task {
+	task, _ := tm.tasks.Get(id)
+	return task
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	base := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	put := func(id int, created time.Time) *Task {
+		task := s.next()
+		task.ID, task.CreatedAt = id, created
+		s.Put(task)
+		return task
+	}
+	ids := func() []int {
+		var result []int
+		for task := range s.List() {
+			result = append(result, task.ID)
+		}
+		return result
+	}
+
+	first := put(1, base)
+	second := put(2, base.Add(time.Minute))
+	if &first.Title == &second.Title || s.next() == second {
+		t.Error("Expected every task put to get its own struct")
+	}
+	put(3, base.Add(2*time.Minute))
+	if task, ok := s.Get(2); !ok || task != second || s.Len() != 3 {
+		t.Errorf("Expected task 2 among 3 tasks, got %v, %v with %d", task, ok, s.Len())
+	}
+
+	s.Delete(2)
+	if _, ok := s.Get(2); ok || s.Len() != 2 {
+		t.Errorf("Expected task 2 to be removed, got %d tasks", s.Len())
+	}
+	s.Put(second)
+	if got := ids(); !slices.Equal(got, []int{1, 2, 3}) || !s.ordered {
+		t.Errorf("Expected task 2 back in its slot, got %v", got)
+	}
+
+	// A task created earlier than the last one stored leaves the slots
+	// out of order until they are compacted
+	put(4, base.Add(-time.Minute))
+	if s.ordered {
+		t.Error("Expected the slots to be out of order")
+	}
+	for id := 10; id < 10+2*minStoreCompaction+1; id++ {
+		put(id, base.Add(time.Hour))
+		s.Delete(id)
+	}
+	put(100, base.Add(2*time.Hour))
+	if got := ids(); !slices.Equal(got, []int{4, 1, 2, 3, 100}) || !s.ordered || s.holes >= minStoreCompaction {
+		t.Errorf("Expected compacted slots in creation order, got %v with %d holes", got, s.holes)
+	}
+	if task, ok := s.Get(2); !ok || task != second {
+		t.Error("Expected tasks to keep their address through compaction")
+	}
+}
+
+func TestMemoryStoreOrder(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for i := range 3 {
+		mustAddTask(t, tm, fmt.Sprintf("Task %d", i))
+		now = now.Add(time.Minute)
+	}
+	if err := tm.DeleteTask(1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.RestoreTask(1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteTask(2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !tm.tasks.(*MemoryStore).ordered {
+		t.Error("Expected restoring and undoing to keep the store in order")
+	}
+
+	// A task added after the clock went back is listed first all the same
+	now = now.Add(-time.Hour)
+	mustAddTask(t, tm, "Backdated")
+	want := []string{"Backdated", "Task 0", "Task 1", "Task 2"}
+	if got := titles(tm.ListTasks(nil)); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	var visited []string
+	tm.ForEachTask(ListOptions{}, func(task *Task) bool {
+		visited = append(visited, task.Title)
+		return true
+	})
+	if !slices.Equal(visited, want) {
+		t.Errorf("Expected ForEachTask to visit %v, got %v", want, visited)
+	}
+}
+
+// layoutBenchmarkManager returns a manager holding n tasks, a quarter of
+// them high priority and a fifth of them done
+func layoutBenchmarkManager(b *testing.B, n int) *TaskManager {
+	tm := NewTaskManager()
+	inputs := make([]TaskInput, n)
+	for i := range inputs {
+		inputs[i] = TaskInput{Title: fmt.Sprintf("Task %d", i), Options: []TaskOption{WithPriority(Priority(i%4 + 1))}}
+	}
+	if _, err := tm.AddTasksBatch(inputs); err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+	for id := 1; id <= n; id += 5 {
+		tm.UpdateTaskFields(id, TaskPatch{Status: ptr(StatusDone)})
+	}
+	return tm
+}
+
+func BenchmarkStoreLayout(b *testing.B) {
+	tm := layoutBenchmarkManager(b, 100_000)
+	done := true
+	var buf []*Task
+	b.Run("list", func(b *testing.B) {
+		for b.Loop() {
+			buf = tm.ListTasksInto(buf, nil)
+		}
+	})
+	b.Run("filter", func(b *testing.B) {
+		for b.Loop() {
+			buf = tm.ListTasksInto(buf, &done, FilterByPriority(PriorityHigh))
+		}
+	})
+	b.Run("count", func(b *testing.B) {
+		for b.Loop() {
+			tm.CountTasks(ListOptions{Priorities: []Priority{PriorityHigh}})
+		}
+	})
+	b.Run("foreach", func(b *testing.B) {
+		for b.Loop() {
+			tm.ForEachTask(ListOptions{}, func(*Task) bool { return true })
+		}
+	})
+}
+
+// mapStore is a TaskStore over a plain map, standing in for other stores
+type mapStore struct {
+	tasks  map[int]*Task
+	nextID int
+}
+
+func (m *mapStore) Get(id int) (*Task, bool) {
+	task, ok := m.tasks[id]
+	return task, ok
+}
+
+func (m *mapStore) Put(task *Task) { m.tasks[task.ID] = task }
+
+func (m *mapStore) Delete(id int) { delete(m.tasks, id) }
+
+func (m *mapStore) List() iter.Seq[*Task] { return maps.Values(m.tasks) }
+
+func (m *mapStore) Len() int { return len(m.tasks) }
+
+func (m *mapStore) NextID() int {
+	m.nextID++
+	return m.nextID
+}
+
+func TestWithTaskStore(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	store := &mapStore{tasks: make(map[int]*Task), nextID: 100}
+	tm := NewTaskManager(WithTaskStore(store), WithClock(func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}))
+
+	first := mustAddTask(t, tm, "First", WithTags("work"))
+	second := mustAddTask(t, tm, "Second")
+	if first.ID != 101 || second.ID != 102 {
+		t.Errorf("Expected the store to hand out IDs 101 and 102, got %d and %d", first.ID, second.ID)
+	}
+	if store.Len() != 2 || store.tasks[first.ID] != first {
+		t.Errorf("Expected the tasks in the store, got %v", store.tasks)
+	}
+	if err := tm.DeleteTask(second.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := store.tasks[second.ID]; ok {
+		t.Error("Expected a deleted task to leave the store")
+	}
+	if err := tm.RestoreTask(second.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	third := mustAddTask(t, tm, "Third")
+	if got, want := titles(tm.ListTasks(nil)), []string{"First", "Second", "Third"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	checkIndex(t, tm, "custom store")
+
+	// A manager over a store that already holds tasks indexes them
+	reopened := NewTaskManager(WithTaskStore(store))
+	if got := titles(reopened.ListTasks(nil, FilterByAnyTag("work"))); !slices.Equal(got, []string{"First"}) {
+		t.Errorf("Expected the loaded tasks to be indexed, got %v", got)
+	}
+	if task := mustAddTask(t, reopened, "Fourth"); task.ID != third.ID+1 || task.Position <= third.Position {
+		t.Errorf("Expected the next ID and a later position, got %d at %d", task.ID, task.Position)
+	}
+	checkIndex(t, reopened, "reopened store")
+}
+
+func TestReopenedStoreIDs(t *testing.T) {
+	store := &mapStore{tasks: make(map[int]*Task)}
+	blobs := NewMemoryBlobStore()
+	tm := NewTaskManager(WithTaskStore(store), WithBlobStore(blobs))
+	task := mustAddTask(t, tm, "Report")
+	comment, _ := tm.AddComment(task.ID, "alice", "First draft")
+	item, _ := tm.AddChecklistItem(task.ID, "Sources")
+	attachment, err := tm.UploadAttachment(task.ID, "notes.txt", "text/plain", strings.NewReader("old"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Whatever is added after reopening the store gets IDs of its own
+	reopened := NewTaskManager(WithTaskStore(store), WithBlobStore(blobs))
+	if c, err := reopened.AddComment(task.ID, "bob", "Second draft"); err != nil || c.ID == comment.ID {
+		t.Errorf("Expected a new comment ID, got %d, %v", c.ID, err)
+	}
+	if i, err := reopened.AddChecklistItem(task.ID, "Outline"); err != nil || i.ID == item.ID {
+		t.Errorf("Expected a new checklist item ID, got %d, %v", i.ID, err)
+	}
+	a, err := reopened.UploadAttachment(task.ID, "notes.txt", "text/plain", strings.NewReader("new"))
+	if err != nil || a.ID == attachment.ID || a.StorageKey == attachment.StorageKey {
+		t.Errorf("Expected a new attachment and blob, got %+v, %v", a, err)
+	}
+	if err := reopened.DeleteComment(task.ID, comment.ID); err != nil {
+		t.Errorf("Expected the first comment removed, got %v", err)
+	}
+	got := storedTask(reopened, task.ID)
+	if len(got.Comments) != 1 || got.Comments[0].Body != "Second draft" || len(got.Checklist) != 2 {
+		t.Errorf("Expected the later comment and both checklist items kept, got %+v", got)
+	}
+	r, _ := blobs.Get(attachment.StorageKey)
+	if data, _ := io.ReadAll(r); string(data) != "old" {
+		t.Errorf("Expected the first attachment's contents kept, got %q", data)
+	}
+}
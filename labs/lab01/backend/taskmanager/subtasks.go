@@ -0,0 +1,102 @@
+package taskmanager
+
+import "errors"
+
+var (
+	// ErrParentNotFound is returned when a task refers to a parent that does not exist
+	ErrParentNotFound = errors.New("parent task not found")
+	// ErrCyclicParent is returned when setting a parent would make a task its own ancestor
+	ErrCyclicParent = errors.New("task cannot be its own ancestor")
+)
+
+// CascadeMode controls what happens to subtasks when their parent is deleted
+type CascadeMode int
+
+const (
+	// CascadeOrphan keeps subtasks and turns them into top-level tasks
+	CascadeOrphan CascadeMode = iota
+	// CascadeRecursive deletes subtasks together with their parent
+	CascadeRecursive
+)
+
+// WithParent makes the task a subtask of the given parent. A parent ID of
+// zero turns the task into a top-level task.
+func WithParent(parentID int) TaskOption {
+	return func(t *Task) {
+		t.ParentID = parentID
+	}
+}
+
+// AddSubtask adds a new task as a child of an existing task
+func (tm *TaskManager) AddSubtask(parentID int, title, description string, opts ...TaskOption) (*Task, error) {
+	if _, err := tm.lookup(parentID); err != nil {
+		return nil, err
+	}
+	opts = append([]TaskOption{WithParent(parentID)}, opts...)
+	return tm.AddTask(title, description, opts...)
+}
+
+// ListChildren returns the direct subtasks of a task, oldest first unless
+// opts say otherwise. Archived and snoozed subtasks are included.
+func (tm *TaskManager) ListChildren(id int, opts ...ListOption) ([]*Task, error) {
+	if _, err := tm.lookup(id); err != nil {
+		return nil, err
+	}
+	opts = append([]ListOption{IncludeArchived(), IncludeSnoozed()}, opts...)
+	return tm.ListTasks(nil, append(opts, where(func(task *Task) bool {
+		return task.ParentID == id
+	}))...), nil
+}
+
+// DeleteTaskCascade moves a task to the trash and handles its subtasks
+// according to mode
+func (tm *TaskManager) DeleteTaskCascade(id int, mode CascadeMode) error {
+	defer tm.beginOperation()()
+
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	for _, child := range tm.children(id) {
+		if mode == CascadeRecursive {
+			if err := tm.DeleteTaskCascade(child.ID, mode); err != nil {
+				return err
+			}
+			continue
+		}
+		tm.update(child, func() {
+			child.ParentID = 0
+		})
+	}
+	tm.trashTask(task)
+	return nil
+}
+
+// children returns the direct subtasks of a task in no particular order
+func (tm *TaskManager) children(id int) []*Task {
+	var result []*Task
+	for task := range tm.tasks.List() {
+		if task.ParentID == id {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// validateParent checks that the task's parent exists and is not one of its descendants
+func (tm *TaskManager) validateParent(task *Task) error {
+	if task.ParentID == 0 {
+		return nil
+	}
+	for ancestorID := task.ParentID; ancestorID != 0; {
+		if ancestorID == task.ID {
+			return ErrCyclicParent
+		}
+		ancestor, ok := tm.tasks.Get(ancestorID)
+		if !ok {
+			return ErrParentNotFound
+		}
+		ancestorID = ancestor.ParentID
+	}
+	return nil
+}
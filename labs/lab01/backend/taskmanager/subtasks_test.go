@@ -0,0 +1,90 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestAddSubtask(t *testing.T) {
+	tm := NewTaskManager()
+	parent := mustAddTask(t, tm, "Move house")
+
+	child, err := tm.AddSubtask(parent.ID, "Pack books", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if child.ParentID != parent.ID {
+		t.Errorf("Expected parent ID %d, got %d", parent.ID, child.ParentID)
+	}
+
+	if _, err := tm.AddSubtask(999, "Orphan", ""); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if _, err := tm.AddTask("Bad parent", "", WithParent(999)); err != ErrParentNotFound {
+		t.Errorf("Expected ErrParentNotFound, got %v", err)
+	}
+
+	children, err := tm.ListChildren(parent.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(children) != 1 || children[0] != child {
+		t.Errorf("Expected the packed books subtask, got %v", children)
+	}
+}
+
+func TestSubtaskCycle(t *testing.T) {
+	tm := NewTaskManager()
+	root := mustAddTask(t, tm, "Root")
+	child := mustAddTask(t, tm, "Child", WithParent(root.ID))
+	grandchild := mustAddTask(t, tm, "Grandchild", WithParent(child.ID))
+
+	tests := []struct {
+		name   string
+		id     int
+		parent int
+	}{
+		{name: "own parent", id: root.ID, parent: root.ID},
+		{name: "descendant as parent", id: root.ID, parent: grandchild.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tm.UpdateTask(tt.id, "Root", "", false, WithParent(tt.parent))
+			if err != ErrCyclicParent {
+				t.Errorf("Expected ErrCyclicParent, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeleteTaskCascade(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           CascadeMode
+		expectedRemain int
+	}{
+		{name: "orphan", mode: CascadeOrphan, expectedRemain: 2},
+		{name: "recursive", mode: CascadeRecursive, expectedRemain: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTaskManager()
+			root := mustAddTask(t, tm, "Root")
+			child := mustAddTask(t, tm, "Child", WithParent(root.ID))
+			mustAddTask(t, tm, "Grandchild", WithParent(child.ID))
+
+			if err := tm.DeleteTaskCascade(root.ID, tt.mode); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			remaining := tm.ListTasks(nil)
+			if len(remaining) != tt.expectedRemain {
+				t.Fatalf("Expected %d remaining tasks, got %d", tt.expectedRemain, len(remaining))
+			}
+			if tt.mode == CascadeOrphan && child.ParentID != 0 {
+				t.Errorf("Expected child to become top-level, got parent %d", child.ParentID)
+			}
+		})
+	}
+}
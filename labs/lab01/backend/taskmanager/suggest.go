@@ -0,0 +1,67 @@
+package taskmanager
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// DefaultSuggestions is the number of titles SuggestTitles returns when the
+// limit is zero or less
+const DefaultSuggestions = 10
+
+// titleKey is the place of a task in the sorted title index
+type titleKey struct {
+	folded string
+	title  string
+	id     int
+}
+
+// newTitleKey returns the title index key of a task
+func newTitleKey(task *Task) titleKey {
+	return titleKey{folded: strings.ToLower(task.Title), title: task.Title, id: task.ID}
+}
+
+// compareTitleKeys orders the title index by folded title, then by ID
+func compareTitleKeys(a, b titleKey) int {
+	if c := strings.Compare(a.folded, b.folded); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// SuggestTitles returns up to limit distinct titles starting with prefix,
+// ignoring case, in alphabetical order, to complete a title as it is typed.
+// Every active task counts, including done and archived ones, so finished
+// chores can be added again quickly. Titles differing only in case are
+// suggested once, spelled as in the oldest task. The titles are kept in a
+// sorted index, so the cost depends on the number of suggestions rather
+// than the number of tasks.
+func (tm *TaskManager) SuggestTitles(prefix string, limit int) []string {
+	prefix = strings.ToLower(strings.TrimLeft(prefix, " \t"))
+	if prefix == "" {
+		return nil
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestions
+	}
+
+	titles := tm.index.titles
+	i, _ := slices.BinarySearchFunc(titles, prefix, func(key titleKey, prefix string) int {
+		return strings.Compare(key.folded, prefix)
+	})
+	var result []string
+	last := ""
+	for ; i < len(titles) && len(result) < limit; i++ {
+		key := titles[i]
+		if !strings.HasPrefix(key.folded, prefix) {
+			break
+		}
+		if len(result) > 0 && key.folded == last {
+			continue
+		}
+		result = append(result, key.title)
+		last = key.folded
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package taskmanager
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSuggestTitles(t *testing.T) {
+	tm := NewTaskManager()
+	for _, title := range []string{"Buy milk", "Pay rent", "buy MILK", "Buy bread", "Book flights", "Build shed"} {
+		mustAddTask(t, tm, title)
+	}
+	done := mustAddTask(t, tm, "Bug triage")
+	if err := tm.Transition(done.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	gone := mustAddTask(t, tm, "Buy tickets")
+	if err := tm.DeleteTask(gone.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		limit    int
+		expected []string
+	}{
+		{name: "prefix", prefix: "bu", expected: []string{"Bug triage", "Build shed", "Buy bread", "Buy milk"}},
+		{name: "ignores case", prefix: "BUY ", expected: []string{"Buy bread", "Buy milk"}},
+		{name: "limit", prefix: "b", limit: 2, expected: []string{"Book flights", "Bug triage"}},
+		{name: "whole title", prefix: "pay rent", expected: []string{"Pay rent"}},
+		{name: "no match", prefix: "zebra", expected: nil},
+		{name: "empty prefix", prefix: "  ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tm.SuggestTitles(tt.prefix, tt.limit); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	task, _ := tm.GetTask(2)
+	if err := tm.UpdateTask(task.ID, "Buy rent stamps", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := tm.SuggestTitles("pay", 0); got != nil {
+		t.Errorf("Expected the old title to be gone after a rename, got %v", got)
+	}
+	if got := tm.SuggestTitles("buy r", 0); !slices.Equal(got, []string{"Buy rent stamps"}) {
+		t.Errorf("Expected the new title to be suggested, got %v", got)
+	}
+}
+
+func TestSuggestTitlesDefaultLimit(t *testing.T) {
+	tm := NewTaskManager()
+	for i := range DefaultSuggestions + 5 {
+		mustAddTask(t, tm, fmt.Sprintf("Task %02d", i))
+	}
+	if got := tm.SuggestTitles("task", 0); len(got) != DefaultSuggestions || got[0] != "Task 00" {
+		t.Errorf("Expected the first %d titles, got %v", DefaultSuggestions, got)
+	}
+}
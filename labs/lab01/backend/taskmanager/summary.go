@@ -0,0 +1,71 @@
+package taskmanager
+
+import "time"
+
+// TaskSummary is the part of a task a list view shows, without the
+// description, comments, history and other bulky fields
+type TaskSummary struct {
+	ID      int
+	Title   string
+	Status  Status
+	DueDate *time.Time
+}
+
+// SummaryPage is one page of task summaries together with the size of the
+// whole listing, as TaskPage is for full tasks
+type SummaryPage struct {
+	Summaries  []TaskSummary
+	Total      int
+	Offset     int
+	Limit      int
+	NextCursor string
+	Stats      *QueryStats
+}
+
+// HasMore reports whether there are tasks after this page
+func (p SummaryPage) HasMore() bool {
+	return p.Offset+len(p.Summaries) < p.Total
+}
+
+// Summary returns the summary of the task. It shares nothing with the task.
+func (t *Task) Summary() TaskSummary {
+	s := TaskSummary{ID: t.ID, Title: t.Title, Status: t.Status}
+	if t.DueDate != nil {
+		due := *t.DueDate
+		s.DueDate = &due
+	}
+	return s
+}
+
+// ListSummaries returns the summaries of the tasks ListTasks would return
+// for the same arguments, in the same order
+func (tm *TaskManager) ListSummaries(filterDone *bool, opts ...ListOption) []TaskSummary {
+	return summarize(tm.list(filterDone, opts...))
+}
+
+// FindSummaries returns the page FindTasks would return for opts as task
+// summaries. Its NextCursor can be passed to FindTasks and the other way
+// round.
+func (tm *TaskManager) FindSummaries(opts ListOptions) (SummaryPage, error) {
+	page, err := tm.findTasks(opts)
+	if err != nil {
+		return SummaryPage{}, err
+	}
+	return SummaryPage{
+		Summaries:  summarize(page.Tasks),
+		Total:      page.Total,
+		Offset:     page.Offset,
+		Limit:      page.Limit,
+		NextCursor: page.NextCursor,
+		Stats:      page.Stats,
+	}, nil
+}
+
+// summarize returns the summaries of tasks, in order
+func summarize(tasks []*Task) []TaskSummary {
+	result := make([]TaskSummary, len(tasks))
+	for i, task := range tasks {
+		result[i] = task.Summary()
+	}
+	return result
+}
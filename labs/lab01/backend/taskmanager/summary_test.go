@@ -0,0 +1,64 @@
+package taskmanager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTaskSummary(t *testing.T) {
+	due := time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC)
+	task := &Task{ID: 3, Title: "Pay rent", Description: "Before the 5th", Status: StatusInProgress, DueDate: &due, Tags: []string{"home"}}
+
+	summary := task.Summary()
+	expected := TaskSummary{ID: 3, Title: "Pay rent", Status: StatusInProgress, DueDate: &due}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, summary)
+	}
+	*summary.DueDate = due.Add(time.Hour)
+	if !task.DueDate.Equal(due) {
+		t.Error("Expected the summary not to share the due date with the task")
+	}
+	if (&Task{ID: 1}).Summary().DueDate != nil {
+		t.Error("Expected no due date in the summary of a task without one")
+	}
+}
+
+func TestListSummaries(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for _, title := range []string{"Pay rent", "Buy milk", "Call mom"} {
+		if _, err := tm.AddTask(title, "A long description"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+	if err := tm.Transition(2, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	open := false
+	expected := []TaskSummary{{ID: 1, Title: "Pay rent", Status: StatusTodo}, {ID: 3, Title: "Call mom", Status: StatusTodo}}
+	if got := tm.ListSummaries(&open); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+
+	page, err := tm.FindSummaries(ListOptions{Sort: SortSpec{{Field: SortTitle}}, Page: Page{Limit: 2}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page.Summaries) != 2 || page.Summaries[0].Title != "Buy milk" || page.Total != 3 || !page.HasMore() {
+		t.Fatalf("Expected the first 2 of 3 summaries by title, got %+v", page)
+	}
+	next, err := tm.FindTasks(ListOptions{Sort: SortSpec{{Field: SortTitle}}, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(next.Tasks) != 1 || next.Tasks[0].Title != "Pay rent" {
+		t.Errorf("Expected the cursor to continue with Pay rent, got %v", titles(next.Tasks))
+	}
+
+	if _, err := tm.FindSummaries(ListOptions{Page: Page{Limit: -1}}); err != ErrInvalidPage {
+		t.Errorf("Expected ErrInvalidPage, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package taskmanager
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithTags replaces the tags of a task. Tags are trimmed, lowercased and
+// deduplicated; empty tags are dropped.
+func WithTags(tags ...string) TaskOption {
+	return func(t *Task) {
+		t.Tags = normalizeTags(tags)
+	}
+}
+
+// HasTag reports whether the task carries the given tag
+func (t *Task) HasTag(tag string) bool {
+	return slices.Contains(t.Tags, normalizeTag(tag))
+}
+
+// hasAnyTag reports whether the task carries at least one of the normalized tags
+func (t *Task) hasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		if slices.Contains(t.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListByTag returns all tasks carrying the given tag. An empty tag, once
+// trimmed, matches no task.
+func (tm *TaskManager) ListByTag(tag string, opts ...ListOption) []*Task {
+	if normalizeTag(tag) == "" {
+		return nil
+	}
+	return tm.ListTasks(nil, append(opts, FilterByAnyTag(tag))...)
+}
+
+// FilterByAllTags limits ListTasks to tasks carrying every one of the given tags
+func FilterByAllTags(tags ...string) ListOption {
+	return func(q *listQuery) {
+		q.allTags = append(q.allTags, normalizeTags(tags)...)
+	}
+}
+
+// FilterByAnyTag limits ListTasks to tasks carrying at least one of the given tags
+func FilterByAnyTag(tags ...string) ListOption {
+	return func(q *listQuery) {
+		q.anyTags = append(q.anyTags, normalizeTags(tags)...)
+	}
+}
+
+// normalizeTag trims and lowercases a single tag
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags normalizes each tag, dropping empty ones and duplicates
+// while keeping the original order
+func normalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || slices.Contains(result, tag) {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
+}
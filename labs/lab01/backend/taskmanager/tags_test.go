@@ -0,0 +1,75 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{name: "no tags", tags: nil, expected: nil},
+		{name: "trim and lowercase", tags: []string{"  Home ", "WORK"}, expected: []string{"home", "work"}},
+		{name: "dedupe", tags: []string{"home", "Home", "HOME "}, expected: []string{"home"}},
+		{name: "drop empty", tags: []string{"", "   ", "errand"}, expected: []string{"errand"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeTags(tt.tags)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestListByTag(t *testing.T) {
+	tm := NewTaskManager()
+	groceries := mustAddTask(t, tm, "Groceries", WithTags("Home", "errand"))
+	mustAddTask(t, tm, "Report", WithTags("work"))
+
+	got := tm.ListByTag(" HOME")
+	if len(got) != 1 || got[0] != groceries {
+		t.Errorf("Expected only the groceries task, got %v", got)
+	}
+	if !groceries.HasTag("Errand") {
+		t.Error("Expected HasTag to match regardless of case")
+	}
+	for _, tag := range []string{"", "  "} {
+		if got := tm.ListByTag(tag); len(got) != 0 {
+			t.Errorf("Expected no tasks for tag %q, got %v", tag, got)
+		}
+	}
+}
+
+func TestListTasksTagFilters(t *testing.T) {
+	tm := NewTaskManager()
+	mustAddTask(t, tm, "Groceries", WithTags("home", "errand"))
+	mustAddTask(t, tm, "Clean", WithTags("home"))
+	mustAddTask(t, tm, "Report", WithTags("work"))
+	mustAddTask(t, tm, "Untagged")
+
+	tests := []struct {
+		name     string
+		opts     []ListOption
+		expected int
+	}{
+		{name: "all tags", opts: []ListOption{FilterByAllTags("home", "errand")}, expected: 1},
+		{name: "any tag", opts: []ListOption{FilterByAnyTag("errand", "work")}, expected: 2},
+		{name: "all and any combined", opts: []ListOption{FilterByAllTags("home"), FilterByAnyTag("errand", "work")}, expected: 1},
+		{name: "unknown tag", opts: []ListOption{FilterByAllTags("garden")}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tm.ListTasks(nil, tt.opts...)
+			if len(got) != tt.expected {
+				t.Errorf("Expected %d tasks, got %d", tt.expected, len(got))
+			}
+		})
+	}
+}
@@ -2,6 +2,11 @@ package taskmanager
 
 import (
 	"errors"
+	"maps"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -16,51 +21,558 @@ var (
 
 // Task represents a single task
 type Task struct {
-	ID          int
-	Title       string
-	Description string
-	Done        bool
-	CreatedAt   time.Time
+	ID           int
+	Title        string
+	Description  string
+	Links        []string
+	Status       Status
+	Priority     Priority
+	Color        string
+	Icon         string
+	DueDate      *time.Time
+	Reminders    []time.Time
+	Location     *Location
+	Tags         []string
+	ParentID     int
+	ProjectID    int
+	Recurrence   *Recurrence
+	DependsOn    []int
+	Related      []TaskLink
+	AssigneeID   string
+	OwnerID      string
+	Visibility   Visibility
+	CustomFields map[string]any
+	Checklist    []ChecklistItem
+	Progress     float64
+	Estimate     time.Duration
+	TrackedTime  time.Duration
+	TimerStarted *time.Time
+	Attachments  []Attachment
+	Comments     []Comment
+	Archived     bool
+	SnoozedUntil *time.Time
+	Pinned       bool
+	Position     int
+	History      []HistoryEntry
+	Version      int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+	DeletedAt    *time.Time
+
+	// partial marks a copy listed with OmitDetails
+	partial bool
 }
 
+// TaskOption sets an optional field on a task being created or updated
+type TaskOption func(*Task)
+
 // TaskManager manages a collection of tasks
 type TaskManager struct {
-	tasks  map[int]*Task
-	nextID int
+	tasks TaskStore
+	trash map[int]*Task
+	ids   IDGenerator
+	now   func() time.Time
+
+	timezone *time.Location
+
+	index *taskIndex
+
+	rand *rand.Rand
+
+	copies bool
+
+	snapshotReads bool
+
+	persister   Persister
+	flushPolicy FlushPolicy
+	// changed holds the IDs of the tasks changed since the last save, when
+	// the persister is an IncrementalPersister
+	changed map[int]struct{}
+
+	listParallelism int
+
+	lastPosition int
+
+	blobs             BlobStore
+	maxAttachmentSize int64
+	nextAttachmentID  int
+
+	maxTasks             int
+	maxTitleLength       int
+	maxDescriptionLength int
+
+	nextCommentID int
+
+	projects      map[int]*Project
+	nextProjectID int
+
+	fields map[string]FieldType
+
+	nextChecklistItemID int
+
+	templates      map[int]*Template
+	nextTemplateID int
+
+	filters map[string]*SavedFilter
+
+	retention RetentionPolicy
+
+	undoDepth int
+	undoStack []*operation
+	redoStack []*operation
+	op        *operation
+
+	ops opCounters
+}
+
+// Option configures a TaskManager
+type Option func(*TaskManager)
+
+// WithClock sets the function used to read the current time
+func WithClock(now func() time.Time) Option {
+	return func(tm *TaskManager) {
+		tm.now = now
+	}
 }
 
 // NewTaskManager creates a new task manager
-func NewTaskManager() *TaskManager {
-	// TODO: Implement task manager initialization
-	return nil
+func NewTaskManager(opts ...Option) *TaskManager {
+	tm := &TaskManager{
+		tasks: NewMemoryStore(),
+		trash: make(map[int]*Task),
+		now:   time.Now,
+
+		timezone: time.Local,
+
+		index: newTaskIndex(),
+
+		rand: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+
+		blobs:             NewMemoryBlobStore(),
+		maxAttachmentSize: DefaultMaxAttachmentSize,
+		nextAttachmentID:  1,
+
+		nextCommentID: 1,
+
+		projects:      make(map[int]*Project),
+		nextProjectID: 1,
+
+		fields: make(map[string]FieldType),
+
+		nextChecklistItemID: 1,
+
+		templates:      make(map[int]*Template),
+		nextTemplateID: 1,
+
+		filters: make(map[string]*SavedFilter),
+
+		undoDepth: DefaultUndoDepth,
+	}
+	for _, opt := range opts {
+		opt(tm)
+	}
+	if tm.ids == nil {
+		tm.ids = tm.tasks
+	}
+	tm.loadStore()
+	return tm
 }
 
 // AddTask adds a new task to the manager
-func (tm *TaskManager) AddTask(title, description string) (*Task, error) {
-	// TODO: Implement task addition
-	return nil, nil
+func (tm *TaskManager) AddTask(title, description string, opts ...TaskOption) (*Task, error) {
+	task, err := tm.addTask(title, description, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tm.export(task), nil
 }
 
-// UpdateTask updates an existing task
-func (tm *TaskManager) UpdateTask(id int, title, description string, done bool) error {
-	// TODO: Implement task update
+// addTask adds a new task and returns the stored task
+func (tm *TaskManager) addTask(title, description string, opts []TaskOption) (*Task, error) {
+	defer tm.beginOperation()()
+
+	task := tm.newTask()
+	initTask(task, title, sanitizeMarkdown(description), tm.now(), opts)
+	if err := tm.validate(task); err != nil {
+		return nil, err
+	}
+	if err := tm.reserve(1); err != nil {
+		return nil, err
+	}
+	tm.insert(task)
+	return task, nil
+}
+
+// initTask makes task a new, unvalidated task created at the given time,
+// with the given title and already sanitized description, the default for
+// every other field, and opts applied
+func initTask(task *Task, title, description string, createdAt time.Time, opts []TaskOption) {
+	*task = Task{
+		Title:       strings.TrimSpace(title),
+		Description: description,
+		Status:      StatusTodo,
+		Priority:    PriorityMedium,
+		Color:       DefaultColor,
+		Icon:        DefaultIcon,
+		Visibility:  VisibilityShared,
+		CreatedAt:   createdAt,
+	}
+	for _, opt := range opts {
+		opt(task)
+	}
+}
+
+// insert assigns the next ID to a validated task and stores it
+func (tm *TaskManager) insert(task *Task) {
+	tm.store(task, tm.ids.NextID())
+	tm.index.put(task)
+	tm.refreshProgress(task.ID)
+}
+
+// store is insert with the given ID and without indexing the task or
+// refreshing the progress of its parents
+func (tm *TaskManager) store(task *Task, id int) {
+	task.ID = id
+	task.Version = 1
+	task.UpdatedAt = task.CreatedAt
+	task.Links = extractLinks(task.Description)
+	tm.lastPosition += positionStep
+	task.Position = tm.lastPosition
+	tm.touch(task.ID)
+	tm.recordCreated(task)
+	tm.tasks.Put(task)
+	tm.ops.added.Add(1)
+}
+
+// UpdateTask updates an existing task. Setting done moves the task to
+// StatusDone; clearing it reopens a done task and leaves other statuses as
+// they are. Pass IfVersion to reject the update if the task changed since
+// the caller read it.
+func (tm *TaskManager) UpdateTask(id int, title, description string, done bool, opts ...TaskOption) error {
+	defer tm.beginOperation()()
+
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	updated := *task
+	updated.Title = strings.TrimSpace(title)
+	updated.Description = sanitizeMarkdown(description)
+	if updated.Status, err = statusAfterUpdate(task.Status, done); err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(&updated)
+	}
+	if updated.Version != task.Version {
+		return ErrConflict
+	}
+	if err := tm.validate(&updated); err != nil {
+		return err
+	}
+	next, err := tm.nextOccurrence(&updated, task.Status, updated.Status)
+	if err != nil {
+		return err
+	}
+
+	previous := task.Status
+	tm.update(task, func() {
+		*task = updated
+		tm.statusChanged(task, previous, next)
+	})
 	return nil
 }
 
-// DeleteTask removes a task from the manager
+// DeleteTask moves a task to the trash, from where it can be restored until
+// the trash is purged. Subtasks of the deleted task are kept and become
+// top-level tasks.
 func (tm *TaskManager) DeleteTask(id int) error {
-	// TODO: Implement task deletion
-	return nil
+	return tm.DeleteTaskCascade(id, CascadeOrphan)
 }
 
 // GetTask retrieves a task by ID
 func (tm *TaskManager) GetTask(id int) (*Task, error) {
-	// TODO: Implement task retrieval
-	return nil, nil
+	tm.ops.lookups.Add(1)
+	task, err := tm.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return tm.export(task), nil
 }
 
-// ListTasks returns all tasks, optionally filtered by done status
-func (tm *TaskManager) ListTasks(filterDone *bool) []*Task {
-	// TODO: Implement task listing with optional filter
-	return nil
+// lookup returns the stored task with the given ID
+func (tm *TaskManager) lookup(id int) (*Task, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+	task, ok := tm.tasks.Get(id)
+	if !ok || tm.expired(task) {
+		return nil, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// ListTasks returns all tasks that are neither archived nor snoozed,
+// optionally filtered by done status. Additional filters and sort orders
+// can be supplied as list options; FindTasks takes the same filters as a
+// single ListOptions value. Tasks streams the same listing.
+func (tm *TaskManager) ListTasks(filterDone *bool, opts ...ListOption) []*Task {
+	q := tm.newQuery(filterDone, opts)
+	return tm.exportListing(tm.matchingInto([]*Task{}, q), q.omitDetails)
+}
+
+// list returns the stored tasks ListTasks would return copies of
+func (tm *TaskManager) list(filterDone *bool, opts ...ListOption) []*Task {
+	return tm.matchingInto([]*Task{}, tm.newQuery(filterDone, opts))
+}
+
+// newQuery collects the filters and sort order of a listing
+func (tm *TaskManager) newQuery(filterDone *bool, opts []ListOption) *listQuery {
+	q := &listQuery{done: filterDone, now: tm.now()}
+	if tm.retention.EvictAfter > 0 {
+		q.evictBefore = q.now.Add(-tm.retention.EvictAfter)
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// validate checks a task and its references to other tasks before it is stored
+func (tm *TaskManager) validate(task *Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	if err := tm.validateLengths(task); err != nil {
+		return err
+	}
+	if err := tm.validateProject(task); err != nil {
+		return err
+	}
+	if err := tm.validateCustomFields(task); err != nil {
+		return err
+	}
+	return tm.validateParent(task)
+}
+
+// validateTask checks the fields of a task before it is stored
+func validateTask(task *Task) error {
+	if task.Title == "" {
+		return ErrEmptyTitle
+	}
+	if !task.Status.Valid() {
+		return ErrInvalidStatus
+	}
+	if !task.Priority.Valid() {
+		return ErrInvalidPriority
+	}
+	if err := validateAppearance(task); err != nil {
+		return err
+	}
+	if task.Recurrence != nil {
+		if err := task.Recurrence.Validate(); err != nil {
+			return err
+		}
+	}
+	if task.Estimate < 0 {
+		return ErrInvalidEstimate
+	}
+	if task.Location != nil {
+		if err := task.Location.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateVisibility(task); err != nil {
+		return err
+	}
+	return validateReminders(task)
+}
+
+// clone returns a deep copy of the task
+func (t *Task) clone() *Task {
+	c := *t
+	if t.DueDate != nil {
+		due := *t.DueDate
+		c.DueDate = &due
+	}
+	if t.Location != nil {
+		loc := *t.Location
+		c.Location = &loc
+	}
+	if t.Recurrence != nil {
+		r := *t.Recurrence
+		r.Weekdays = slices.Clone(r.Weekdays)
+		c.Recurrence = &r
+	}
+	if t.TimerStarted != nil {
+		started := *t.TimerStarted
+		c.TimerStarted = &started
+	}
+	if t.SnoozedUntil != nil {
+		until := *t.SnoozedUntil
+		c.SnoozedUntil = &until
+	}
+	if t.CompletedAt != nil {
+		completed := *t.CompletedAt
+		c.CompletedAt = &completed
+	}
+	if t.DeletedAt != nil {
+		deleted := *t.DeletedAt
+		c.DeletedAt = &deleted
+	}
+	c.Reminders = slices.Clone(t.Reminders)
+	c.Tags = slices.Clone(t.Tags)
+	c.CustomFields = maps.Clone(t.CustomFields)
+	c.DependsOn = slices.Clone(t.DependsOn)
+	c.Links = slices.Clone(t.Links)
+	c.Related = slices.Clone(t.Related)
+	c.Checklist = slices.Clone(t.Checklist)
+	c.Attachments = slices.Clone(t.Attachments)
+	c.Comments = slices.Clone(t.Comments)
+	c.History = slices.Clone(t.History)
+	return &c
+}
+
+// ListOption configures filtering and ordering for ListTasks
+type ListOption func(*listQuery)
+
+// where limits a listing to tasks matching keep. It backs the specialised
+// List methods so they honour the same options as ListTasks.
+func where(keep func(*Task) bool) ListOption {
+	return func(q *listQuery) {
+		if prev := q.keep; prev != nil {
+			q.keep = func(task *Task) bool { return prev(task) && keep(task) }
+			return
+		}
+		q.keep = keep
+	}
+}
+
+// listQuery holds the filters and sort order collected from list options
+type listQuery struct {
+	now            time.Time
+	omitDetails    bool
+	evictBefore    time.Time
+	done           *bool
+	statuses       map[Status]bool
+	priorities     map[Priority]bool
+	allTags        []string
+	anyTags        []string
+	assignee       *string
+	viewer         *string
+	project        *int
+	customFields   map[string]any
+	due            *TimeRange
+	created        *TimeRange
+	completed      *TimeRange
+	text           string
+	pattern        *regexp.Regexp
+	archived       archiveFilter
+	includeSnoozed bool
+	keep           func(*Task) bool
+	pinnedFirst    bool
+	sort           SortSpec
+	sortByPriority bool
+	sortByPosition bool
+	sortByDueDate  bool
+	sortBySnooze   bool
+	stats          *QueryStats
+}
+
+// matches reports whether the task passes every filter in the query
+func (q *listQuery) matches(task *Task) bool {
+	if !q.evictBefore.IsZero() && task.closedBefore(q.evictBefore) {
+		return false
+	}
+	if !q.archived.matches(task) {
+		return false
+	}
+	if !q.includeSnoozed && task.IsSnoozed(q.now) {
+		return false
+	}
+	if q.done != nil && task.IsDone() != *q.done {
+		return false
+	}
+	if len(q.statuses) > 0 && !q.statuses[task.Status] {
+		return false
+	}
+	if len(q.priorities) > 0 && !q.priorities[task.Priority] {
+		return false
+	}
+	for _, tag := range q.allTags {
+		if !task.HasTag(tag) {
+			return false
+		}
+	}
+	if len(q.anyTags) > 0 && !task.hasAnyTag(q.anyTags) {
+		return false
+	}
+	if q.assignee != nil && task.AssigneeID != *q.assignee {
+		return false
+	}
+	if q.viewer != nil && !task.VisibleTo(*q.viewer) {
+		return false
+	}
+	if q.project != nil && task.ProjectID != *q.project {
+		return false
+	}
+	for name, value := range q.customFields {
+		if !customFieldEqual(task.CustomFields[name], value) {
+			return false
+		}
+	}
+	if q.due != nil && (task.DueDate == nil || !q.due.Contains(*task.DueDate)) {
+		return false
+	}
+	if q.created != nil && !q.created.Contains(task.CreatedAt) {
+		return false
+	}
+	if q.completed != nil && (task.CompletedAt == nil || !q.completed.Contains(*task.CompletedAt)) {
+		return false
+	}
+	if q.text != "" && !task.containsText(q.text) {
+		return false
+	}
+	if q.pattern != nil && !task.matchesPattern(q.pattern) {
+		return false
+	}
+	return q.keep == nil || q.keep(task)
+}
+
+// defaultOrder reports whether the query lists tasks in the order of
+// creation time and ID
+func (q *listQuery) defaultOrder() bool {
+	return !q.pinnedFirst && len(q.sort) == 0 && !q.sortByPriority &&
+		!q.sortByPosition && !q.sortByDueDate && !q.sortBySnooze
+}
+
+// less orders tasks by creation time, or by pinned flag, sort spec,
+// priority, manual position, due date and snooze end first when requested.
+// Tasks created at the same moment are ordered by ID, so no two tasks ever
+// tie and a listing comes out the same on every call.
+func (q *listQuery) less(a, b *Task) bool {
+	if q.pinnedFirst && a.Pinned != b.Pinned {
+		return a.Pinned
+	}
+	if c := q.sort.compare(a, b); c != 0 {
+		return c < 0
+	}
+	if q.sortByPriority && a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if q.sortByPosition && a.Position != b.Position {
+		return a.Position < b.Position
+	}
+	if q.sortByDueDate && !a.DueDate.Equal(*b.DueDate) {
+		return a.DueDate.Before(*b.DueDate)
+	}
+	if q.sortBySnooze && !a.SnoozedUntil.Equal(*b.SnoozedUntil) {
+		return a.SnoozedUntil.Before(*b.SnoozedUntil)
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
 }
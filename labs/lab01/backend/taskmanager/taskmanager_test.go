@@ -12,8 +12,8 @@ func TestNewTaskManager(t *testing.T) {
 	if tm.tasks == nil {
 		t.Error("tasks map is nil")
 	}
-	if tm.nextID != 1 {
-		t.Errorf("Expected nextID to be 1, got %d", tm.nextID)
+	if id := tm.ids.NextID(); id != 1 {
+		t.Errorf("Expected the first ID to be 1, got %d", id)
 	}
 }
 
@@ -64,8 +64,8 @@ func TestAddTask(t *testing.T) {
 			if task.Description != tt.description {
 				t.Errorf("Expected description %s, got %s", tt.description, task.Description)
 			}
-			if task.Done {
-				t.Error("New task should not be marked as done")
+			if task.Status != StatusTodo {
+				t.Errorf("New task should have status %v, got %v", StatusTodo, task.Status)
 			}
 			if task.CreatedAt.IsZero() {
 				t.Error("CreatedAt should not be zero")
@@ -194,8 +194,8 @@ func TestUpdateTask(t *testing.T) {
 			if updatedTask.Description != tt.description {
 				t.Errorf("Expected description %s, got %s", tt.description, updatedTask.Description)
 			}
-			if updatedTask.Done != tt.done {
-				t.Errorf("Expected done %v, got %v", tt.done, updatedTask.Done)
+			if updatedTask.IsDone() != tt.done {
+				t.Errorf("Expected done %v, got %v", tt.done, updatedTask.IsDone())
 			}
 		})
 	}
@@ -252,3 +252,21 @@ func TestDeleteTask(t *testing.T) {
 		})
 	}
 }
+
+// mustAddTask adds a task or fails the test
+func mustAddTask(t testing.TB, tm *TaskManager, title string, opts ...TaskOption) *Task {
+	t.Helper()
+	task, err := tm.AddTask(title, "", opts...)
+	if err != nil {
+		t.Fatalf("Failed to add task %q: %v", title, err)
+	}
+	return task
+}
+
+func titles(tasks []*Task) []string {
+	result := make([]string, len(tasks))
+	for i, task := range tasks {
+		result[i] = task.Title
+	}
+	return result
+}
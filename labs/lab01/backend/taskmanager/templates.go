@@ -0,0 +1,165 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTemplateNotFound is returned when a template is not found
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrEmptyTemplateName is returned when a template has no name
+	ErrEmptyTemplateName = errors.New("template name cannot be empty")
+	// ErrMissingTemplateVar is returned when a title pattern uses a variable that was not supplied
+	ErrMissingTemplateVar = errors.New("missing template variable")
+)
+
+// Template is a reusable blueprint for creating tasks. TitlePattern may
+// contain {name} placeholders filled from TemplateOverrides.Vars; {date}
+// is replaced by the current date when not supplied.
+type Template struct {
+	ID           int
+	Name         string
+	TitlePattern string
+	Description  string
+	Priority     Priority
+	Tags         []string
+	Checklist    []string
+	Estimate     time.Duration
+}
+
+// TemplateOverrides customizes a task created from a template
+type TemplateOverrides struct {
+	// Title replaces the rendered title pattern when non-empty
+	Title string
+	// Description replaces the template description when non-empty
+	Description string
+	// Vars fills the placeholders of the title pattern
+	Vars map[string]string
+	// Options are applied after the template's own fields
+	Options []TaskOption
+}
+
+var templateVar = regexp.MustCompile(`\{(\w+)\}`)
+
+// CreateTemplate stores a new template
+func (tm *TaskManager) CreateTemplate(t Template) (*Template, error) {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return nil, ErrEmptyTemplateName
+	}
+	if strings.TrimSpace(t.TitlePattern) == "" {
+		return nil, ErrEmptyTitle
+	}
+	if t.Priority == 0 {
+		t.Priority = PriorityMedium
+	}
+	if !t.Priority.Valid() {
+		return nil, ErrInvalidPriority
+	}
+	if t.Estimate < 0 {
+		return nil, ErrInvalidEstimate
+	}
+	t.Tags = normalizeTags(t.Tags)
+	t.Checklist = slices.Clone(t.Checklist)
+
+	t.ID = tm.nextTemplateID
+	tm.nextTemplateID++
+	tm.templates[t.ID] = &t
+	return &t, nil
+}
+
+// GetTemplate retrieves a template by ID
+func (tm *TaskManager) GetTemplate(id int) (*Template, error) {
+	t, ok := tm.templates[id]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+	return t, nil
+}
+
+// ListTemplates returns all templates sorted by name
+func (tm *TaskManager) ListTemplates() []*Template {
+	result := make([]*Template, 0, len(tm.templates))
+	for _, t := range tm.templates {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// clone returns a copy of the template that shares no memory with t
+func (t *Template) clone() *Template {
+	c := *t
+	c.Tags = slices.Clone(t.Tags)
+	c.Checklist = slices.Clone(t.Checklist)
+	return &c
+}
+
+// DeleteTemplate removes a template. Tasks created from it are not affected.
+func (tm *TaskManager) DeleteTemplate(id int) error {
+	if _, err := tm.GetTemplate(id); err != nil {
+		return err
+	}
+	delete(tm.templates, id)
+	return nil
+}
+
+// CreateFromTemplate adds a task built from a template, including its checklist
+func (tm *TaskManager) CreateFromTemplate(templateID int, overrides TemplateOverrides) (*Task, error) {
+	defer tm.beginOperation()()
+
+	t, err := tm.GetTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+	title := overrides.Title
+	if title == "" {
+		if title, err = tm.renderTitle(t.TitlePattern, overrides.Vars); err != nil {
+			return nil, err
+		}
+	}
+	description := t.Description
+	if overrides.Description != "" {
+		description = overrides.Description
+	}
+
+	opts := []TaskOption{WithPriority(t.Priority), WithTags(t.Tags...), WithEstimate(t.Estimate)}
+	task, err := tm.addTask(title, description, append(opts, overrides.Options...))
+	if err != nil {
+		return nil, err
+	}
+	for _, text := range t.Checklist {
+		if _, err := tm.AddChecklistItem(task.ID, text); err != nil {
+			return nil, err
+		}
+	}
+	return tm.export(task), nil
+}
+
+// renderTitle fills the placeholders of a title pattern
+func (tm *TaskManager) renderTitle(pattern string, vars map[string]string) (string, error) {
+	var missing []string
+	title := templateVar.ReplaceAllStringFunc(pattern, func(match string) string {
+		name := match[1 : len(match)-1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if name == "date" {
+			return tm.now().Format("2006-01-02")
+		}
+		missing = append(missing, name)
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingTemplateVar, strings.Join(missing, ", "))
+	}
+	return title, nil
+}
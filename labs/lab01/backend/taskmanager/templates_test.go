@@ -0,0 +1,118 @@
+package taskmanager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateTemplate(t *testing.T) {
+	tm := NewTaskManager()
+	tests := []struct {
+		name        string
+		template    Template
+		expectError error
+	}{
+		{name: "valid template", template: Template{Name: "Weekly report", TitlePattern: "Report for {date}"}},
+		{name: "missing name", template: Template{TitlePattern: "Report"}, expectError: ErrEmptyTemplateName},
+		{name: "missing title", template: Template{Name: "Empty"}, expectError: ErrEmptyTitle},
+		{name: "bad priority", template: Template{Name: "Bad", TitlePattern: "Bad", Priority: 42}, expectError: ErrInvalidPriority},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := tm.CreateTemplate(tt.template)
+
+			if tt.expectError != nil {
+				if err != tt.expectError {
+					t.Errorf("Expected %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if template.Priority != PriorityMedium {
+				t.Errorf("Expected default priority, got %v", template.Priority)
+			}
+		})
+	}
+
+	if got := tm.ListTemplates(); len(got) != 1 {
+		t.Errorf("Expected 1 template, got %d", len(got))
+	}
+}
+
+func TestCreateFromTemplate(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	template, err := tm.CreateTemplate(Template{
+		Name:         "Lab submission",
+		TitlePattern: "Submit {lab} ({date})",
+		Description:  "Push the branch and open a PR",
+		Priority:     PriorityHigh,
+		Tags:         []string{"School"},
+		Checklist:    []string{"Run tests", "Open PR"},
+		Estimate:     time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	task, err := tm.CreateFromTemplate(template.ID, TemplateOverrides{
+		Vars:    map[string]string{"lab": "lab01"},
+		Options: []TaskOption{WithPriority(PriorityUrgent)},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Title != "Submit lab01 (2025-07-01)" {
+		t.Errorf("Expected rendered title, got %q", task.Title)
+	}
+	if task.Description != template.Description || !task.HasTag("school") || task.Estimate != time.Hour {
+		t.Errorf("Expected template fields to be copied, got %+v", task)
+	}
+	if task.Priority != PriorityUrgent {
+		t.Errorf("Expected override priority, got %v", task.Priority)
+	}
+	if len(task.Checklist) != 2 || task.Checklist[1].Text != "Open PR" {
+		t.Errorf("Expected template checklist, got %+v", task.Checklist)
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tm.ListTasks(nil)) != 0 {
+		t.Error("Expected a single undo to remove the created task")
+	}
+}
+
+func TestCreateFromTemplateErrors(t *testing.T) {
+	tm := NewTaskManager()
+	template, err := tm.CreateTemplate(Template{Name: "Lab", TitlePattern: "Submit {lab}"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := tm.CreateFromTemplate(999, TemplateOverrides{}); err != ErrTemplateNotFound {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+	if _, err := tm.CreateFromTemplate(template.ID, TemplateOverrides{}); !errors.Is(err, ErrMissingTemplateVar) {
+		t.Errorf("Expected ErrMissingTemplateVar, got %v", err)
+	}
+	task, err := tm.CreateFromTemplate(template.ID, TemplateOverrides{Title: "Custom"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Title != "Custom" {
+		t.Errorf("Expected title override, got %q", task.Title)
+	}
+
+	if err := tm.DeleteTemplate(template.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.GetTemplate(template.ID); err != ErrTemplateNotFound {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package taskmanager
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTimerRunning is returned when starting a timer that is already running
+	ErrTimerRunning = errors.New("timer already running")
+	// ErrTimerNotRunning is returned when stopping a timer that is not running
+	ErrTimerNotRunning = errors.New("timer not running")
+)
+
+// TimeReportEntry is the tracked time of a single task
+type TimeReportEntry struct {
+	TaskID  int
+	Title   string
+	Tracked time.Duration
+	Running bool
+}
+
+// TimeReport summarizes tracked time over a set of tasks
+type TimeReport struct {
+	Entries []TimeReportEntry
+	Total   time.Duration
+}
+
+// StartTimer starts tracking time on a task
+func (tm *TaskManager) StartTimer(id int) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if task.TimerStarted != nil {
+		return ErrTimerRunning
+	}
+	tm.update(task, func() {
+		now := tm.now()
+		task.TimerStarted = &now
+	})
+	return nil
+}
+
+// StopTimer stops the running timer on a task and adds the elapsed time to
+// its tracked total. Closing a task stops its timer as well.
+func (tm *TaskManager) StopTimer(id int) error {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return err
+	}
+	if task.TimerStarted == nil {
+		return ErrTimerNotRunning
+	}
+	tm.update(task, func() {
+		tm.stopTimer(task)
+	})
+	return nil
+}
+
+// TrackedTime returns the total time tracked on a task, including a timer
+// that is still running
+func (tm *TaskManager) TrackedTime(id int) (time.Duration, error) {
+	task, err := tm.lookup(id)
+	if err != nil {
+		return 0, err
+	}
+	return task.trackedAt(tm.now()), nil
+}
+
+// TimeReport returns the tracked time of every task matching the list
+// options that has any time tracked, snoozed ones included, in ListTasks
+// order
+func (tm *TaskManager) TimeReport(opts ...ListOption) TimeReport {
+	var report TimeReport
+	now := tm.now()
+	for _, task := range tm.list(nil, append([]ListOption{IncludeSnoozed()}, opts...)...) {
+		tracked := task.trackedAt(now)
+		if tracked == 0 && task.TimerStarted == nil {
+			continue
+		}
+		report.Entries = append(report.Entries, TimeReportEntry{
+			TaskID:  task.ID,
+			Title:   task.Title,
+			Tracked: tracked,
+			Running: task.TimerStarted != nil,
+		})
+		report.Total += tracked
+	}
+	return report
+}
+
+// trackedAt returns the tracked time as of now
+func (t *Task) trackedAt(now time.Time) time.Duration {
+	if t.TimerStarted == nil {
+		return t.TrackedTime
+	}
+	return t.TrackedTime + now.Sub(*t.TimerStarted)
+}
+
+// stopTimer folds a running timer into the tracked total
+func (tm *TaskManager) stopTimer(task *Task) {
+	if task.TimerStarted == nil {
+		return
+	}
+	task.TrackedTime = task.trackedAt(tm.now())
+	task.TimerStarted = nil
+}
@@ -0,0 +1,91 @@
+package taskmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartStopTimer(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Essay")
+
+	if err := tm.StopTimer(task.ID); err != ErrTimerNotRunning {
+		t.Errorf("Expected ErrTimerNotRunning, got %v", err)
+	}
+	if err := tm.StartTimer(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.StartTimer(task.ID); err != ErrTimerRunning {
+		t.Errorf("Expected ErrTimerRunning, got %v", err)
+	}
+
+	now = now.Add(25 * time.Minute)
+	if tracked, _ := tm.TrackedTime(task.ID); tracked != 25*time.Minute {
+		t.Errorf("Expected running timer to count, got %v", tracked)
+	}
+	if err := tm.StopTimer(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.StartTimer(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(5 * time.Minute)
+	if err := tm.StopTimer(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.TrackedTime != 30*time.Minute {
+		t.Errorf("Expected 30m tracked, got %v", task.TrackedTime)
+	}
+}
+
+func TestCompletingStopsTimer(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	task := mustAddTask(t, tm, "Essay")
+	if err := tm.StartTimer(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if err := tm.Transition(task.ID, StatusDone); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.TimerStarted != nil || task.TrackedTime != time.Hour {
+		t.Errorf("Expected timer to stop with 1h tracked, got %v running=%v", task.TrackedTime, task.TimerStarted != nil)
+	}
+}
+
+func TestTimeReport(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	essay := mustAddTask(t, tm, "Essay", WithTags("school"))
+	lab := mustAddTask(t, tm, "Lab", WithTags("school"))
+	mustAddTask(t, tm, "Untracked", WithTags("school"))
+	chores := mustAddTask(t, tm, "Chores", WithTags("home"))
+
+	for _, id := range []int{essay.ID, lab.ID, chores.ID} {
+		if err := tm.StartTimer(id); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	now = now.Add(time.Hour)
+	if err := tm.StopTimer(essay.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(30 * time.Minute)
+
+	report := tm.TimeReport(FilterByAllTags("school"))
+	if len(report.Entries) != 2 {
+		t.Fatalf("Expected 2 tracked school tasks, got %+v", report.Entries)
+	}
+	if report.Total != time.Hour+90*time.Minute {
+		t.Errorf("Expected 2h30m total, got %v", report.Total)
+	}
+	for _, entry := range report.Entries {
+		if entry.TaskID == lab.ID && !entry.Running {
+			t.Error("Expected the lab timer to be reported as running")
+		}
+	}
+}
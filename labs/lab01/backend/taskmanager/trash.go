@@ -0,0 +1,86 @@
+package taskmanager
+
+import "sort"
+
+// RestoreTask moves a task out of the trash. References to a parent or
+// project that no longer exist are cleared.
+func (tm *TaskManager) RestoreTask(id int) error {
+	task, ok := tm.trash[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	tm.update(task, func() {
+		if _, ok := tm.tasks.Get(task.ParentID); !ok {
+			task.ParentID = 0
+		}
+		if _, ok := tm.projects[task.ProjectID]; !ok {
+			task.ProjectID = 0
+		}
+		task.DeletedAt = nil
+	})
+	delete(tm.trash, id)
+	tm.tasks.Put(task)
+	tm.index.put(task)
+	tm.refreshProgress(id)
+	tm.ops.restored.Add(1)
+	return nil
+}
+
+// ListTrash returns deleted tasks, most recently deleted first, and by ID
+// among tasks deleted at the same time
+func (tm *TaskManager) ListTrash() []*Task {
+	result := make([]*Task, 0, len(tm.trash))
+	for _, task := range tm.trash {
+		result = append(result, task)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].DeletedAt.Equal(*result[j].DeletedAt) {
+			return result[i].DeletedAt.After(*result[j].DeletedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return tm.exportAll(result)
+}
+
+// PurgeTrash permanently removes every task in the trash and returns how
+// many were removed. Purging cannot be undone and clears the undo history.
+// Without WithDefensiveCopies, tasks callers still hold of the purged ones
+// are cleared to their zero value.
+func (tm *TaskManager) PurgeTrash() int {
+	tm.clearUndo()
+	count := len(tm.trash)
+	for _, task := range tm.trash {
+		tm.purgeTask(task)
+	}
+	return count
+}
+
+// trashTask moves a task from the active set into the trash
+func (tm *TaskManager) trashTask(task *Task) {
+	tm.update(task, func() {
+		now := tm.now()
+		task.DeletedAt = &now
+	})
+	tm.tasks.Delete(task.ID)
+	tm.index.remove(task.ID)
+	tm.trash[task.ID] = task
+	tm.refreshProgress(task.ParentID)
+	tm.ops.deleted.Add(1)
+}
+
+// purgeTask permanently removes a trashed task and drops every reference to it
+func (tm *TaskManager) purgeTask(task *Task) {
+	delete(tm.trash, task.ID)
+	tm.removeDependents(task.ID)
+	tm.removeRelated(task.ID)
+	tm.releaseAttachments(task)
+	forgetTask(task)
+	tm.ops.purged.Add(1)
+}
+
+// forgetTask clears the struct of a task removed for good. It may share a
+// chunk or slab with tasks still stored, which would otherwise keep its
+// title, description and history reachable along with them.
+func forgetTask(task *Task) {
+	*task = Task{}
+}
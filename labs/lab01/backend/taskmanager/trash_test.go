@@ -0,0 +1,125 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeleteAndRestoreTask(t *testing.T) {
+	tm := NewTaskManager()
+	project, _ := tm.CreateProject("Home")
+	parent := mustAddTask(t, tm, "Parent")
+	task := mustAddTask(t, tm, "Child", WithParent(parent.ID), WithProject(project.ID))
+
+	if err := tm.DeleteTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.GetTask(task.ID); err != ErrTaskNotFound {
+		t.Errorf("Expected deleted task to be hidden, got %v", err)
+	}
+	if task.DeletedAt == nil {
+		t.Error("Expected DeletedAt to be set")
+	}
+	if trash := tm.ListTrash(); len(trash) != 1 || trash[0] != task {
+		t.Errorf("Expected the deleted task in the trash, got %v", trash)
+	}
+
+	if err := tm.RestoreTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.GetTask(task.ID); err != nil {
+		t.Errorf("Expected restored task to be visible, got %v", err)
+	}
+	if task.DeletedAt != nil || task.ParentID != parent.ID || task.ProjectID != project.ID {
+		t.Errorf("Expected restored task to keep its references, got %+v", task)
+	}
+	if err := tm.RestoreTask(task.ID); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound restoring twice, got %v", err)
+	}
+}
+
+func TestRestoreClearsMissingReferences(t *testing.T) {
+	tm := NewTaskManager()
+	project, _ := tm.CreateProject("Home")
+	parent := mustAddTask(t, tm, "Parent")
+	task := mustAddTask(t, tm, "Child", WithParent(parent.ID), WithProject(project.ID))
+
+	if err := tm.DeleteTaskCascade(parent.ID, CascadeRecursive); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.DeleteProject(project.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.RestoreTask(task.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.ParentID != 0 || task.ProjectID != 0 {
+		t.Errorf("Expected missing parent and project to be cleared, got %+v", task)
+	}
+}
+
+func TestListTrashOrder(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	for range 10 {
+		mustAddTask(t, tm, "Task")
+	}
+	for _, id := range []int{7, 2, 9, 4, 1} {
+		tm.DeleteTask(id)
+	}
+	now = now.Add(time.Minute)
+	tm.DeleteTask(5)
+
+	// Tasks deleted at the same time come by ID, whatever the order of
+	// the trash map
+	want := []int{5, 1, 2, 4, 7, 9}
+	for range 5 {
+		var got []int
+		for _, task := range tm.ListTrash() {
+			got = append(got, task.ID)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	first := mustAddTask(t, tm, "First")
+	second := mustAddTask(t, tm, "Second")
+	kept := mustAddTask(t, tm, "Kept")
+
+	if err := tm.DeleteTask(first.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := tm.DeleteTask(second.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if trash := tm.ListTrash(); len(trash) != 2 || trash[0] != second {
+		t.Errorf("Expected most recently deleted first, got %v", trash)
+	}
+
+	firstID := first.ID
+	if purged := tm.PurgeTrash(); purged != 2 {
+		t.Errorf("Expected 2 purged tasks, got %d", purged)
+	}
+	if trash := tm.ListTrash(); len(trash) != 0 {
+		t.Errorf("Expected empty trash, got %v", trash)
+	}
+	if err := tm.RestoreTask(firstID); err != ErrTaskNotFound {
+		t.Errorf("Expected purged task to be gone, got %v", err)
+	}
+	// A purged task shares its chunk with the kept one, so nothing of it
+	// may stay reachable through it
+	if first.ID != 0 || first.Title != "" || first.History != nil {
+		t.Errorf("Expected the purged task cleared, got %+v", first)
+	}
+	if got := tm.ListTasks(nil); len(got) != 1 || got[0] != kept {
+		t.Errorf("Expected only the kept task, got %v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package taskmanager
+
+import "errors"
+
+// DefaultUndoDepth is the number of operations kept for undo unless
+// WithUndoDepth says otherwise
+const DefaultUndoDepth = 20
+
+var (
+	// ErrNothingToUndo is returned by Undo when there is no operation to revert
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo is returned by Redo when there is no undone operation to reapply
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// taskState is a snapshot of a task and where it was stored. A nil task
+// means the task did not exist.
+type taskState struct {
+	task    *Task
+	trashed bool
+}
+
+// operation holds the states of every task touched by one add, update or
+// delete, before and after it ran
+type operation struct {
+	ids    []int
+	before map[int]taskState
+	after  map[int]taskState
+}
+
+// WithUndoDepth sets how many operations Undo can revert. Zero disables undo.
+func WithUndoDepth(depth int) Option {
+	return func(tm *TaskManager) {
+		tm.undoDepth = max(depth, 0)
+	}
+}
+
+// Undo reverts the most recent add, update or delete
+func (tm *TaskManager) Undo() error {
+	if len(tm.undoStack) == 0 {
+		return ErrNothingToUndo
+	}
+	op := tm.undoStack[len(tm.undoStack)-1]
+	tm.undoStack = tm.undoStack[:len(tm.undoStack)-1]
+	tm.applyStates(op.ids, op.before)
+	tm.redoStack = append(tm.redoStack, op)
+	tm.ops.undone.Add(1)
+	return nil
+}
+
+// Redo reapplies the most recently undone operation. Any new change made
+// after an undo discards the operations that could be redone.
+func (tm *TaskManager) Redo() error {
+	if len(tm.redoStack) == 0 {
+		return ErrNothingToRedo
+	}
+	op := tm.redoStack[len(tm.redoStack)-1]
+	tm.redoStack = tm.redoStack[:len(tm.redoStack)-1]
+	tm.applyStates(op.ids, op.after)
+	tm.undoStack = append(tm.undoStack, op)
+	tm.ops.redone.Add(1)
+	return nil
+}
+
+// CanUndo reports whether Undo has an operation to revert
+func (tm *TaskManager) CanUndo() bool {
+	return len(tm.undoStack) > 0
+}
+
+// CanRedo reports whether Redo has an operation to reapply
+func (tm *TaskManager) CanRedo() bool {
+	return len(tm.redoStack) > 0
+}
+
+// beginOperation starts recording an undoable operation and returns the
+// function that finishes it, meant to be deferred. Operations started while
+// another one runs become part of the outer one.
+func (tm *TaskManager) beginOperation() func() {
+	if tm.op != nil || tm.undoDepth == 0 {
+		return func() {}
+	}
+	op := &operation{before: make(map[int]taskState)}
+	tm.op = op
+	return func() {
+		tm.op = nil
+		if len(op.ids) == 0 {
+			return
+		}
+		op.after = make(map[int]taskState, len(op.ids))
+		for _, id := range op.ids {
+			op.after[id] = tm.stateOf(id)
+		}
+		tm.undoStack = append(tm.undoStack, op)
+		if len(tm.undoStack) > tm.undoDepth {
+			tm.undoStack = tm.undoStack[1:]
+		}
+		tm.redoStack = nil
+	}
+}
+
+// touch must be called before a task is changed. Inside an operation it
+// remembers the task's previous state; outside one it invalidates redo.
+func (tm *TaskManager) touch(id int) {
+	tm.markChanged(id)
+	if tm.op == nil {
+		tm.redoStack = nil
+		return
+	}
+	if _, ok := tm.op.before[id]; ok {
+		return
+	}
+	tm.op.ids = append(tm.op.ids, id)
+	tm.op.before[id] = tm.stateOf(id)
+}
+
+// clearUndo forgets every recorded operation
+func (tm *TaskManager) clearUndo() {
+	tm.undoStack = nil
+	tm.redoStack = nil
+}
+
+// stateOf snapshots a task and where it is stored
+func (tm *TaskManager) stateOf(id int) taskState {
+	if task, ok := tm.tasks.Get(id); ok {
+		return taskState{task: task.clone()}
+	}
+	if task, ok := tm.trash[id]; ok {
+		return taskState{task: task.clone(), trashed: true}
+	}
+	return taskState{}
+}
+
+// applyStates puts each task back into a recorded state. Existing tasks are
+// updated in place so pointers held by callers stay valid; comments and
+// history are kept, and the restored fields are recorded as new changes.
+func (tm *TaskManager) applyStates(ids []int, states map[int]taskState) {
+	var parents []int
+	for _, id := range ids {
+		state := states[id]
+		current, ok := tm.tasks.Get(id)
+		if !ok {
+			current = tm.trash[id]
+		}
+		if current != nil {
+			parents = append(parents, current.ParentID)
+		}
+		tm.markChanged(id)
+		tm.tasks.Delete(id)
+		delete(tm.trash, id)
+		tm.index.remove(id)
+		if state.task == nil {
+			continue
+		}
+
+		task := state.task.clone()
+		if current != nil {
+			before := current.clone()
+			task.Comments = current.Comments
+			task.History = current.History
+			task.UpdatedAt = current.UpdatedAt
+			task.Version = current.Version
+			if tm.recordChanges(before, task) {
+				tm.fieldsChanged(task)
+			}
+			*current = *task
+			task = current
+		}
+		if state.trashed {
+			tm.trash[id] = task
+		} else {
+			tm.tasks.Put(task)
+			tm.index.put(task)
+		}
+	}
+	for _, id := range append(ids, parents...) {
+		tm.refreshProgress(id)
+	}
+}
@@ -0,0 +1,113 @@
+package taskmanager
+
+import (
+	"testing"
+)
+
+func TestUndoRedoAdd(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Task")
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.GetTask(task.ID); err != ErrTaskNotFound {
+		t.Errorf("Expected undone task to be gone, got %v", err)
+	}
+
+	if err := tm.Redo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := tm.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("Expected redone task to be back, got %v", err)
+	}
+	if got.Title != "Task" {
+		t.Errorf("Expected title Task, got %s", got.Title)
+	}
+}
+
+func TestUndoRedoUpdate(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Draft")
+	if err := tm.UpdateTask(task.ID, "Final", "Ready", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Title != "Draft" || task.Status != StatusTodo {
+		t.Errorf("Expected update to be reverted in place, got %+v", task)
+	}
+	history, _ := tm.GetTaskHistory(task.ID)
+	if last := history[len(history)-1]; last.Field != "status" || last.NewValue != "todo" {
+		t.Errorf("Expected undo to be recorded in history, got %+v", last)
+	}
+
+	if err := tm.Redo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Title != "Final" || task.Status != StatusDone {
+		t.Errorf("Expected update to be reapplied, got %+v", task)
+	}
+}
+
+func TestUndoDeleteCascade(t *testing.T) {
+	tm := NewTaskManager()
+	parent := mustAddTask(t, tm, "Parent")
+	child := mustAddTask(t, tm, "Child", WithParent(parent.ID))
+
+	if err := tm.DeleteTask(parent.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := tm.GetTask(parent.ID); err != nil {
+		t.Errorf("Expected parent to be restored, got %v", err)
+	}
+	if child.ParentID != parent.ID {
+		t.Errorf("Expected child to be reattached, got parent %d", child.ParentID)
+	}
+	if len(tm.ListTrash()) != 0 {
+		t.Errorf("Expected empty trash after undoing the delete")
+	}
+}
+
+func TestUndoDepthAndRedoInvalidation(t *testing.T) {
+	tm := NewTaskManager(WithUndoDepth(2))
+	for _, title := range []string{"One", "Two", "Three"} {
+		mustAddTask(t, tm, title)
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.Undo(); err != ErrNothingToUndo {
+		t.Errorf("Expected ErrNothingToUndo beyond the configured depth, got %v", err)
+	}
+	if len(tm.ListTasks(nil)) != 1 {
+		t.Errorf("Expected only the first task to remain")
+	}
+
+	mustAddTask(t, tm, "Four")
+	if tm.CanRedo() {
+		t.Error("Expected a new change to discard the redo history")
+	}
+	if err := tm.Redo(); err != ErrNothingToRedo {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestUndoDisabled(t *testing.T) {
+	tm := NewTaskManager(WithUndoDepth(0))
+	mustAddTask(t, tm, "Task")
+	if tm.CanUndo() {
+		t.Error("Expected undo to be disabled")
+	}
+}
@@ -0,0 +1,23 @@
+package taskmanager
+
+import "errors"
+
+// ErrConflict is returned when a task changed since the version the caller
+// expected
+var ErrConflict = errors.New("task was changed by someone else")
+
+// IfVersion makes UpdateTask fail with ErrConflict unless the task is still
+// at the given version. It has no effect when adding a task.
+func IfVersion(version int) TaskOption {
+	return func(t *Task) {
+		t.Version = version
+	}
+}
+
+// fieldsChanged bumps the version and UpdatedAt of a task whose fields
+// changed. Comments have their own IDs and leave the version alone.
+func (tm *TaskManager) fieldsChanged(task *Task) {
+	task.Version++
+	task.UpdatedAt = tm.now()
+	tm.ops.updated.Add(1)
+}
@@ -0,0 +1,83 @@
+package taskmanager
+
+import "testing"
+
+func TestTaskVersion(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Report")
+	if task.Version != 1 {
+		t.Fatalf("Expected a new task at version 1, got %d", task.Version)
+	}
+
+	if err := tm.UpdateTask(task.ID, "Draft", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.AssignTask(task.ID, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tm.AssignTask(task.ID, "alice"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tm.AddComment(task.ID, "bob", "Hi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Version != 3 {
+		t.Errorf("Expected only field changes to bump the version, got %d", task.Version)
+	}
+
+	if err := tm.Undo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if task.Title != "Report" || task.Version != 4 {
+		t.Errorf("Expected undo to restore the title and bump the version, got %q at %d", task.Title, task.Version)
+	}
+}
+
+func TestUpdateTaskIfVersion(t *testing.T) {
+	tm := NewTaskManager()
+	task := mustAddTask(t, tm, "Report")
+	read := task.Version
+
+	tests := []struct {
+		name        string
+		update      func() error
+		expectError error
+	}{
+		{
+			name:   "first client",
+			update: func() error { return tm.UpdateTask(task.ID, "Report v2", "", false, IfVersion(read)) },
+		},
+		{
+			name:        "second client with stale version",
+			update:      func() error { return tm.UpdateTask(task.ID, "Report v3", "", false, IfVersion(read)) },
+			expectError: ErrConflict,
+		},
+		{
+			name:        "stale patch",
+			update:      func() error { return tm.UpdateTaskFields(task.ID, TaskPatch{Title: ptr("v4"), Version: ptr(read)}) },
+			expectError: ErrConflict,
+		},
+		{
+			name: "patch with current version",
+			update: func() error {
+				return tm.UpdateTaskFields(task.ID, TaskPatch{Description: ptr("notes"), Version: ptr(read + 1)})
+			},
+		},
+		{
+			name:   "update without a version",
+			update: func() error { return tm.UpdateTask(task.ID, "Report v5", "", false) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.update(); err != tt.expectError {
+				t.Errorf("Expected %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if task.Title != "Report v5" || task.Version != 4 {
+		t.Errorf("Expected Report v5 at version 4, got %q at %d", task.Title, task.Version)
+	}
+}
@@ -0,0 +1,85 @@
+package taskmanager
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidVisibility is returned when a task visibility is not one of the known values
+	ErrInvalidVisibility = errors.New("invalid task visibility")
+	// ErrPrivateWithoutOwner is returned when a task is made private but has no owner
+	ErrPrivateWithoutOwner = errors.New("private task needs an owner")
+)
+
+// Visibility controls who can see a task
+type Visibility int
+
+const (
+	// VisibilityShared is the default visibility: everyone can see the task
+	VisibilityShared Visibility = iota + 1
+	// VisibilityPrivate limits the task to its owner and assignee
+	VisibilityPrivate
+)
+
+// Valid reports whether the visibility is one of the known values
+func (v Visibility) Valid() bool {
+	return v == VisibilityShared || v == VisibilityPrivate
+}
+
+// String returns a human-readable name for the visibility
+func (v Visibility) String() string {
+	switch v {
+	case VisibilityShared:
+		return "shared"
+	case VisibilityPrivate:
+		return "private"
+	default:
+		return "unknown"
+	}
+}
+
+// WithVisibility sets who can see a task
+func WithVisibility(v Visibility) TaskOption {
+	return func(t *Task) {
+		t.Visibility = v
+	}
+}
+
+// WithOwner sets the user a task belongs to
+func WithOwner(ownerID string) TaskOption {
+	ownerID = strings.TrimSpace(ownerID)
+	return func(t *Task) {
+		t.OwnerID = ownerID
+	}
+}
+
+// VisibleTo reports whether the given user may see the task. Shared tasks
+// are visible to everyone, private ones to their owner and assignee.
+func (t *Task) VisibleTo(userID string) bool {
+	if t.Visibility != VisibilityPrivate {
+		return true
+	}
+	return userID != "" && (userID == t.OwnerID || userID == t.AssigneeID)
+}
+
+// AsUser lists tasks the way the given user sees them, leaving out private
+// tasks of other users. Listings without AsUser are not filtered by
+// visibility.
+func AsUser(userID string) ListOption {
+	userID = strings.TrimSpace(userID)
+	return func(q *listQuery) {
+		q.viewer = &userID
+	}
+}
+
+// validateVisibility checks that a task's visibility can be enforced
+func validateVisibility(task *Task) error {
+	if !task.Visibility.Valid() {
+		return ErrInvalidVisibility
+	}
+	if task.Visibility == VisibilityPrivate && task.OwnerID == "" {
+		return ErrPrivateWithoutOwner
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package taskmanager
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWithVisibility(t *testing.T) {
+	tm := NewTaskManager()
+
+	tests := []struct {
+		name        string
+		opts        []TaskOption
+		expectError error
+	}{
+		{name: "shared by default"},
+		{name: "private with owner", opts: []TaskOption{WithOwner("alice"), WithVisibility(VisibilityPrivate)}},
+		{name: "private without owner", opts: []TaskOption{WithVisibility(VisibilityPrivate)}, expectError: ErrPrivateWithoutOwner},
+		{name: "invalid visibility", opts: []TaskOption{WithVisibility(Visibility(9))}, expectError: ErrInvalidVisibility},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := tm.AddTask("Task", "", tt.opts...)
+			if err != tt.expectError {
+				t.Fatalf("Expected %v, got %v", tt.expectError, err)
+			}
+			if err == nil && len(tt.opts) == 0 && task.Visibility != VisibilityShared {
+				t.Errorf("Expected shared visibility, got %v", task.Visibility)
+			}
+		})
+	}
+}
+
+func TestListAsUser(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm := NewTaskManager(WithClock(func() time.Time { return now }))
+	mustAddTask(t, tm, "Team standup")
+	now = now.Add(time.Minute)
+	mustAddTask(t, tm, "Alice diary", WithOwner("alice"), WithVisibility(VisibilityPrivate))
+	now = now.Add(time.Minute)
+	delegated := mustAddTask(t, tm, "Alice errand", WithOwner("alice"), WithVisibility(VisibilityPrivate))
+	if err := tm.AssignTask(delegated.ID, "bob"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ListOption
+		expected []string
+	}{
+		{name: "owner", opts: []ListOption{AsUser("alice")}, expected: []string{"Team standup", "Alice diary", "Alice errand"}},
+		{name: "assignee", opts: []ListOption{AsUser("bob")}, expected: []string{"Team standup", "Alice errand"}},
+		{name: "other user", opts: []ListOption{AsUser("carol")}, expected: []string{"Team standup"}},
+		{name: "anonymous", opts: []ListOption{AsUser("")}, expected: []string{"Team standup"}},
+		{name: "no identity", expected: []string{"Team standup", "Alice diary", "Alice errand"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tm.ListTasks(nil, tt.opts...)); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package taskmanager
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// walSuffix is added to the path of a WALStore's checkpoint for its log
+const walSuffix = ".wal"
+
+// DefaultCheckpointBytes is how large a WALStore's log grows before the
+// store checkpoints, unless WALOptions says otherwise
+const DefaultCheckpointBytes = 4 << 20
+
+// walHeaderSize is the size of the length and checksum before each record
+const walHeaderSize = 8
+
+// walCRC is the table of the checksum of each log record
+var walCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// walRecord is one record of the log, holding the changes of one save
+type walRecord struct {
+	Put    []*Task `json:"put,omitempty"`
+	Delete []int   `json:"delete,omitempty"`
+	NextID int     `json:"next_id"`
+}
+
+// WALOptions tunes a WALStore
+type WALOptions struct {
+	// CheckpointBytes is how large the log grows before the store writes a
+	// checkpoint and empties it. Zero means DefaultCheckpointBytes.
+	CheckpointBytes int64
+	// CheckpointRecords, when positive, also checkpoints once the log
+	// holds that many records, however small they are
+	CheckpointRecords int
+	// KeepSnapshots is how many checkpoints older than the current one are
+	// kept, numbered from path.1 for the latest. Zero keeps none.
+	KeepSnapshots int
+	// Encryption, when set, encrypts the checkpoints and each record of
+	// the log, as OpenEncryptedFileStore does
+	Encryption *Encryption
+	// Format is the encoding of the checkpoints, as in FileOptions. The
+	// records of the log are always JSON.
+	Format FileFormat
+}
+
+// WALStore keeps the active tasks in memory, as a MemoryStore does, and
+// saves them to a write-ahead log when given to WithPersister as well. It
+// is an IncrementalPersister: the manager records the tasks each write
+// changes, and each save appends one record holding those tasks and the
+// IDs of those that are gone, and syncs it, so a save costs in proportion
+// to what changed rather than to the number of tasks. Once the log reaches
+// WALOptions.CheckpointBytes or CheckpointRecords, the store compacts it:
+// it writes every task to a checkpoint file, as a FileStore does, empties
+// the log and keeps the checkpoint it replaced among the KeepSnapshots
+// older ones. Opening the store loads the checkpoint and replays the log
+// over it. A crash can only damage the record being appended, so a last
+// record that is cut short or fails its checksum is dropped; damage before
+// it fails with ErrCorruptFile rather than dropping the records after it.
+// As with FileStore, only the active tasks are saved.
+type WALStore struct {
+	*FileStore
+	opts WALOptions
+
+	// mu keeps saves from overlapping
+	mu  sync.Mutex
+	log *os.File
+	// size is the length of the log and records the number of records
+	// in it
+	size     int64
+	records  int
+	replayed int
+}
+
+// OpenWALStore loads the checkpoint at path and replays the log next to it,
+// path with a .wal suffix, creating both when they do not exist yet. The
+// store must be closed with Close once the manager is.
+func OpenWALStore(path string, opts WALOptions) (*WALStore, error) {
+	if opts.CheckpointBytes <= 0 {
+		opts.CheckpointBytes = DefaultCheckpointBytes
+	}
+	checkpoint, err := OpenFileStoreWith(path, FileOptions{Encryption: opts.Encryption, Format: opts.Format})
+	if err != nil {
+		return nil, err
+	}
+	log, err := os.OpenFile(path+walSuffix, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s := &WALStore{FileStore: checkpoint, opts: opts, log: log}
+	if err := s.replay(); err != nil {
+		log.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay applies the records of the log to the tasks of the checkpoint and
+// cuts off a last record that was torn
+func (s *WALStore) replay() error {
+	data, err := io.ReadAll(s.log)
+	if err != nil {
+		return err
+	}
+	nextID := s.seq.peek()
+	offset := 0
+	for {
+		record, n, ok, err := decodeWALRecord(data[offset:], s.enc)
+		if err != nil {
+			return fmt.Errorf("%w: %s at byte %d", err, s.path+walSuffix, offset)
+		}
+		if !ok {
+			break
+		}
+		for _, task := range record.Put {
+			s.Put(task)
+			nextID = max(nextID, task.ID+1)
+		}
+		for _, id := range record.Delete {
+			s.Delete(id)
+		}
+		nextID = max(nextID, record.NextID)
+		offset += n
+		s.replayed++
+	}
+	s.records = s.replayed
+	if offset < len(data) {
+		// A torn record would otherwise hide the records appended after it
+		if err := s.log.Truncate(int64(offset)); err != nil {
+			return err
+		}
+	}
+	if _, err := s.log.Seek(int64(offset), io.SeekStart); err != nil {
+		return err
+	}
+	s.size = int64(offset)
+	s.seq = NewSequenceIDs(nextID, 1)
+	return nil
+}
+
+// decodeWALRecord decodes the record at the start of data, decrypting it
+// with enc when it is encrypted, and returns its length, or false when
+// data holds a torn last record: one cut short, or failing its checksum
+// with nothing after it. A record failing its checksum before the end of
+// the log, or that enc cannot decrypt or is not a record once its checksum
+// matches, is an error rather than the end of the log, so the records
+// after it are not cut off.
+func decodeWALRecord(data []byte, enc *Encryption) (walRecord, int, bool, error) {
+	var record walRecord
+	if len(data) < walHeaderSize {
+		return record, 0, false, nil
+	}
+	length := int(binary.BigEndian.Uint32(data))
+	if length > len(data)-walHeaderSize {
+		return record, 0, false, nil
+	}
+	payload := data[walHeaderSize : walHeaderSize+length]
+	if crc32.Checksum(payload, walCRC) != binary.BigEndian.Uint32(data[4:]) {
+		if walHeaderSize+length == len(data) {
+			return record, 0, false, nil
+		}
+		return record, 0, false, fmt.Errorf("%w: checksum mismatch", ErrCorruptFile)
+	}
+	payload, err := enc.open(payload)
+	if err != nil {
+		return record, 0, false, err
+	}
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, 0, false, fmt.Errorf("%w: %v", ErrCorruptFile, err)
+	}
+	return record, walHeaderSize + length, true, nil
+}
+
+// encodeWALRecord frames a record, encrypted with enc when it is not nil,
+// with its length and checksum
+func encodeWALRecord(record walRecord, enc *Encryption) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if payload, err = enc.seal(payload); err != nil {
+		return nil, err
+	}
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	frame = binary.BigEndian.AppendUint32(frame, crc32.Checksum(payload, walCRC))
+	return append(frame, payload...), nil
+}
+
+// Replayed returns the number of log records OpenWALStore replayed
+func (s *WALStore) Replayed() int {
+	return s.replayed
+}
+
+// PersistChanges appends the changes to the log, and writes a checkpoint
+// once the log is large enough
+func (s *WALStore) PersistChanges(changes *Changes) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.appendChanges(changes); err != nil {
+		return err
+	}
+	if s.size >= s.opts.CheckpointBytes || s.opts.CheckpointRecords > 0 && s.records >= s.opts.CheckpointRecords {
+		return s.checkpoint(changes.Snapshot())
+	}
+	return nil
+}
+
+// Persist saves every task of the snapshot, as Checkpoint does
+func (s *WALStore) Persist(snapshot *Snapshot) error {
+	return s.Checkpoint(snapshot)
+}
+
+// Checkpoint writes the snapshot to the checkpoint straight away, whatever
+// the size of the log, and empties the log
+func (s *WALStore) Checkpoint(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.log == nil {
+		return os.ErrClosed
+	}
+	return s.checkpoint(snapshot)
+}
+
+// appendChanges appends a record of the changes to the log and syncs it
+func (s *WALStore) appendChanges(changes *Changes) error {
+	if s.log == nil {
+		return os.ErrClosed
+	}
+	if len(changes.Put) == 0 && len(changes.Delete) == 0 {
+		return nil
+	}
+	record := walRecord{Put: changes.Put, Delete: changes.Delete, NextID: s.seq.peek()}
+	frame, err := encodeWALRecord(record, s.enc)
+	if err != nil {
+		return err
+	}
+	if _, err := s.log.Write(frame); err != nil {
+		// Drop what was written of the record, so the next one follows
+		// the last whole record
+		s.log.Truncate(s.size)
+		s.log.Seek(s.size, io.SeekStart)
+		return err
+	}
+	if err := s.log.Sync(); err != nil {
+		return err
+	}
+	s.size += int64(len(frame))
+	s.records++
+	return nil
+}
+
+// checkpoint writes every task of the snapshot to the checkpoint file,
+// empties the log and rotates the older checkpoints. The records of the
+// log are no newer than the snapshot, so a crash in between leaves records
+// that bring the tasks they hold back to their last saved state, and no
+// saved change is lost.
+func (s *WALStore) checkpoint(snapshot *Snapshot) error {
+	if err := s.FileStore.Persist(snapshot); err != nil {
+		return err
+	}
+	if err := s.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.size, s.records = 0, 0
+	if err := s.log.Sync(); err != nil {
+		return err
+	}
+	return s.rotateSnapshots()
+}
+
+// snapshotPath returns the path of the n-th newest older checkpoint
+func (s *WALStore) snapshotPath(n int) string {
+	return s.path + "." + strconv.Itoa(n)
+}
+
+// rotateSnapshots shifts the older checkpoints up by one, dropping the
+// oldest, and keeps the checkpoint just replaced, which FileStore left as
+// the backup, as the first
+func (s *WALStore) rotateSnapshots() error {
+	keep := s.opts.KeepSnapshots
+	if keep <= 0 {
+		return nil
+	}
+	if err := os.Remove(s.snapshotPath(keep)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for n := keep - 1; n >= 1; n-- {
+		if err := os.Rename(s.snapshotPath(n), s.snapshotPath(n+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	backup := s.path + backupSuffix
+	// The backup is replaced by the next checkpoint, so the snapshot gets
+	// its own link to the file, or a copy where links are not supported
+	err := os.Link(backup, s.snapshotPath(1))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		data, err := os.ReadFile(backup)
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(s.snapshotPath(1), data, "")
+	}
+	syncDir(filepath.Dir(s.path))
+	return nil
+}
+
+// Snapshots returns the paths of the older checkpoints kept, newest first.
+// Each opens with OpenFileStore, or OpenEncryptedFileStore when the store
+// is encrypted.
+func (s *WALStore) Snapshots() []string {
+	var paths []string
+	for n := 1; n <= s.opts.KeepSnapshots; n++ {
+		if _, err := os.Stat(s.snapshotPath(n)); err == nil {
+			paths = append(paths, s.snapshotPath(n))
+		}
+	}
+	return paths
+}
+
+// LogSize returns the length of the log in bytes
+func (s *WALStore) LogSize() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Close closes the log. Saves after Close fail with os.ErrClosed.
+func (s *WALStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.log == nil {
+		return nil
+	}
+	err := s.log.Close()
+	s.log = nil
+	return err
+}
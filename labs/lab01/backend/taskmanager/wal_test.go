@@ -0,0 +1,275 @@
+package taskmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// openWALManager opens the store at path and a manager saving to it, with a
+// fixed clock so tasks compare equal once loaded
+func openWALManager(t *testing.T, path string, opts WALOptions) (*WALStore, *SafeTaskManager) {
+	t.Helper()
+	store, err := OpenWALStore(path, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	return store, NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{Interval: time.Hour}), WithClock(clock))
+}
+
+func TestWALStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, s := openWALManager(t, path, WALOptions{})
+	first, _ := s.AddTask("Write report", "", WithTags("work"))
+	s.Flush()
+	second, _ := s.AddTask("Removed", "")
+	s.Flush()
+	s.DeleteTask(second.ID)
+	s.UpdateTaskFields(first.ID, TaskPatch{Status: ptr(StatusDone)})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no checkpoint before the log is full, got %v", err)
+	}
+
+	reopened, r := openWALManager(t, path, WALOptions{})
+	defer r.Close()
+	if reopened.Replayed() != 3 {
+		t.Errorf("Expected 3 records replayed, got %d", reopened.Replayed())
+	}
+	want, _ := s.GetTask(first.ID)
+	if got, err := r.GetTask(first.ID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+	if _, err := r.GetTask(second.ID); err == nil {
+		t.Error("Expected the deleted task to stay deleted")
+	}
+	if task, _ := r.AddTask("Later", ""); task.ID != 3 {
+		t.Errorf("Expected IDs to carry on at 3, got %d", task.ID)
+	}
+}
+
+func TestWALStoreAppendsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, s := openWALManager(t, path, WALOptions{})
+	defer s.Close()
+	for range 200 {
+		s.AddTask("Task with a long enough title to take some room", "")
+	}
+	s.Flush()
+	full := store.LogSize()
+
+	// A save after one change appends that task alone
+	s.UpdateTaskFields(1, TaskPatch{Status: ptr(StatusDone)})
+	s.Flush()
+	if grown := store.LogSize() - full; grown <= 0 || grown > full/50 {
+		t.Errorf("Expected one task appended, got %d bytes after %d", grown, full)
+	}
+	// A save without changes appends nothing
+	size := store.LogSize()
+	s.Flush()
+	if store.LogSize() != size {
+		t.Errorf("Expected nothing appended, got %d bytes", store.LogSize()-size)
+	}
+}
+
+func TestWALStoreCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, s := openWALManager(t, path, WALOptions{CheckpointBytes: 1024})
+	for i := range 10 {
+		s.AddTask("Task", "", WithPriority(PriorityLow+Priority(i%3)))
+		s.Flush()
+	}
+	if size := store.LogSize(); size >= 1024 {
+		t.Errorf("Expected the log emptied by checkpoints, got %d bytes", size)
+	}
+	s.Close()
+	store.Close()
+
+	reopened, r := openWALManager(t, path, WALOptions{CheckpointBytes: 1024})
+	if got := len(r.ListTasks(nil)); got != 10 {
+		t.Errorf("Expected 10 tasks from the checkpoint and log, got %d", got)
+	}
+	if err := reopened.Checkpoint(r.Snapshot()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reopened.LogSize() != 0 {
+		t.Errorf("Expected an empty log, got %d bytes", reopened.LogSize())
+	}
+	r.Close()
+}
+
+func TestWALStoreCrashes(t *testing.T) {
+	// saved leaves a checkpoint and a log of two records, the second
+	// retitling the first task
+	saved := func(t *testing.T) string {
+		path := filepath.Join(t.TempDir(), "tasks.json")
+		store, s := openWALManager(t, path, WALOptions{})
+		s.AddTask("First", "")
+		store.Checkpoint(s.Snapshot())
+		s.AddTask("Second", "")
+		s.Flush()
+		s.UpdateTaskFields(1, TaskPatch{Title: ptr("Renamed")})
+		s.Close()
+		store.Close()
+		return path
+	}
+
+	tests := []struct {
+		name  string
+		crash func(t *testing.T, path string)
+		want  []string
+	}{
+		{"clean", func(*testing.T, string) {}, []string{"Renamed", "Second"}},
+		{"torn record", func(t *testing.T, path string) {
+			data, _ := os.ReadFile(path + walSuffix)
+			os.WriteFile(path+walSuffix, data[:len(data)-5], 0o644)
+		}, []string{"First", "Second"}},
+		{"damaged last record", func(t *testing.T, path string) {
+			data, _ := os.ReadFile(path + walSuffix)
+			data[len(data)-3] ^= 0xff
+			os.WriteFile(path+walSuffix, data, 0o644)
+		}, []string{"First", "Second"}},
+		{"torn header", func(t *testing.T, path string) {
+			data, _ := os.ReadFile(path + walSuffix)
+			_, n, _, _ := decodeWALRecord(data, nil)
+			os.WriteFile(path+walSuffix, data[:n+3], 0o644)
+		}, []string{"First", "Second"}},
+		{"checkpoint not emptying the log", func(t *testing.T, path string) {
+			store, s := openWALManager(t, path, WALOptions{})
+			log, _ := os.ReadFile(path + walSuffix)
+			store.Checkpoint(s.Snapshot())
+			store.Close()
+			os.WriteFile(path+walSuffix, log, 0o644)
+		}, []string{"Renamed", "Second"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := saved(t)
+			tt.crash(t, path)
+			_, s := openWALManager(t, path, WALOptions{})
+			if got := titles(s.ListTasks(nil)); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			// Records appended after a torn one are replayed too
+			s.AddTask("Third", "")
+			s.Close()
+			_, r := openWALManager(t, path, WALOptions{})
+			if got := titles(r.ListTasks(nil)); !slices.Equal(got, append(tt.want, "Third")) {
+				t.Errorf("Expected %v and Third, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWALStoreDamagedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, s := openWALManager(t, path, WALOptions{})
+	s.AddTask("First", "")
+	s.Flush()
+	s.AddTask("Second", "")
+	s.Close()
+	store.Close()
+
+	// Damage before the last record is not a torn write, so the store does
+	// not open rather than drop the records after it
+	data, _ := os.ReadFile(path + walSuffix)
+	data[walHeaderSize+2] ^= 0xff
+	os.WriteFile(path+walSuffix, data, 0o644)
+	if _, err := OpenWALStore(path, WALOptions{}); !errors.Is(err, ErrCorruptFile) {
+		t.Errorf("Expected ErrCorruptFile, got %v", err)
+	}
+	if got, _ := os.ReadFile(path + walSuffix); !slices.Equal(got, data) {
+		t.Errorf("Expected the log left as it was, got %d bytes of %d", len(got), len(data))
+	}
+}
+
+func TestWALStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	opts := WALOptions{CheckpointRecords: 3, KeepSnapshots: 2}
+	store, s := openWALManager(t, path, opts)
+	for i := range 10 {
+		s.AddTask(fmt.Sprintf("Task %d", i+1), "")
+		s.Flush()
+	}
+	s.Close()
+	store.Close()
+
+	// Checkpoints were written after the 3rd, 6th and 9th saves, and the
+	// two the last one replaced are kept, newest first
+	reopened, r := openWALManager(t, path, opts)
+	defer r.Close()
+	if reopened.Replayed() != 1 {
+		t.Errorf("Expected 1 record left to replay, got %d", reopened.Replayed())
+	}
+	if got := len(r.ListTasks(nil)); got != 10 {
+		t.Errorf("Expected 10 tasks, got %d", got)
+	}
+	snapshots := reopened.Snapshots()
+	if want := []string{path + ".1", path + ".2"}; !slices.Equal(snapshots, want) {
+		t.Fatalf("Expected snapshots %v, got %v", want, snapshots)
+	}
+	for i, want := range []int{6, 3} {
+		old, err := OpenFileStore(snapshots[i])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if old.Len() != want {
+			t.Errorf("Expected snapshot %d to hold %d tasks, got %d", i+1, want, old.Len())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 snapshots kept, got %v", err)
+	}
+}
+
+func TestWALStoreSavesEveryWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := OpenWALStore(path, WALOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer store.Close()
+	clock := func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	s := NewSafeTaskManager(WithTaskStore(store), WithPersister(store, FlushPolicy{Interval: time.Hour}), WithClock(clock), WithUndoDepth(10))
+	defer s.Close()
+	// saved returns the tasks of a store as JSON, in ID order
+	saved := func(store TaskStore) string {
+		tasks := slices.SortedFunc(store.List(), func(a, b *Task) int { return a.ID - b.ID })
+		data, _ := json.Marshal(tasks)
+		return string(data)
+	}
+
+	// Every write records the tasks it changes, so replaying the log
+	// gives back the tasks of the manager after each one
+	for _, name := range slices.Sorted(maps.Keys(safeOps)) {
+		for id := 1; id <= 4; id++ {
+			safeOps[name](s, id)
+			if err := s.Flush(); err != nil {
+				t.Fatalf("%s: Unexpected error: %v", name, err)
+			}
+			replayed, err := OpenWALStore(path, WALOptions{})
+			if err != nil {
+				t.Fatalf("%s: Unexpected error: %v", name, err)
+			}
+			replayed.Close()
+			s.mu.RLock()
+			want := saved(store)
+			s.mu.RUnlock()
+			if got := saved(replayed); got != want {
+				t.Fatalf("%s on task %d: Expected the log to replay to\n%s\ngot\n%s", name, id, want, got)
+			}
+		}
+	}
+}